@@ -0,0 +1,102 @@
+package gonoleks
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// WrapHandler wraps a net/http handler so it can be used as a Gonoleks handler
+// This allows reusing middleware and handlers from the net/http ecosystem
+//
+//	app.GET("/metrics", gonoleks.WrapHandler(promhttp.Handler()))
+func WrapHandler(h http.Handler) handlerFunc {
+	handler := fasthttpadaptor.NewFastHTTPHandler(h)
+	return func(c *Context) {
+		handler(c.requestCtx)
+	}
+}
+
+// WrapHandlerFunc wraps a net/http handler function so it can be used as a Gonoleks handler
+//
+//	app.GET("/legacy", gonoleks.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	    w.Write([]byte("Hello from net/http"))
+//	}))
+func WrapHandlerFunc(f http.HandlerFunc) handlerFunc {
+	return WrapHandler(f)
+}
+
+// NativeHandler exposes the application as a net/http handler
+// It is meant for environments that only speak net/http, such as serverless adapters or httptest,
+// and trades the zero-allocation request path for compatibility
+// All routes must be registered before calling it
+func (g *Gonoleks) NativeHandler() http.Handler {
+	g.setupRouter()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		req.Header.SetMethod(r.Method)
+		req.SetRequestURI(r.URL.RequestURI())
+		req.Header.SetHost(r.Host)
+		for key, values := range r.Header {
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+		if r.Body != nil {
+			reader := io.Reader(r.Body)
+			if g.MaxRequestBodySize > 0 {
+				reader = io.LimitReader(r.Body, int64(g.MaxRequestBodySize)+1)
+			}
+			body, err := io.ReadAll(reader)
+			if err != nil {
+				http.Error(w, fasthttp.StatusMessage(StatusBadRequest), StatusBadRequest)
+				return
+			}
+			if g.MaxRequestBodySize > 0 && len(body) > g.MaxRequestBodySize {
+				http.Error(w, fasthttp.StatusMessage(StatusRequestEntityTooLarge), StatusRequestEntityTooLarge)
+				return
+			}
+			req.SetBody(body)
+		}
+		var remoteAddr net.Addr
+		if addr, err := net.ResolveTCPAddr(NetworkTCP, r.RemoteAddr); err == nil {
+			remoteAddr = addr
+		}
+		var fctx fasthttp.RequestCtx
+		fctx.Init(req, remoteAddr, nil)
+		g.router.Handler(&fctx)
+		// Copy the response back to the net/http writer
+		header := w.Header()
+		for key, value := range fctx.Response.Header.All() {
+			k := string(key)
+			if k == HeaderContentLength {
+				continue
+			}
+			header.Add(k, string(value))
+		}
+		body := fctx.Response.Body()
+		header.Set(HeaderContentLength, strconv.Itoa(len(body)))
+		w.WriteHeader(fctx.Response.StatusCode())
+		if r.Method != MethodHead {
+			_, _ = w.Write(body)
+		}
+	})
+}
+
+// Test serves req in memory through NativeHandler and returns the response,
+// e.g. to run table tests or replay fixtures without a listener
+// All routes must be registered before the first call
+//
+//	resp := app.Test(httptest.NewRequest(gonoleks.MethodGet, "/users/1", nil))
+func (g *Gonoleks) Test(req *http.Request) *http.Response {
+	g.testOnce.Do(func() { g.testHandler = g.NativeHandler() })
+	rec := httptest.NewRecorder()
+	g.testHandler.ServeHTTP(rec, req)
+	return rec.Result()
+}
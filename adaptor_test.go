@@ -0,0 +1,78 @@
+package gonoleks
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestWrapHandler(t *testing.T) {
+	app := New()
+	app.GET("/std", WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderXTest, r.URL.Query().Get("q"))
+		w.WriteHeader(StatusAccepted)
+		_, _ = io.WriteString(w, "from net/http")
+	})))
+	app.POST("/echo", WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	app.setupRouter()
+
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/std?q=value")
+	reqCtx.Request.Header.SetMethod(MethodGet)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusAccepted, reqCtx.Response.StatusCode())
+	assert.Equal(t, "from net/http", string(reqCtx.Response.Body()))
+	assert.Equal(t, "value", string(reqCtx.Response.Header.Peek(HeaderXTest)))
+
+	reqCtx = &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/echo")
+	reqCtx.Request.Header.SetMethod(MethodPost)
+	reqCtx.Request.SetBodyString("payload")
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "payload", string(reqCtx.Response.Body()))
+}
+
+func TestNativeHandler(t *testing.T) {
+	app := New()
+	app.MaxRequestBodySize = 16
+	app.GET("/user/:id", func(c *Context) {
+		c.Header(HeaderXTest, c.GetHeader(HeaderXTest))
+		c.SetCookie("seen", "yes", 0, "/", "", false, false)
+		_ = c.JSON(StatusOK, H{"id": c.Param("id"), "ip": c.RemoteIP()})
+	})
+	app.POST("/echo", func(c *Context) {
+		c.String(StatusCreated, "%s", c.Body())
+	})
+	handler := app.NativeHandler()
+
+	req := httptest.NewRequest(MethodGet, "/user/42", nil)
+	req.Header.Set(HeaderXTest, "header-value")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, StatusOK, rec.Code)
+	assert.Equal(t, MIMEApplicationJSONCharsetUTF8, rec.Header().Get(HeaderContentType))
+	assert.Equal(t, "header-value", rec.Header().Get(HeaderXTest))
+	assert.Contains(t, rec.Header().Get(HeaderSetCookie), "seen=yes")
+	assert.JSONEq(t, `{"id":"42","ip":"192.0.2.1"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(MethodPost, "/echo", strings.NewReader("hello")))
+	assert.Equal(t, StatusCreated, rec.Code)
+	assert.Equal(t, "hello", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(MethodPost, "/echo", strings.NewReader(strings.Repeat("x", 32))))
+	assert.Equal(t, StatusRequestEntityTooLarge, rec.Code, "Bodies above MaxRequestBodySize should be rejected")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(MethodGet, "/missing", nil))
+	assert.Equal(t, StatusNotFound, rec.Code)
+}
@@ -0,0 +1,212 @@
+package gonoleks
+
+import (
+	"cmp"
+	"crypto/subtle"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// AdminConfig defines the config of the admin endpoints
+type AdminConfig struct {
+	// Prefix is the path the admin endpoints are mounted under
+	Prefix string // Default = "/admin"
+
+	// Token is a secret operators send as a bearer token in the Authorization header
+	Token string
+
+	// Middlewares run before every admin endpoint, e.g. an authentication middleware
+	// followed by Authorize, instead of or in addition to Token
+	Middlewares []handlerFunc
+}
+
+// AdminStats is the response of the admin stats endpoint
+type AdminStats struct {
+	Uptime          string          `json:"uptime"`
+	Goroutines      int             `json:"goroutines"`
+	OpenConnections int32           `json:"open_connections"`
+	Concurrency     uint32          `json:"concurrency"`
+	Maintenance     bool            `json:"maintenance"`
+	Draining        bool            `json:"draining"`
+	Version         VersionInfo     `json:"version"`
+	Memory          AdminMemory     `json:"memory"`
+	Templates       []TemplateStats `json:"templates,omitempty"`
+}
+
+// AdminMemory describes the memory use of the process
+type AdminMemory struct {
+	Alloc      uint64 `json:"alloc"`
+	HeapInuse  uint64 `json:"heap_inuse"`
+	Sys        uint64 `json:"sys"`
+	NumGC      uint32 `json:"num_gc"`
+	PauseTotal string `json:"pause_total"`
+}
+
+// processStart is when the process started serving, used for the uptime
+var processStart = time.Now()
+
+// MountAdmin registers the admin endpoints under config.Prefix, for operators to control the
+// running app without building these endpoints for every service
+// Admin requests are never turned away by maintenance mode or the rate limit
+// It panics unless config protects the endpoints with a Token or Middlewares
+//
+//	GET    /admin/routes       route table
+//	GET    /admin/stats        runtime statistics
+//	GET    /admin/config       runtime config, see RuntimeConfig
+//	PATCH  /admin/config       change the log level, rate limit, trusted proxies or maintenance mode
+//	PUT    /admin/maintenance  enable maintenance mode
+//	DELETE /admin/maintenance  disable maintenance mode
+//	POST   /admin/gc           run the garbage collector and return memory to the OS
+//	PUT    /admin/drain        close connections after their current request
+//	DELETE /admin/drain        stop draining
+func (g *Gonoleks) MountAdmin(config AdminConfig) {
+	if config.Prefix == "" {
+		config.Prefix = "/admin"
+	}
+	config.Prefix = "/" + strings.Trim(config.Prefix, "/")
+	g.adminPrefix = config.Prefix
+	g.registerAdmin(g.Group(config.Prefix), config)
+}
+
+// AdminApp returns a separate app serving the admin endpoints of g at config.Prefix,
+// typically "/", to run on a private port
+//
+//	admin := app.AdminApp(gonoleks.AdminConfig{Prefix: "/", Token: os.Getenv("ADMIN_TOKEN")})
+//	go admin.Run("127.0.0.1:9090")
+func (g *Gonoleks) AdminApp(config AdminConfig) *Gonoleks {
+	if config.Prefix == "" {
+		config.Prefix = "/admin"
+	}
+	admin := New()
+	admin.ServerName = g.ServerName
+	admin.SetDiagnosticsLogger(g.diagnostics)
+	g.registerAdmin(admin.Group(strings.TrimSuffix(config.Prefix, "/")), config)
+	return admin
+}
+
+// registerAdmin registers the admin endpoints of g on group
+func (g *Gonoleks) registerAdmin(group *RouterGroup, config AdminConfig) {
+	if config.Token == "" && len(config.Middlewares) == 0 {
+		panic("admin endpoints require a Token or Middlewares")
+	}
+	if config.Token != "" {
+		group.Use(adminToken(config.Token))
+	}
+	group.Use(config.Middlewares...)
+	group.GET("/routes", g.adminRoutes)
+	group.GET("/stats", g.adminStats)
+	group.Match([]string{MethodGet, MethodPatch}, "/config", g.ReloadHandler())
+	group.PUT("/maintenance", func(c *Context) {
+		g.SetMaintenance(true)
+		g.diagnostics.Warn("Maintenance mode enabled", "ip", c.ClientIP())
+		c.Status(StatusNoContent)
+	})
+	group.DELETE("/maintenance", func(c *Context) {
+		g.SetMaintenance(false)
+		g.diagnostics.Info("Maintenance mode disabled", "ip", c.ClientIP())
+		c.Status(StatusNoContent)
+	})
+	group.POST("/gc", g.adminGC)
+	group.PUT("/drain", func(c *Context) {
+		g.draining.Store(true)
+		g.diagnostics.Warn("Draining connections", "ip", c.ClientIP())
+		c.Status(StatusNoContent)
+	})
+	group.DELETE("/drain", func(c *Context) {
+		g.draining.Store(false)
+		g.diagnostics.Info("Stopped draining connections", "ip", c.ClientIP())
+		c.Status(StatusNoContent)
+	})
+}
+
+// adminToken instances a middleware accepting only requests with the bearer token
+func adminToken(token string) handlerFunc {
+	return func(c *Context) {
+		given, ok := strings.CutPrefix(c.GetHeader(HeaderAuthorization), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.requestCtx.Error(fasthttp.StatusMessage(StatusUnauthorized), StatusUnauthorized)
+			c.requestCtx.Response.Header.Set(HeaderWWWAuthenticate, "Bearer")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// adminRoutes answers with the route table
+func (g *Gonoleks) adminRoutes(c *Context) {
+	routes := make([]RouteInfo, 0, len(g.routeIndex))
+	for _, route := range g.routeIndex {
+		info := RouteInfo{Method: route.Method, Path: route.Path, Metadata: route.Metadata}
+		for _, handler := range route.Handlers {
+			info.Handlers = append(info.Handlers, nameOfFunction(handler))
+		}
+		routes = append(routes, info)
+	}
+	slices.SortFunc(routes, func(a, b RouteInfo) int {
+		return cmp.Or(strings.Compare(a.Path, b.Path), strings.Compare(a.Method, b.Method))
+	})
+	_ = c.JSON(StatusOK, routes)
+}
+
+// adminStats answers with the runtime statistics
+func (g *Gonoleks) adminStats(c *Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	stats := AdminStats{
+		Uptime:      time.Since(processStart).Round(time.Second).String(),
+		Goroutines:  runtime.NumGoroutine(),
+		Maintenance: g.liveOptions().maintenance,
+		Draining:    g.draining.Load(),
+		Version:     g.Version(),
+		Memory:      adminMemory(&mem),
+		Templates:   g.TemplateStats(),
+	}
+	if g.httpServer != nil {
+		stats.OpenConnections = g.httpServer.GetOpenConnectionsCount()
+		stats.Concurrency = g.httpServer.GetCurrentConcurrency()
+	}
+	c.requestCtx.Response.Header.Set(HeaderCacheControl, "no-store")
+	_ = c.JSON(StatusOK, stats)
+}
+
+// adminGC runs the garbage collector and answers with the memory use before and after
+func (g *Gonoleks) adminGC(c *Context) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	debug.FreeOSMemory()
+	took := time.Since(start)
+	runtime.ReadMemStats(&after)
+	g.diagnostics.Info("Garbage collection triggered", "ip", c.ClientIP(), "duration", took)
+	_ = c.JSON(StatusOK, H{
+		"duration": took.String(),
+		"before":   adminMemory(&before),
+		"after":    adminMemory(&after),
+	})
+}
+
+// adminMemory extracts the memory statistics shown by the admin endpoints
+func adminMemory(mem *runtime.MemStats) AdminMemory {
+	return AdminMemory{
+		Alloc:      mem.Alloc,
+		HeapInuse:  mem.HeapInuse,
+		Sys:        mem.Sys,
+		NumGC:      mem.NumGC,
+		PauseTotal: time.Duration(mem.PauseTotalNs).String(),
+	}
+}
+
+// isAdminPath reports whether the request targets the admin endpoints mounted by MountAdmin
+func (g *Gonoleks) isAdminPath(c *Context) bool {
+	if g.adminPrefix == "" {
+		return false
+	}
+	path := getString(c.requestCtx.Path())
+	return path == g.adminPrefix || strings.HasPrefix(path, g.adminPrefix+"/")
+}
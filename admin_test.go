@@ -0,0 +1,135 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMountAdmin(t *testing.T) {
+	app := New()
+	app.GET("/users/:id", func(c *Context) { c.String(StatusOK, "ok") })
+	app.MountAdmin(AdminConfig{Token: "s3cret"})
+	app.setupRouter()
+	auth := map[string]string{HeaderAuthorization: "Bearer s3cret"}
+
+	reqCtx := newProxiedRequest(MethodGet, "/admin/routes", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusUnauthorized, reqCtx.Response.StatusCode())
+	reqCtx = newProxiedRequest(MethodGet, "/admin/routes", "203.0.113.5", map[string]string{HeaderAuthorization: "Bearer wrong"})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusUnauthorized, reqCtx.Response.StatusCode())
+
+	reqCtx = newProxiedRequest(MethodGet, "/admin/routes", "203.0.113.5", auth)
+	app.router.Handler(reqCtx)
+	require.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	var routes []RouteInfo
+	require.NoError(t, sonic.Unmarshal(reqCtx.Response.Body(), &routes))
+	paths := make([]string, len(routes))
+	for i, route := range routes {
+		paths[i] = route.Method + " " + route.Path
+	}
+	assert.Contains(t, paths, "GET /users/:id")
+	assert.Contains(t, paths, "PUT /admin/maintenance")
+
+	reqCtx = newProxiedRequest(MethodGet, "/admin/stats", "203.0.113.5", auth)
+	app.router.Handler(reqCtx)
+	require.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	var stats AdminStats
+	require.NoError(t, sonic.Unmarshal(reqCtx.Response.Body(), &stats))
+	assert.Positive(t, stats.Goroutines)
+	assert.Positive(t, stats.Memory.Sys)
+	assert.False(t, stats.Maintenance)
+
+	reqCtx = newProxiedRequest(MethodPost, "/admin/gc", "203.0.113.5", auth)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Contains(t, string(reqCtx.Response.Body()), `"after"`)
+}
+
+func TestMountAdminMaintenanceAndDrain(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	app.MountAdmin(AdminConfig{Prefix: "/ops/", Token: "s3cret"})
+	app.setupRouter()
+	auth := map[string]string{HeaderAuthorization: "Bearer s3cret"}
+
+	reqCtx := newProxiedRequest(MethodPut, "/ops/maintenance", "203.0.113.5", auth)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusNoContent, reqCtx.Response.StatusCode())
+	reqCtx = newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusServiceUnavailable, reqCtx.Response.StatusCode())
+	// Admin endpoints stay reachable during maintenance
+	reqCtx = newProxiedRequest(MethodGet, "/ops/config", "203.0.113.5", auth)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Contains(t, string(reqCtx.Response.Body()), `"maintenance":true`)
+	reqCtx = newProxiedRequest(MethodDelete, "/ops/maintenance", "203.0.113.5", auth)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusNoContent, reqCtx.Response.StatusCode())
+	reqCtx = newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+
+	reqCtx = newProxiedRequest(MethodPut, "/ops/drain", "203.0.113.5", auth)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusNoContent, reqCtx.Response.StatusCode())
+	reqCtx = newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.True(t, reqCtx.Response.ConnectionClose())
+	reqCtx = newProxiedRequest(MethodDelete, "/ops/drain", "203.0.113.5", auth)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusNoContent, reqCtx.Response.StatusCode())
+	reqCtx = newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.False(t, reqCtx.Response.ConnectionClose())
+}
+
+func TestMountAdminRateLimit(t *testing.T) {
+	app := New()
+	app.RateLimit = 1
+	app.MountAdmin(AdminConfig{Prefix: "/ops", Token: "s3cret"})
+	app.setupRouter()
+
+	// Token guesses are throttled like any other request
+	reqCtx := newProxiedRequest(MethodGet, "/ops/config", "203.0.113.5", map[string]string{HeaderAuthorization: "Bearer guess1"})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusUnauthorized, reqCtx.Response.StatusCode())
+	reqCtx = newProxiedRequest(MethodGet, "/ops/config", "203.0.113.5", map[string]string{HeaderAuthorization: "Bearer guess2"})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusTooManyRequests, reqCtx.Response.StatusCode())
+}
+
+func TestAdminApp(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	app.setupRouter()
+
+	policy := Authorize(Policy{Roles: []string{"ops"}})
+	admin := app.AdminApp(AdminConfig{Prefix: "/", Middlewares: []handlerFunc{policy}})
+	admin.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodPut, "/maintenance", "203.0.113.5", nil)
+	admin.router.Handler(reqCtx)
+	assert.Equal(t, StatusUnauthorized, reqCtx.Response.StatusCode())
+	assert.False(t, *app.RuntimeConfig().Maintenance)
+
+	authenticated := app.AdminApp(AdminConfig{Prefix: "/", Middlewares: []handlerFunc{
+		func(c *Context) { c.SetPrincipal(&Principal{ID: "op", Roles: []string{"ops"}}); c.Next() },
+		policy,
+	}})
+	authenticated.setupRouter()
+	reqCtx = newProxiedRequest(MethodPut, "/maintenance", "203.0.113.5", nil)
+	authenticated.router.Handler(reqCtx)
+	assert.Equal(t, StatusNoContent, reqCtx.Response.StatusCode())
+	assert.True(t, *app.RuntimeConfig().Maintenance)
+	reqCtx = newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusServiceUnavailable, reqCtx.Response.StatusCode())
+
+	assert.Panics(t, func() { app.AdminApp(AdminConfig{}) })
+}
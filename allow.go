@@ -0,0 +1,124 @@
+package gonoleks
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/valyala/fasthttp"
+)
+
+// maxAllowCacheEntries bounds the number of cached Allow values for parameterized paths
+const maxAllowCacheEntries = 4096
+
+// allowCache holds precomputed Allow header values
+// Static paths are computed once, parameterized paths are cached on first use up to a limit
+type allowCache struct {
+	static  map[string]string
+	dynamic sync.Map
+	size    atomic.Int64
+}
+
+// allowedMethodOrder is the order in which methods are listed in the Allow header
+// Custom methods follow in alphabetical order
+var allowedMethodOrder = []string{
+	MethodGet, MethodHead, MethodPost, MethodPut, MethodPatch,
+	MethodDelete, MethodConnect, MethodTrace,
+}
+
+// allowed determines which HTTP methods are supported for a given path
+// It returns a comma-separated list of allowed methods for the path, with OPTIONS appended
+func (r *router) allowed(path string, ctx *Context) string {
+	cache := r.allowCache
+	if cache == nil {
+		cache = r.buildAllowCache()
+	}
+	if allow, ok := cache.static[path]; ok {
+		return allow
+	}
+	if allow, ok := cache.dynamic.Load(path); ok {
+		return allow.(string)
+	}
+	allow := r.computeAllowed(path, ctx)
+	// Unmatched paths are not cached, so random 404 paths cannot use up the cache
+	if allow != "" && cache.size.Load() < maxAllowCacheEntries {
+		// The path may alias a request buffer that is reused once the request is served
+		if _, loaded := cache.dynamic.LoadOrStore(strings.Clone(path), allow); !loaded {
+			cache.size.Add(1)
+		}
+	}
+	return allow
+}
+
+// buildAllowCache precomputes the Allow values of all static routes
+func (r *router) buildAllowCache() *allowCache {
+	cache := &allowCache{static: make(map[string]string, len(r.staticRoutes))}
+	scratch := &Context{paramValues: make(map[string]string)}
+	for key := range r.staticRoutes {
+		for method := range r.trees {
+			if path, ok := strings.CutPrefix(key, method); ok && strings.HasPrefix(path, "/") {
+				if _, done := cache.static[path]; !done {
+					cache.static[path] = r.computeAllowed(path, scratch)
+				}
+			}
+		}
+	}
+	cache.static["*"] = r.computeAllowed("*", scratch)
+	r.allowCache = cache
+	return cache
+}
+
+// computeAllowed walks every method tree to find the methods matching path
+func (r *router) computeAllowed(path string, ctx *Context) string {
+	methods := make([]string, 0, len(r.trees))
+	pathLen := len(path)
+	// Handle * and /* requests
+	all := (pathLen == 1 && path[0] == '*') || (pathLen > 1 && path[1] == '*')
+	for method, tree := range r.trees {
+		if method == MethodOptions {
+			continue
+		}
+		if all || tree.matchRoute(path, ctx) != nil {
+			methods = append(methods, method)
+		}
+	}
+	if len(ctx.paramValues) > 0 {
+		clear(ctx.paramValues)
+	}
+	if len(methods) == 0 {
+		return ""
+	}
+	slices.SortFunc(methods, compareMethods)
+	if !all {
+		methods = append(methods, MethodOptions)
+	}
+	return strings.Join(methods, ", ")
+}
+
+// compareMethods orders standard methods by allowedMethodOrder, then custom methods alphabetically
+func compareMethods(a, b string) int {
+	ia, ib := slices.Index(allowedMethodOrder, a), slices.Index(allowedMethodOrder, b)
+	switch {
+	case ia >= 0 && ib >= 0:
+		return ia - ib
+	case ia >= 0:
+		return -1
+	case ib >= 0:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// handleOptions answers an OPTIONS request with the methods allowed for path
+// It returns true if the request was handled, false when no route matches the path
+func (r *router) handleOptions(fctx *fasthttp.RequestCtx, path string, ctx *Context) bool {
+	allow := r.allowed(path, ctx)
+	if allow == "" {
+		return false
+	}
+	fctx.Response.Header.Set(HeaderAllow, allow)
+	fctx.SetStatusCode(StatusNoContent)
+	ctx.handlers = append(ctx.handlers, r.globalMiddleware...)
+	return true
+}
@@ -0,0 +1,109 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestAllowedMethodsCache(t *testing.T) {
+	app := New()
+	handler := func(c *Context) {}
+	app.DELETE("/users/:id", handler)
+	app.GET("/users/:id", handler)
+	app.PUT("/users/:id", handler)
+	app.POST("/users", handler)
+	app.GET("/users", handler)
+	app.Handle("PROPFIND", "/users", handler)
+	app.setupRouter()
+
+	ctx := &Context{paramValues: make(map[string]string)}
+	assert.Equal(t, "GET, POST, PROPFIND, OPTIONS", app.router.allowed("/users", ctx))
+	assert.Equal(t, "GET, PUT, DELETE, OPTIONS", app.router.allowed("/users/42", ctx))
+	assert.Equal(t, "", app.router.allowed("/missing", ctx))
+	assert.Equal(t, "GET, POST, PUT, DELETE, PROPFIND", app.router.allowed("*", ctx))
+	assert.Empty(t, ctx.paramValues)
+
+	cache := app.router.allowCache
+	assert.Equal(t, "GET, POST, PROPFIND, OPTIONS", cache.static["/users"])
+	cached, ok := cache.dynamic.Load("/users/42")
+	assert.True(t, ok)
+	assert.Equal(t, "GET, PUT, DELETE, OPTIONS", cached)
+	assert.Equal(t, int64(1), cache.size.Load())
+	// Unmatched paths are not cached
+	_, ok = cache.dynamic.Load("/missing")
+	assert.False(t, ok)
+
+	// Registering a route invalidates the cache
+	app.router.handle(MethodPatch, "/users/:id", handlersChain{handler})
+	assert.Nil(t, app.router.allowCache)
+	assert.Equal(t, "GET, PUT, PATCH, DELETE, OPTIONS", app.router.allowed("/users/42", ctx))
+}
+
+func TestAllowedMethodsCacheLimit(t *testing.T) {
+	r := &router{app: New()}
+	r.handle(MethodGet, "/items/:id", handlersChain{func(c *Context) {}})
+	cache := r.buildAllowCache()
+	cache.size.Store(maxAllowCacheEntries)
+
+	ctx := &Context{paramValues: make(map[string]string)}
+	assert.Equal(t, "GET, OPTIONS", r.allowed("/items/1", ctx))
+	_, ok := cache.dynamic.Load("/items/1")
+	assert.False(t, ok)
+}
+
+func TestAllowedMethodsCacheKeepAlive(t *testing.T) {
+	app := New()
+	app.HandleMethodNotAllowed = true
+	handler := func(c *Context) {}
+	app.GET("/users/:id", handler)
+	app.DELETE("/users/:id", handler)
+	app.GET("/posts/:id", handler)
+	client := keepAliveClient(t, app)
+
+	assert.Equal(t, StatusMethodNotAllowed, keepAliveRequest(t, client, MethodPost, "/users/1", nil))
+	assert.Equal(t, StatusMethodNotAllowed, keepAliveRequest(t, client, MethodPost, "/posts/1", nil))
+	// Cached keys must not be overwritten by later requests on the same connection
+	cache := app.router.allowCache
+	users, ok := cache.dynamic.Load("/users/1")
+	assert.True(t, ok)
+	assert.Equal(t, "GET, DELETE, OPTIONS", users)
+	posts, ok := cache.dynamic.Load("/posts/1")
+	assert.True(t, ok)
+	assert.Equal(t, "GET, OPTIONS", posts)
+}
+
+func TestHandleOPTIONS(t *testing.T) {
+	app := New()
+	app.HandleOPTIONS = true
+	app.Use(func(c *Context) {
+		c.Header(HeaderAccessControlAllowOrigin, "*")
+		c.Next()
+	})
+	app.GET("/users/:id", func(c *Context) {})
+	app.OPTIONS("/custom", func(c *Context) { c.Status(StatusOK) })
+	app.GET("/custom", func(c *Context) {})
+	app.setupRouter()
+
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.Header.SetMethod(MethodOptions)
+	reqCtx.Request.SetRequestURI("/users/7")
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusNoContent, reqCtx.Response.StatusCode())
+	assert.Equal(t, "GET, OPTIONS", string(reqCtx.Response.Header.Peek(HeaderAllow)))
+	assert.Equal(t, "*", string(reqCtx.Response.Header.Peek(HeaderAccessControlAllowOrigin)))
+
+	// Explicit OPTIONS routes take precedence
+	reqCtx = &fasthttp.RequestCtx{}
+	reqCtx.Request.Header.SetMethod(MethodOptions)
+	reqCtx.Request.SetRequestURI("/custom")
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+
+	reqCtx = &fasthttp.RequestCtx{}
+	reqCtx.Request.Header.SetMethod(MethodOptions)
+	reqCtx.Request.SetRequestURI("/missing")
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusNotFound, reqCtx.Response.StatusCode())
+}
@@ -1,10 +1,13 @@
 package gonoleks
 
 import (
+	"context"
+	"errors"
 	"net"
-	"os"
+	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"charm.land/log/v2"
@@ -41,13 +44,74 @@ type Options struct {
 	// MaxRequestBodySize sets the maximum request body size
 	MaxRequestBodySize int
 
+	// MaxConnsPerIP limits the number of concurrent connections per client IP
+	MaxConnsPerIP int
+
+	// MaxRequestsPerConn closes connections after serving this many requests
+	MaxRequestsPerConn int
+
+	// TCPKeepalive enables TCP keep-alive probes on accepted connections
+	TCPKeepalive bool
+
+	// TCPKeepalivePeriod sets the interval between TCP keep-alive probes
+	TCPKeepalivePeriod time.Duration // Default = 15 seconds
+
+	// SleepWhenConcurrencyLimitsExceeded pauses accepting new connections for this long
+	// when Concurrency is reached, instead of answering them with 503 Service Unavailable right away
+	SleepWhenConcurrencyLimitsExceeded time.Duration
+
+	// MaxIdleWorkerDuration stops workers that stayed idle for longer than this
+	MaxIdleWorkerDuration time.Duration // Default = 10 seconds
+
+	// Debug enables the development aids, DebugErrorPage and CheckResponseSchemas, without setting them one by one
+	// It is never enabled implicitly, not even by Default, and must not be enabled in production
+	Debug bool
+
+	// DebugErrorPage answers panics and 500 responses with an HTML page showing the panic,
+	// its stack trace and source, and the request, to clients preferring HTML
+	// It is enabled by Debug and must not be enabled in production
+	DebugErrorPage bool
+
+	// CheckResponseSchemas validates successful JSON responses of the routes declaring a ResponseSchema
+	// and logs mismatches
+	// It is enabled by Debug and meant for development since it decodes every response
+	CheckResponseSchemas bool
+
+	// TrackInFlight records the requests being served, see InFlight and ShutdownWithContext
+	TrackInFlight bool
+
+	// ShutdownTimeout bounds how long Shutdown waits for in-flight requests
+	ShutdownTimeout time.Duration
+
+	// HeaderReadTimeout bounds the time a client may take to send the request headers,
+	// protecting against slowloris attacks
+	// The body is then read within BodyReadTimeout or ReadTimeout, or within the same deadline when
+	// both are zero, and idle keep-alive connections are closed after it when IdleTimeout is zero
+	HeaderReadTimeout time.Duration
+
+	// BodyReadTimeout bounds the time a client may take to send the request body once the headers
+	// are in, overriding ReadTimeout for the body
+	BodyReadTimeout time.Duration
+
+	// SlowRequestThreshold logs requests whose headers and body took longer than this to read
+	SlowRequestThreshold time.Duration
+
+	// AbortSlowRequests responds with 408 Request Timeout instead of running the handlers
+	// for requests exceeding SlowRequestThreshold
+	AbortSlowRequests bool
+
 	// DisableKeepalive disables keep-alive connections, causing the server to close connections
 	// after sending the first response to the client
 	DisableKeepalive bool
 
-	// GETOnly rejects all non-GET requests when set to true
+	// GETOnly answers requests with methods other than GET, HEAD and OPTIONS with GETOnlyStatus
+	// and an Allow header listing the read-only methods of the route
+	// Use ListenGETOnly instead to restrict a single listener
 	GETOnly bool
 
+	// GETOnlyStatus is the status code of requests rejected by GETOnly, e.g. 403 Forbidden
+	GETOnlyStatus int // Default = 405
+
 	// DisableHeaderNamesNormalizing prevents header name normalization when enabled
 	DisableHeaderNamesNormalizing bool
 
@@ -63,6 +127,12 @@ type Options struct {
 	// CaseInSensitive enables case-insensitive routing
 	CaseInSensitive bool
 
+	// PathNormalization configures how request paths are normalized before routing
+	PathNormalization PathNormalization
+
+	// HeaderHardening configures strict checks rejecting ambiguous request headers, see StrictHeaderHardening
+	HeaderHardening HeaderHardening
+
 	// MaxRouteParams sets the maximum number of route parameters
 	MaxRouteParams int
 
@@ -73,8 +143,72 @@ type Options struct {
 	// but the requested method is not supported; otherwise returns 404
 	HandleMethodNotAllowed bool
 
+	// TrackRouteHits counts requests per static route, see RouteHits and WarmupSnapshot
+	TrackRouteHits bool
+
+	// WarmupSnapshot is a route hit snapshot file written by WriteRouteHits
+	// The hottest WarmupTopN routes in it are preloaded into the route cache at startup,
+	// and it is rewritten on Shutdown when TrackRouteHits is enabled
+	WarmupSnapshot string
+
+	// WarmupTopN is the number of routes preloaded from WarmupSnapshot
+	WarmupTopN int // Default = 100
+
+	// HandleOPTIONS answers OPTIONS requests for paths without an explicit OPTIONS route
+	// with 204 No Content and an Allow header listing the registered methods
+	HandleOPTIONS bool
+
 	// Prefork spawns multiple Go processes listening on the same port when enabled
 	Prefork bool
+
+	// URLSigningKey is the HMAC key used by SignURL and VerifySignedURL
+	URLSigningKey []byte
+
+	// DecodeCharset transcodes request bodies to UTF-8 before binding them, when their Content-Type
+	// declares another charset, e.g. "text/xml; charset=ISO-8859-1" from legacy clients
+	// Bodies in a charset without a decoder fail to bind with 415 Unsupported Media Type,
+	// see RegisterCharset
+	DecodeCharset bool
+
+	// SlowTemplateThreshold logs a warning for every HTML render taking longer, zero disables it
+	// Render counts and durations are always available from TemplateStats
+	SlowTemplateThreshold time.Duration
+
+	// DisableByteRange ignores Range request headers when serving files,
+	// so files are always sent in full
+	DisableByteRange bool
+
+	// Maintenance answers every request with 503 Service Unavailable, see SetMaintenance
+	Maintenance bool
+
+	// RateLimit is the number of requests per second allowed per client IP, zero disables it
+	// Requests over the limit are answered with 429 Too Many Requests, see SetRateLimit
+	// The client IP is the address of the direct peer unless it is one of TrustedProxies
+	RateLimit float64
+
+	// RateLimitBurst is the number of requests a client IP may make at once
+	RateLimitBurst int // Default = RateLimit rounded up
+
+	// RateLimitStore counts requests for the rate limit in a Store shared between instances,
+	// e.g. a RedisStore, so the limit applies to the whole cluster
+	// Requests are counted in fixed windows of RateLimitBurst/RateLimit seconds
+	RateLimitStore Store
+
+	// RateLimitSyncInterval switches the rate limit to an approximate distributed mode,
+	// where requests are counted locally and the counts are pushed to RateLimitStore in the
+	// background at this interval, so requests never wait for the store
+	// The cluster may exceed the limit by what its instances admit within one interval
+	RateLimitSyncInterval time.Duration
+
+	// RateLimitLocalBurst is the number of requests of a client IP an instance admits between
+	// two syncs with RateLimitStore, bounding how far the cluster may exceed the limit
+	// It also bounds what each instance admits per window while the store is unreachable
+	RateLimitLocalBurst int // Default = RateLimitBurst
+
+	// TrustedProxies lists the IP addresses and CIDR ranges of proxies whose
+	// forwarding headers, e.g. X-Forwarded-Proto, are honored
+	// When empty, the scheme, host and rate limit ignore forwarding headers, while ClientIP honors them from any peer
+	TrustedProxies []string
 }
 
 // Gonoleks is the main struct for the application
@@ -87,8 +221,45 @@ type Gonoleks struct {
 	registeredRoutes []*Route
 	middlewares      handlersChain
 	Options
-	enableStartupMessage bool
-	enableLogging        bool
+	enableStartupMessage     bool
+	startup                  StartupConfig
+	version                  *VersionInfo
+	enableLogging            bool
+	trustedProxies           atomic.Pointer[trustedProxySet]
+	live                     atomic.Pointer[liveOptions]
+	liveMu                   sync.Mutex
+	rateLimiter              ipRateLimiter
+	syncedRateLimiter        syncedRateLimiter
+	adminPrefix              string
+	draining                 atomic.Bool
+	clientIPResolver         ClientIPResolver
+	fallbackMiddlewares      handlersChain
+	customMethods            []string
+	warmupRoutes             []string
+	connStarts               sync.Map
+	connHooks                connHooks
+	inFlight                 sync.Map
+	inFlightCount            atomic.Int64
+	featureFlags             FeatureFlags
+	deprecations             []*routeDeprecation
+	deprecationsMu           sync.Mutex
+	methodRestrictedPrefixes []string
+	getOnlyListeners         []net.Listener
+	getOnlyServers           []*fasthttp.Server
+	namedHandlers            map[string]handlerFunc
+	bindings                 map[string]Binding
+	templateEngine           TemplateEngine
+	namedRoutes              map[string]*Route
+	services                 *serviceContainer
+	paramRules               []paramRule
+	testOnce                 sync.Once
+	testHandler              http.Handler
+	clock                    Clock
+	randSeed                 *uint64
+	templateStats            sync.Map // Template name -> *templateStat
+	routeIndex               map[string]*Route
+	bans                     banList
+	diagnostics              *log.Logger
 }
 
 // Route struct stores information about a registered HTTP route
@@ -96,6 +267,9 @@ type Route struct {
 	Method   string
 	Path     string
 	Handlers handlersChain
+	// Metadata holds arbitrary values attached to the route, e.g. by Register
+	Metadata map[string]any
+	app      *Gonoleks
 }
 
 // tlsConfig holds TLS configuration for HTTPS servers
@@ -122,6 +296,7 @@ func createInstance(debugMode bool) *Gonoleks {
 		enableStartupMessage: debugMode,
 		enableLogging:        debugMode,
 		secureJsonPrefix:     "while(1);",
+		diagnostics:          defaultDiagnostics.With(),
 		Options:              defaultOptions(),
 	}
 	// Initialize the embedded RouteHandler
@@ -134,6 +309,7 @@ func createInstance(debugMode bool) *Gonoleks {
 		pool: sync.Pool{
 			New: func() any {
 				return &Context{
+					app:         g,
 					paramValues: make(map[string]string, 4),
 					handlers:    make(handlersChain, 0, 6),
 					index:       -1,
@@ -186,6 +362,9 @@ func (g *Gonoleks) RunTLS(addr, certFile, keyFile string) error {
 
 // prepareServer prepares the server for running by setting up the router and recreating the HTTP server
 func (g *Gonoleks) prepareServer(addr string) (string, string) {
+	if addr == "" {
+		g.diagnostics.Warnf("Empty port format, using default port %s", defaultPort)
+	}
 	address := resolveAddress(addr)
 	networkProtocol := detectNetworkProtocol(address)
 	g.setupRouter()
@@ -196,14 +375,15 @@ func (g *Gonoleks) prepareServer(addr string) (string, string) {
 
 // runServer runs the server in standard mode
 func (g *Gonoleks) runServer(address, networkProtocol string, tlsConfig *tlsConfig) error {
-	listener, err := net.Listen(networkProtocol, address)
+	listener, err := g.listen(networkProtocol, address)
 	if err != nil {
 		return err
 	}
 	g.address = address
 	if g.enableStartupMessage {
-		g.printStartupMessage(address)
+		g.printStartupMessage(address, tlsConfig != nil)
 	}
+	g.serveGETOnlyListeners(tlsConfig)
 	if tlsConfig != nil {
 		return g.httpServer.ServeTLS(listener, tlsConfig.certFile, tlsConfig.keyFile)
 	}
@@ -213,7 +393,10 @@ func (g *Gonoleks) runServer(address, networkProtocol string, tlsConfig *tlsConf
 // runWithPrefork runs the server in prefork mode
 func (g *Gonoleks) runWithPrefork(address, networkProtocol string, tlsConfig *tlsConfig) error {
 	if g.enableStartupMessage {
-		g.printStartupMessage(address)
+		g.printStartupMessage(address, tlsConfig != nil)
+	}
+	if len(g.getOnlyListeners) > 0 {
+		g.diagnostics.Warn("GET-only listeners are not served in prefork mode", "listeners", len(g.getOnlyListeners))
 	}
 	pf := prefork.New(g.httpServer)
 	pf.Reuseport = true
@@ -226,35 +409,49 @@ func (g *Gonoleks) runWithPrefork(address, networkProtocol string, tlsConfig *tl
 
 // newHTTPServer creates and configures a new fasthttp server instance
 func (g *Gonoleks) newHTTPServer() *fasthttp.Server {
-	return &fasthttp.Server{
-		Handler:                       g.router.Handler,
-		Name:                          g.ServerName,
-		Concurrency:                   g.Concurrency,
-		ReadBufferSize:                g.ReadBufferSize,
-		WriteBufferSize:               g.WriteBufferSize,
-		ReadTimeout:                   g.ReadTimeout,
-		WriteTimeout:                  g.WriteTimeout,
-		IdleTimeout:                   g.IdleTimeout,
-		MaxRequestBodySize:            g.MaxRequestBodySize,
-		DisableKeepalive:              g.DisableKeepalive,
-		ReduceMemoryUsage:             true,
-		GetOnly:                       g.GETOnly,
-		DisableHeaderNamesNormalizing: g.DisableHeaderNamesNormalizing,
-		NoDefaultServerHeader:         g.DisableDefaultServerHeader,
-		NoDefaultDate:                 g.DisableDefaultDate,
-		NoDefaultContentType:          g.DisableDefaultContentType,
+	server := &fasthttp.Server{
+		Handler:                            g.router.Handler,
+		Name:                               g.ServerName,
+		Concurrency:                        g.Concurrency,
+		ReadBufferSize:                     g.ReadBufferSize,
+		WriteBufferSize:                    g.WriteBufferSize,
+		ReadTimeout:                        g.ReadTimeout,
+		WriteTimeout:                       g.WriteTimeout,
+		IdleTimeout:                        g.IdleTimeout,
+		MaxRequestBodySize:                 g.MaxRequestBodySize,
+		MaxConnsPerIP:                      g.MaxConnsPerIP,
+		MaxRequestsPerConn:                 g.MaxRequestsPerConn,
+		TCPKeepalive:                       g.TCPKeepalive,
+		TCPKeepalivePeriod:                 g.TCPKeepalivePeriod,
+		SleepWhenConcurrencyLimitsExceeded: g.SleepWhenConcurrencyLimitsExceeded,
+		MaxIdleWorkerDuration:              g.MaxIdleWorkerDuration,
+		DisableKeepalive:                   g.DisableKeepalive,
+		ReduceMemoryUsage:                  true,
+		DisableHeaderNamesNormalizing:      g.DisableHeaderNamesNormalizing,
+		NoDefaultServerHeader:              g.DisableDefaultServerHeader,
+		NoDefaultDate:                      g.DisableDefaultDate,
+		NoDefaultContentType:               g.DisableDefaultContentType,
 	}
+	g.configureConnLimits(server)
+	return server
 }
 
 // registerRoute adds a new route with the specified method, path, and handlers
 func (g *Gonoleks) registerRoute(method, path string, handlers handlersChain) *Route {
+	if g.PathNormalization.Unicode != nil {
+		path = g.PathNormalization.Unicode(path)
+	}
 	if g.CaseInSensitive {
 		path = strings.ToLower(path)
 	}
+	if g.GETOnly && !isGETOnlyMethod(method) {
+		g.diagnostics.Warn("Route is unreachable because GETOnly rejects its method", "method", method, "path", path)
+	}
 	route := &Route{
 		Path:     path,
 		Method:   method,
 		Handlers: handlers,
+		app:      g,
 	}
 	// Add route to registered routes
 	g.registeredRoutes = append(g.registeredRoutes, route)
@@ -263,26 +460,77 @@ func (g *Gonoleks) registerRoute(method, path string, handlers handlersChain) *R
 
 // setupRouter initializes the router with all registered routes
 func (g *Gonoleks) setupRouter() {
+	g.setTrustedProxies(g.TrustedProxies)
+	g.liveMu.Lock()
+	live := g.liveOptions()
+	g.live.Store(&live)
+	g.liveMu.Unlock()
 	// Store global middlewares in router before clearing them
-	g.router.globalMiddleware = make(handlersChain, len(g.middlewares))
-	copy(g.router.globalMiddleware, g.middlewares)
+	// They wrap NoRoute and NoMethod chains unless FallbackMiddleware chose others
+	fallback := g.middlewares
+	if g.fallbackMiddlewares != nil {
+		fallback = g.fallbackMiddlewares
+	}
+	g.router.globalMiddleware = make(handlersChain, len(fallback))
+	copy(g.router.globalMiddleware, fallback)
+	if g.routeIndex == nil {
+		g.routeIndex = make(map[string]*Route, len(g.registeredRoutes))
+	}
 	for _, route := range g.registeredRoutes {
 		g.router.handle(route.Method, route.Path, route.Handlers)
+		g.routeIndex[route.Method+" "+route.Path] = route
 	}
+	g.router.buildAllowCache()
+	g.warmupRouter()
 	g.registeredRoutes = nil
 	g.middlewares = nil
 }
 
 // Shutdown gracefully shuts down the server
+// It waits for in-flight requests up to ShutdownTimeout, or indefinitely when it is zero
 func (g *Gonoleks) Shutdown() error {
-	err := g.httpServer.Shutdown()
-	if err == nil && g.address != "" {
-		log.Infof("%s stopped listening on %s", g.ServerName, g.address)
-		return nil
+	ctx := context.Background()
+	if g.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.ShutdownTimeout)
+		defer cancel()
 	}
+	_, err := g.ShutdownWithContext(ctx)
 	return err
 }
 
+// ShutdownWithContext gracefully shuts down the server, waiting for in-flight requests
+// until ctx is done
+// With TrackInFlight enabled, the returned report lists the requests that were
+// running when shutdown began and the ones still stuck when ctx expired
+func (g *Gonoleks) ShutdownWithContext(ctx context.Context) (ShutdownReport, error) {
+	report := ShutdownReport{Started: time.Now()}
+	if g.TrackInFlight {
+		report.InFlight = g.InFlight()
+		logInFlight(g.diagnostics, "Shutting down with in-flight requests", report.InFlight)
+	}
+	g.shutdownGETOnlyServers(ctx)
+	err := g.httpServer.ShutdownWithContext(ctx)
+	report.Duration = time.Since(report.Started)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		report.TimedOut = true
+		if g.TrackInFlight {
+			report.Stuck = g.InFlight()
+			logInFlight(g.diagnostics, "Shutdown deadline exceeded with stuck requests", report.Stuck)
+		}
+	}
+	if g.TrackRouteHits && g.WarmupSnapshot != "" {
+		if err := g.saveWarmupSnapshot(); err != nil {
+			g.diagnostics.Warn("Failed to write route warmup snapshot", "file", g.WarmupSnapshot, "error", err)
+		}
+	}
+	if err == nil && g.address != "" {
+		g.diagnostics.Infof("%s stopped listening on %s", g.ServerName, g.address)
+		return report, nil
+	}
+	return report, err
+}
+
 // Use registers global middleware functions to be executed for all routes
 func (g *Gonoleks) Use(middlewares ...handlerFunc) IRoutes {
 	g.middlewares = append(g.middlewares, middlewares...)
@@ -300,6 +548,18 @@ func (g *Gonoleks) NoMethod(handlers ...handlerFunc) {
 	g.router.noMethod = handlers
 }
 
+// FallbackMiddleware chooses the middlewares that wrap the NoRoute and NoMethod chains,
+// including the default 404 and 405 responses
+// By default all global middlewares registered with Use apply, calling it without
+// arguments runs the fallback chains without any of them
+//
+//	app.Use(gonoleks.Recovery(), auth, gonoleks.Logger())
+//	app.FallbackMiddleware(gonoleks.Recovery(), gonoleks.Logger())
+func (g *Gonoleks) FallbackMiddleware(middlewares ...handlerFunc) {
+	g.fallbackMiddlewares = make(handlersChain, 0, len(middlewares))
+	g.fallbackMiddlewares = append(g.fallbackMiddlewares, middlewares...)
+}
+
 // SecureJsonPrefix sets the secureJSONPrefix used in Context.SecureJSON
 func (g *Gonoleks) SecureJsonPrefix(prefix string) {
 	g.secureJsonPrefix = prefix
@@ -310,13 +570,3 @@ func (g *Gonoleks) SecureJsonPrefix(prefix string) {
 func (g *Gonoleks) HandleContext(c *Context) {
 	g.router.Handler(c.requestCtx)
 }
-
-// printStartupMessage displays server startup information in the console
-func (g *Gonoleks) printStartupMessage(addr string) {
-	if prefork.IsChild() {
-		log.Info("Worker process started", "pid", os.Getpid())
-	} else {
-		port := addr[strings.LastIndex(addr, ":"):]
-		log.Infof("%s started on %s", g.ServerName, port)
-	}
-}
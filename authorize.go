@@ -0,0 +1,141 @@
+package gonoleks
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// principalKey is the user value key under which the authenticated principal is stored
+const principalKey = "gonoleksPrincipal"
+
+// Principal describes the authenticated identity of the current request
+// It is set by authentication middleware and consumed by Authorize
+type Principal struct {
+	// ID uniquely identifies the principal (user id, client id, etc.)
+	ID string
+
+	// Roles lists the roles granted to the principal
+	Roles []string
+
+	// Permissions lists the permissions granted to the principal
+	// A permission may end with "*" to match any permission sharing its prefix,
+	// e.g. "posts:*" grants "posts:read" and "posts:write", while "*" grants everything
+	Permissions []string
+
+	// Attributes holds arbitrary data used by attribute-based decisions
+	Attributes map[string]any
+}
+
+// DecisionFunc makes a custom authorization decision for the given principal
+// It allows plugging in attribute-based policies or external engines such as OPA
+type DecisionFunc func(c *Context, p *Principal) bool
+
+// Policy declares the requirements a principal must satisfy to access a route
+type Policy struct {
+	// Roles grants access when the principal has at least one of the listed roles
+	Roles []string
+
+	// Permissions grants access only when the principal has all the listed permissions
+	Permissions []string
+
+	// Decide is consulted after the role and permission checks have passed
+	Decide DecisionFunc
+}
+
+// SetPrincipal stores the authenticated principal for the current request
+// Authentication middleware should call it before Authorize runs
+func (c *Context) SetPrincipal(p *Principal) {
+	c.requestCtx.SetUserValue(principalKey, p)
+}
+
+// Principal returns the authenticated principal of the current request, or nil if none was set
+func (c *Context) Principal() *Principal {
+	p, _ := c.requestCtx.UserValue(principalKey).(*Principal)
+	return p
+}
+
+// CurrentUser returns the signed in user of the current request, e.g. the principal resolved by
+// OAuth2Config.OnToken, or nil if none was set
+func (c *Context) CurrentUser() *Principal {
+	return c.Principal()
+}
+
+// HasRole reports whether the principal has the given role
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// HasPermission reports whether the principal has the given permission,
+// taking wildcard permissions into account
+func (p *Principal) HasPermission(permission string) bool {
+	for _, granted := range p.Permissions {
+		if matchPermission(granted, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether the policy grants access to the principal
+func (pol Policy) Allows(c *Context, p *Principal) bool {
+	if p == nil {
+		return false
+	}
+	if len(pol.Roles) > 0 {
+		hasRole := false
+		for _, role := range pol.Roles {
+			if p.HasRole(role) {
+				hasRole = true
+				break
+			}
+		}
+		if !hasRole {
+			return false
+		}
+	}
+	for _, permission := range pol.Permissions {
+		if !p.HasPermission(permission) {
+			return false
+		}
+	}
+	if pol.Decide != nil {
+		return pol.Decide(c, p)
+	}
+	return true
+}
+
+// Authorize returns a middleware that enforces the given policy
+// Requests without a principal are rejected with 401 Unauthorized,
+// requests whose principal does not satisfy the policy with 403 Forbidden
+//
+//	admin := app.Group("/admin", auth, gonoleks.Authorize(gonoleks.Policy{Roles: []string{"admin"}}))
+func Authorize(policy Policy) handlerFunc {
+	return func(c *Context) {
+		p := c.Principal()
+		if p == nil {
+			c.requestCtx.Error(fasthttp.StatusMessage(StatusUnauthorized), StatusUnauthorized)
+			c.Abort()
+			return
+		}
+		if !policy.Allows(c, p) {
+			c.requestCtx.Error(fasthttp.StatusMessage(StatusForbidden), StatusForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// matchPermission checks a granted permission against a required one
+// A trailing "*" in the granted permission matches any suffix
+func matchPermission(granted, required string) bool {
+	if granted == required {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(granted, "*"); ok {
+		return strings.HasPrefix(required, prefix)
+	}
+	return false
+}
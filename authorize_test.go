@@ -0,0 +1,87 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestPrincipalChecks(t *testing.T) {
+	p := &Principal{
+		ID:          "42",
+		Roles:       []string{"editor"},
+		Permissions: []string{"posts:*", "comments:read"},
+	}
+
+	assert.True(t, p.HasRole("editor"))
+	assert.False(t, p.HasRole("admin"))
+	assert.True(t, p.HasPermission("posts:write"), "Wildcard permission should match")
+	assert.True(t, p.HasPermission("comments:read"))
+	assert.False(t, p.HasPermission("comments:write"))
+
+	admin := &Principal{Permissions: []string{"*"}}
+	assert.True(t, admin.HasPermission("anything:at:all"), "Global wildcard should match everything")
+}
+
+func TestPolicyAllows(t *testing.T) {
+	ctx, _ := createTestContext()
+	p := &Principal{
+		Roles:       []string{"editor"},
+		Permissions: []string{"posts:read"},
+		Attributes:  map[string]any{"department": "news"},
+	}
+
+	assert.True(t, Policy{}.Allows(ctx, p), "Empty policy should allow any principal")
+	assert.False(t, Policy{}.Allows(ctx, nil), "Nil principal should never be allowed")
+	assert.True(t, Policy{Roles: []string{"admin", "editor"}}.Allows(ctx, p))
+	assert.False(t, Policy{Roles: []string{"admin"}}.Allows(ctx, p))
+	assert.False(t, Policy{Permissions: []string{"posts:read", "posts:write"}}.Allows(ctx, p))
+
+	abac := Policy{
+		Permissions: []string{"posts:read"},
+		Decide: func(c *Context, p *Principal) bool {
+			return p.Attributes["department"] == "news"
+		},
+	}
+	assert.True(t, abac.Allows(ctx, p))
+	p.Attributes["department"] = "sports"
+	assert.False(t, abac.Allows(ctx, p))
+}
+
+func TestAuthorizeMiddleware(t *testing.T) {
+	app := New()
+	authenticate := func(c *Context) {
+		switch c.GetHeader(HeaderAuthorization) {
+		case "admin":
+			c.SetPrincipal(&Principal{ID: "1", Roles: []string{"admin"}})
+		case "user":
+			c.SetPrincipal(&Principal{ID: "2", Roles: []string{"user"}})
+		}
+		c.Next()
+	}
+	admin := app.Group("/admin", authenticate, Authorize(Policy{Roles: []string{"admin"}}))
+	admin.GET("/dashboard", func(c *Context) {
+		c.String(StatusOK, "welcome %s", c.Principal().ID)
+	})
+	app.setupRouter()
+
+	tests := []struct {
+		authorization string
+		expectedCode  int
+	}{
+		{"", StatusUnauthorized},
+		{"user", StatusForbidden},
+		{"admin", StatusOK},
+	}
+	for _, tt := range tests {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI("/admin/dashboard")
+		reqCtx.Request.Header.SetMethod(MethodGet)
+		if tt.authorization != "" {
+			reqCtx.Request.Header.Set(HeaderAuthorization, tt.authorization)
+		}
+		app.router.Handler(reqCtx)
+		assert.Equal(t, tt.expectedCode, reqCtx.Response.StatusCode(), "Unexpected status for %q", tt.authorization)
+	}
+}
@@ -0,0 +1,175 @@
+package gonoleks
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// BannedIP is an entry of the ban list
+type BannedIP struct {
+	// Prefix is the banned address or network, e.g. "203.0.113.7/32" or "198.51.100.0/24"
+	Prefix netip.Prefix
+
+	// Until is when the ban expires, zero for a permanent ban
+	Until time.Time
+}
+
+// banList holds the banned prefixes, keyed by their masked form
+type banList struct {
+	mu      sync.RWMutex
+	entries map[netip.Prefix]time.Time
+	size    atomic.Int32
+}
+
+// BanIP bans an IP address or CIDR network for duration, or permanently when it is zero
+// Connections from a banned peer are closed as soon as they are accepted, and requests whose
+// client IP is banned, e.g. behind a trusted proxy, are answered with 403 Forbidden before any
+// middleware runs
+// Banning an address again replaces its expiry
+//
+//	app.BanIP("203.0.113.7", time.Hour)
+//	app.BanIP("198.51.100.0/24", 0)
+func (g *Gonoleks) BanIP(ip string, duration time.Duration) error {
+	prefix, err := parseBanPrefix(ip)
+	if err != nil {
+		return err
+	}
+	var until time.Time
+	if duration > 0 {
+		until = g.now().Add(duration)
+	}
+	b := &g.bans
+	b.mu.Lock()
+	if b.entries == nil {
+		b.entries = make(map[netip.Prefix]time.Time)
+	}
+	b.entries[prefix] = until
+	b.size.Store(int32(len(b.entries)))
+	b.mu.Unlock()
+	return nil
+}
+
+// UnbanIP lifts the ban of an IP address or CIDR network previously passed to BanIP
+// It reports whether a ban was lifted
+func (g *Gonoleks) UnbanIP(ip string) bool {
+	prefix, err := parseBanPrefix(ip)
+	if err != nil {
+		return false
+	}
+	b := &g.bans
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.entries[prefix]; !ok {
+		return false
+	}
+	delete(b.entries, prefix)
+	b.size.Store(int32(len(b.entries)))
+	return true
+}
+
+// BannedIPs returns the active bans ordered by prefix
+func (g *Gonoleks) BannedIPs() []BannedIP {
+	b := &g.bans
+	now := g.now()
+	b.mu.RLock()
+	bans := make([]BannedIP, 0, len(b.entries))
+	for prefix, until := range b.entries {
+		if until.IsZero() || now.Before(until) {
+			bans = append(bans, BannedIP{Prefix: prefix, Until: until})
+		}
+	}
+	b.mu.RUnlock()
+	slices.SortFunc(bans, func(a, b BannedIP) int {
+		if c := a.Prefix.Addr().Compare(b.Prefix.Addr()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Prefix.Bits(), b.Prefix.Bits())
+	})
+	return bans
+}
+
+// isBanned reports whether ip is covered by an active ban, dropping expired bans on the way
+func (g *Gonoleks) isBanned(ip net.IP) bool {
+	b := &g.bans
+	if b.size.Load() == 0 {
+		return false
+	}
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	now := g.now()
+	var expired []netip.Prefix
+	banned := false
+	b.mu.RLock()
+	for prefix, until := range b.entries {
+		if !prefix.Contains(addr) {
+			continue
+		}
+		if !until.IsZero() && !now.Before(until) {
+			expired = append(expired, prefix)
+			continue
+		}
+		banned = true
+		break
+	}
+	b.mu.RUnlock()
+	if len(expired) > 0 {
+		b.mu.Lock()
+		for _, prefix := range expired {
+			if until, ok := b.entries[prefix]; ok && !until.IsZero() && !now.Before(until) {
+				delete(b.entries, prefix)
+			}
+		}
+		b.size.Store(int32(len(b.entries)))
+		b.mu.Unlock()
+	}
+	return banned
+}
+
+// rejectBanned answers requests from banned clients with 403 Forbidden
+// It returns true if the request was rejected
+func (g *Gonoleks) rejectBanned(c *Context) bool {
+	if g.bans.size.Load() == 0 {
+		return false
+	}
+	fctx := c.requestCtx
+	if !g.isBanned(fctx.RemoteIP()) && !g.isBanned(net.ParseIP(c.ClientIP())) {
+		return false
+	}
+	fctx.Error(fasthttp.StatusMessage(StatusForbidden), StatusForbidden)
+	fctx.SetConnectionClose()
+	c.diagnostics().Debug("Request from banned IP rejected", "ip", c.ClientIP(), "path", string(fctx.Path()))
+	return true
+}
+
+// parseBanPrefix parses an IP address or CIDR network into a masked prefix
+func parseBanPrefix(ip string) (netip.Prefix, error) {
+	if strings.Contains(ip, "/") {
+		prefix, err := netip.ParsePrefix(ip)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid network %q: %w", ip, err)
+		}
+		addr, bits := prefix.Addr(), prefix.Bits()
+		if addr.Is4In6() {
+			addr, bits = addr.Unmap(), max(bits-96, 0)
+		}
+		return netip.PrefixFrom(addr, bits).Masked(), nil
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP address %q: %w", ip, err)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
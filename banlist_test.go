@@ -0,0 +1,84 @@
+package gonoleks
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestBanIP(t *testing.T) {
+	app := New()
+	app.TrustedProxies = []string{"10.0.0.0/8"}
+	app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	app.setupRouter()
+
+	require.NoError(t, app.BanIP("203.0.113.7", 0))
+	require.NoError(t, app.BanIP("198.51.100.0/24", time.Hour))
+	require.Error(t, app.BanIP("not-an-ip", 0))
+
+	tests := []struct {
+		name     string
+		remoteIP string
+		headers  map[string]string
+		code     int
+	}{
+		{"banned address", "203.0.113.7", nil, StatusForbidden},
+		{"banned network", "198.51.100.42", nil, StatusForbidden},
+		{"banned client behind trusted proxy", "10.0.0.1", map[string]string{HeaderXForwardedFor: "198.51.100.9"}, StatusForbidden},
+		{"spoofed header from untrusted peer", "192.0.2.1", map[string]string{HeaderXForwardedFor: "203.0.113.7"}, StatusOK},
+		{"allowed address", "192.0.2.1", nil, StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqCtx := newProxiedRequest(MethodGet, "/", tt.remoteIP, tt.headers)
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.code, reqCtx.Response.StatusCode())
+		})
+	}
+
+	bans := app.BannedIPs()
+	require.Len(t, bans, 2)
+	assert.Equal(t, "198.51.100.0/24", bans[0].Prefix.String())
+	assert.False(t, bans[0].Until.IsZero())
+	assert.Equal(t, "203.0.113.7/32", bans[1].Prefix.String())
+	assert.True(t, bans[1].Until.IsZero())
+
+	assert.True(t, app.UnbanIP("203.0.113.7"))
+	assert.False(t, app.UnbanIP("203.0.113.7"))
+	reqCtx := newProxiedRequest(MethodGet, "/", "203.0.113.7", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+}
+
+func TestBanIPExpiry(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	app := New()
+	app.SetClock(clock)
+	require.NoError(t, app.BanIP("203.0.113.7", time.Minute))
+	assert.True(t, app.isBanned(net.ParseIP("203.0.113.7")))
+	clock.Advance(time.Minute)
+
+	assert.False(t, app.isBanned(net.ParseIP("203.0.113.7")))
+	assert.Empty(t, app.BannedIPs())
+	assert.Zero(t, app.bans.size.Load(), "expired bans should be dropped")
+}
+
+func TestBanIPClosesConnections(t *testing.T) {
+	app := New()
+	require.NoError(t, app.BanIP("127.0.0.1", 0))
+	server := app.newHTTPServer()
+	server.Handler = func(fctx *fasthttp.RequestCtx) { fctx.SetStatusCode(StatusOK) }
+
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(ln) }()
+	defer func() { _ = server.Shutdown() }()
+
+	_, _, err = fasthttp.GetTimeout(nil, "http://"+ln.Addr().String()+"/", time.Second)
+	require.Error(t, err)
+	assert.Eventually(t, func() bool { return app.ConnStats().Rejected >= 1 }, time.Second, 10*time.Millisecond)
+}
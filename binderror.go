@@ -0,0 +1,200 @@
+package gonoleks
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxBindErrorValue is the number of bytes of a received value kept in a BindError
+const maxBindErrorValue = 64
+
+// BindError describes why a single value could not be bound
+type BindError struct {
+	// Field is the key of the value, e.g. the query parameter name or the dotted JSON path
+	// It is empty for errors affecting the whole body, such as malformed JSON
+	Field string `json:"field,omitempty"`
+
+	// Source is the name of the binding, e.g. "query", "form", "header" or "json"
+	Source string `json:"source"`
+
+	// Type is the expected Go type, e.g. "int" or "time.Time"
+	Type string `json:"type,omitempty"`
+
+	// Value is the received value, truncated to 64 bytes
+	Value string `json:"value,omitempty"`
+
+	// Err is the underlying cause
+	Err error `json:"-"`
+}
+
+// Error implements the error interface
+func (e *BindError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Source)
+	if e.Field != "" {
+		b.WriteString(" field ")
+		b.WriteString(strconv.Quote(e.Field))
+	}
+	b.WriteString(": ")
+	if e.Type != "" {
+		b.WriteString("expected ")
+		b.WriteString(e.Type)
+		if e.Value != "" {
+			b.WriteString(", got ")
+			b.WriteString(strconv.Quote(e.Value))
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+// Unwrap returns the underlying cause
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// BindErrors lists every value that failed to bind, returned by the bindings so APIs can
+// report all problems at once
+//
+//	var errs gonoleks.BindErrors
+//	if err := c.ShouldBind(&req); errors.As(err, &errs) {
+//		c.JSON(http.StatusBadRequest, gonoleks.H{"errors": errs})
+//	}
+type BindErrors []*BindError
+
+// Error implements the error interface
+func (e BindErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the individual errors for errors.Is and errors.As
+func (e BindErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Problems converts the errors to validation problems for AbortWithStatusProblem
+func (e BindErrors) Problems() []ValidationProblem {
+	problems := make([]ValidationProblem, len(e))
+	for i, err := range e {
+		message := err.Err.Error()
+		if err.Type != "" {
+			message = "expected " + err.Type + ": " + message
+		}
+		problems[i] = ValidationProblem{In: err.Source, Name: err.Field, Message: message}
+	}
+	return problems
+}
+
+// newBindError returns a BindError for a value that failed to decode into a field of type t
+func newBindError(source, field string, t reflect.Type, value string, err error) *BindError {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		err = numErr.Err
+	}
+	return &BindError{
+		Field:  field,
+		Source: source,
+		Type:   t.String(),
+		Value:  truncateBindValue(value),
+		Err:    err,
+	}
+}
+
+// newRequiredError returns a BindError for a required field without a value
+func newRequiredError(source, field string) *BindError {
+	return &BindError{Field: field, Source: source, Err: ErrBindRequired}
+}
+
+// bodyBindError wraps an error decoding a whole request body
+func bodyBindError(source string, err error) error {
+	var errs BindErrors
+	if errors.As(err, &errs) {
+		return err
+	}
+	return BindErrors{{Source: source, Err: err}}
+}
+
+// jsonBindError converts a JSON decoding error into BindErrors
+// The fast decoder reports neither field nor value, so the body is decoded again with
+// encoding/json to locate the failure, which only costs time for invalid requests
+func jsonBindError(body []byte, obj any, err error) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return bodyBindError("json", err)
+	}
+	detailErr := json.Unmarshal(body, reflect.New(rv.Type().Elem()).Interface())
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(detailErr, &typeErr) && typeErr.Type != nil {
+		return BindErrors{{
+			Field:  typeErr.Field,
+			Source: "json",
+			Type:   typeErr.Type.String(),
+			Value:  truncateBindValue(jsonValueAt(body, int(typeErr.Offset), typeErr.Value)),
+			Err:    ErrBindTypeMismatch,
+		}}
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(detailErr, &syntaxErr) {
+		return bodyBindError("json", syntaxErr)
+	}
+	return bodyBindError("json", err)
+}
+
+// jsonValueAt returns the JSON literal ending at offset, or the description for objects and arrays
+func jsonValueAt(body []byte, offset int, description string) string {
+	if offset <= 0 || offset > len(body) {
+		return description
+	}
+	end := offset
+	switch body[end-1] {
+	case '{', '[':
+		return description
+	case '"':
+		for start := end - 2; start >= 0; start-- {
+			if body[start] != '"' {
+				continue
+			}
+			escapes := 0
+			for i := start - 1; i >= 0 && body[i] == '\\'; i-- {
+				escapes++
+			}
+			if escapes%2 == 0 {
+				if value, err := strconv.Unquote(string(body[start:end])); err == nil {
+					return value
+				}
+				return string(body[start+1 : end-1])
+			}
+		}
+		return description
+	}
+	start := end
+	for start > 0 && !strings.ContainsRune(":,[ \t\r\n", rune(body[start-1])) {
+		start--
+	}
+	return string(body[start:end])
+}
+
+// truncateBindValue shortens a received value to maxBindErrorValue bytes on a rune boundary
+func truncateBindValue(value string) string {
+	if len(value) <= maxBindErrorValue {
+		return value
+	}
+	cut := maxBindErrorValue
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut] + "..."
+}
@@ -0,0 +1,81 @@
+package gonoleks
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindErrorsFromQuery(t *testing.T) {
+	c, requestCtx := createTestContext()
+	requestCtx.Request.SetRequestURI("/search?page=two&size=99999&tag=1&tag=x")
+	var query struct {
+		Page int    `form:"page"`
+		Size uint16 `form:"size"`
+		Tags []int  `form:"tag"`
+	}
+	err := c.ShouldBindQuery(&query)
+	var errs BindErrors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 3, "Every invalid field should be reported")
+
+	assert.Equal(t, &BindError{Field: "page", Source: "query", Type: "int", Value: "two", Err: strconv.ErrSyntax}, errs[0])
+	assert.Equal(t, strconv.ErrRange, errs[1].Err)
+	assert.Equal(t, "tag", errs[2].Field)
+	assert.Equal(t, "x", errs[2].Value)
+	assert.Equal(t, `query field "page": expected int, got "two": invalid syntax`, errs[0].Error())
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+}
+
+func TestBindErrorsFromJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+		typ   string
+		value string
+	}{
+		{"String for int", `{"name":"gopher","age":"thirteen"}`, "age", "int", "thirteen"},
+		{"Float for int", `{"age":13.5}`, "age", "int", "13.5"},
+		{"Escaped string", `{"age":"a\"b"}`, "age", "int", `a"b`},
+		{"Object for string", `{"name":{"first":"go"}}`, "name", "string", "object"},
+		{"Malformed", `{"name":`, "", "", ""},
+		{"Empty", ``, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, requestCtx := createTestContext()
+			requestCtx.Request.SetBodyString(tt.body)
+			var user bindingUser
+			err := c.ShouldBindJSON(&user)
+			var errs BindErrors
+			require.True(t, errors.As(err, &errs))
+			require.Len(t, errs, 1)
+			assert.Equal(t, "json", errs[0].Source)
+			assert.Equal(t, tt.field, errs[0].Field)
+			assert.Equal(t, tt.typ, errs[0].Type)
+			assert.Equal(t, tt.value, errs[0].Value)
+		})
+	}
+}
+
+func TestBindErrorValueTruncated(t *testing.T) {
+	err := newBindError("form", "note", reflect.TypeFor[string](), strings.Repeat("é", 40), strconv.ErrSyntax)
+	assert.Equal(t, 64+len("..."), len(err.Value))
+	assert.True(t, strings.HasSuffix(err.Value, "é..."))
+}
+
+func TestBindWritesProblemDetails(t *testing.T) {
+	c, requestCtx := createTestContext()
+	requestCtx.Request.SetRequestURI("/users?age=old")
+	var user bindingUser
+	require.Error(t, c.BindQuery(&user))
+	assert.Equal(t, StatusBadRequest, requestCtx.Response.StatusCode())
+	assert.Equal(t, MIMEApplicationProblemJSON, string(requestCtx.Response.Header.ContentType()))
+	assert.Contains(t, string(requestCtx.Response.Body()), `{"in":"query","name":"age","message":"expected int: invalid syntax"}`)
+}
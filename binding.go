@@ -0,0 +1,408 @@
+package gonoleks
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// Binding decodes request data into a struct
+type Binding interface {
+	// Name identifies the binding, e.g. "json" or "header"
+	Name() string
+
+	// Bind decodes the request data of c into obj
+	Bind(c *Context, obj any) error
+}
+
+// bodyKey is the user value key under which ShouldBindBodyWith keeps the raw body
+const bodyKey = "gonoleksBody"
+
+// BindingBody is a Binding that decodes a raw body, allowing the body to be bound
+// more than once with Context.ShouldBindBodyWith
+type BindingBody interface {
+	Binding
+
+	// BindBody decodes body into obj
+	BindBody(body []byte, obj any) error
+}
+
+// Built-in bindings for Context.ShouldBindWith
+var (
+	BindingJSON     BindingBody = jsonBinding{}
+	BindingXML      BindingBody = xmlBinding{config: XMLBindingConfig{AllowDTD: true}}
+	BindingYAML     BindingBody = yamlBinding{}
+	BindingProtoBuf BindingBody = protoBufBinding{}
+	BindingForm     Binding     = formBinding{}
+	BindingQuery    Binding     = queryBinding{}
+	BindingHeader   Binding     = headerBinding{}
+	BindingCookie   Binding     = cookieBinding{}
+)
+
+// DefaultBinding returns the binding for a request method and Content-Type
+// Requests without a body bind the query string, the others are chosen by media type
+// and fall back to JSON
+func DefaultBinding(method, contentType string) Binding {
+	if method == MethodGet || method == MethodHead {
+		return BindingForm
+	}
+	mediaType := normalizeMediaType(contentType)
+	switch {
+	case mediaType == MIMEApplicationXML || mediaType == MIMETextXML || strings.HasSuffix(mediaType, "+xml"):
+		return BindingXML
+	case mediaType == MIMEApplicationYAML || mediaType == "application/yaml":
+		return BindingYAML
+	case mediaType == MIMEApplicationProtoBuf || mediaType == MIMEApplicationProto:
+		return BindingProtoBuf
+	case mediaType == MIMEApplicationForm || mediaType == MIMEMultipartForm:
+		return BindingForm
+	default:
+		return BindingJSON
+	}
+}
+
+// RegisterBinding makes ShouldBind and Bind decode requests of a media type with b,
+// e.g. vendor media types or custom wire formats
+// Registered media types take precedence over the built-in ones, and registering a media type
+// again replaces its binding
+// It must be called before the server starts, and panics on an empty media type or a nil binding
+//
+//	app.RegisterBinding("application/vnd.acme.order+json", orderBinding{})
+//	app.RegisterBinding("application/msgpack", msgpackBinding{})
+func (g *Gonoleks) RegisterBinding(mediaType string, b Binding) {
+	mediaType = normalizeMediaType(mediaType)
+	if mediaType == "" || b == nil {
+		panic("binding requires a media type and a non-nil Binding")
+	}
+	if g.bindings == nil {
+		g.bindings = make(map[string]Binding)
+	}
+	g.bindings[mediaType] = b
+}
+
+// normalizeMediaType strips the parameters of a Content-Type and lowercases it
+func normalizeMediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// defaultBinding returns the binding registered with RegisterBinding for the media type
+// of the request, falling back to DefaultBinding
+func (c *Context) defaultBinding() Binding {
+	method := string(c.requestCtx.Method())
+	if c.app != nil && len(c.app.bindings) > 0 && method != MethodGet && method != MethodHead {
+		if b, ok := c.app.bindings[normalizeMediaType(c.ContentType())]; ok {
+			return b
+		}
+	}
+	return DefaultBinding(method, c.ContentType())
+}
+
+// ShouldBind decodes the request into obj using the binding registered with RegisterBinding
+// for the request Content-Type, or else the one selected by DefaultBinding
+func (c *Context) ShouldBind(obj any) error {
+	return c.ShouldBindWith(obj, c.defaultBinding())
+}
+
+// ShouldBindWith decodes the request into obj using the given binding
+func (c *Context) ShouldBindWith(obj any, b Binding) error {
+	if c.app != nil && c.app.DecodeCharset {
+		if err := c.decodeCharset(); err != nil {
+			return err
+		}
+	}
+	return b.Bind(c, obj)
+}
+
+// ShouldBindBodyWith decodes the request body into obj using the given binding and keeps
+// a copy of the raw body on the context, so later middlewares and handlers can bind the
+// same body again, e.g. after a signature check, even if the request body was replaced
+//
+//	var envelope Envelope
+//	if err := c.ShouldBindBodyWith(&envelope, gonoleks.BindingJSON); err != nil {
+//		return err
+//	}
+func (c *Context) ShouldBindBodyWith(obj any, b BindingBody) error {
+	body, ok := c.requestCtx.UserValue(bodyKey).([]byte)
+	if !ok {
+		if c.app != nil && c.app.DecodeCharset {
+			if err := c.decodeCharset(); err != nil {
+				return err
+			}
+		}
+		body = bytes.Clone(c.requestCtx.Request.Body())
+		c.requestCtx.SetUserValue(bodyKey, body)
+	}
+	return b.BindBody(body, obj)
+}
+
+// ShouldBindJSON is a shortcut for c.ShouldBindWith(obj, BindingJSON)
+func (c *Context) ShouldBindJSON(obj any) error {
+	return c.ShouldBindWith(obj, BindingJSON)
+}
+
+// ShouldBindXML is a shortcut for c.ShouldBindWith(obj, BindingXML)
+func (c *Context) ShouldBindXML(obj any) error {
+	return c.ShouldBindWith(obj, BindingXML)
+}
+
+// ShouldBindYAML is a shortcut for c.ShouldBindWith(obj, BindingYAML)
+func (c *Context) ShouldBindYAML(obj any) error {
+	return c.ShouldBindWith(obj, BindingYAML)
+}
+
+// ShouldBindQuery is a shortcut for c.ShouldBindWith(obj, BindingQuery)
+func (c *Context) ShouldBindQuery(obj any) error {
+	return c.ShouldBindWith(obj, BindingQuery)
+}
+
+// ShouldBindHeader is a shortcut for c.ShouldBindWith(obj, BindingHeader)
+func (c *Context) ShouldBindHeader(obj any) error {
+	return c.ShouldBindWith(obj, BindingHeader)
+}
+
+// ShouldBindCookie is a shortcut for c.ShouldBindWith(obj, BindingCookie)
+//
+//	type Session struct {
+//		ID    string `cookie:"session_id,required"`
+//		Theme string `cookie:"theme,omitempty"`
+//	}
+func (c *Context) ShouldBindCookie(obj any) error {
+	return c.ShouldBindWith(obj, BindingCookie)
+}
+
+// Bind is like ShouldBind but aborts with 400 Bad Request when decoding fails
+func (c *Context) Bind(obj any) error {
+	return c.BindWith(obj, c.defaultBinding())
+}
+
+// BindWith is like ShouldBindWith but aborts with 400 Bad Request when decoding fails
+// Binding errors are answered with a problem details body listing every invalid value
+func (c *Context) BindWith(obj any, b Binding) error {
+	err := c.ShouldBindWith(obj, b)
+	if err == nil {
+		return nil
+	}
+	var errs BindErrors
+	if errors.As(err, &errs) {
+		_ = c.AbortWithStatusProblem(StatusBadRequest, "request binding failed", errs.Problems())
+		return err
+	}
+	if errors.Is(err, ErrUnsupportedCharset) {
+		return c.AbortWithError(StatusUnsupportedMediaType, err)
+	}
+	return c.AbortWithError(StatusBadRequest, err)
+}
+
+// BindJSON is a shortcut for c.BindWith(obj, BindingJSON)
+func (c *Context) BindJSON(obj any) error {
+	return c.BindWith(obj, BindingJSON)
+}
+
+// BindXML is a shortcut for c.BindWith(obj, BindingXML)
+func (c *Context) BindXML(obj any) error {
+	return c.BindWith(obj, BindingXML)
+}
+
+// BindYAML is a shortcut for c.BindWith(obj, BindingYAML)
+func (c *Context) BindYAML(obj any) error {
+	return c.BindWith(obj, BindingYAML)
+}
+
+// BindQuery is a shortcut for c.BindWith(obj, BindingQuery)
+func (c *Context) BindQuery(obj any) error {
+	return c.BindWith(obj, BindingQuery)
+}
+
+// BindCookie is a shortcut for c.BindWith(obj, BindingCookie)
+func (c *Context) BindCookie(obj any) error {
+	return c.BindWith(obj, BindingCookie)
+}
+
+// BindHeader is a shortcut for c.BindWith(obj, BindingHeader)
+//
+//	type RequestMeta struct {
+//		RequestID   string    `header:"x-request-id"`
+//		Forwarded   []string  `header:"X-Forwarded-For"`
+//		IfModified  time.Time `header:"If-Modified-Since"`
+//		ContentType string
+//	}
+func (c *Context) BindHeader(obj any) error {
+	return c.BindWith(obj, BindingHeader)
+}
+
+type jsonBinding struct{}
+
+func (jsonBinding) Name() string { return "json" }
+
+func (b jsonBinding) Bind(c *Context, obj any) error {
+	return b.BindBody(c.requestCtx.Request.Body(), obj)
+}
+
+func (jsonBinding) BindBody(body []byte, obj any) error {
+	if len(body) == 0 {
+		return bodyBindError("json", ErrEmptyRequestBody)
+	}
+	if err := sonic.Unmarshal(body, obj); err != nil {
+		return jsonBindError(body, obj, err)
+	}
+	return nil
+}
+
+// xmlBinding binds XML bodies, enforcing the limits of its config, see NewXMLBinding
+type xmlBinding struct {
+	config XMLBindingConfig
+}
+
+func (xmlBinding) Name() string { return "xml" }
+
+func (b xmlBinding) Bind(c *Context, obj any) error {
+	return b.BindBody(c.requestCtx.Request.Body(), obj)
+}
+
+func (b xmlBinding) BindBody(body []byte, obj any) error {
+	if len(body) == 0 {
+		return bodyBindError("xml", ErrEmptyRequestBody)
+	}
+	if err := newXMLDecoder(bytes.NewReader(body), b.config).Decode(obj); err != nil {
+		return bodyBindError("xml", err)
+	}
+	return nil
+}
+
+type yamlBinding struct{}
+
+func (yamlBinding) Name() string { return "yaml" }
+
+func (b yamlBinding) Bind(c *Context, obj any) error {
+	return b.BindBody(c.requestCtx.Request.Body(), obj)
+}
+
+func (yamlBinding) BindBody(body []byte, obj any) error {
+	if len(body) == 0 {
+		return bodyBindError("yaml", ErrEmptyRequestBody)
+	}
+	if err := yaml.Unmarshal(body, obj); err != nil {
+		return bodyBindError("yaml", err)
+	}
+	return nil
+}
+
+type protoBufBinding struct{}
+
+func (protoBufBinding) Name() string { return "protobuf" }
+
+func (b protoBufBinding) Bind(c *Context, obj any) error {
+	return b.BindBody(c.requestCtx.Request.Body(), obj)
+}
+
+func (protoBufBinding) BindBody(body []byte, obj any) error {
+	msg, ok := obj.(proto.Message)
+	if !ok {
+		return ErrProtoMessageInterface
+	}
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return bodyBindError("protobuf", err)
+	}
+	return nil
+}
+
+// formBinding binds urlencoded and multipart form fields, followed by query parameters
+type formBinding struct{}
+
+func (formBinding) Name() string { return "form" }
+
+func (formBinding) Bind(c *Context, obj any) error {
+	values := c.formValues()
+	for key, query := range c.QueryValues() {
+		values[key] = append(values[key], query...)
+	}
+	return formDecoder{tag: "form", source: "form", location: c.Location()}.Decode(obj, values)
+}
+
+type queryBinding struct{}
+
+func (queryBinding) Name() string { return "query" }
+
+func (queryBinding) Bind(c *Context, obj any) error {
+	return formDecoder{tag: "form", source: "query", location: c.Location()}.Decode(obj, c.QueryValues())
+}
+
+// headerBinding binds request headers
+// Header names match case-insensitively and regardless of dashes and underscores, so the tags
+// "X-Request-ID", "x-request-id" and "x_request_id" and an untagged RequestID field are equivalent
+// Repeated headers fill slice fields, and time.Time fields parse HTTP dates by default
+type headerBinding struct{}
+
+func (headerBinding) Name() string { return "header" }
+
+func (headerBinding) Bind(c *Context, obj any) error {
+	values := make(url.Values)
+	for key, value := range c.requestCtx.Request.Header.All() {
+		values[string(key)] = append(values[string(key)], string(value))
+	}
+	decoder := formDecoder{
+		tag:          "header",
+		source:       "header",
+		normalizeKey: canonicalHeaderName,
+		timeFormat:   timeFormatHTTP,
+		location:     c.Location(),
+	}
+	return decoder.Decode(obj, values)
+}
+
+// cookieBinding binds request cookies by their exact, case-sensitive name
+// Values are unescaped like those returned by Context.Cookie
+type cookieBinding struct{}
+
+func (cookieBinding) Name() string { return "cookie" }
+
+func (cookieBinding) Bind(c *Context, obj any) error {
+	values := make(url.Values)
+	for key, value := range c.requestCtx.Request.Header.Cookies() {
+		unescaped, err := url.QueryUnescape(string(value))
+		if err != nil {
+			unescaped = string(value)
+		}
+		values[string(key)] = append(values[string(key)], unescaped)
+	}
+	decoder := formDecoder{
+		tag:          "cookie",
+		source:       "cookie",
+		normalizeKey: func(key string) string { return key },
+		location:     c.Location(),
+	}
+	return decoder.Decode(obj, values)
+}
+
+// canonicalHeaderName lowercases a header name and drops dashes and underscores for matching
+func canonicalHeaderName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '-' || r == '_':
+			return -1
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return r
+	}, name)
+}
+
+// formValues returns the urlencoded and multipart form fields of the request body
+func (c *Context) formValues() url.Values {
+	values := make(url.Values)
+	for key, value := range c.requestCtx.PostArgs().All() {
+		values[string(key)] = append(values[string(key)], string(value))
+	}
+	if form, err := c.requestCtx.MultipartForm(); err == nil {
+		for key, fields := range form.Value {
+			values[key] = append(values[key], fields...)
+		}
+	}
+	return values
+}
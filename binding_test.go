@@ -0,0 +1,209 @@
+package gonoleks
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bindingUser struct {
+	Name string `json:"name" xml:"name" yaml:"name" form:"name"`
+	Age  int    `json:"age" xml:"age" yaml:"age" form:"age"`
+}
+
+func TestDefaultBinding(t *testing.T) {
+	tests := []struct {
+		method      string
+		contentType string
+		expected    Binding
+	}{
+		{MethodGet, "", BindingForm},
+		{MethodPost, MIMEApplicationJSONCharsetUTF8, BindingJSON},
+		{MethodPost, "application/vnd.api+json", BindingJSON},
+		{MethodPut, MIMEApplicationXML, BindingXML},
+		{MethodPost, "application/atom+xml", BindingXML},
+		{MethodPost, MIMEApplicationYAML, BindingYAML},
+		{MethodPost, MIMEApplicationProtoBuf, BindingProtoBuf},
+		{MethodPost, MIMEApplicationForm, BindingForm},
+		{MethodPost, MIMEMultipartForm + "; boundary=x", BindingForm},
+		{MethodPost, "", BindingJSON},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, DefaultBinding(tt.method, tt.contentType), tt.method+" "+tt.contentType)
+	}
+}
+
+func TestShouldBindBodies(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"JSON", MIMEApplicationJSON, `{"name":"gopher","age":13}`},
+		{"XML", MIMEApplicationXML, `<user><name>gopher</name><age>13</age></user>`},
+		{"YAML", MIMEApplicationYAML, "name: gopher\nage: 13\n"},
+		{"Form", MIMEApplicationForm, "name=gopher&age=13"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, requestCtx := createTestContext()
+			requestCtx.Request.Header.SetMethod(MethodPost)
+			requestCtx.Request.Header.SetContentType(tt.contentType)
+			requestCtx.Request.SetBodyString(tt.body)
+			var user bindingUser
+			require.NoError(t, c.ShouldBind(&user))
+			assert.Equal(t, bindingUser{Name: "gopher", Age: 13}, user)
+		})
+	}
+}
+
+func TestShouldBindQuery(t *testing.T) {
+	c, requestCtx := createTestContext()
+	requestCtx.Request.SetRequestURI("/users?name=gopher&age=13")
+	var user bindingUser
+	require.NoError(t, c.ShouldBindQuery(&user))
+	assert.Equal(t, bindingUser{Name: "gopher", Age: 13}, user)
+}
+
+// csvBinding binds "name,age" bodies for TestRegisterBinding
+type csvBinding struct{}
+
+func (csvBinding) Name() string { return "csv" }
+
+func (csvBinding) Bind(c *Context, obj any) error {
+	name, age, _ := strings.Cut(string(c.requestCtx.Request.Body()), ",")
+	user := obj.(*bindingUser)
+	user.Name = name
+	user.Age, _ = strconv.Atoi(age)
+	return nil
+}
+
+func TestRegisterBinding(t *testing.T) {
+	app := New()
+	app.RegisterBinding("Text/CSV; charset=utf-8", csvBinding{})
+	app.RegisterBinding(MIMEApplicationXML, BindingJSON)
+
+	c, requestCtx := createTestContext()
+	c.app = app
+	requestCtx.Request.Header.SetMethod(MethodPost)
+	requestCtx.Request.Header.SetContentType("text/csv; header=absent")
+	requestCtx.Request.SetBodyString("gopher,13")
+	var user bindingUser
+	require.NoError(t, c.ShouldBind(&user))
+	assert.Equal(t, bindingUser{Name: "gopher", Age: 13}, user)
+
+	// Registered media types override the built-in ones
+	requestCtx.Request.Header.SetContentType(MIMEApplicationXML)
+	requestCtx.Request.SetBodyString(`{"name":"gopher","age":14}`)
+	require.NoError(t, c.Bind(&user))
+	assert.Equal(t, 14, user.Age)
+
+	// Other media types still use DefaultBinding
+	requestCtx.Request.Header.SetContentType(MIMEApplicationForm)
+	requestCtx.Request.SetBodyString("name=gopher&age=15")
+	require.NoError(t, c.ShouldBind(&user))
+	assert.Equal(t, 15, user.Age)
+
+	assert.Panics(t, func() { app.RegisterBinding("", csvBinding{}) })
+	assert.Panics(t, func() { app.RegisterBinding("text/csv", nil) })
+}
+
+func TestBindAbortsOnError(t *testing.T) {
+	c, requestCtx := createTestContext()
+	requestCtx.Request.Header.SetMethod(MethodPost)
+	var user bindingUser
+	assert.ErrorIs(t, c.BindJSON(&user), ErrEmptyRequestBody)
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, StatusBadRequest, requestCtx.Response.StatusCode())
+}
+
+func TestBindHeader(t *testing.T) {
+	type requestMeta struct {
+		RequestID   string    `header:"x-request-id"`
+		TraceID     string    `header:"x_trace_id"`
+		Forwarded   []string  `header:"X-Forwarded-For"`
+		IfModified  time.Time `header:"If-Modified-Since"`
+		Custom      time.Time `header:"X-Expires" time_format:"2006-01-02"`
+		ContentType string
+		UserAgent   string
+	}
+	c, requestCtx := createTestContext()
+	requestCtx.Request.Header.Set("X-Request-ID", "abc")
+	requestCtx.Request.Header.Set("X-Trace-Id", "trace")
+	requestCtx.Request.Header.Add("X-Forwarded-For", "192.0.2.1")
+	requestCtx.Request.Header.Add("X-Forwarded-For", "198.51.100.7")
+	requestCtx.Request.Header.Set("If-Modified-Since", "Tue, 05 Mar 2024 10:30:00 GMT")
+	requestCtx.Request.Header.Set("X-Expires", "2024-12-31")
+	requestCtx.Request.Header.SetContentType(MIMEApplicationJSON)
+	requestCtx.Request.Header.SetUserAgent("tester")
+
+	var meta requestMeta
+	require.NoError(t, c.BindHeader(&meta))
+	assert.Equal(t, "abc", meta.RequestID)
+	assert.Equal(t, "trace", meta.TraceID)
+	assert.Equal(t, []string{"192.0.2.1", "198.51.100.7"}, meta.Forwarded, "Repeated headers should fill slices")
+	assert.Equal(t, time.Date(2024, time.March, 5, 10, 30, 0, 0, time.UTC), meta.IfModified)
+	assert.Equal(t, time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC), meta.Custom)
+	assert.Equal(t, MIMEApplicationJSON, meta.ContentType)
+	assert.Equal(t, "tester", meta.UserAgent, "Untagged fields should match the canonical header name")
+
+	requestCtx.Request.Header.Set("If-Modified-Since", "yesterday")
+	assert.Error(t, c.BindHeader(&meta))
+	assert.Equal(t, StatusBadRequest, requestCtx.Response.StatusCode())
+}
+
+func TestShouldBindCookie(t *testing.T) {
+	type preferences struct {
+		SessionID string   `cookie:"session_id,required"`
+		Theme     string   `cookie:"theme,omitempty"`
+		PageSize  int      `cookie:"page_size"`
+		Recent    []string `cookie:"recent"`
+		Ignored   string   `cookie:"-"`
+	}
+	c, requestCtx := createTestContext()
+	requestCtx.Request.Header.Set(HeaderCookie, "session_id=abc%20123; page_size=50; recent=a; recent=b; Theme=dark")
+
+	prefs := preferences{Theme: "light"}
+	require.NoError(t, c.ShouldBindCookie(&prefs))
+	assert.Equal(t, "abc 123", prefs.SessionID, "Cookie values should be unescaped")
+	assert.Equal(t, "light", prefs.Theme, "Cookie names should match case-sensitively")
+	assert.Equal(t, 50, prefs.PageSize)
+	assert.Equal(t, []string{"a", "b"}, prefs.Recent)
+
+	c, requestCtx = createTestContext()
+	requestCtx.Request.Header.Set(HeaderCookie, "page_size=big")
+	err := c.BindCookie(&prefs)
+	var errs BindErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "cookie", errs[0].Source)
+	assert.Equal(t, "session_id", errs[0].Field)
+	assert.ErrorIs(t, errs[0], ErrBindRequired)
+	assert.Equal(t, "page_size", errs[1].Field)
+	assert.Equal(t, StatusBadRequest, requestCtx.Response.StatusCode())
+}
+
+func TestShouldBindBodyWith(t *testing.T) {
+	c, requestCtx := createTestContext()
+	requestCtx.Request.Header.SetContentType(MIMEApplicationJSON)
+	requestCtx.Request.SetBodyString(`{"name":"gopher","age":13}`)
+
+	var envelope struct {
+		Name string `json:"name"`
+	}
+	require.NoError(t, c.ShouldBindBodyWith(&envelope, BindingJSON))
+	assert.Equal(t, "gopher", envelope.Name)
+
+	requestCtx.Request.SetBodyString("replaced")
+	var user bindingUser
+	require.NoError(t, c.ShouldBindBodyWith(&user, BindingJSON), "The cached body should be reused")
+	assert.Equal(t, bindingUser{Name: "gopher", Age: 13}, user)
+
+	var yamlUser bindingUser
+	require.NoError(t, c.ShouldBindBodyWith(&yamlUser, BindingYAML), "JSON is valid YAML")
+	assert.Equal(t, user, yamlUser)
+}
@@ -0,0 +1,34 @@
+package gonoleks
+
+import (
+	"strconv"
+)
+
+// MaxResponseBodySize instances a middleware that caps the size of the response body
+// written by the remaining handlers, guarding against a handler accidentally
+// serializing a huge object
+// Responses over limit bytes are logged and replaced with a 500 problem details response
+// Streamed bodies cannot be measured and are passed through unchanged
+//
+//	app.GET("/export", gonoleks.MaxResponseBodySize(10<<20), exportHandler)
+func MaxResponseBodySize(limit int) handlerFunc {
+	return func(c *Context) {
+		c.Next()
+		resp := &c.requestCtx.Response
+		if limit <= 0 || resp.IsBodyStream() {
+			return
+		}
+		size := len(resp.Body())
+		if size <= limit {
+			return
+		}
+		c.diagnostics().Error("Response body size limit exceeded",
+			"method", string(c.requestCtx.Method()),
+			"path", string(c.requestCtx.Path()),
+			"size", size,
+			"limit", limit,
+		)
+		resp.Reset()
+		_ = c.AbortWithStatusProblem(StatusInternalServerError, "response body exceeds "+strconv.Itoa(limit)+" bytes", nil)
+	}
+}
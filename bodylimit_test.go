@@ -0,0 +1,46 @@
+package gonoleks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxResponseBodySize(t *testing.T) {
+	app := New()
+	app.GET("/small", MaxResponseBodySize(16), func(c *Context) {
+		c.String(StatusOK, "fits")
+	})
+	app.GET("/huge", MaxResponseBodySize(16), func(c *Context) {
+		c.Header(HeaderXTest, "leaked")
+		c.String(StatusOK, "%s", strings.Repeat("x", 17))
+	})
+	app.GET("/unlimited", func(c *Context) {
+		c.String(StatusOK, "%s", strings.Repeat("x", 17))
+	})
+	app.setupRouter()
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/small", StatusOK, "fits"},
+		{"/huge", StatusInternalServerError, `"detail":"response body exceeds 16 bytes"`},
+		{"/unlimited", StatusOK, strings.Repeat("x", 17)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			reqCtx := newProxiedRequest(MethodGet, tt.path, "203.0.113.5", nil)
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.code, reqCtx.Response.StatusCode())
+			assert.Contains(t, string(reqCtx.Response.Body()), tt.body)
+		})
+	}
+
+	reqCtx := newProxiedRequest(MethodGet, "/huge", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, MIMEApplicationProblemJSON, string(reqCtx.Response.Header.ContentType()))
+	assert.Empty(t, reqCtx.Response.Header.Peek(HeaderXTest), "Headers of the oversized response should be discarded")
+}
@@ -0,0 +1,49 @@
+package gonoleks
+
+import (
+	"bytes"
+	"io/fs"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// prepareByteRange decides whether the Range request header is honored when serving a file
+// The header is dropped when byte ranges are disabled or when the If-Range validator
+// no longer matches the file, so the full file is sent as required by RFC 9110, 13.1.5
+func (c *Context) prepareByteRange(info fs.FileInfo) {
+	header := &c.requestCtx.Request.Header
+	if len(header.Peek(HeaderRange)) == 0 {
+		return
+	}
+	if c.byteRangeDisabled() || !c.ifRangeMatches(info) {
+		header.Del(HeaderRange)
+	}
+}
+
+// finishByteRange removes the Accept-Ranges response header when byte ranges are disabled
+func (c *Context) finishByteRange() {
+	if c.byteRangeDisabled() {
+		c.requestCtx.Response.Header.Del(HeaderAcceptRanges)
+	}
+}
+
+// byteRangeDisabled reports whether Options.DisableByteRange is set
+func (c *Context) byteRangeDisabled() bool {
+	return c.app != nil && c.app.DisableByteRange
+}
+
+// ifRangeMatches evaluates the If-Range request header against a file
+// Entity tags generated for files are weak and therefore never satisfy If-Range,
+// a date only matches the exact modification time
+func (c *Context) ifRangeMatches(info fs.FileInfo) bool {
+	ifRange := c.requestCtx.Request.Header.Peek(HeaderIfRange)
+	if len(ifRange) == 0 {
+		return true
+	}
+	if info == nil || info.ModTime().IsZero() || ifRange[0] == '"' || bytes.HasPrefix(ifRange, []byte("W/")) {
+		return false
+	}
+	since, err := fasthttp.ParseHTTPDate(ifRange)
+	return err == nil && since.Equal(info.ModTime().Truncate(time.Second))
+}
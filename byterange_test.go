@@ -0,0 +1,69 @@
+package gonoleks
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestFileByteRange(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "video.bin")
+	require.NoError(t, os.WriteFile(filePath, []byte("0123456789"), 0o600))
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filePath, modTime, modTime))
+
+	app := New()
+	app.GET("/file", func(c *Context) {
+		c.File(filePath)
+	})
+	app.GET("/attachment", func(c *Context) {
+		c.FileAttachment(filePath, "video.bin")
+	})
+	app.StaticFile("/static-file", filePath)
+	app.StaticFileFS("/static-file-fs", "video.bin", fstest.MapFS{"video.bin": {Data: []byte("0123456789"), ModTime: modTime}})
+	app.Static("/static", dir)
+	app.setupRouter()
+
+	request := func(uri string, headers map[string]string) *fasthttp.RequestCtx {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(uri)
+		reqCtx.Request.Header.SetMethod(MethodGet)
+		for key, value := range headers {
+			reqCtx.Request.Header.Set(key, value)
+		}
+		app.router.Handler(reqCtx)
+		return reqCtx
+	}
+
+	for _, uri := range []string{"/file", "/attachment", "/static-file", "/static-file-fs", "/static/video.bin"} {
+		t.Run(uri, func(t *testing.T) {
+			reqCtx := request(uri, map[string]string{HeaderRange: "bytes=2-5"})
+			assert.Equal(t, StatusPartialContent, reqCtx.Response.StatusCode())
+			assert.Equal(t, "2345", string(reqCtx.Response.Body()))
+			assert.Equal(t, "bytes 2-5/10", string(reqCtx.Response.Header.Peek(HeaderContentRange)))
+
+			// A matching If-Range date keeps the range
+			reqCtx = request(uri, map[string]string{HeaderRange: "bytes=2-5", HeaderIfRange: modTime.Format(http.TimeFormat)})
+			assert.Equal(t, StatusPartialContent, reqCtx.Response.StatusCode())
+
+			// A stale If-Range validator sends the full file
+			reqCtx = request(uri, map[string]string{HeaderRange: "bytes=2-5", HeaderIfRange: modTime.Add(-time.Hour).Format(http.TimeFormat)})
+			assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+			assert.Equal(t, "0123456789", string(reqCtx.Response.Body()))
+		})
+	}
+
+	app.DisableByteRange = true
+	reqCtx := request("/file", map[string]string{HeaderRange: "bytes=2-5"})
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, "0123456789", string(reqCtx.Response.Body()))
+	assert.Empty(t, reqCtx.Response.Header.Peek(HeaderAcceptRanges))
+}
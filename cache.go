@@ -0,0 +1,134 @@
+package gonoleks
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheControlOptions describes a Cache-Control response policy
+// Zero durations are omitted, use NoCache to require revalidation on every use
+type CacheControlOptions struct {
+	// Public allows shared caches to store responses to authenticated requests
+	Public bool
+
+	// Private restricts storage to the client cache
+	Private bool
+
+	// NoCache requires caches to revalidate before using a stored response
+	NoCache bool
+
+	// NoStore forbids caches from storing the response at all
+	NoStore bool
+
+	// NoTransform forbids intermediaries from transforming the content
+	NoTransform bool
+
+	// MustRevalidate forbids serving the response stale once it has expired
+	MustRevalidate bool
+
+	// ProxyRevalidate is MustRevalidate for shared caches only
+	ProxyRevalidate bool
+
+	// Immutable tells clients the response will not change while fresh
+	Immutable bool
+
+	// MaxAge is how long the response stays fresh
+	MaxAge time.Duration
+
+	// SMaxAge overrides MaxAge for shared caches
+	SMaxAge time.Duration
+
+	// StaleWhileRevalidate allows serving a stale response while revalidating it in the background
+	StaleWhileRevalidate time.Duration
+
+	// StaleIfError allows serving a stale response when revalidation fails
+	StaleIfError time.Duration
+}
+
+// String returns the Cache-Control header value for the options
+func (o CacheControlOptions) String() string {
+	directives := make([]string, 0, 4)
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{o.Public, "public"},
+		{o.Private, "private"},
+		{o.NoCache, "no-cache"},
+		{o.NoStore, "no-store"},
+		{o.NoTransform, "no-transform"},
+		{o.MustRevalidate, "must-revalidate"},
+		{o.ProxyRevalidate, "proxy-revalidate"},
+		{o.Immutable, "immutable"},
+	}
+	for _, flag := range flags {
+		if flag.set {
+			directives = append(directives, flag.name)
+		}
+	}
+	durations := []struct {
+		value time.Duration
+		name  string
+	}{
+		{o.MaxAge, "max-age"},
+		{o.SMaxAge, "s-maxage"},
+		{o.StaleWhileRevalidate, "stale-while-revalidate"},
+		{o.StaleIfError, "stale-if-error"},
+	}
+	for _, d := range durations {
+		if d.value > 0 {
+			directives = append(directives, d.name+"="+strconv.FormatInt(int64(d.value/time.Second), 10))
+		}
+	}
+	return strings.Join(directives, ", ")
+}
+
+// CacheControl sets the Cache-Control response header from the given options
+//
+//	c.CacheControl(gonoleks.CacheControlOptions{Public: true, MaxAge: time.Hour, StaleWhileRevalidate: time.Minute})
+func (c *Context) CacheControl(opts CacheControlOptions) *Context {
+	c.requestCtx.Response.Header.Set(HeaderCacheControl, opts.String())
+	return c
+}
+
+// Vary adds the given request headers to the Vary response header, skipping ones already listed
+func (c *Context) Vary(headers ...string) *Context {
+	existing := string(c.requestCtx.Response.Header.Peek(HeaderVary))
+	values := make([]string, 0, len(headers)+2)
+	for value := range strings.SplitSeq(existing, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	for _, header := range headers {
+		header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+		if header == "" || containsFold(values, header) {
+			continue
+		}
+		values = append(values, header)
+	}
+	// A Vary of "*" already covers every header
+	if containsFold(values, "*") {
+		values = []string{"*"}
+	}
+	c.requestCtx.Response.Header.Set(HeaderVary, strings.Join(values, ", "))
+	return c
+}
+
+// Expires sets the Expires response header to the given time
+func (c *Context) Expires(t time.Time) *Context {
+	c.requestCtx.Response.Header.Set(HeaderExpires, t.UTC().Format(http.TimeFormat))
+	return c
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, s) {
+			return true
+		}
+	}
+	return false
+}
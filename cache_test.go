@@ -0,0 +1,40 @@
+package gonoleks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextCacheControl(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     CacheControlOptions
+		expected string
+	}{
+		{"Empty", CacheControlOptions{}, ""},
+		{"No store", CacheControlOptions{NoStore: true}, "no-store"},
+		{"Shared cache", CacheControlOptions{Public: true, MaxAge: time.Minute, SMaxAge: time.Hour, StaleWhileRevalidate: 30 * time.Second}, "public, max-age=60, s-maxage=3600, stale-while-revalidate=30"},
+		{"Immutable asset", CacheControlOptions{Public: true, Immutable: true, MaxAge: 365 * 24 * time.Hour}, "public, immutable, max-age=31536000"},
+		{"Private revalidated", CacheControlOptions{Private: true, NoCache: true, MustRevalidate: true}, "private, no-cache, must-revalidate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, requestCtx := createTestContext()
+			ctx.CacheControl(tt.opts)
+			assert.Equal(t, tt.expected, string(requestCtx.Response.Header.Peek(HeaderCacheControl)))
+		})
+	}
+}
+
+func TestContextVaryAndExpires(t *testing.T) {
+	ctx, requestCtx := createTestContext()
+	ctx.Vary("accept-encoding").Vary(HeaderAccept, HeaderAcceptEncoding, "Accept-Language")
+	assert.Equal(t, "Accept-Encoding, Accept, Accept-Language", string(requestCtx.Response.Header.Peek(HeaderVary)))
+	ctx.Vary("*")
+	assert.Equal(t, "*", string(requestCtx.Response.Header.Peek(HeaderVary)))
+
+	ctx.Expires(time.Date(2025, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)))
+	assert.Equal(t, "Thu, 02 Jan 2025 02:04:05 GMT", string(requestCtx.Response.Header.Peek(HeaderExpires)))
+}
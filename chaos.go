@@ -0,0 +1,134 @@
+package gonoleks
+
+import (
+	"math/rand/v2"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ChaosRule describes the faults injected into the requests it matches
+type ChaosRule struct {
+	// Methods restricts the rule to these HTTP methods, empty matches every method
+	Methods []string
+
+	// PathPrefix restricts the rule to request paths starting with it
+	PathPrefix string
+
+	// Match restricts the rule to the requests it reports, in addition to Methods and PathPrefix
+	Match func(c *Context) bool
+
+	// Rate is the fraction of matching requests the faults are injected into, between 0 and 1
+	Rate float64 // Default = 1
+
+	// Latency delays the request before it is handled
+	Latency time.Duration
+
+	// Jitter adds a random delay up to this duration to Latency
+	Jitter time.Duration
+
+	// Status answers with this error status and a problem details body instead of handling the request
+	Status int
+
+	// Reset closes the connection abruptly instead of answering, as a crashed server or proxy would
+	Reset bool
+}
+
+// ChaosConfig defines the config for the Chaos middleware
+type ChaosConfig struct {
+	// Rules are tried in order, the first matching rule applies
+	Rules []ChaosRule
+
+	// Header restricts fault injection to requests carrying this header, e.g. "X-Chaos",
+	// so staging clients opt in to it; empty applies it to every request
+	Header string
+}
+
+// Chaos instances a middleware injecting latency, error responses and connection resets into
+// a fraction of the requests, to test the resilience of clients
+// It is meant for test and staging environments
+//
+//	app.Use(gonoleks.Chaos(gonoleks.ChaosConfig{
+//	    Header: "X-Chaos",
+//	    Rules: []gonoleks.ChaosRule{
+//	        {PathPrefix: "/api/payments", Rate: 0.2, Status: gonoleks.StatusServiceUnavailable},
+//	        {Rate: 0.1, Latency: time.Second, Jitter: 500 * time.Millisecond},
+//	        {Rate: 0.01, Reset: true},
+//	    },
+//	}))
+func Chaos(config ChaosConfig) handlerFunc {
+	rules := slices.Clone(config.Rules)
+	for i := range rules {
+		if rules[i].Rate == 0 {
+			rules[i].Rate = 1
+		}
+	}
+	return func(c *Context) {
+		if config.Header != "" && len(c.requestCtx.Request.Header.Peek(config.Header)) == 0 {
+			c.Next()
+			return
+		}
+		rule := chaosRule(c, rules)
+		if rule == nil || (rule.Rate < 1 && c.Rand().Float64() >= rule.Rate) {
+			c.Next()
+			return
+		}
+		if delay := rule.Latency + chaosJitter(c.Rand(), rule.Jitter); delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-serverDone(c.requestCtx):
+				timer.Stop()
+			}
+		}
+		switch {
+		case rule.Reset:
+			c.diagnostics().Debug("Chaos reset connection", "method", string(c.requestCtx.Method()), "path", string(c.requestCtx.Path()))
+			c.Abort()
+			c.requestCtx.HijackSetNoResponse(true)
+			c.requestCtx.Hijack(resetConn)
+		case rule.Status != 0:
+			c.diagnostics().Debug("Chaos injected error", "method", string(c.requestCtx.Method()), "path", string(c.requestCtx.Path()), "status", rule.Status)
+			_ = c.AbortWithStatusProblem(rule.Status, "injected fault", nil)
+		default:
+			c.Next()
+		}
+	}
+}
+
+// chaosRule returns the first rule matching the request, or nil
+func chaosRule(c *Context, rules []ChaosRule) *ChaosRule {
+	method := getString(c.requestCtx.Method())
+	path := getString(c.requestCtx.Path())
+	for i := range rules {
+		rule := &rules[i]
+		if len(rule.Methods) > 0 && !slices.Contains(rule.Methods, method) {
+			continue
+		}
+		if !strings.HasPrefix(path, rule.PathPrefix) {
+			continue
+		}
+		if rule.Match != nil && !rule.Match(c) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// chaosJitter returns a random duration in [0, jitter) drawn from r
+func chaosJitter(r *rand.Rand, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+	return time.Duration(r.Int64N(int64(jitter)))
+}
+
+// resetConn closes a hijacked connection, with a TCP reset when possible
+func resetConn(conn net.Conn) {
+	if tcp, ok := netConn(conn).(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	_ = conn.Close()
+}
@@ -0,0 +1,120 @@
+package gonoleks
+
+import (
+	"bufio"
+	"io"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestChaos(t *testing.T) {
+	app := New()
+	app.Use(Chaos(ChaosConfig{
+		Header: "X-Chaos",
+		Rules: []ChaosRule{
+			{Methods: []string{MethodPost}, PathPrefix: "/pay", Status: StatusServiceUnavailable},
+			{PathPrefix: "/slow", Latency: 20 * time.Millisecond, Jitter: 10 * time.Millisecond},
+			{Match: func(c *Context) bool { return c.Query("never") != "" }, Rate: 0.0000001, Status: StatusTeapot},
+		},
+	}))
+	app.GET("/*path", func(c *Context) { c.String(StatusOK, "ok") })
+	app.POST("/pay", func(c *Context) { c.String(StatusOK, "paid") })
+	app.setupRouter()
+	chaos := map[string]string{"X-Chaos": "1"}
+
+	// Requests without the header are left alone
+	reqCtx := newProxiedRequest(MethodPost, "/pay", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+
+	reqCtx = newProxiedRequest(MethodPost, "/pay", "203.0.113.5", chaos)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusServiceUnavailable, reqCtx.Response.StatusCode())
+	assert.Equal(t, MIMEApplicationProblemJSON, string(reqCtx.Response.Header.ContentType()))
+	assert.Contains(t, string(reqCtx.Response.Body()), "injected fault")
+
+	// Rules match on method and path
+	reqCtx = newProxiedRequest(MethodGet, "/pay", "203.0.113.5", chaos)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "ok", string(reqCtx.Response.Body()))
+
+	start := time.Now()
+	reqCtx = newProxiedRequest(MethodGet, "/slow", "203.0.113.5", chaos)
+	app.router.Handler(reqCtx)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, "ok", string(reqCtx.Response.Body()))
+
+	// Rate samples the matching requests
+	for range 10 {
+		reqCtx = newProxiedRequest(MethodGet, "/a?never=1", "203.0.113.5", chaos)
+		app.router.Handler(reqCtx)
+		assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	}
+}
+
+func TestChaosReset(t *testing.T) {
+	app := New()
+	app.Use(Chaos(ChaosConfig{Rules: []ChaosRule{{PathPrefix: "/reset", Reset: true}}}))
+	app.GET("/*path", func(c *Context) { c.String(StatusOK, "ok") })
+	app.setupRouter()
+	server := app.newHTTPServer()
+	ln := fasthttputil.NewInmemoryListener()
+	go func() { _ = server.Serve(ln) }()
+	defer ln.Close()
+
+	request := func(path string) (string, error) {
+		conn, err := ln.Dial()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = conn.Write([]byte("GET " + path + " HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+		require.NoError(t, err)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		return bufio.NewReader(conn).ReadString('\n')
+	}
+
+	line, err := request("/ok")
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1 200 OK\r\n", line)
+
+	line, err = request("/reset")
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Empty(t, line)
+}
+
+func TestChaosSeeded(t *testing.T) {
+	statuses := func() []int {
+		app := New()
+		app.SetRandSeed(7)
+		app.Use(Chaos(ChaosConfig{Rules: []ChaosRule{{Rate: 0.5, Status: StatusServiceUnavailable}}}))
+		app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+		app.setupRouter()
+		var statuses []int
+		for range 5 {
+			reqCtx := newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+			app.router.Handler(reqCtx)
+			statuses = append(statuses, reqCtx.Response.StatusCode())
+		}
+		return statuses
+	}
+	// Every request draws from the seeded generator, so the faults are reproducible
+	first := statuses()
+	assert.Equal(t, first, statuses())
+	for _, status := range first {
+		assert.Equal(t, first[0], status)
+	}
+}
+
+func TestChaosJitter(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	assert.Zero(t, chaosJitter(r, 0))
+	for range 100 {
+		jitter := chaosJitter(r, time.Millisecond)
+		assert.GreaterOrEqual(t, jitter, time.Duration(0))
+		assert.Less(t, jitter, time.Millisecond)
+	}
+}
@@ -0,0 +1,195 @@
+package gonoleks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/valyala/fasthttp"
+)
+
+// CharsetDecoder converts text in a charset to UTF-8
+type CharsetDecoder func(src []byte) ([]byte, error)
+
+var (
+	charsetsMu sync.RWMutex
+	charsets   = map[string]CharsetDecoder{
+		"iso-8859-1":   decodeLatin1,
+		"iso_8859-1":   decodeLatin1,
+		"latin1":       decodeLatin1,
+		"l1":           decodeLatin1,
+		"windows-1252": decodeWindows1252,
+		"cp1252":       decodeWindows1252,
+		"us-ascii":     decodeWindows1252,
+		"ascii":        decodeWindows1252,
+		"utf-16":       decodeUTF16(false),
+		"utf-16be":     decodeUTF16(false),
+		"utf-16le":     decodeUTF16(true),
+	}
+)
+
+// RegisterCharset registers the decoder of a charset, case-insensitively, for Options.DecodeCharset
+// and XML encoding declarations
+// ISO-8859-1, Windows-1252, US-ASCII and UTF-16 are built in, other charsets such as Shift_JIS
+// can be added from golang.org/x/text
+//
+//	gonoleks.RegisterCharset("shift_jis", japanese.ShiftJIS.NewDecoder().Bytes)
+func RegisterCharset(name string, decoder CharsetDecoder) {
+	charsetsMu.Lock()
+	charsets[strings.ToLower(name)] = decoder
+	charsetsMu.Unlock()
+}
+
+// lookupCharset returns the decoder of a charset, nil for UTF-8
+func lookupCharset(name string) (CharsetDecoder, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "utf-8" || name == "utf8" {
+		return nil, nil
+	}
+	charsetsMu.RLock()
+	decoder, ok := charsets[name]
+	charsetsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCharset, name)
+	}
+	return decoder, nil
+}
+
+// decodeCharset transcodes the request body to UTF-8 according to the charset parameter of its
+// Content-Type, and rewrites the parameter to UTF-8 so the body is transcoded only once
+// Multipart bodies are left unchanged, as their parts declare their own charsets
+func (c *Context) decodeCharset() error {
+	contentType := c.ContentType()
+	if !strings.Contains(strings.ToLower(contentType), "charset") || strings.HasPrefix(contentType, "multipart/") {
+		return nil
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	decoder, err := lookupCharset(params["charset"])
+	if err != nil || decoder == nil {
+		return err
+	}
+	var body []byte
+	if mediaType == MIMEApplicationForm {
+		body, err = decodeFormCharset(c.requestCtx.Request.Body(), decoder)
+	} else {
+		body, err = decoder(c.requestCtx.Request.Body())
+	}
+	if err != nil {
+		return fmt.Errorf("charset %s: %w", params["charset"], err)
+	}
+	params["charset"] = "utf-8"
+	c.requestCtx.Request.SetBody(body)
+	c.requestCtx.Request.Header.SetContentType(mime.FormatMediaType(mediaType, params))
+	if mediaType == MIMEApplicationForm {
+		// Form fields may have been parsed from the original body already
+		c.requestCtx.PostArgs().ParseBytes(body)
+	}
+	return nil
+}
+
+// decodeFormCharset transcodes the unescaped names and values of a urlencoded form,
+// as percent-encoded bytes are in the charset of the form too
+func decodeFormCharset(body []byte, decoder CharsetDecoder) ([]byte, error) {
+	var src, dst fasthttp.Args
+	src.ParseBytes(body)
+	for key, value := range src.All() {
+		decodedKey, err := decoder(key)
+		if err != nil {
+			return nil, err
+		}
+		decodedValue, err := decoder(value)
+		if err != nil {
+			return nil, err
+		}
+		dst.AddBytesKV(decodedKey, decodedValue)
+	}
+	return dst.AppendBytes(nil), nil
+}
+
+// xmlCharsetReader decodes XML documents declaring an encoding other than UTF-8
+// Documents already in UTF-8, e.g. transcoded by Options.DecodeCharset, are passed through
+func xmlCharsetReader(label string, input io.Reader) (io.Reader, error) {
+	decoder, err := lookupCharset(label)
+	if err != nil || decoder == nil {
+		return input, err
+	}
+	src, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	if utf8.Valid(src) {
+		return bytes.NewReader(src), nil
+	}
+	decoded, err := decoder(src)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(decoded), nil
+}
+
+// decodeLatin1 decodes ISO-8859-1, whose bytes are the first 256 code points
+func decodeLatin1(src []byte) ([]byte, error) {
+	dst := make([]byte, 0, len(src)+len(src)/4)
+	for _, b := range src {
+		dst = utf8.AppendRune(dst, rune(b))
+	}
+	return dst, nil
+}
+
+// windows1252 maps the bytes 0x80 to 0x9F of Windows-1252, which differ from ISO-8859-1
+// Unassigned bytes keep their ISO-8859-1 code point
+var windows1252 = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+// decodeWindows1252 decodes Windows-1252
+// US-ASCII is decoded the same way, as legacy clients send Windows-1252 text labeled as such
+func decodeWindows1252(src []byte) ([]byte, error) {
+	dst := make([]byte, 0, len(src)+len(src)/4)
+	for _, b := range src {
+		r := rune(b)
+		if b >= 0x80 && b < 0xA0 {
+			r = windows1252[b-0x80]
+		}
+		dst = utf8.AppendRune(dst, r)
+	}
+	return dst, nil
+}
+
+// decodeUTF16 returns a UTF-16 decoder for the given byte order, overridden by a byte order mark
+func decodeUTF16(littleEndian bool) CharsetDecoder {
+	return func(src []byte) ([]byte, error) {
+		littleEndian := littleEndian
+		if len(src) >= 2 {
+			switch {
+			case src[0] == 0xFE && src[1] == 0xFF:
+				littleEndian, src = false, src[2:]
+			case src[0] == 0xFF && src[1] == 0xFE:
+				littleEndian, src = true, src[2:]
+			}
+		}
+		if len(src)%2 != 0 {
+			return nil, ErrInvalidCharsetData
+		}
+		units := make([]uint16, len(src)/2)
+		for i := range units {
+			if littleEndian {
+				units[i] = uint16(src[2*i]) | uint16(src[2*i+1])<<8
+			} else {
+				units[i] = uint16(src[2*i])<<8 | uint16(src[2*i+1])
+			}
+		}
+		return []byte(string(utf16.Decode(units))), nil
+	}
+}
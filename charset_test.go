@@ -0,0 +1,114 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharsetDecoders(t *testing.T) {
+	tests := []struct {
+		charset  string
+		input    []byte
+		expected string
+	}{
+		{"ISO-8859-1", []byte("caf\xe9 \x80"), "café \u0080"},
+		{"windows-1252", []byte("caf\xe9 \x80\x96"), "café €–"},
+		{"US-ASCII", []byte("plain"), "plain"},
+		{"UTF-16", []byte{0xFF, 0xFE, 'h', 0, 0xE9, 0}, "hé"},
+		{"UTF-16", []byte{0, 'h', 0, 0xE9}, "hé"},
+		{"utf-16le", []byte{0x3D, 0xD8, 0x00, 0xDE}, "😀"},
+	}
+	for _, tt := range tests {
+		decoder, err := lookupCharset(tt.charset)
+		require.NoError(t, err)
+		decoded, err := decoder(tt.input)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, string(decoded), tt.charset)
+	}
+
+	decoder, err := lookupCharset("UTF-8")
+	assert.NoError(t, err)
+	assert.Nil(t, decoder)
+	_, err = lookupCharset("koi8-r")
+	assert.ErrorIs(t, err, ErrUnsupportedCharset)
+	_, err = decodeUTF16(false)([]byte{0})
+	assert.ErrorIs(t, err, ErrInvalidCharsetData)
+}
+
+func charsetContext(contentType string, body []byte) (*Context, *Gonoleks) {
+	c, requestCtx := createTestContext()
+	c.app = New()
+	c.app.DecodeCharset = true
+	requestCtx.Request.Header.SetMethod(MethodPost)
+	requestCtx.Request.Header.SetContentType(contentType)
+	requestCtx.Request.SetBody(body)
+	return c, c.app
+}
+
+func TestDecodeCharsetBinding(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"JSON", "application/json; charset=ISO-8859-1", "{\"name\":\"Jos\xe9\",\"age\":13}"},
+		{"XML", "application/xml; charset=windows-1252", "<?xml version=\"1.0\" encoding=\"windows-1252\"?><user><name>Jos\xe9</name><age>13</age></user>"},
+		{"Form", MIMEApplicationForm + "; charset=latin1", "name=Jos%E9&age=13"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := charsetContext(tt.contentType, []byte(tt.body))
+			var user bindingUser
+			require.NoError(t, c.ShouldBind(&user))
+			assert.Equal(t, bindingUser{Name: "José", Age: 13}, user)
+			// The body is transcoded once, binding again sees UTF-8
+			assert.Contains(t, c.ContentType(), "charset=utf-8")
+			require.NoError(t, c.ShouldBind(&user))
+			assert.Equal(t, "José", user.Name)
+		})
+	}
+}
+
+func TestDecodeCharsetFormParsedEarly(t *testing.T) {
+	c, _ := charsetContext(MIMEApplicationForm+"; charset=windows-1252", []byte("name=\x80+%E9"))
+	// A middleware reading the form parses the original body
+	c.requestCtx.PostArgs()
+	var user bindingUser
+	require.NoError(t, c.ShouldBind(&user))
+	assert.Equal(t, "€ é", user.Name)
+}
+
+func TestDecodeCharsetErrors(t *testing.T) {
+	c, _ := charsetContext("application/json; charset=koi8-r", []byte(`{"name":"x"}`))
+	var user bindingUser
+	assert.ErrorIs(t, c.Bind(&user), ErrUnsupportedCharset)
+	assert.Equal(t, StatusUnsupportedMediaType, c.requestCtx.Response.StatusCode())
+
+	c, _ = charsetContext("application/json; charset=utf-16", []byte{'{'})
+	err := c.Bind(&user)
+	assert.ErrorIs(t, err, ErrInvalidCharsetData)
+	assert.Equal(t, StatusBadRequest, c.requestCtx.Response.StatusCode())
+
+	// Without the option, the charset is ignored
+	c, app := charsetContext("application/json; charset=koi8-r", []byte(`{"name":"x"}`))
+	app.DecodeCharset = false
+	require.NoError(t, c.ShouldBind(&user))
+	assert.Equal(t, "x", user.Name)
+}
+
+func TestRegisterCharset(t *testing.T) {
+	RegisterCharset("X-Upper", func(src []byte) ([]byte, error) { return []byte("{\"name\":\"UP\"}"), nil })
+	c, _ := charsetContext("application/json; charset=x-upper", []byte("anything"))
+	var user bindingUser
+	require.NoError(t, c.ShouldBindBodyWith(&user, BindingJSON))
+	assert.Equal(t, "UP", user.Name)
+}
+
+func TestXMLEncodingDeclaration(t *testing.T) {
+	var user bindingUser
+	body := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><user><name>Jos\xe9</name></user>")
+	require.NoError(t, BindingXML.BindBody(body, &user))
+	assert.Equal(t, "José", user.Name)
+}
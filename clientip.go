@@ -0,0 +1,122 @@
+package gonoleks
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// ClientIPResolver determines the client IP address of a request
+// It returns an empty string when the IP cannot be determined, in which case
+// Context.ClientIP falls back to the address of the direct peer
+type ClientIPResolver func(c *Context) string
+
+// SetClientIPResolver replaces the strategy used by Context.ClientIP
+// Built-in resolvers cover common CDNs and can be combined with ClientIPResolvers
+//
+//	app.SetClientIPResolver(gonoleks.ClientIPResolvers(
+//		gonoleks.ClientIPFromCFConnectingIP,
+//		gonoleks.ClientIPFromXForwardedFor,
+//	))
+func (g *Gonoleks) SetClientIPResolver(resolver ClientIPResolver) {
+	g.clientIPResolver = resolver
+}
+
+// ClientIPResolvers returns a resolver trying each resolver in order until one returns an IP
+func ClientIPResolvers(resolvers ...ClientIPResolver) ClientIPResolver {
+	return func(c *Context) string {
+		for _, resolver := range resolvers {
+			if ip := resolver(c); ip != "" {
+				return ip
+			}
+		}
+		return ""
+	}
+}
+
+// ClientIPFromHeader returns a resolver reading the client IP from a single-value header
+// The header is only honored when the request comes from a trusted proxy
+func ClientIPFromHeader(header string) ClientIPResolver {
+	return func(c *Context) string {
+		if !c.isTrustedProxy() {
+			return ""
+		}
+		return normalizeIP(c.GetHeader(header))
+	}
+}
+
+// ClientIPFromCFConnectingIP resolves the client IP from the CF-Connecting-IP header set by Cloudflare
+func ClientIPFromCFConnectingIP(c *Context) string {
+	return ClientIPFromHeader(HeaderCFConnectingIP)(c)
+}
+
+// ClientIPFromTrueClientIP resolves the client IP from the True-Client-IP header set by Akamai and Cloudflare Enterprise
+func ClientIPFromTrueClientIP(c *Context) string {
+	return ClientIPFromHeader(HeaderTrueClientIP)(c)
+}
+
+// ClientIPFromXRealIP resolves the client IP from the X-Real-IP header
+func ClientIPFromXRealIP(c *Context) string {
+	return ClientIPFromHeader(HeaderXRealIP)(c)
+}
+
+// ClientIPFromXForwardedFor resolves the client IP from the X-Forwarded-For header
+// When Options.TrustedProxies is set, the rightmost address that is not a trusted proxy is returned,
+// since entries left of it may be forged by the client
+// Otherwise the leftmost address is returned
+func ClientIPFromXForwardedFor(c *Context) string {
+	if !c.isTrustedProxy() {
+		return ""
+	}
+	return c.forwardedChainIP(strings.Split(c.GetHeader(HeaderXForwardedFor), ","))
+}
+
+// ClientIPFromForwarded resolves the client IP from the "for" parameter of the RFC 7239 Forwarded header
+// Hops are evaluated like ClientIPFromXForwardedFor, obfuscated identifiers such as "unknown" yield no IP
+func ClientIPFromForwarded(c *Context) string {
+	element, ok := c.forwardedElement()
+	if !ok {
+		return ""
+	}
+	ip := normalizeIP(element.For)
+	if ip == "" || c.app == nil || len(c.app.trustedPrefixes()) == 0 || !c.app.isTrustedAddr(net.ParseIP(ip)) {
+		return ip
+	}
+	// Every hop is a trusted proxy
+	return ""
+}
+
+// forwardedChainIP picks the client address from a list of hops ordered from client to nearest proxy
+func (c *Context) forwardedChainIP(hops []string) string {
+	if c.app == nil || len(c.app.trustedPrefixes()) == 0 {
+		if len(hops) == 0 {
+			return ""
+		}
+		return normalizeIP(hops[0])
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := normalizeIP(hops[i])
+		if ip == "" {
+			return ""
+		}
+		if !c.app.isTrustedAddr(net.ParseIP(ip)) {
+			return ip
+		}
+	}
+	return ""
+}
+
+// normalizeIP validates an IP address, optionally bracketed or with a port,
+// and returns it in canonical form, or an empty string if it is invalid
+func normalizeIP(value string) string {
+	value = strings.TrimSpace(value)
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return ""
+	}
+	return addr.Unmap().String()
+}
@@ -0,0 +1,84 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIPTrustedProxies(t *testing.T) {
+	app := New()
+	app.TrustedProxies = []string{"10.0.0.0/8"}
+	app.setupRouter()
+
+	newContext := func(remoteIP string, headers map[string]string) *Context {
+		return &Context{app: app, requestCtx: newProxiedRequest(MethodGet, "/", remoteIP, headers)}
+	}
+
+	// Spoofed entries left of the first untrusted hop are ignored
+	c := newContext("10.0.0.2", map[string]string{HeaderXForwardedFor: "1.1.1.1, 203.0.113.7, 10.0.0.1"})
+	assert.Equal(t, "203.0.113.7", c.ClientIP())
+
+	// Headers from untrusted peers are ignored
+	c = newContext("198.51.100.1", map[string]string{HeaderXForwardedFor: "1.1.1.1", HeaderXRealIP: "2.2.2.2"})
+	assert.Equal(t, "198.51.100.1", c.ClientIP())
+
+	c = newContext("10.0.0.2", map[string]string{HeaderXRealIP: "2.2.2.2"})
+	assert.Equal(t, "2.2.2.2", c.ClientIP())
+
+	c = newContext("10.0.0.2", map[string]string{HeaderXForwardedFor: "not-an-ip"})
+	assert.Equal(t, "10.0.0.2", c.ClientIP())
+}
+
+func TestSetClientIPResolver(t *testing.T) {
+	app := New()
+	app.SetClientIPResolver(ClientIPResolvers(ClientIPFromCFConnectingIP, ClientIPFromTrueClientIP))
+	app.setupRouter()
+
+	c := &Context{app: app, requestCtx: newProxiedRequest(MethodGet, "/", "10.0.0.2", map[string]string{
+		HeaderCFConnectingIP: "203.0.113.9",
+		HeaderXForwardedFor:  "1.1.1.1",
+	})}
+	assert.Equal(t, "203.0.113.9", c.ClientIP())
+
+	c = &Context{app: app, requestCtx: newProxiedRequest(MethodGet, "/", "10.0.0.2", map[string]string{
+		HeaderTrueClientIP: "2001:db8::1",
+	})}
+	assert.Equal(t, "2001:db8::1", c.ClientIP())
+
+	// The resolver replaces the default, so X-Forwarded-For is not consulted
+	c = &Context{app: app, requestCtx: newProxiedRequest(MethodGet, "/", "10.0.0.2", map[string]string{
+		HeaderXForwardedFor: "1.1.1.1",
+	})}
+	assert.Equal(t, "10.0.0.2", c.ClientIP())
+}
+
+func TestClientIPFromForwarded(t *testing.T) {
+	app := New()
+	app.SetClientIPResolver(ClientIPFromForwarded)
+	app.setupRouter()
+
+	c := &Context{app: app, requestCtx: newProxiedRequest(MethodGet, "/", "10.0.0.2", map[string]string{
+		HeaderForwarded: `for="[2001:db8:cafe::17]:4711";proto=https, for=192.0.2.43`,
+	})}
+	assert.Equal(t, "2001:db8:cafe::17", c.ClientIP())
+
+	app.TrustedProxies = []string{"10.0.0.0/8", "192.0.2.43"}
+	app.setTrustedProxies(app.TrustedProxies)
+	assert.Equal(t, "2001:db8:cafe::17", c.ClientIP())
+
+	c = &Context{app: app, requestCtx: newProxiedRequest(MethodGet, "/", "10.0.0.2", map[string]string{
+		HeaderForwarded: "for=unknown",
+	})}
+	assert.Equal(t, "10.0.0.2", c.ClientIP())
+}
+
+func TestNormalizeIP(t *testing.T) {
+	assert.Equal(t, "192.0.2.1", normalizeIP(" 192.0.2.1 "))
+	assert.Equal(t, "192.0.2.1", normalizeIP("192.0.2.1:8080"))
+	assert.Equal(t, "2001:db8::1", normalizeIP("[2001:db8::1]:443"))
+	assert.Equal(t, "2001:db8::1", normalizeIP("[2001:db8::1]"))
+	assert.Equal(t, "192.0.2.1", normalizeIP("::ffff:192.0.2.1"))
+	assert.Empty(t, normalizeIP("unknown"))
+	assert.Empty(t, normalizeIP(""))
+}
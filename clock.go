@@ -0,0 +1,105 @@
+package gonoleks
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// randKey is the user value key of the random number generator of the request
+const randKey = "gonoleksRand"
+
+// Clock tells the time to handlers through Context.Now, see SetClock
+type Clock interface {
+	Now() time.Time
+}
+
+// ManualClock is a Clock for tests, which only moves when it is set or advanced
+// It is safe for concurrent use
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock returns a ManualClock stopped at now
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now returns the time the clock is stopped at
+func (m *ManualClock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// Set stops the clock at now
+func (m *ManualClock) Set(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now
+}
+
+// Advance moves the clock forward by d
+func (m *ManualClock) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+}
+
+// SetClock sets the clock of Context.Now, e.g. a ManualClock in tests, nil restores the system clock
+// It must be set before the server starts, gonolekstest.Deterministic sets it along with SetRandSeed
+//
+//	clock := gonoleks.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	app.SetClock(clock)
+//	clock.Advance(time.Hour)
+func (g *Gonoleks) SetClock(clock Clock) {
+	g.clock = clock
+}
+
+// SetRandSeed makes Context.Rand deterministic: the generator of every request starts from seed,
+// so a request draws the same numbers whatever the order requests are served in
+// It is meant for tests and must be set before the server starts
+func (g *Gonoleks) SetRandSeed(seed uint64) {
+	g.randSeed = &seed
+}
+
+// Now returns the current time of the app clock, the system clock unless SetClock replaced it
+// Handlers reading the time through it become deterministic in tests
+func (c *Context) Now() time.Time {
+	if c.app != nil {
+		return c.app.now()
+	}
+	return time.Now()
+}
+
+// now returns the current time of the app clock
+func (g *Gonoleks) now() time.Time {
+	if g.clock != nil {
+		return g.clock.Now()
+	}
+	return time.Now()
+}
+
+// Rand returns the random number generator of the request, randomly seeded unless SetRandSeed
+// made it deterministic
+// It is created on first use and must not be used by other goroutines
+//
+//	code := fmt.Sprintf("%06d", c.Rand().IntN(1000000))
+func (c *Context) Rand() *rand.Rand {
+	if c.requestCtx != nil {
+		if r, ok := c.requestCtx.UserValue(randKey).(*rand.Rand); ok {
+			return r
+		}
+	}
+	var r *rand.Rand
+	if c.app != nil && c.app.randSeed != nil {
+		r = rand.New(rand.NewPCG(*c.app.randSeed, *c.app.randSeed))
+	} else {
+		r = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	if c.requestCtx != nil {
+		c.requestCtx.SetUserValue(randKey, r)
+	}
+	return r
+}
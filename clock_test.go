@@ -0,0 +1,66 @@
+package gonoleks
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextNow(t *testing.T) {
+	c, _ := createTestContext()
+	assert.WithinDuration(t, time.Now(), c.Now(), time.Second)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	app := New()
+	app.SetClock(clock)
+	app.GET("/now", func(c *Context) { c.String(StatusOK, "%s", c.Now().Format(time.RFC3339)) })
+
+	body := func() string {
+		resp := app.Test(httptest.NewRequest(MethodGet, "/now", nil))
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b)
+	}
+	assert.Equal(t, "2024-01-01T00:00:00Z", body())
+	clock.Advance(90 * time.Minute)
+	assert.Equal(t, "2024-01-01T01:30:00Z", body())
+	clock.Set(start.AddDate(1, 0, 0))
+	assert.Equal(t, "2025-01-01T00:00:00Z", body())
+
+	app.SetClock(nil)
+	assert.NotEqual(t, "2025-01-01T00:00:00Z", body())
+}
+
+func TestContextRand(t *testing.T) {
+	app := New()
+	app.SetRandSeed(42)
+	app.GET("/rand", func(c *Context) {
+		// The generator is scoped to the request
+		assert.Same(t, c.Rand(), c.Rand())
+		c.String(StatusOK, "%s", strconv.Itoa(c.Rand().IntN(1000000))+" "+strconv.Itoa(c.Rand().IntN(1000000)))
+	})
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/rand", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	first := string(reqCtx.Response.Body())
+	reqCtx = newProxiedRequest(MethodGet, "/rand", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, first, string(reqCtx.Response.Body()))
+
+	c, _ := createTestContext()
+	a, b := c.Rand(), c.Rand()
+	assert.Same(t, a, b)
+	c.app = app
+	c.requestCtx.RemoveUserValue(randKey)
+	assert.Equal(t, first, strconv.Itoa(c.Rand().IntN(1000000))+" "+strconv.Itoa(c.Rand().IntN(1000000)))
+
+	// Copies outside the request get a generator of their own
+	detached := c.Copy()
+	assert.NotSame(t, detached.Rand(), detached.Rand())
+}
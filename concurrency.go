@@ -0,0 +1,49 @@
+package gonoleks
+
+import (
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// MaxConcurrent instances a middleware that bounds how many requests run the
+// remaining handlers at the same time
+// Requests over the limit wait up to queueTimeout for a free slot and are then
+// rejected with 503 Service Unavailable. A non-positive queueTimeout sheds them immediately
+// The limit is shared by every route the returned middleware is attached to,
+// so use it on a group to bound the group as a whole or per route for separate limits
+func MaxConcurrent(n int, queueTimeout time.Duration) handlerFunc {
+	if n <= 0 {
+		n = 1
+	}
+	slots := make(chan struct{}, n)
+	return func(c *Context) {
+		select {
+		case slots <- struct{}{}:
+		default:
+			if !waitForSlot(slots, queueTimeout) {
+				c.requestCtx.Error(fasthttp.StatusMessage(StatusServiceUnavailable), StatusServiceUnavailable)
+				c.requestCtx.Response.Header.Set(HeaderRetryAfter, "1")
+				c.Abort()
+				return
+			}
+		}
+		defer func() { <-slots }()
+		c.Next()
+	}
+}
+
+// waitForSlot blocks until a slot is acquired or the timeout expires
+func waitForSlot(slots chan struct{}, timeout time.Duration) bool {
+	if timeout <= 0 {
+		return false
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,56 @@
+package gonoleks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestMaxConcurrent(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	app := New()
+	app.GET("/report", MaxConcurrent(1, 20*time.Millisecond), func(c *Context) {
+		started <- struct{}{}
+		<-release
+		c.String(StatusOK, "done")
+	})
+	app.GET("/queued", MaxConcurrent(1, time.Second), func(c *Context) {
+		started <- struct{}{}
+		<-release
+		c.String(StatusOK, "done")
+	})
+	app.setupRouter()
+
+	request := func(path string) *fasthttp.RequestCtx {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(path)
+		reqCtx.Request.Header.SetMethod(MethodGet)
+		app.router.Handler(reqCtx)
+		return reqCtx
+	}
+	run := func(path string) <-chan *fasthttp.RequestCtx {
+		done := make(chan *fasthttp.RequestCtx, 1)
+		go func() { done <- request(path) }()
+		return done
+	}
+
+	// The slot is taken, so another request is shed after the queue timeout
+	first := run("/report")
+	<-started
+	shed := request("/report")
+	assert.Equal(t, StatusServiceUnavailable, shed.Response.StatusCode())
+	assert.Equal(t, "1", string(shed.Response.Header.Peek(HeaderRetryAfter)))
+
+	// A request queued within the timeout runs once the slot is released
+	holder := run("/queued")
+	<-started
+	waiting := run("/queued")
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	assert.Equal(t, StatusOK, (<-first).Response.StatusCode())
+	assert.Equal(t, StatusOK, (<-holder).Response.StatusCode())
+	assert.Equal(t, "done", string((<-waiting).Response.Body()))
+}
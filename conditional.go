@@ -0,0 +1,120 @@
+package gonoleks
+
+import (
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ServeConditional sets the Last-Modified and ETag response headers and evaluates
+// the If-None-Match and If-Modified-Since request headers against them
+// When the client copy is still fresh it responds with 304 Not Modified and returns true,
+// in which case the handler should not write a body
+// A zero modTime or an empty etag skips the corresponding validator
+//
+//	if c.ServeConditional(report.UpdatedAt, report.Version) {
+//		return
+//	}
+func (c *Context) ServeConditional(modTime time.Time, etag string) bool {
+	if !modTime.IsZero() {
+		c.requestCtx.Response.Header.Set(HeaderLastModified, modTime.UTC().Format(http.TimeFormat))
+	}
+	if etag != "" {
+		etag = quoteETag(etag)
+		c.requestCtx.Response.Header.Set(HeaderETag, etag)
+	}
+	if !c.isNotModified(modTime, etag) {
+		return false
+	}
+	c.NotModified()
+	return true
+}
+
+// NotModified responds with 304 Not Modified and aborts the handler chain
+// The validators already set on the response are kept, the body and content headers are dropped
+func (c *Context) NotModified() {
+	c.requestCtx.Response.ResetBody()
+	c.requestCtx.Response.Header.Del(HeaderContentType)
+	c.requestCtx.Response.Header.Del(HeaderContentEncoding)
+	c.requestCtx.SetStatusCode(StatusNotModified)
+	c.Abort()
+}
+
+// isNotModified reports whether the request validators match the given ones
+// If-None-Match takes precedence over If-Modified-Since as required by RFC 9110, 13.2.2
+func (c *Context) isNotModified(modTime time.Time, etag string) bool {
+	method := c.requestCtx.Method()
+	if string(method) != MethodGet && string(method) != MethodHead {
+		return false
+	}
+	if inm := c.requestCtx.Request.Header.Peek(HeaderIfNoneMatch); len(inm) > 0 {
+		return etag != "" && etagMatches(string(inm), etag)
+	}
+	if modTime.IsZero() {
+		return false
+	}
+	ims := c.requestCtx.Request.Header.Peek(HeaderIfModifiedSince)
+	if len(ims) == 0 {
+		return false
+	}
+	since, err := fasthttp.ParseHTTPDate(ims)
+	if err != nil {
+		return false
+	}
+	// HTTP dates have a one second resolution
+	return !modTime.Truncate(time.Second).After(since)
+}
+
+// etagMatches performs the weak comparison of an If-None-Match header value against an entity tag
+func etagMatches(header, etag string) bool {
+	target := strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == target {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteETag wraps an entity tag in double quotes unless it already is a quoted or weak tag
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// fileETag builds a weak entity tag from a file size and modification time
+func fileETag(size int64, modTime time.Time) string {
+	return `W/"` + strconv.FormatInt(size, 16) + "-" + strconv.FormatInt(modTime.UnixNano(), 16) + `"`
+}
+
+// serveFileConditional evaluates conditional request headers for a file
+// Files without a modification time, such as those from embed.FS, are always served
+func (c *Context) serveFileConditional(info fs.FileInfo) bool {
+	if info == nil || info.IsDir() || info.ModTime().IsZero() {
+		return false
+	}
+	return c.ServeConditional(info.ModTime(), fileETag(info.Size(), info.ModTime()))
+}
+
+// serveStaticConditional adds an ETag to a file response produced by fasthttp.FS
+// and answers If-None-Match requests with 304 Not Modified
+// fasthttp.FS already handles If-Modified-Since itself
+func (c *Context) serveStaticConditional() {
+	lastModified := c.requestCtx.Response.Header.Peek(HeaderLastModified)
+	if len(lastModified) == 0 {
+		return
+	}
+	modTime, err := fasthttp.ParseHTTPDate(lastModified)
+	if err != nil {
+		return
+	}
+	size := int64(c.requestCtx.Response.Header.ContentLength())
+	c.ServeConditional(modTime, fileETag(size, modTime))
+}
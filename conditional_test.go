@@ -0,0 +1,97 @@
+package gonoleks
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestContextServeConditional(t *testing.T) {
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	app := New()
+	app.GET("/report", func(c *Context) {
+		if c.ServeConditional(modTime, "v1") {
+			return
+		}
+		c.String(StatusOK, "report")
+	})
+	app.POST("/report", func(c *Context) {
+		if c.ServeConditional(modTime, "v1") {
+			return
+		}
+		c.String(StatusOK, "updated")
+	})
+	app.setupRouter()
+
+	tests := []struct {
+		name     string
+		method   string
+		header   string
+		value    string
+		expected int
+	}{
+		{"No validators", MethodGet, "", "", StatusOK},
+		{"Matching ETag", MethodGet, HeaderIfNoneMatch, `"v1"`, StatusNotModified},
+		{"Weak matching ETag", MethodGet, HeaderIfNoneMatch, `"v0", W/"v1"`, StatusNotModified},
+		{"Wildcard ETag", MethodGet, HeaderIfNoneMatch, "*", StatusNotModified},
+		{"Stale ETag", MethodGet, HeaderIfNoneMatch, `"v0"`, StatusOK},
+		{"Not modified since", MethodGet, HeaderIfModifiedSince, modTime.Format(http.TimeFormat), StatusNotModified},
+		{"Modified since", MethodGet, HeaderIfModifiedSince, modTime.Add(-time.Hour).Format(http.TimeFormat), StatusOK},
+		{"Unsafe method", MethodPost, HeaderIfNoneMatch, `"v1"`, StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqCtx := &fasthttp.RequestCtx{}
+			reqCtx.Request.SetRequestURI("/report")
+			reqCtx.Request.Header.SetMethod(tt.method)
+			if tt.header != "" {
+				reqCtx.Request.Header.Set(tt.header, tt.value)
+			}
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.expected, reqCtx.Response.StatusCode())
+			assert.Equal(t, `"v1"`, string(reqCtx.Response.Header.Peek(HeaderETag)))
+			assert.Equal(t, modTime.Format(http.TimeFormat), string(reqCtx.Response.Header.Peek(HeaderLastModified)))
+			if tt.expected == StatusNotModified {
+				assert.Empty(t, reqCtx.Response.Body())
+			}
+		})
+	}
+}
+
+func TestContextFileConditional(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "data.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("hello"), 0o600))
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filePath, modTime, modTime))
+
+	app := New()
+	app.GET("/file", func(c *Context) {
+		c.File(filePath)
+	})
+	app.Static("/static", dir)
+	app.setupRouter()
+
+	for _, path := range []string{"/file", "/static/data.txt"} {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(path)
+		reqCtx.Request.Header.SetMethod(MethodGet)
+		app.router.Handler(reqCtx)
+		assert.Equal(t, StatusOK, reqCtx.Response.StatusCode(), path)
+		etag := string(reqCtx.Response.Header.Peek(HeaderETag))
+		assert.NotEmpty(t, etag, path)
+
+		reqCtx = &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(path)
+		reqCtx.Request.Header.SetMethod(MethodGet)
+		reqCtx.Request.Header.Set(HeaderIfNoneMatch, etag)
+		app.router.Handler(reqCtx)
+		assert.Equal(t, StatusNotModified, reqCtx.Response.StatusCode(), path)
+	}
+}
@@ -0,0 +1,112 @@
+package gonoleks
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ConnStats reports connection counters of the server
+type ConnStats struct {
+	// Open is the number of connections currently open
+	Open int64
+
+	// Accepted is the total number of connections accepted
+	Accepted uint64
+
+	// Rejected is the total number of connections closed by an OnConnOpen hook or the ban list
+	Rejected uint64
+
+	// Closed is the total number of accepted connections closed or hijacked
+	Closed uint64
+}
+
+// connHooks holds the connection hooks and counters
+type connHooks struct {
+	mu       sync.RWMutex
+	onOpen   []func(conn net.Conn) error
+	onClose  []func(conn net.Conn)
+	rejected sync.Map
+	open     atomic.Int64
+	accepted atomic.Uint64
+	refused  atomic.Uint64
+	closed   atomic.Uint64
+}
+
+// OnConnOpen registers a hook called for every new connection before any request is read
+// Returning an error closes the connection, e.g. to enforce a licensed connection limit
+//
+//	app.OnConnOpen(func(conn net.Conn) error {
+//		if app.ConnStats().Open > licensedConns {
+//			return errors.New("connection limit reached")
+//		}
+//		return nil
+//	})
+func (g *Gonoleks) OnConnOpen(hook func(conn net.Conn) error) {
+	g.connHooks.mu.Lock()
+	g.connHooks.onOpen = append(g.connHooks.onOpen, hook)
+	g.connHooks.mu.Unlock()
+}
+
+// OnConnClose registers a hook called when an accepted connection is closed or hijacked
+func (g *Gonoleks) OnConnClose(hook func(conn net.Conn)) {
+	g.connHooks.mu.Lock()
+	g.connHooks.onClose = append(g.connHooks.onClose, hook)
+	g.connHooks.mu.Unlock()
+}
+
+// ConnStats returns the connection counters, e.g. for a live connection dashboard
+func (g *Gonoleks) ConnStats() ConnStats {
+	return ConnStats{
+		Open:     g.connHooks.open.Load(),
+		Accepted: g.connHooks.accepted.Load(),
+		Rejected: g.connHooks.refused.Load(),
+		Closed:   g.connHooks.closed.Load(),
+	}
+}
+
+// connState dispatches fasthttp connection state changes to the hooks and counters
+func (g *Gonoleks) connState(conn net.Conn, state fasthttp.ConnState) {
+	if g.SlowRequestThreshold > 0 {
+		g.trackRequestStart(conn, state)
+	}
+	h := &g.connHooks
+	switch state {
+	case fasthttp.StateNew:
+		if tcpAddr, ok := conn.RemoteAddr().(*net.TCPAddr); ok && g.isBanned(tcpAddr.IP) {
+			h.rejected.Store(conn, struct{}{})
+			h.refused.Add(1)
+			g.diagnostics.Debug("Connection from banned IP rejected", "remote", conn.RemoteAddr())
+			_ = conn.Close()
+			return
+		}
+		h.mu.RLock()
+		hooks := h.onOpen
+		h.mu.RUnlock()
+		for _, hook := range hooks {
+			if err := hook(conn); err != nil {
+				h.rejected.Store(conn, struct{}{})
+				h.refused.Add(1)
+				g.diagnostics.Debug("Connection rejected", "remote", conn.RemoteAddr(), "error", err)
+				_ = conn.Close()
+				return
+			}
+		}
+		h.accepted.Add(1)
+		h.open.Add(1)
+	case fasthttp.StateHijacked, fasthttp.StateClosed:
+		if _, rejected := h.rejected.LoadAndDelete(conn); rejected {
+			return
+		}
+		h.open.Add(-1)
+		h.closed.Add(1)
+		h.mu.RLock()
+		hooks := h.onClose
+		h.mu.RUnlock()
+		for _, hook := range hooks {
+			hook(conn)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package gonoleks
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestConnHooks(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	var opened, closed atomic.Int32
+	app.OnConnOpen(func(conn net.Conn) error {
+		if opened.Add(1) > 1 {
+			return errors.New("connection limit reached")
+		}
+		return nil
+	})
+	app.OnConnClose(func(conn net.Conn) { closed.Add(1) })
+	app.setupRouter()
+	server := app.newHTTPServer()
+
+	ln := fasthttputil.NewInmemoryListener()
+	go func() { _ = server.Serve(ln) }()
+	defer ln.Close()
+	client := &fasthttp.Client{
+		Dial:            func(addr string) (net.Conn, error) { return ln.Dial() },
+		MaxConnsPerHost: 1,
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI("http://example.com/")
+	require.NoError(t, client.Do(req, resp))
+	assert.Equal(t, "ok", string(resp.Body()))
+	require.Eventually(t, func() bool { return app.ConnStats().Open == 1 }, time.Second, time.Millisecond)
+
+	// A second connection is refused by the hook
+	conn, err := ln.Dial()
+	require.NoError(t, err)
+	_, _ = conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	buf := make([]byte, 1)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conn.Read(buf)
+	assert.Error(t, err)
+	_ = conn.Close()
+
+	client.CloseIdleConnections()
+	require.Eventually(t, func() bool { return app.ConnStats().Open == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, ConnStats{Open: 0, Accepted: 1, Rejected: 1, Closed: 1}, app.ConnStats())
+	assert.Equal(t, int32(1), closed.Load())
+}
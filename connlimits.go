@@ -0,0 +1,69 @@
+package gonoleks
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// configureConnLimits installs the header and body read deadlines and slow request tracking on the server
+func (g *Gonoleks) configureConnLimits(server *fasthttp.Server) {
+	bodyTimeout := g.ReadTimeout
+	if g.BodyReadTimeout > 0 {
+		bodyTimeout = g.BodyReadTimeout
+	}
+	if g.HeaderReadTimeout > 0 {
+		// fasthttp applies ReadTimeout as soon as a request starts arriving,
+		// the body deadline is restored once the headers are in
+		server.ReadTimeout = g.HeaderReadTimeout
+	}
+	if g.HeaderReadTimeout > 0 || g.BodyReadTimeout > 0 {
+		server.HeaderReceived = func(*fasthttp.RequestHeader) fasthttp.RequestConfig {
+			return fasthttp.RequestConfig{ReadTimeout: bodyTimeout}
+		}
+	}
+	server.ConnState = g.connState
+}
+
+// listen opens the listener of the server, honoring TCPKeepalive for TCP networks
+// fasthttp only applies its keep-alive settings to listeners it opens itself
+func (g *Gonoleks) listen(network, address string) (net.Listener, error) {
+	lc := net.ListenConfig{KeepAlive: -1}
+	if g.TCPKeepalive {
+		lc.KeepAlive = g.TCPKeepalivePeriod
+	}
+	return lc.Listen(context.Background(), network, address)
+}
+
+// trackRequestStart records when each connection starts receiving a request
+func (g *Gonoleks) trackRequestStart(conn net.Conn, state fasthttp.ConnState) {
+	switch state {
+	case fasthttp.StateActive:
+		g.connStarts.Store(conn, time.Now())
+	case fasthttp.StateIdle, fasthttp.StateHijacked, fasthttp.StateClosed:
+		g.connStarts.Delete(conn)
+	}
+}
+
+// checkSlowRequest logs requests that took longer than SlowRequestThreshold to read
+// It returns true if the request must be aborted
+func (g *Gonoleks) checkSlowRequest(fctx *fasthttp.RequestCtx) bool {
+	start, ok := g.connStarts.Load(fctx.Conn())
+	if !ok {
+		return false
+	}
+	elapsed := time.Since(start.(time.Time))
+	if elapsed <= g.SlowRequestThreshold {
+		return false
+	}
+	g.diagnostics.Warn("Slow request",
+		"method", string(fctx.Method()),
+		"path", string(fctx.Path()),
+		"ip", fctx.RemoteIP().String(),
+		"read", elapsed,
+		"aborted", g.AbortSlowRequests,
+	)
+	return g.AbortSlowRequests
+}
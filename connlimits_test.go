@@ -0,0 +1,138 @@
+package gonoleks
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestConnLimitOptions(t *testing.T) {
+	app := New()
+	app.MaxConnsPerIP = 10
+	app.MaxRequestsPerConn = 100
+	app.TCPKeepalive = true
+	app.TCPKeepalivePeriod = time.Minute
+	app.ReadTimeout = 5 * time.Second
+	app.HeaderReadTimeout = time.Second
+	server := app.newHTTPServer()
+
+	assert.Equal(t, 10, server.MaxConnsPerIP)
+	assert.Equal(t, 100, server.MaxRequestsPerConn)
+	assert.True(t, server.TCPKeepalive)
+	assert.Equal(t, time.Minute, server.TCPKeepalivePeriod)
+	assert.Equal(t, time.Second, server.ReadTimeout)
+	require.NotNil(t, server.HeaderReceived)
+	assert.Equal(t, 5*time.Second, server.HeaderReceived(nil).ReadTimeout)
+	assert.NotNil(t, server.ConnState)
+}
+
+func TestListenerTuningOptions(t *testing.T) {
+	app := New()
+	app.SleepWhenConcurrencyLimitsExceeded = 100 * time.Millisecond
+	app.MaxIdleWorkerDuration = time.Minute
+	app.ReadTimeout = 5 * time.Second
+	app.BodyReadTimeout = 30 * time.Second
+	server := app.newHTTPServer()
+
+	assert.Equal(t, 100*time.Millisecond, server.SleepWhenConcurrencyLimitsExceeded)
+	assert.Equal(t, time.Minute, server.MaxIdleWorkerDuration)
+	assert.Equal(t, 5*time.Second, server.ReadTimeout, "headers keep the full read timeout")
+	require.NotNil(t, server.HeaderReceived)
+	assert.Equal(t, 30*time.Second, server.HeaderReceived(nil).ReadTimeout)
+
+	app.HeaderReadTimeout = time.Second
+	server = app.newHTTPServer()
+	assert.Equal(t, time.Second, server.ReadTimeout)
+	assert.Equal(t, 30*time.Second, server.HeaderReceived(nil).ReadTimeout)
+}
+
+func TestListenTCPKeepalive(t *testing.T) {
+	for _, keepalive := range []bool{false, true} {
+		app := New()
+		app.TCPKeepalive = keepalive
+		app.TCPKeepalivePeriod = time.Minute
+		ln, err := app.listen("tcp4", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		accepted := make(chan net.Conn, 1)
+		go func() {
+			conn, _ := ln.Accept()
+			accepted <- conn
+		}()
+		client, err := net.Dial("tcp4", ln.Addr().String())
+		require.NoError(t, err)
+		conn := <-accepted
+		require.NotNil(t, conn)
+		assert.IsType(t, &net.TCPConn{}, conn)
+		_ = conn.Close()
+		_ = client.Close()
+		_ = ln.Close()
+	}
+}
+
+func TestHeaderReadTimeout(t *testing.T) {
+	app := New()
+	app.HeaderReadTimeout = 50 * time.Millisecond
+	app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	app.setupRouter()
+	server := app.newHTTPServer()
+
+	ln := fasthttputil.NewInmemoryListener()
+	go func() { _ = server.Serve(ln) }()
+	defer ln.Close()
+
+	conn, err := ln.Dial()
+	require.NoError(t, err)
+	defer conn.Close()
+	// Send an incomplete header and stall like a slowloris client
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n"))
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = bufio.NewReader(conn).ReadString(0)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection was not closed after the header read timeout")
+	}
+}
+
+func TestSlowRequestDetector(t *testing.T) {
+	app := New()
+	app.SlowRequestThreshold = time.Second
+	app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	app.setupRouter()
+	assert.NotNil(t, app.newHTTPServer().ConnState)
+
+	serve := func(start time.Time) *fasthttp.RequestCtx {
+		server, client := net.Pipe()
+		defer server.Close()
+		defer client.Close()
+		app.trackRequestStart(server, fasthttp.StateActive)
+		app.connStarts.Store(server, start)
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Init2(server, nil, false)
+		reqCtx.Request.SetRequestURI("/")
+		app.router.Handler(reqCtx)
+		app.trackRequestStart(server, fasthttp.StateIdle)
+		_, tracked := app.connStarts.Load(server)
+		assert.False(t, tracked)
+		return reqCtx
+	}
+
+	// Slow requests are only logged by default
+	assert.Equal(t, StatusOK, serve(time.Now().Add(-2*time.Second)).Response.StatusCode())
+
+	app.AbortSlowRequests = true
+	assert.Equal(t, StatusRequestTimeout, serve(time.Now().Add(-2*time.Second)).Response.StatusCode())
+	assert.Equal(t, StatusOK, serve(time.Now()).Response.StatusCode())
+}
@@ -15,28 +15,53 @@ const (
 	MethodTrace   = "TRACE"   // RFC 7231, 4.3.8
 )
 
+// WebDAV methods, routable with Handle like any valid method token
+// Gonoleks.RegisterMethod also includes them in the routes registered with Any
+const (
+	MethodPropfind  = "PROPFIND"  // RFC 4918, 9.1
+	MethodProppatch = "PROPPATCH" // RFC 4918, 9.2
+	MethodMkcol     = "MKCOL"     // RFC 4918, 9.3
+	MethodCopy      = "COPY"      // RFC 4918, 9.8
+	MethodMove      = "MOVE"      // RFC 4918, 9.9
+	MethodLock      = "LOCK"      // RFC 4918, 9.10
+	MethodUnlock    = "UNLOCK"    // RFC 4918, 9.11
+	MethodReport    = "REPORT"    // RFC 3253, 3.6
+	MethodSearch    = "SEARCH"    // RFC 5323, 2
+)
+
 // MIME types
 const (
-	MIMETextXML               = "text/xml"
-	MIMETextHTML              = "text/html"
-	MIMETextPlain             = "text/plain"
-	MIMETextJavaScript        = "text/javascript"
-	MIMETextCSS               = "text/css"
-	MIMEApplicationXML        = "application/xml"
-	MIMEApplicationJSON       = "application/json"
-	MIMEApplicationYAML       = "application/x-yaml"
-	MIMEApplicationTOML       = "application/toml"
-	MIMEApplicationProtoBuf   = "application/x-protobuf"
-	MIMEApplicationJavaScript = "application/javascript"
-	MIMEApplicationForm       = "application/x-www-form-urlencoded"
-	MIMEOctetStream           = "application/octet-stream"
-	MIMEMultipartForm         = "multipart/form-data"
+	MIMETextXML                 = "text/xml"
+	MIMETextHTML                = "text/html"
+	MIMETextPlain               = "text/plain"
+	MIMETextJavaScript          = "text/javascript"
+	MIMETextCSS                 = "text/css"
+	MIMETextEventStream         = "text/event-stream"
+	MIMETextCSV                 = "text/csv"
+	MIMEApplicationXML          = "application/xml"
+	MIMEApplicationJSON         = "application/json"
+	MIMEApplicationYAML         = "application/x-yaml"
+	MIMEApplicationTOML         = "application/toml"
+	MIMEApplicationProtoBuf     = "application/x-protobuf"
+	MIMEApplicationJavaScript   = "application/javascript"
+	MIMEApplicationForm         = "application/x-www-form-urlencoded"
+	MIMEOctetStream             = "application/octet-stream"
+	MIMEMultipartForm           = "multipart/form-data"
+	MIMEApplicationProblemJSON  = "application/problem+json"
+	MIMEApplicationProto        = "application/proto"
+	MIMEApplicationGRPCWeb      = "application/grpc-web"
+	MIMEApplicationGRPCWebProto = "application/grpc-web+proto"
+	MIMEApplicationGRPCWebText  = "application/grpc-web-text"
+	MIMEImagePNG                = "image/png"
+	MIMEImageJPEG               = "image/jpeg"
+	MIMEApplicationXLSX         = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 
 	MIMETextXMLCharsetUTF8         = "text/xml; charset=utf-8"
 	MIMETextHTMLCharsetUTF8        = "text/html; charset=utf-8"
 	MIMETextPlainCharsetUTF8       = "text/plain; charset=utf-8"
 	MIMETextJavaScriptCharsetUTF8  = "text/javascript; charset=utf-8"
 	MIMETextCSSCharsetUTF8         = "text/css; charset=utf-8"
+	MIMETextCSVCharsetUTF8         = "text/csv; charset=utf-8"
 	MIMEApplicationXMLCharsetUTF8  = "application/xml; charset=utf-8"
 	MIMEApplicationJSONCharsetUTF8 = "application/json; charset=utf-8"
 )
@@ -177,6 +202,8 @@ const (
 	HeaderXForwardedSsl                      = "X-Forwarded-Ssl"
 	HeaderXUrlScheme                         = "X-Url-Scheme"
 	HeaderXRealIP                            = "X-Real-IP"
+	HeaderCFConnectingIP                     = "CF-Connecting-IP"
+	HeaderTrueClientIP                       = "True-Client-IP"
 	HeaderLocation                           = "Location"
 	HeaderFrom                               = "From"
 	HeaderHost                               = "Host"
@@ -217,10 +244,13 @@ const (
 	HeaderSecWebSocketProtocol               = "Sec-WebSocket-Protocol"
 	HeaderSecWebSocketVersion                = "Sec-WebSocket-Version"
 	HeaderAcceptPatch                        = "Accept-Patch"
+	HeaderAcceptPost                         = "Accept-Post"
 	HeaderAcceptPushPolicy                   = "Accept-Push-Policy"
 	HeaderAcceptSignature                    = "Accept-Signature"
 	HeaderAltSvc                             = "Alt-Svc"
 	HeaderDate                               = "Date"
+	HeaderDeprecation                        = "Deprecation"
+	HeaderSunset                             = "Sunset"
 	HeaderIndex                              = "Index"
 	HeaderLargeAllocation                    = "Large-Allocation"
 	HeaderLink                               = "Link"
@@ -230,13 +260,26 @@ const (
 	HeaderSignature                          = "Signature"
 	HeaderSignedHeaders                      = "Signed-Headers"
 	HeaderSourceMap                          = "SourceMap"
+	HeaderTraceparent                        = "Traceparent"
 	HeaderUpgrade                            = "Upgrade"
+	HeaderXAppVersion                        = "X-App-Version"
+	HeaderHXRequest                          = "HX-Request"
+	HeaderHXBoosted                          = "HX-Boosted"
+	HeaderHXTarget                           = "HX-Target"
+	HeaderXUpTarget                          = "X-Up-Target"
+	HeaderTurboFrame                         = "Turbo-Frame"
 	HeaderXDNSPrefetchControl                = "X-DNS-Prefetch-Control"
 	HeaderXPingback                          = "X-Pingback"
 	HeaderXRequestID                         = "X-Request-ID"
+	HeaderXRequestDeadline                   = "X-Request-Deadline"
+	HeaderXRequestTimeout                    = "X-Request-Timeout"
+	HeaderXTimezone                          = "X-Timezone"
+	HeaderXNonce                             = "X-Nonce"
+	HeaderXTimestamp                         = "X-Timestamp"
 	HeaderXRequestedWith                     = "X-Requested-With"
 	HeaderXRobotsTag                         = "X-Robots-Tag"
 	HeaderXUACompatible                      = "X-UA-Compatible"
+	HeaderXTotalCount                        = "X-Total-Count"
 	HeaderAccessControlAllowPrivateNetwork   = "Access-Control-Allow-Private-Network"
 	HeaderAccessControlRequestPrivateNetwork = "Access-Control-Request-Private-Network"
 	HeaderXTest                              = "X-Test"
@@ -273,4 +316,57 @@ var (
 	ErrCannotReadNilBody            = errors.New("cannot read nil body")
 	ErrNamedCookieNotPresent        = errors.New("named cookie not present")
 	ErrFileNotFound                 = errors.New("file not found")
+	ErrOAuth2StateMismatch          = errors.New("OAuth2 state mismatch")
+	ErrOAuth2MissingCode            = errors.New("OAuth2 callback is missing the authorization code")
+	ErrOAuth2AuthorizationDenied    = errors.New("OAuth2 authorization denied")
+	ErrOAuth2TokenExchange          = errors.New("OAuth2 token request failed")
+	ErrOpenAPIInvalidSpec           = errors.New("invalid OpenAPI document")
+	ErrRPCMalformedFrame            = errors.New("malformed gRPC-Web frame")
+	ErrRPCCompressionUnsupported    = errors.New("compressed gRPC-Web frames are not supported")
+	ErrCrashReportRejected          = errors.New("crash report rejected")
+	ErrURLSigningKeyMissing         = errors.New("URL signing key is not configured")
+	ErrSignedURLInvalid             = errors.New("invalid URL signature")
+	ErrSignedURLExpired             = errors.New("signed URL has expired")
+	ErrUnsafePath                   = errors.New("unsafe file path")
+	ErrNotAcceptable                = errors.New("none of the offered formats is acceptable")
+	ErrNoHealthyBackend             = errors.New("no healthy backend available")
+	ErrUnknownBackend               = errors.New("unknown backend")
+	ErrInvalidBackend               = errors.New("backend URL must be an absolute http or https URL")
+	ErrDeadlineExceeded             = errors.New("request deadline exceeded")
+	ErrBindTarget                   = errors.New("binding target must be a non-nil pointer to a struct")
+	ErrBindUnsupportedType          = errors.New("unsupported field type for binding")
+	ErrEmptyRequestBody             = errors.New("request body is empty")
+	ErrBindTypeMismatch             = errors.New("type mismatch")
+	ErrBindRequired                 = errors.New("value is required")
+	ErrNonceMissing                 = errors.New("request nonce is missing or invalid")
+	ErrNonceReplayed                = errors.New("request nonce has already been used")
+	ErrNonceStale                   = errors.New("request timestamp is outside the accepted window")
+	ErrTenantMissing                = errors.New("request does not identify a tenant")
+	ErrTenantUnknown                = errors.New("unknown tenant")
+	ErrBindIndexLimit               = errors.New("slice index exceeds the limit of 1000")
+	ErrUnknownHandler               = errors.New("unknown handler name")
+	ErrInvalidRouteSpec             = errors.New("invalid route spec")
+	ErrHubClosed                    = errors.New("hub is closed")
+	ErrHubClientExists              = errors.New("hub client ID is already registered")
+	ErrClientDisconnected           = errors.New("client disconnected")
+	ErrInvalidRuntimeConfig         = errors.New("invalid runtime config value")
+	ErrKVNotInteger                 = errors.New("value is not an integer")
+	ErrUnsupportedCharset           = errors.New("unsupported charset")
+	ErrInvalidCharsetData           = errors.New("invalid data for charset")
+	ErrXMLTooDeep                   = errors.New("XML document exceeds the maximum depth")
+	ErrXMLTooManyTokens             = errors.New("XML document exceeds the maximum number of tokens")
+	ErrXMLDirective                 = errors.New("XML document contains a DTD or other directive")
+	ErrTemplateEngineMissing        = errors.New("no template engine is set")
+	ErrTemplateParse                = errors.New("failed to parse templates")
+	ErrTemplateRender               = errors.New("failed to render template")
+	ErrUnknownRoute                 = errors.New("unknown route name")
+	ErrRouteParams                  = errors.New("invalid route params")
+	ErrInvalidPagination            = errors.New("invalid pagination params")
+	ErrInvalidListQuery             = errors.New("invalid sort or filter params")
+	ErrServiceNotProvided           = errors.New("no service of this type is provided")
+	ErrServiceCycle                 = errors.New("service dependency cycle")
+	ErrResponseStatus               = errors.New("response has an error status")
+	ErrHandlerPanicked              = errors.New("handler panicked")
+	ErrInvalidStub                  = errors.New("invalid stub")
+	ErrFixtureMismatch              = errors.New("response does not match fixture")
 )
@@ -0,0 +1,73 @@
+package gonoleks
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// RequireContentType instances a middleware that rejects requests with an unsafe method, such as
+// POST, PUT, PATCH or DELETE, whose body is not of one of the given media types with
+// 415 Unsupported Media Type, listing the accepted types in Accept-Post or Accept-Patch
+// Media types may be wildcards such as "image/*", "*/*" or "application/*+json", and
+// parameters of the request Content-Type are ignored unless a type names a charset,
+// in which case a differing charset is rejected. Requests without a body pass unchecked
+//
+//	api.POST("/users", gonoleks.RequireContentType(gonoleks.MIMEApplicationJSON), createUser)
+func RequireContentType(types ...string) handlerFunc {
+	accepted := strings.Join(types, ", ")
+	return func(c *Context) {
+		method := string(c.requestCtx.Method())
+		if isSafeMethod(method) || (len(c.requestCtx.Request.Body()) == 0 && c.ContentType() == "") {
+			c.Next()
+			return
+		}
+		if contentTypeAllowed(c.ContentType(), types) {
+			c.Next()
+			return
+		}
+		c.requestCtx.Error(fasthttp.StatusMessage(StatusUnsupportedMediaType), StatusUnsupportedMediaType)
+		switch method {
+		case MethodPost:
+			c.requestCtx.Response.Header.Set(HeaderAcceptPost, accepted)
+		case MethodPatch:
+			c.requestCtx.Response.Header.Set(HeaderAcceptPatch, accepted)
+		}
+		c.Abort()
+	}
+}
+
+// isSafeMethod reports whether a method is safe as defined by RFC 9110 section 9.2.1
+func isSafeMethod(method string) bool {
+	return method == MethodGet || method == MethodHead || method == MethodOptions || method == MethodTrace
+}
+
+// contentTypeAllowed reports whether a Content-Type header matches any of the media types
+func contentTypeAllowed(contentType string, types []string) bool {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range types {
+		allowed, allowedParams, err := mime.ParseMediaType(t)
+		if err != nil || !matchContentType(allowed, mediaType) {
+			continue
+		}
+		charset, ok := allowedParams["charset"]
+		if !ok || params["charset"] == "" || strings.EqualFold(charset, params["charset"]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchContentType reports whether a media range, including structured syntax suffix
+// wildcards such as "application/*+json", matches a media type
+func matchContentType(mediaRange, mediaType string) bool {
+	if prefix, suffix, ok := strings.Cut(mediaRange, "/*+"); ok {
+		typ, subtype, _ := strings.Cut(mediaType, "/")
+		return strings.EqualFold(prefix, typ) && strings.HasSuffix(strings.ToLower(subtype), "+"+strings.ToLower(suffix))
+	}
+	return matchMediaRange(mediaRange, mediaType)
+}
@@ -0,0 +1,57 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireContentType(t *testing.T) {
+	app := New()
+	app.Use(RequireContentType(MIMEApplicationJSON+"; charset=utf-8", "application/*+json", "image/*"))
+	app.Any("/upload", func(c *Context) {
+		c.Status(StatusNoContent)
+	})
+	app.setupRouter()
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		code        int
+	}{
+		{"JSON", MethodPost, MIMEApplicationJSON, "{}", StatusNoContent},
+		{"JSON with charset", MethodPut, "Application/JSON; charset=UTF-8", "{}", StatusNoContent},
+		{"JSON with other charset", MethodPost, "application/json; charset=iso-8859-1", "{}", StatusUnsupportedMediaType},
+		{"Structured suffix", MethodPatch, "application/merge-patch+json", "{}", StatusNoContent},
+		{"Type wildcard", MethodPost, "image/png", "png", StatusNoContent},
+		{"Mismatch", MethodPost, MIMEApplicationXML, "<a/>", StatusUnsupportedMediaType},
+		{"Malformed", MethodPost, "json;", "{}", StatusUnsupportedMediaType},
+		{"Missing with body", MethodDelete, "", "{}", StatusUnsupportedMediaType},
+		{"Missing without body", MethodDelete, "", "", StatusNoContent},
+		{"Safe method", MethodGet, MIMEApplicationXML, "", StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.contentType != "" {
+				headers[HeaderContentType] = tt.contentType
+			}
+			reqCtx := newProxiedRequest(tt.method, "/upload", "203.0.113.5", headers)
+			reqCtx.Request.SetBodyString(tt.body)
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.code, reqCtx.Response.StatusCode())
+		})
+	}
+}
+
+func TestRequireContentTypeAcceptHeaders(t *testing.T) {
+	c, requestCtx := createTestContext()
+	requestCtx.Request.Header.SetMethod(MethodPatch)
+	requestCtx.Request.Header.SetContentType(MIMETextPlain)
+	requestCtx.Request.SetBodyString("x")
+	RequireContentType(MIMEApplicationJSON, "application/merge-patch+json")(c)
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, "application/json, application/merge-patch+json", string(requestCtx.Response.Header.Peek(HeaderAcceptPatch)))
+}
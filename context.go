@@ -1,17 +1,20 @@
 package gonoleks
 
 import (
+	"bufio"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"io/fs"
 	"maps"
+	"mime"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
-	"charm.land/log/v2"
 	"github.com/bytedance/sonic"
 	"github.com/valyala/fasthttp"
 	"google.golang.org/protobuf/proto"
@@ -24,13 +27,18 @@ type handlerFunc func(c *Context)
 // handlersChain is a slice of handlers
 type handlersChain []handlerFunc
 
+// errorsKey is the user value key under which the errors recorded with Error are stored
+const errorsKey = "gonoleksErrors"
+
 // Context represents the current HTTP request and response context
 type Context struct {
+	app         *Gonoleks
 	requestCtx  *fasthttp.RequestCtx
 	paramValues map[string]string
 	fullPath    string
 	handlers    handlersChain
 	index       int
+	queryCache  url.Values
 }
 
 // Context returns the underlying fasthttp RequestCtx object
@@ -42,6 +50,7 @@ func (c *Context) Context() *fasthttp.RequestCtx {
 // This has to be used when the context has to be passed to a goroutine
 func (c *Context) Copy() *Context {
 	contextCopy := &Context{
+		app:        c.app,
 		requestCtx: nil,
 		fullPath:   c.fullPath,
 		index:      c.index,
@@ -115,13 +124,53 @@ func (c *Context) AbortWithStatusJSON(code int, jsonObj any) error {
 	return c.JSON(code, jsonObj)
 }
 
-// AbortWithError calls `AbortWithStatus()` and logs the given error
+// AbortWithError calls `AbortWithStatus()`, logs the given error and records it with Error
 func (c *Context) AbortWithError(code int, err error) error {
 	c.AbortWithStatus(code)
-	log.Error(err, "code", code)
+	c.Error(err)
+	c.diagnostics().Error(err, "code", code)
+	if c.app != nil && c.app.debugErrorPageEnabled() {
+		c.requestCtx.SetUserValue(debugErrorKey, err)
+	}
+	return err
+}
+
+// Error records an error of the request without changing the response,
+// for middleware such as WithResource to act on after the handler
+func (c *Context) Error(err error) error {
+	if err != nil {
+		errs, _ := c.requestCtx.UserValue(errorsKey).([]error)
+		c.requestCtx.SetUserValue(errorsKey, append(errs, err))
+	}
 	return err
 }
 
+// Errors returns the errors recorded with Error and AbortWithError
+func (c *Context) Errors() []error {
+	errs, _ := c.requestCtx.UserValue(errorsKey).([]error)
+	return errs
+}
+
+// AbortWithStatusProblem calls `Abort()` and writes an RFC 9457 problem details body
+// with the given status, detail message and validation problems
+func (c *Context) AbortWithStatusProblem(code int, detail string, problems []ValidationProblem) error {
+	c.Abort()
+	body := H{
+		"type":   "about:blank",
+		"title":  fasthttp.StatusMessage(code),
+		"status": code,
+		"detail": detail,
+	}
+	if len(problems) > 0 {
+		body["errors"] = problems
+	}
+	if err := c.JSON(code, body); err != nil {
+		return err
+	}
+	c.requestCtx.Response.Header.SetContentType(MIMEApplicationProblemJSON)
+	return nil
+}
+
 // Set is used to store a new key/value pair exclusively for this context
 func (c *Context) Set(key, value any) {
 	if key == nil {
@@ -216,42 +265,48 @@ func (c *Context) GetQuery(key string) (string, bool) {
 // QueryArray returns a slice of strings for a given query key
 // The length of the slice depends on the number of parameters with the given key
 func (c *Context) QueryArray(key string) []string {
-	values := []string{}
-	for k, v := range c.requestCtx.QueryArgs().All() {
-		if string(k) == key {
-			values = append(values, getString(v))
-		}
-	}
+	values, _ := c.GetQueryArray(key)
 	return values
 }
 
 // GetQueryArray returns a slice of strings for a given query key, plus
 // a boolean value whether at least one value exists for the given key
 func (c *Context) GetQueryArray(key string) ([]string, bool) {
-	values := []string{}
-	for k, v := range c.requestCtx.QueryArgs().All() {
-		if string(k) == key {
-			values = append(values, getString(v))
-		}
+	if values, ok := c.QueryValues()[key]; ok {
+		return values, len(values) > 0
 	}
-	return values, len(values) > 0
+	return []string{}, false
 }
 
 // QueryMap returns a map for a given query key
 func (c *Context) QueryMap(key string) map[string]string {
 	result := make(map[string]string)
-	for k, v := range c.requestCtx.QueryArgs().All() {
-		keyStr := string(k)
+	for keyStr, values := range c.QueryValues() {
 		// Check if the key has the format we're looking for (e.g., user[name], user[email])
 		if strings.HasPrefix(keyStr, key+"[") && strings.HasSuffix(keyStr, "]") {
 			// Extract the map key from between the brackets
 			mapKey := keyStr[len(key)+1 : len(keyStr)-1]
-			result[mapKey] = getString(v)
+			result[mapKey] = values[len(values)-1]
 		}
 	}
 	return result
 }
 
+// QueryValues returns all query string parameters of the request URL
+// The query string is parsed once per request and cached, so repeated lookups are O(1)
+// The returned values are shared with QueryArray and QueryMap and must not be modified
+func (c *Context) QueryValues() url.Values {
+	if c.queryCache == nil {
+		args := c.requestCtx.QueryArgs()
+		c.queryCache = make(url.Values, args.Len())
+		for k, v := range args.All() {
+			key := string(k)
+			c.queryCache[key] = append(c.queryCache[key], string(v))
+		}
+	}
+	return c.queryCache
+}
+
 // GetQueryMap returns a map for a given query key, plus a boolean value
 // whether at least one value exists for the given key
 func (c *Context) GetQueryMap(key string) (map[string]string, bool) {
@@ -390,22 +445,28 @@ func (c *Context) GetPostFormMap(key string) (map[string]string, bool) {
 // ClientIP returns the client IP address
 // It tries to determine the real IP address by checking various headers
 // in the following order:
-// 1. X-Forwarded-For
-// 2. X-Real-IP
-// 3. RemoteIP (direct connection)
+// 1. Forwarded (RFC 7239)
+// 2. X-Forwarded-For
+// 3. X-Real-IP
+// 4. RemoteIP (direct connection)
+//
+// Headers are only honored when the request comes from a trusted proxy
+// A custom strategy can be installed with Gonoleks.SetClientIPResolver
 func (c *Context) ClientIP() string {
-	// Check X-Forwarded-For header first
-	if xff := c.GetHeader(HeaderXForwardedFor); xff != "" {
-		// X-Forwarded-For can contain multiple IPs (client, proxy1, proxy2, ...)
-		// The client IP is the first one in the list
-		if commaIndex := strings.IndexByte(xff, ','); commaIndex >= 0 {
-			return strings.TrimSpace(xff[:commaIndex])
+	if c.app != nil && c.app.clientIPResolver != nil {
+		if ip := c.app.clientIPResolver(c); ip != "" {
+			return ip
 		}
-		return strings.TrimSpace(xff)
+		return c.RemoteIP()
+	}
+	if ip := ClientIPFromForwarded(c); ip != "" {
+		return ip
 	}
-	// Check X-Real-IP header
-	if xrip := c.GetHeader(HeaderXRealIP); xrip != "" {
-		return strings.TrimSpace(xrip)
+	if ip := ClientIPFromXForwardedFor(c); ip != "" {
+		return ip
+	}
+	if ip := ClientIPFromXRealIP(c); ip != "" {
+		return ip
 	}
 	// Fall back to direct connection IP
 	return c.RemoteIP()
@@ -431,6 +492,49 @@ func (c *Context) IsWebsocket() bool {
 	return false
 }
 
+// IsAJAX returns true if the request was sent by XMLHttpRequest,
+// as indicated by the X-Requested-With header
+func (c *Context) IsAJAX() bool {
+	return strings.EqualFold(c.GetHeader(HeaderXRequestedWith), "XMLHttpRequest")
+}
+
+// IsJSON returns true if the request body is JSON, including types such as application/problem+json
+func (c *Context) IsJSON() bool {
+	mediaType := c.mediaType()
+	return mediaType == MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+// IsHTML returns true if the request body is HTML
+func (c *Context) IsHTML() bool {
+	return c.mediaType() == MIMETextHTML
+}
+
+// mediaType returns the lowercase request Content-Type without parameters
+func (c *Context) mediaType() string {
+	mediaType, _, _ := strings.Cut(c.ContentType(), ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// Secure returns true if the request was made over HTTPS, directly or through a trusted proxy
+func (c *Context) Secure() bool {
+	return c.Scheme() == "https"
+}
+
+// Protocol returns the HTTP protocol version of the request, e.g. "HTTP/1.1"
+func (c *Context) Protocol() string {
+	return string(c.requestCtx.Request.Header.Protocol())
+}
+
+// BaseURL returns the scheme and host of the request, e.g. "https://example.com"
+func (c *Context) BaseURL() string {
+	return c.Scheme() + "://" + c.Host()
+}
+
+// Referer returns the Referer request header
+func (c *Context) Referer() string {
+	return c.GetHeader(HeaderReferer)
+}
+
 // Status sets the HTTP response code without sending any content
 func (c *Context) Status(code int) *Context {
 	c.requestCtx.Response.SetStatusCode(code)
@@ -443,6 +547,34 @@ func (c *Context) Header(key, value string) *Context {
 	return c
 }
 
+// AddHeader appends a response header value, keeping any values already set for the key,
+// e.g. to send several Link or Vary headers
+func (c *Context) AddHeader(key, value string) *Context {
+	c.requestCtx.Response.Header.Add(key, value)
+	return c
+}
+
+// SetHeaders sets multiple response headers, overwriting existing values
+func (c *Context) SetHeaders(headers map[string]string) *Context {
+	for key, value := range headers {
+		c.requestCtx.Response.Header.Set(key, value)
+	}
+	return c
+}
+
+// SetContentLength sets the Content-Length response header
+// A negative length selects chunked transfer encoding
+func (c *Context) SetContentLength(length int) *Context {
+	c.requestCtx.Response.Header.SetContentLength(length)
+	return c
+}
+
+// SetLastModified sets the Last-Modified response header in HTTP date format
+func (c *Context) SetLastModified(t time.Time) *Context {
+	c.requestCtx.Response.Header.SetLastModified(t)
+	return c
+}
+
 // GetHeader returns the value from request headers
 func (c *Context) GetHeader(key string) string {
 	return getString(c.requestCtx.Request.Header.PeekBytes(getBytes(key)))
@@ -481,7 +613,7 @@ func (c *Context) SetCookie(name, value string, maxAge int, path, domain string,
 	cookie.SetSecure(secure)
 	cookie.SetHTTPOnly(httpOnly)
 	if maxAge > 0 {
-		cookie.SetExpire(time.Now().Add(time.Duration(maxAge) * time.Second))
+		cookie.SetExpire(c.Now().Add(time.Duration(maxAge) * time.Second))
 	} else if maxAge < 0 {
 		cookie.SetExpire(time.Unix(1, 0))
 	}
@@ -505,13 +637,17 @@ func (c *Context) Cookie(name string) (string, error) {
 
 // JSON serializes the given struct as JSON into the response body
 // It also sets the Content-Type as "application/json; charset=utf-8"
+// With JSONETag enabled, successful GET and HEAD responses carry a strong ETag
 func (c *Context) JSON(code int, obj any) error {
 	c.requestCtx.Response.Header.SetContentType(MIMEApplicationJSONCharsetUTF8)
 	c.requestCtx.Response.SetStatusCode(code)
+	if c.jsonETagEnabled(code) {
+		return c.jsonWithETag(obj)
+	}
 	// Use pre-allocated buffer from fasthttp for better performance
 	jsonBytes, err := sonic.ConfigFastest.Marshal(obj)
 	if err != nil {
-		log.Error(ErrJSONMarshalingFailed, "error", err)
+		c.diagnostics().Error(ErrJSONMarshalingFailed, "error", err)
 		return fmt.Errorf("%v: %w", ErrJSONMarshal, err)
 	}
 	// Write directly to response body
@@ -527,7 +663,7 @@ func (c *Context) IndentedJSON(code int, obj any) error {
 	c.requestCtx.Response.Header.SetContentType(MIMEApplicationJSON)
 	raw, err := sonic.ConfigFastest.MarshalIndent(obj, "", "    ")
 	if err != nil {
-		log.Error(ErrIndentedJSONMarshalingFailed, "error", err)
+		c.diagnostics().Error(ErrIndentedJSONMarshalingFailed, "error", err)
 		return fmt.Errorf("%v: %w", ErrIndentedJSONMarshal, err)
 	}
 	c.requestCtx.Response.SetBodyRaw(raw)
@@ -544,7 +680,7 @@ func (c *Context) SecureJSON(code int, obj any) error {
 	c.requestCtx.Response.Header.SetContentType(MIMEApplicationJSON)
 	raw, err := sonic.ConfigFastest.Marshal(obj)
 	if err != nil {
-		log.Error(ErrSecureJSONMarshalingFailed, "error", err)
+		c.diagnostics().Error(ErrSecureJSONMarshalingFailed, "error", err)
 		return fmt.Errorf("%v: %w", ErrSecureJSONMarshal, err)
 	}
 	// Prefix the JSON with the secure string
@@ -560,7 +696,7 @@ func (c *Context) AsciiJSON(code int, obj any) error {
 	c.requestCtx.Response.Header.SetContentType(MIMEApplicationJSON)
 	ret, err := sonic.ConfigFastest.Marshal(obj)
 	if err != nil {
-		log.Error(ErrAsciiJSONMarshalingFailed, "error", err)
+		c.diagnostics().Error(ErrAsciiJSONMarshalingFailed, "error", err)
 		return fmt.Errorf("%v: %w", ErrAsciiJSONMarshal, err)
 	}
 	// Escape all non-ASCII and special characters as \uXXXX
@@ -590,7 +726,7 @@ func (c *Context) PureJSON(code int, obj any) error {
 	c.requestCtx.Response.Header.SetContentType(MIMEApplicationJSON)
 	raw, err := sonic.ConfigFastest.Marshal(obj)
 	if err != nil {
-		log.Error(ErrPureJSONMarshalingFailed, "error", err)
+		c.diagnostics().Error(ErrPureJSONMarshalingFailed, "error", err)
 		return fmt.Errorf("%v: %w", ErrPureJSONMarshal, err)
 	}
 	c.requestCtx.Response.SetBodyRaw(raw)
@@ -604,7 +740,7 @@ func (c *Context) XML(code int, obj any) error {
 	c.requestCtx.Response.Header.SetContentType(MIMEApplicationXML)
 	raw, err := xml.Marshal(obj)
 	if err != nil {
-		log.Error(ErrXMLMarshalingFailed, "error", err)
+		c.diagnostics().Error(ErrXMLMarshalingFailed, "error", err)
 		return fmt.Errorf("%v: %w", ErrXMLMarshal, err)
 	}
 	c.requestCtx.Response.SetBodyRaw(raw)
@@ -618,7 +754,7 @@ func (c *Context) YAML(code int, obj any) error {
 	c.requestCtx.Response.Header.SetContentType(MIMEApplicationYAML)
 	raw, err := yaml.Marshal(obj)
 	if err != nil {
-		log.Error(ErrYAMLMarshalingFailed, "error", err)
+		c.diagnostics().Error(ErrYAMLMarshalingFailed, "error", err)
 		return fmt.Errorf("%v: %w", ErrXMLMarshal, err)
 	}
 	c.requestCtx.Response.SetBodyRaw(raw)
@@ -635,12 +771,12 @@ func (c *Context) ProtoBuf(code int, obj any) error {
 	msg, ok := obj.(proto.Message)
 	if !ok {
 		err := ErrProtoMessageInterface
-		log.Error(ErrProtoBufMarshalingFailed, "error", err)
+		c.diagnostics().Error(ErrProtoBufMarshalingFailed, "error", err)
 		return fmt.Errorf("%v: %w", ErrProtoBufMarshal, err)
 	}
 	raw, err := proto.Marshal(msg)
 	if err != nil {
-		log.Error(ErrProtoBufMarshalingFailed, "error", err)
+		c.diagnostics().Error(ErrProtoBufMarshalingFailed, "error", err)
 		return fmt.Errorf("%v: %w", ErrProtoBufMarshal, err)
 	}
 	c.requestCtx.Response.SetBodyRaw(raw)
@@ -672,39 +808,123 @@ func (c *Context) Data(code int, contentType string, data []byte) *Context {
 	return c
 }
 
+// EarlyHints sends a 103 Early Hints informational response carrying the given Link header values
+// It lets browsers start preloading resources while the handler is still producing the final response
+//
+//	c.EarlyHints("</style.css>; rel=preload; as=style", "<https://cdn.example.com>; rel=preconnect")
+func (c *Context) EarlyHints(links ...string) error {
+	for _, link := range links {
+		c.requestCtx.Response.Header.Add(HeaderLink, link)
+	}
+	return c.requestCtx.EarlyHints()
+}
+
+// Stream sends a streamed response, flushing the buffered bytes to the client after every step
+// The step function is called until it returns false
+// It runs after the handler has returned, so it must not access the Context
+//
+//	c.Stream(func(w io.Writer) bool {
+//	    msg, ok := <-messages
+//	    if ok {
+//	        fmt.Fprintf(w, "%s\n", msg)
+//	    }
+//	    return ok
+//	})
+func (c *Context) Stream(step func(w io.Writer) bool) {
+	c.requestCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		for step(w) {
+			if err := w.Flush(); err != nil {
+				// The client went away, stop producing data
+				return
+			}
+		}
+	})
+}
+
 // File writes the specified file into the body stream in an efficient way
+// Conditional requests are answered with 304 Not Modified and byte ranges are supported
 func (c *Context) File(filePath string) {
-	if !c.checkFileExists(filePath) {
+	info, ok := c.checkFileExists(filePath)
+	if !ok || c.serveFileConditional(info) {
 		return
 	}
+	c.sendFile(filePath, info)
+}
+
+// sendFile writes a local file honoring byte range requests
+func (c *Context) sendFile(filePath string, info fs.FileInfo) {
+	c.prepareByteRange(info)
 	c.requestCtx.SendFile(filePath)
+	c.finishByteRange()
 }
 
 // FileFromFS writes the specified file from fs.FS into the body stream in an efficient way
-func (c *Context) FileFromFS(filePath string, fs fs.FS) {
-	if !c.checkFileExists(filePath) {
+// Conditional requests are answered with 304 Not Modified and byte ranges are supported
+func (c *Context) FileFromFS(filePath string, fsys fs.FS) {
+	cleaned, ok := sanitizePath(filePath)
+	if !ok {
+		_ = c.AbortWithError(StatusBadRequest, ErrUnsafePath)
 		return
 	}
-	fasthttp.ServeFS(c.requestCtx, fs, filePath)
+	filePath = cleaned
+	info, err := fs.Stat(fsys, strings.TrimPrefix(filePath, "/"))
+	if err != nil && os.IsNotExist(err) {
+		_ = c.AbortWithError(StatusNotFound, ErrFileNotFound)
+		return
+	}
+	if c.serveFileConditional(info) {
+		return
+	}
+	c.prepareByteRange(info)
+	fasthttp.ServeFS(c.requestCtx, fsys, filePath)
+	c.finishByteRange()
 }
 
 // FileAttachment writes the specified file into the body stream in an efficient way
 // On the client side, the file will typically be downloaded with the given filename
 func (c *Context) FileAttachment(filePath, fileName string) {
-	if !c.checkFileExists(filePath) {
+	c.fileWithDisposition(filePath, "attachment", fileName)
+}
+
+// FileInline writes the specified file into the body stream in an efficient way
+// On the client side, the file will typically be displayed in the browser, e.g. a PDF preview,
+// and saved with the given filename
+func (c *Context) FileInline(filePath, fileName string) {
+	c.fileWithDisposition(filePath, "inline", fileName)
+}
+
+// fileWithDisposition sends a file with a Content-Disposition header
+// The content type is derived from the extension of fileName when known,
+// otherwise from the file path or by sniffing the file content
+func (c *Context) fileWithDisposition(filePath, disposition, fileName string) {
+	info, ok := c.checkFileExists(filePath)
+	if !ok {
 		return
 	}
-	c.requestCtx.Response.Header.Set(HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
-	c.requestCtx.SendFile(filePath)
+	c.requestCtx.Response.Header.Set(HeaderContentDisposition, contentDisposition(disposition, fileName))
+	if c.serveFileConditional(info) {
+		return
+	}
+	c.sendFile(filePath, info)
+	if contentType := mime.TypeByExtension(filepath.Ext(fileName)); contentType != "" && c.requestCtx.Response.StatusCode() < StatusMultipleChoices {
+		c.requestCtx.SetContentType(contentType)
+	}
 }
 
 // checkFileExists checks if file exists and handles error response
-func (c *Context) checkFileExists(filePath string) bool {
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+// Paths with parent directory references are rejected since they usually come from unsanitized input,
+// use an absolute path to serve files outside the working directory
+func (c *Context) checkFileExists(filePath string) (os.FileInfo, bool) {
+	if hasUnsafePathSegment(filepath.ToSlash(filePath)) {
+		_ = c.AbortWithError(StatusBadRequest, ErrUnsafePath)
+		return nil, false
+	}
+	info, err := os.Stat(filePath)
+	if os.IsNotExist(err) {
 		_ = c.AbortWithError(StatusNotFound, ErrFileNotFound)
-		return false
+		return nil, false
 	}
-	return true
+	return info, true
 }
 
 // SetAccepted sets the formats that are accepted by the client
@@ -3,13 +3,20 @@ package gonoleks
 import (
 	"embed"
 	"errors"
+	"io"
 	"io/fs"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
 
 	"github.com/gonoleks/gonoleks/testdata/protoexample"
 )
@@ -255,6 +262,29 @@ func TestContextHeaders(t *testing.T) {
 	assert.Equal(t, "application/json, application/xml", acceptHeader)
 }
 
+func TestContextHeaderSetters(t *testing.T) {
+	ctx, requestCtx := createTestContext()
+
+	ctx.AddHeader(HeaderVary, HeaderAccept).AddHeader(HeaderVary, HeaderAcceptEncoding)
+	var vary []string
+	for _, value := range requestCtx.Response.Header.PeekAll(HeaderVary) {
+		vary = append(vary, string(value))
+	}
+	assert.Equal(t, []string{HeaderAccept, HeaderAcceptEncoding}, vary)
+
+	ctx.Header(HeaderXTest, "old")
+	ctx.SetHeaders(map[string]string{HeaderXTest: "new", HeaderCacheControl: "no-store"})
+	assert.Equal(t, "new", string(requestCtx.Response.Header.Peek(HeaderXTest)))
+	assert.Equal(t, "no-store", string(requestCtx.Response.Header.Peek(HeaderCacheControl)))
+
+	ctx.SetContentLength(42)
+	assert.Equal(t, 42, requestCtx.Response.Header.ContentLength())
+
+	modified := time.Date(2024, time.March, 5, 10, 30, 0, 0, time.UTC)
+	ctx.SetLastModified(modified)
+	assert.Equal(t, "Tue, 05 Mar 2024 10:30:00 GMT", string(requestCtx.Response.Header.Peek(HeaderLastModified)))
+}
+
 func TestContextJSONRendering(t *testing.T) {
 	ctx, requestCtx := createTestContext()
 	testData := TestUser{Name: "john", Email: "john@example.com"}
@@ -412,3 +442,128 @@ func TestContextFileHandling(t *testing.T) {
 		ctx.FileFromFS("testdata/test_file.txt", embedFS)
 	})
 }
+
+func TestContextEarlyHintsAndStream(t *testing.T) {
+	app := New()
+	app.GET("/page", func(c *Context) {
+		assert.NoError(t, c.EarlyHints("</style.css>; rel=preload; as=style"))
+		c.String(StatusOK, "page")
+	})
+	app.GET("/stream", func(c *Context) {
+		chunks := []string{"one", "two", "three"}
+		c.Stream(func(w io.Writer) bool {
+			if len(chunks) == 0 {
+				return false
+			}
+			_, _ = io.WriteString(w, chunks[0]+"\n")
+			chunks = chunks[1:]
+			return true
+		})
+	})
+	app.setupRouter()
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+	go func() {
+		_ = fasthttp.Serve(ln, app.router.Handler)
+	}()
+
+	conn, err := ln.Dial()
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /page HTTP/1.1\r\nHost: example.com\r\nConnection: close\r\n\r\n"))
+	require.NoError(t, err)
+	raw, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	response := string(raw)
+	assert.True(t, strings.HasPrefix(response, "HTTP/1.1 103 Early Hints\r\nLink: </style.css>; rel=preload; as=style\r\n\r\n"), response)
+	assert.Contains(t, response, "HTTP/1.1 200 OK")
+
+	// Streamed bodies are produced step by step
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/stream")
+	reqCtx.Request.Header.SetMethod(MethodGet)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "one\ntwo\nthree\n", string(reqCtx.Response.Body()))
+}
+
+func TestContextFileInline(t *testing.T) {
+	dir := t.TempDir()
+	blob := filepath.Join(dir, "blob")
+	require.NoError(t, os.WriteFile(blob, []byte("%PDF-1.4 test"), 0o600))
+
+	app := New()
+	app.GET("/preview", func(c *Context) {
+		c.FileInline(blob, "résumé.pdf")
+	})
+	app.GET("/download", func(c *Context) {
+		c.FileAttachment(blob, "data")
+	})
+	app.setupRouter()
+
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/preview")
+	reqCtx.Request.Header.SetMethod(MethodGet)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, `inline; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`, string(reqCtx.Response.Header.Peek(HeaderContentDisposition)))
+	assert.Equal(t, "application/pdf", string(reqCtx.Response.Header.ContentType()))
+
+	// Without a known extension the content type is sniffed from the file
+	reqCtx = &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/download")
+	reqCtx.Request.Header.SetMethod(MethodGet)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, `attachment; filename="data"`, string(reqCtx.Response.Header.Peek(HeaderContentDisposition)))
+	assert.Equal(t, "application/pdf", string(reqCtx.Response.Header.ContentType()))
+}
+
+func TestContextRequestAccessors(t *testing.T) {
+	ctx, requestCtx := createTestContext()
+	requestCtx.Request.Header.Set(HeaderXRequestedWith, "XMLHttpRequest")
+	requestCtx.Request.Header.SetContentType("application/problem+json; charset=utf-8")
+	requestCtx.Request.Header.Set(HeaderReferer, "https://example.com/from")
+	requestCtx.Request.Header.Set(HeaderHost, "api.example.com")
+	requestCtx.Request.Header.Set(HeaderXForwardedProto, "https")
+	assert.False(t, ctx.Secure())
+	ctx.app = New()
+	ctx.app.setTrustedProxies([]string{requestCtx.RemoteIP().String()})
+	assert.True(t, ctx.IsAJAX())
+	assert.True(t, ctx.IsJSON())
+	assert.False(t, ctx.IsHTML())
+	assert.True(t, ctx.Secure())
+	assert.Equal(t, "HTTP/1.1", ctx.Protocol())
+	assert.Equal(t, "https://api.example.com", ctx.BaseURL())
+	assert.Equal(t, "https://example.com/from", ctx.Referer())
+
+	ctx, requestCtx = createTestContext()
+	requestCtx.Request.Header.SetContentType("TEXT/HTML")
+	assert.False(t, ctx.IsAJAX())
+	assert.False(t, ctx.IsJSON())
+	assert.True(t, ctx.IsHTML())
+	assert.False(t, ctx.Secure())
+}
+
+func TestContextQueryValues(t *testing.T) {
+	ctx, requestCtx := createTestContext()
+	requestCtx.Request.SetRequestURI("/search?tag=go&tag=web&q=router&filter[lang]=en&empty=")
+
+	values := ctx.QueryValues()
+	assert.Equal(t, url.Values{
+		"tag":          {"go", "web"},
+		"q":            {"router"},
+		"filter[lang]": {"en"},
+		"empty":        {""},
+	}, values)
+	// The parsed query is cached for the rest of the request
+	requestCtx.QueryArgs().Add("tag", "late")
+	assert.Equal(t, []string{"go", "web"}, ctx.QueryArray("tag"))
+
+	arr, ok := ctx.GetQueryArray("empty")
+	assert.True(t, ok)
+	assert.Equal(t, []string{""}, arr)
+	arr, ok = ctx.GetQueryArray("missing")
+	assert.False(t, ok)
+	assert.Empty(t, arr)
+	assert.Equal(t, map[string]string{"lang": "en"}, ctx.QueryMap("filter"))
+}
@@ -0,0 +1,147 @@
+package gonoleks
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// deadlineKey is the user value key under which the request deadline is stored
+const deadlineKey = "gonoleksDeadline"
+
+// RequestDeadlineConfig defines the config for RequestDeadlineWithConfig
+type RequestDeadlineConfig struct {
+	// Header carries an absolute deadline as RFC 3339 time or Unix milliseconds
+	Header string // Default = "X-Request-Deadline"
+
+	// TimeoutHeader carries a relative timeout as a Go duration, e.g. "250ms", or milliseconds
+	// The earlier of both deadlines applies when both headers are present
+	TimeoutHeader string // Default = "X-Request-Timeout"
+
+	// Default is the timeout applied when the request carries no deadline
+	// No deadline is set when zero
+	Default time.Duration
+
+	// Max caps the timeout a client may request
+	Max time.Duration
+}
+
+// RequestDeadline instances a middleware that applies the deadline sent by upstream gateways
+// in the X-Request-Deadline or X-Request-Timeout header to the request
+// Requests whose deadline already passed are answered with 504 Gateway Timeout
+func RequestDeadline() handlerFunc {
+	return RequestDeadlineWithConfig(RequestDeadlineConfig{})
+}
+
+// RequestDeadlineWithConfig instances a request deadline middleware with config
+func RequestDeadlineWithConfig(conf RequestDeadlineConfig) handlerFunc {
+	if conf.Header == "" {
+		conf.Header = HeaderXRequestDeadline
+	}
+	if conf.TimeoutHeader == "" {
+		conf.TimeoutHeader = HeaderXRequestTimeout
+	}
+	return func(c *Context) {
+		now := c.Now()
+		deadline, ok := parseDeadline(c.GetHeader(conf.Header))
+		if timeout, found := parseTimeout(c.GetHeader(conf.TimeoutHeader)); found {
+			if d := now.Add(timeout); !ok || d.Before(deadline) {
+				deadline, ok = d, true
+			}
+		}
+		if !ok && conf.Default > 0 {
+			deadline, ok = now.Add(conf.Default), true
+		}
+		if !ok {
+			c.Next()
+			return
+		}
+		if conf.Max > 0 && deadline.Sub(now) > conf.Max {
+			deadline = now.Add(conf.Max)
+		}
+		if !deadline.After(now) {
+			c.requestCtx.Error(fasthttp.StatusMessage(StatusGatewayTimeout), StatusGatewayTimeout)
+			c.Abort()
+			return
+		}
+		c.requestCtx.SetUserValue(deadlineKey, deadline)
+		c.Next()
+	}
+}
+
+// RequestDeadline returns the deadline applied by the RequestDeadline middleware, if any
+func (c *Context) RequestDeadline() (time.Time, bool) {
+	deadline, ok := c.requestCtx.UserValue(deadlineKey).(time.Time)
+	return deadline, ok
+}
+
+// DeadlineContext returns a context carrying the values of Detach that is canceled
+// at the request deadline, for passing to database drivers and other context aware clients
+// The cancel function must be called to release resources
+func (c *Context) DeadlineContext() (context.Context, context.CancelFunc) {
+	ctx := c.Detach()
+	if deadline, ok := c.RequestDeadline(); ok {
+		return context.WithDeadline(ctx, deadline)
+	}
+	return context.WithCancel(ctx)
+}
+
+// Do performs an outbound request within the request deadline and propagates it
+// through the X-Request-Deadline header, so downstream services stop working
+// once the caller has given up
+// A default client is used when client is nil
+// It returns ErrDeadlineExceeded without sending the request when the deadline already passed
+//
+//	req.SetRequestURI("http://inventory/items/42")
+//	if err := c.Do(nil, req, resp); err != nil {
+//		return err
+//	}
+func (c *Context) Do(client *fasthttp.Client, req *fasthttp.Request, resp *fasthttp.Response) error {
+	if client == nil {
+		client = defaultOutboundClient
+	}
+	deadline, ok := c.RequestDeadline()
+	if !ok {
+		return client.Do(req, resp)
+	}
+	if !deadline.After(c.Now()) {
+		return ErrDeadlineExceeded
+	}
+	req.Header.Set(HeaderXRequestDeadline, deadline.UTC().Format(time.RFC3339Nano))
+	if id := c.requestID(); id != "" && len(req.Header.Peek(HeaderXRequestID)) == 0 {
+		req.Header.Set(HeaderXRequestID, id)
+	}
+	return client.DoDeadline(req, resp, deadline)
+}
+
+// defaultOutboundClient is used by Context.Do when no client is given
+var defaultOutboundClient = &fasthttp.Client{}
+
+// parseDeadline parses an RFC 3339 time or Unix milliseconds
+func parseDeadline(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), true
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	return t, err == nil
+}
+
+// parseTimeout parses a Go duration or a number of milliseconds
+func parseTimeout(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, true
+	}
+	d, err := time.ParseDuration(value)
+	return d, err == nil
+}
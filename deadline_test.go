@@ -0,0 +1,110 @@
+package gonoleks
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRequestDeadline(t *testing.T) {
+	app := New()
+	app.Use(RequestDeadlineWithConfig(RequestDeadlineConfig{Max: time.Minute}))
+	var remaining time.Duration
+	var found bool
+	app.GET("/work", func(c *Context) {
+		var deadline time.Time
+		deadline, found = c.RequestDeadline()
+		remaining = time.Until(deadline)
+		c.Status(StatusOK)
+	})
+	app.setupRouter()
+
+	future := time.Now().Add(10 * time.Second)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		code    int
+		found   bool
+		min     time.Duration
+		max     time.Duration
+	}{
+		{"No header", nil, StatusOK, false, 0, 0},
+		{"RFC 3339 deadline", map[string]string{HeaderXRequestDeadline: future.UTC().Format(time.RFC3339Nano)}, StatusOK, true, 9 * time.Second, 10 * time.Second},
+		{"Unix milliseconds deadline", map[string]string{HeaderXRequestDeadline: strconv.FormatInt(future.UnixMilli(), 10)}, StatusOK, true, 9 * time.Second, 10 * time.Second},
+		{"Timeout duration", map[string]string{HeaderXRequestTimeout: "2s"}, StatusOK, true, time.Second, 2 * time.Second},
+		{"Timeout milliseconds", map[string]string{HeaderXRequestTimeout: "500"}, StatusOK, true, 0, 500 * time.Millisecond},
+		{"Earlier of both", map[string]string{HeaderXRequestDeadline: future.UTC().Format(time.RFC3339Nano), HeaderXRequestTimeout: "1s"}, StatusOK, true, 0, time.Second},
+		{"Capped at max", map[string]string{HeaderXRequestTimeout: "1h"}, StatusOK, true, 59 * time.Second, time.Minute},
+		{"Expired", map[string]string{HeaderXRequestDeadline: time.Now().Add(-time.Second).UTC().Format(time.RFC3339)}, StatusGatewayTimeout, false, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, remaining = false, 0
+			reqCtx := newProxiedRequest(MethodGet, "/work", "203.0.113.5", tt.headers)
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.code, reqCtx.Response.StatusCode())
+			assert.Equal(t, tt.found, found)
+			if tt.found {
+				assert.Greater(t, remaining, tt.min)
+				assert.LessOrEqual(t, remaining, tt.max)
+			}
+		})
+	}
+}
+
+func TestRequestDeadlineDefault(t *testing.T) {
+	c, _ := createTestContext()
+	handler := RequestDeadlineWithConfig(RequestDeadlineConfig{Default: 3 * time.Second})
+	handler(c)
+	deadline, ok := c.RequestDeadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(3*time.Second), deadline, time.Second)
+}
+
+func TestDeadlineContext(t *testing.T) {
+	c, _ := createTestContext()
+	ctx, cancel := c.DeadlineContext()
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+	cancel()
+
+	deadline := time.Now().Add(50 * time.Millisecond)
+	c.requestCtx.SetUserValue(deadlineKey, deadline)
+	ctx, cancel = c.DeadlineContext()
+	defer cancel()
+	got, ok := ctx.Deadline()
+	require.True(t, ok)
+	assert.Equal(t, deadline, got)
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
+
+func TestContextDoPropagatesDeadline(t *testing.T) {
+	received := make(chan string, 1)
+	client := startTestUpstream(t, func(ctx *fasthttp.RequestCtx) {
+		received <- string(ctx.Request.Header.Peek(HeaderXRequestDeadline))
+	})
+
+	c, _ := createTestContext()
+	deadline := time.Now().Add(5 * time.Second)
+	c.requestCtx.SetUserValue(deadlineKey, deadline)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI("http://upstream/items")
+	require.NoError(t, c.Do(client, req, resp))
+
+	sent, ok := parseDeadline(<-received)
+	require.True(t, ok)
+	assert.True(t, sent.Equal(deadline))
+
+	c.requestCtx.SetUserValue(deadlineKey, time.Now().Add(-time.Second))
+	assert.ErrorIs(t, c.Do(client, req, resp), ErrDeadlineExceeded)
+}
@@ -0,0 +1,231 @@
+package gonoleks
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// debugPanicKey is the user value key under which the panic of a request is kept for the debug error page
+const debugPanicKey = "gonoleksDebugPanic"
+
+// debugErrorKey is the user value key under which AbortWithError keeps its error for the debug error page
+const debugErrorKey = "gonoleksDebugError"
+
+// debugSourceContext is the number of source lines shown around the line that panicked
+const debugSourceContext = 5
+
+// debugPanic describes a recovered panic
+type debugPanic struct {
+	Value  string
+	Stack  string
+	Frames []debugFrame
+	Source []debugSourceLine
+}
+
+// debugFrame is a call stack frame of a panic
+type debugFrame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// debugSourceLine is a source line around the line that panicked
+type debugSourceLine struct {
+	Number  int
+	Text    string
+	Current bool
+}
+
+// debugHeader is a request header shown on the debug error page
+type debugHeader struct {
+	Name  string
+	Value string
+}
+
+// debugPage is the data of the debug error page template
+type debugPage struct {
+	Status    int
+	Title     string
+	Panic     *debugPanic
+	Error     string
+	Method    string
+	URI       string
+	Route     string
+	ClientIP  string
+	RequestID string
+	Headers   []debugHeader
+	GoVersion string
+}
+
+// debugErrorPageEnabled reports whether the debug error page is enabled, by DebugErrorPage or Debug
+func (g *Gonoleks) debugErrorPageEnabled() bool {
+	return g.DebugErrorPage || g.Debug
+}
+
+// capturePanic records the value and call stack of a panic
+// It must be called from the deferred function that recovered rcv
+func capturePanic(rcv any) *debugPanic {
+	p := &debugPanic{Value: fmt.Sprint(rcv), Stack: string(debug.Stack())}
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	// Frames up to runtime.gopanic belong to the deferred function that recovered
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		switch {
+		case frame.Function == "runtime.gopanic":
+			panicking = true
+		case panicking && !strings.HasPrefix(frame.Function, "runtime."):
+			p.Frames = append(p.Frames, debugFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	if len(p.Frames) > 0 {
+		p.Source = readSourceLines(p.Frames[0].File, p.Frames[0].Line)
+	}
+	return p
+}
+
+// readSourceLines returns the lines of file around line, or nil when the file cannot be read
+func readSourceLines(file string, line int) []debugSourceLine {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(string(src), "\n")
+	first := max(line-debugSourceContext, 1)
+	last := min(line+debugSourceContext, len(lines))
+	source := make([]debugSourceLine, 0, last-first+1)
+	for n := first; n <= last; n++ {
+		source = append(source, debugSourceLine{Number: n, Text: lines[n-1], Current: n == line})
+	}
+	return source
+}
+
+// debugErrorPage renders the debug error page for panics and 500 responses of the remaining handlers
+// Panics are recovered like Recovery does, and the page is only sent to clients preferring HTML,
+// the others receive the usual terse response
+func debugErrorPage(c *Context) {
+	defer func() {
+		if rcv := recover(); rcv != nil {
+			p := capturePanic(rcv)
+			c.diagnostics().Error("Recovered from error", "error", rcv, "request_id", c.requestID())
+			c.requestCtx.SetUserValue(debugPanicKey, p)
+			c.requestCtx.Error(fasthttp.StatusMessage(StatusInternalServerError), StatusInternalServerError)
+			c.Abort()
+			c.renderDebugPage()
+		}
+	}()
+	c.Next()
+	if c.requestCtx.Response.StatusCode() == StatusInternalServerError {
+		c.renderDebugPage()
+	}
+}
+
+// renderDebugPage replaces the response body with the debug error page when the client prefers HTML
+func (c *Context) renderDebugPage() {
+	if c.GetHeader(HeaderAccept) == "" || c.Accepts(MIMEApplicationJSON, MIMETextHTML) != MIMETextHTML {
+		return
+	}
+	resp := &c.requestCtx.Response
+	if resp.IsBodyStream() {
+		return
+	}
+	page := debugPage{
+		Status:    resp.StatusCode(),
+		Title:     fasthttp.StatusMessage(resp.StatusCode()),
+		Method:    string(c.requestCtx.Method()),
+		URI:       string(c.requestCtx.RequestURI()),
+		Route:     c.fullPath,
+		ClientIP:  c.ClientIP(),
+		RequestID: c.requestID(),
+		GoVersion: runtime.Version(),
+	}
+	page.Panic, _ = c.requestCtx.UserValue(debugPanicKey).(*debugPanic)
+	if err, ok := c.requestCtx.UserValue(debugErrorKey).(error); ok {
+		page.Error = err.Error()
+	}
+	for key, value := range c.requestCtx.Request.Header.All() {
+		name := http.CanonicalHeaderKey(string(key))
+		if slices.Contains(redactedHeaders, name) {
+			page.Headers = append(page.Headers, debugHeader{Name: name, Value: "[REDACTED]"})
+			continue
+		}
+		page.Headers = append(page.Headers, debugHeader{Name: name, Value: string(value)})
+	}
+	var buf bytes.Buffer
+	if err := debugPageTemplate.Execute(&buf, page); err != nil {
+		c.diagnostics().Error("Failed to render debug error page", "error", err)
+		return
+	}
+	resp.Header.SetContentType(MIMETextHTMLCharsetUTF8)
+	resp.SetBodyRaw(buf.Bytes())
+}
+
+// debugPageTemplate is the debug error page
+var debugPageTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Status}} {{.Title}}</title>
+<style>
+body{margin:0;font:14px/1.5 -apple-system,BlinkMacSystemFont,"Segoe UI",sans-serif;color:#222;background:#fafafa}
+header{padding:24px 32px;background:#b3261e;color:#fff}
+header h1{margin:0;font-size:22px}
+header p{margin:8px 0 0;font-family:monospace;white-space:pre-wrap;word-break:break-word}
+section{padding:16px 32px}
+h2{font-size:16px;margin:0 0 8px}
+table{border-collapse:collapse;width:100%;background:#fff}
+td{padding:4px 8px;border-bottom:1px solid #eee;vertical-align:top;font-family:monospace;word-break:break-all}
+td:first-child{width:200px;color:#666}
+pre{margin:0;padding:8px 0;background:#fff;overflow-x:auto}
+.line{display:block;padding:0 8px}
+.line.current{background:#fde7e5;font-weight:bold}
+.num{display:inline-block;width:48px;color:#999;user-select:none}
+ol{margin:0;padding-left:24px;font-family:monospace}
+li span{color:#666}
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Status}} {{.Title}}</h1>
+{{with .Panic}}<p>panic: {{.Value}}</p>{{else}}{{with .Error}}<p>{{.}}</p>{{end}}{{end}}
+</header>
+{{with .Panic}}{{if .Source}}{{$frame := index .Frames 0}}
+<section>
+<h2>{{$frame.File}}:{{$frame.Line}}</h2>
+<pre>{{range .Source}}<span class="line{{if .Current}} current{{end}}"><span class="num">{{.Number}}</span>{{.Text}}</span>{{end}}</pre>
+</section>{{end}}
+<section>
+<h2>Stack trace</h2>
+<ol>{{range .Frames}}<li>{{.Function}}<br><span>{{.File}}:{{.Line}}</span></li>{{end}}</ol>
+</section>{{end}}
+<section>
+<h2>Request</h2>
+<table>
+<tr><td>Method</td><td>{{.Method}}</td></tr>
+<tr><td>URI</td><td>{{.URI}}</td></tr>
+{{with .Route}}<tr><td>Route</td><td>{{.}}</td></tr>{{end}}
+<tr><td>Client IP</td><td>{{.ClientIP}}</td></tr>
+{{with .RequestID}}<tr><td>Request ID</td><td>{{.}}</td></tr>{{end}}
+<tr><td>Go version</td><td>{{.GoVersion}}</td></tr>
+</table>
+</section>
+<section>
+<h2>Headers</h2>
+<table>{{range .Headers}}<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>{{end}}</table>
+</section>
+</body>
+</html>
+`))
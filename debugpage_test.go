@@ -0,0 +1,84 @@
+package gonoleks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugErrorPagePanic(t *testing.T) {
+	app := New()
+	app.DebugErrorPage = true
+	app.GET("/panic", func(c *Context) { panic("something <broke>") })
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/panic", "203.0.113.5", map[string]string{
+		HeaderAccept:        "text/html,application/xhtml+xml,*/*;q=0.8",
+		HeaderAuthorization: "Bearer token",
+	})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusInternalServerError, reqCtx.Response.StatusCode())
+	assert.Equal(t, MIMETextHTMLCharsetUTF8, string(reqCtx.Response.Header.ContentType()))
+	body := string(reqCtx.Response.Body())
+	assert.Contains(t, body, "panic: something &lt;broke&gt;")
+	assert.Contains(t, body, "debugpage_test.go")
+	// The source of the panicking line is shown
+	assert.Contains(t, body, `panic(&#34;something &lt;broke&gt;&#34;)`)
+	assert.Contains(t, body, "/panic")
+	assert.Contains(t, body, "<td>Authorization</td><td>[REDACTED]</td>")
+
+	// Clients not preferring HTML receive the terse response
+	reqCtx = newProxiedRequest(MethodGet, "/panic", "203.0.113.5", map[string]string{HeaderAccept: "*/*"})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusInternalServerError, reqCtx.Response.StatusCode())
+	assert.Equal(t, "Internal Server Error", string(reqCtx.Response.Body()))
+}
+
+func TestDebugErrorPageWithRecovery(t *testing.T) {
+	app := New()
+	app.DebugErrorPage = true
+	app.Use(Recovery())
+	app.GET("/panic", func(c *Context) { panic("recovered elsewhere") })
+	app.GET("/error", func(c *Context) { _ = c.AbortWithError(StatusInternalServerError, errors.New("database is down")) })
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/panic", "203.0.113.5", map[string]string{HeaderAccept: MIMETextHTML})
+	app.router.Handler(reqCtx)
+	assert.Contains(t, string(reqCtx.Response.Body()), "panic: recovered elsewhere")
+	assert.Contains(t, string(reqCtx.Response.Body()), "Stack trace")
+
+	reqCtx = newProxiedRequest(MethodGet, "/error", "203.0.113.5", map[string]string{HeaderAccept: MIMETextHTML})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusInternalServerError, reqCtx.Response.StatusCode())
+	assert.Contains(t, string(reqCtx.Response.Body()), "database is down")
+}
+
+func TestDebugErrorPageDisabled(t *testing.T) {
+	// Default does not expose debug pages, they need an explicit opt-in
+	assert.False(t, Default().debugErrorPageEnabled())
+	debug := Default()
+	debug.Debug = true
+	assert.True(t, debug.debugErrorPageEnabled())
+
+	app := New()
+	require.False(t, app.debugErrorPageEnabled())
+	app.Use(Recovery())
+	app.GET("/panic", func(c *Context) { panic("hidden") })
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/panic", "203.0.113.5", map[string]string{HeaderAccept: MIMETextHTML})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusInternalServerError, reqCtx.Response.StatusCode())
+	assert.NotContains(t, string(reqCtx.Response.Body()), "hidden")
+}
+
+func TestReadSourceLines(t *testing.T) {
+	lines := readSourceLines("debugpage_test.go", 1)
+	require.NotEmpty(t, lines)
+	assert.Equal(t, "package gonoleks", lines[0].Text)
+	assert.True(t, lines[0].Current)
+	assert.Len(t, lines, debugSourceContext+1)
+	assert.Nil(t, readSourceLines("missing.go", 1))
+}
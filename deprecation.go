@@ -0,0 +1,108 @@
+package gonoleks
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// deprecationLogInterval is the number of uses of a deprecated route between usage log entries
+const deprecationLogInterval = 100
+
+// DeprecationConfig defines the config for Route.DeprecateWithConfig
+type DeprecationConfig struct {
+	// Deprecated is when the route was deprecated, sent in the Deprecation header
+	Deprecated time.Time // Default = time of the DeprecateWithConfig call
+
+	// Sunset is when the route stops being available, sent in the Sunset header
+	// No Sunset header is sent when zero
+	Sunset time.Time
+
+	// Link points to documentation about the deprecation and its replacement
+	Link string
+
+	// RejectAfterSunset answers requests with 410 Gone once the sunset date has passed
+	RejectAfterSunset bool
+}
+
+// DeprecatedRoute reports the usage of a deprecated route
+type DeprecatedRoute struct {
+	Method string
+	Path   string
+	Sunset time.Time
+	Hits   uint64
+}
+
+// routeDeprecation holds the state of a deprecated route
+type routeDeprecation struct {
+	method string
+	path   string
+	conf   DeprecationConfig
+	hits   atomic.Uint64
+}
+
+// Deprecate marks the route as deprecated, see DeprecateWithConfig
+//
+//	app.GET("/v1/users", listUsersV1).Deprecate(sunset, "https://example.com/docs/migrate-to-v2")
+func (r *Route) Deprecate(sunset time.Time, link string) *Route {
+	return r.DeprecateWithConfig(DeprecationConfig{Sunset: sunset, Link: link})
+}
+
+// DeprecateWithConfig marks the route as deprecated
+// Responses carry the Deprecation header of RFC 9745, the Sunset header of RFC 8594 and a Link
+// to the documentation, and every use is counted and periodically logged, see Gonoleks.DeprecatedRoutes
+// It must be called before the server starts
+func (r *Route) DeprecateWithConfig(conf DeprecationConfig) *Route {
+	if conf.Deprecated.IsZero() {
+		conf.Deprecated = time.Now()
+	}
+	d := &routeDeprecation{method: r.Method, path: r.Path, conf: conf}
+	if r.app != nil {
+		r.app.deprecationsMu.Lock()
+		r.app.deprecations = append(r.app.deprecations, d)
+		r.app.deprecationsMu.Unlock()
+	}
+	r.Handlers = append(handlersChain{d.handle}, r.Handlers...)
+	return r
+}
+
+// DeprecatedRoutes returns the usage of every deprecated route
+func (g *Gonoleks) DeprecatedRoutes() []DeprecatedRoute {
+	g.deprecationsMu.Lock()
+	defer g.deprecationsMu.Unlock()
+	routes := make([]DeprecatedRoute, len(g.deprecations))
+	for i, d := range g.deprecations {
+		routes[i] = DeprecatedRoute{Method: d.method, Path: d.path, Sunset: d.conf.Sunset, Hits: d.hits.Load()}
+	}
+	return routes
+}
+
+// handle sets the deprecation headers and counts the use of the route
+func (d *routeDeprecation) handle(c *Context) {
+	hits := d.hits.Add(1)
+	if hits == 1 || hits%deprecationLogInterval == 0 {
+		c.diagnostics().Warn("Deprecated route used", "method", d.method, "path", d.path, "hits", hits)
+	}
+	sunset := !d.conf.Sunset.IsZero()
+	rejected := sunset && d.conf.RejectAfterSunset && c.Now().After(d.conf.Sunset)
+	if rejected {
+		// Error resets the response, so it goes before the headers
+		c.requestCtx.Error(fasthttp.StatusMessage(StatusGone), StatusGone)
+	}
+	header := &c.requestCtx.Response.Header
+	header.Set(HeaderDeprecation, "@"+strconv.FormatInt(d.conf.Deprecated.Unix(), 10))
+	if sunset {
+		header.Set(HeaderSunset, d.conf.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.conf.Link != "" {
+		header.Add(HeaderLink, "<"+d.conf.Link+`>; rel="deprecation"; type="text/html"`)
+	}
+	if rejected {
+		c.Abort()
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,70 @@
+package gonoleks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteDeprecate(t *testing.T) {
+	app := New()
+	sunset := time.Date(2099, time.January, 1, 0, 0, 0, 0, time.UTC)
+	deprecated := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	var calls int
+	app.GET("/v1/users", func(c *Context) {
+		calls++
+		c.Status(StatusOK)
+	}).DeprecateWithConfig(DeprecationConfig{
+		Deprecated: deprecated,
+		Sunset:     sunset,
+		Link:       "https://example.com/docs/v2",
+	})
+	app.GET("/v2/users", func(c *Context) {
+		c.Status(StatusOK)
+	})
+	app.setupRouter()
+
+	for range 3 {
+		reqCtx := newProxiedRequest(MethodGet, "/v1/users", "203.0.113.5", nil)
+		app.router.Handler(reqCtx)
+		assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+		assert.Equal(t, "@1717200000", string(reqCtx.Response.Header.Peek(HeaderDeprecation)))
+		assert.Equal(t, "Thu, 01 Jan 2099 00:00:00 GMT", string(reqCtx.Response.Header.Peek(HeaderSunset)))
+		assert.Equal(t, `<https://example.com/docs/v2>; rel="deprecation"; type="text/html"`, string(reqCtx.Response.Header.Peek(HeaderLink)))
+	}
+	assert.Equal(t, 3, calls)
+
+	reqCtx := newProxiedRequest(MethodGet, "/v2/users", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Empty(t, reqCtx.Response.Header.Peek(HeaderDeprecation))
+
+	routes := app.DeprecatedRoutes()
+	require.Len(t, routes, 1)
+	assert.Equal(t, DeprecatedRoute{Method: MethodGet, Path: "/v1/users", Sunset: sunset, Hits: 3}, routes[0])
+}
+
+func TestRouteDeprecateRejectAfterSunset(t *testing.T) {
+	app := New()
+	app.GET("/legacy", func(c *Context) {
+		c.Status(StatusOK)
+	}).DeprecateWithConfig(DeprecationConfig{
+		Sunset:            time.Now().Add(-time.Hour),
+		RejectAfterSunset: true,
+	})
+	app.GET("/lenient", func(c *Context) {
+		c.Status(StatusOK)
+	}).Deprecate(time.Now().Add(-time.Hour), "")
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/legacy", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusGone, reqCtx.Response.StatusCode())
+	assert.NotEmpty(t, reqCtx.Response.Header.Peek(HeaderSunset), "Headers should survive the rejection")
+
+	reqCtx = newProxiedRequest(MethodGet, "/lenient", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode(), "Past sunsets should not reject unless configured")
+	assert.Empty(t, reqCtx.Response.Header.Peek(HeaderLink))
+}
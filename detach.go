@@ -0,0 +1,126 @@
+package gonoleks
+
+import (
+	"context"
+	"maps"
+
+	"charm.land/log/v2"
+)
+
+// loggerKey is the user value key under which the request logger is stored
+const loggerKey = "gonoleksLogger"
+
+// detachedKey is the type of the context keys used by Detach
+type detachedKey int
+
+const (
+	requestIDContextKey detachedKey = iota
+	traceParentContextKey
+	principalContextKey
+	loggerContextKey
+)
+
+// detachedContext carries request scoped values after the request has completed
+type detachedContext struct {
+	context.Context
+	requestID   string
+	traceParent string
+	principal   *Principal
+	logger      *log.Logger
+	keys        map[any]any
+}
+
+// Value returns the request scoped value for key, including values stored with Context.Set
+func (d *detachedContext) Value(key any) any {
+	switch key {
+	case requestIDContextKey:
+		return d.requestID
+	case traceParentContextKey:
+		return d.traceParent
+	case principalContextKey:
+		return d.principal
+	case loggerContextKey:
+		return d.logger
+	}
+	if value, ok := d.keys[key]; ok {
+		return value
+	}
+	return d.Context.Value(key)
+}
+
+// Detach returns a standard context that can be used by goroutines outliving the request
+// It carries the request id, the W3C trace parent, the authenticated principal, the request logger
+// and a copy of the values stored with Set, but never references the pooled fasthttp context
+// The returned context is never canceled
+//
+//	ctx := c.Detach()
+//	go func() {
+//		gonoleks.LoggerFromContext(ctx).Info("Sending welcome email")
+//	}()
+func (c *Context) Detach() context.Context {
+	d := &detachedContext{
+		Context:     context.Background(),
+		requestID:   c.requestID(),
+		traceParent: c.GetHeader(HeaderTraceparent),
+		principal:   c.Principal(),
+		logger:      c.Logger(),
+	}
+	if keys, ok := c.requestCtx.UserValue("keys").(map[any]any); ok {
+		d.keys = maps.Clone(keys)
+	}
+	return d
+}
+
+// SetLogger replaces the logger returned by Logger for the current request
+func (c *Context) SetLogger(logger *log.Logger) {
+	c.requestCtx.SetUserValue(loggerKey, logger)
+}
+
+// Logger returns the logger of the current request
+// Unless replaced with SetLogger, it is the default logger with the request id attached
+func (c *Context) Logger() *log.Logger {
+	if logger, ok := c.requestCtx.UserValue(loggerKey).(*log.Logger); ok {
+		return logger
+	}
+	logger := log.Default()
+	if id := c.requestID(); id != "" {
+		logger = logger.With("request_id", id)
+	}
+	return logger
+}
+
+// requestID returns the X-Request-ID request header, falling back to the response header
+// set by request id middleware
+func (c *Context) requestID() string {
+	if id := c.GetHeader(HeaderXRequestID); id != "" {
+		return id
+	}
+	return string(c.requestCtx.Response.Header.Peek(HeaderXRequestID))
+}
+
+// RequestIDFromContext returns the request id carried by a context returned from Detach
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// TraceParentFromContext returns the W3C traceparent carried by a context returned from Detach
+func TraceParentFromContext(ctx context.Context) string {
+	traceParent, _ := ctx.Value(traceParentContextKey).(string)
+	return traceParent
+}
+
+// PrincipalFromContext returns the principal carried by a context returned from Detach, or nil
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalContextKey).(*Principal)
+	return p
+}
+
+// LoggerFromContext returns the logger carried by a context returned from Detach,
+// or the default logger if there is none
+func LoggerFromContext(ctx context.Context) *log.Logger {
+	if logger, ok := ctx.Value(loggerContextKey).(*log.Logger); ok && logger != nil {
+		return logger
+	}
+	return log.Default()
+}
@@ -0,0 +1,61 @@
+package gonoleks
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"charm.land/log/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextDetach(t *testing.T) {
+	ctx, requestCtx := createTestContext()
+	requestCtx.Request.Header.Set(HeaderXRequestID, "req-1")
+	requestCtx.Request.Header.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	principal := &Principal{ID: "alice"}
+	ctx.SetPrincipal(principal)
+	ctx.Set("tenant", "acme")
+
+	detached := ctx.Detach()
+	// Values must survive the pooled context being reset
+	requestCtx.ResetUserValues()
+	requestCtx.Request.Reset()
+
+	assert.Equal(t, "req-1", RequestIDFromContext(detached))
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", TraceParentFromContext(detached))
+	assert.Same(t, principal, PrincipalFromContext(detached))
+	assert.Equal(t, "acme", detached.Value("tenant"))
+	assert.Nil(t, detached.Value("missing"))
+	assert.NotNil(t, LoggerFromContext(detached))
+	assert.NoError(t, detached.Err())
+	assert.Nil(t, detached.Done())
+}
+
+func TestContextDetachResponseRequestID(t *testing.T) {
+	ctx, requestCtx := createTestContext()
+	requestCtx.Response.Header.Set(HeaderXRequestID, "generated")
+	assert.Equal(t, "generated", RequestIDFromContext(ctx.Detach()))
+	assert.Nil(t, PrincipalFromContext(ctx.Detach()))
+}
+
+func TestContextLogger(t *testing.T) {
+	ctx, _ := createTestContext()
+	assert.Same(t, log.Default(), ctx.Logger())
+
+	var buf bytes.Buffer
+	logger := log.New(&buf)
+	ctx.SetLogger(logger)
+	assert.Same(t, logger, ctx.Logger())
+
+	LoggerFromContext(ctx.Detach()).Info("background work")
+	assert.Contains(t, buf.String(), "background work")
+}
+
+func TestFromContextDefaults(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, RequestIDFromContext(ctx))
+	assert.Empty(t, TraceParentFromContext(ctx))
+	assert.Nil(t, PrincipalFromContext(ctx))
+	assert.Same(t, log.Default(), LoggerFromContext(ctx))
+}
@@ -0,0 +1,41 @@
+package gonoleks
+
+import (
+	"os"
+
+	"charm.land/log/v2"
+)
+
+// defaultDiagnostics logs framework diagnostics that are not tied to an app,
+// and is the parent of the diagnostics logger of every app
+var defaultDiagnostics = log.NewWithOptions(os.Stderr, log.Options{
+	Prefix:          "gonoleks",
+	ReportTimestamp: true,
+})
+
+// Diagnostics returns the logger of the framework's own messages, such as render errors,
+// router warnings and startup lines, which is kept apart from the access log of the Logger middleware
+// Its level and output are configured per app, e.g. to silence the framework while keeping access logs
+//
+//	app.Diagnostics().SetLevel(log.ErrorLevel)
+//	app.Diagnostics().SetOutput(io.Discard)
+func (g *Gonoleks) Diagnostics() *log.Logger {
+	return g.diagnostics
+}
+
+// SetDiagnosticsLogger replaces the logger of the framework's own messages,
+// e.g. with a JSON logger feeding the same pipeline as the application logs
+func (g *Gonoleks) SetDiagnosticsLogger(logger *log.Logger) {
+	if logger == nil {
+		logger = defaultDiagnostics.With()
+	}
+	g.diagnostics = logger
+}
+
+// diagnostics returns the diagnostics logger of the app serving the request
+func (c *Context) diagnostics() *log.Logger {
+	if c.app != nil && c.app.diagnostics != nil {
+		return c.app.diagnostics
+	}
+	return defaultDiagnostics
+}
@@ -0,0 +1,49 @@
+package gonoleks
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"charm.land/log/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnosticsLogger(t *testing.T) {
+	var diag, access bytes.Buffer
+	app := New()
+	app.SetDiagnosticsLogger(log.New(&diag))
+	app.GETOnly = true
+	app.POST("/orders", func(c *Context) {})
+	assert.Contains(t, diag.String(), "Route is unreachable")
+
+	app.Use(LoggerWithWriter(&access))
+	defer log.SetOutput(os.Stderr)
+	app.GET("/fail", func(c *Context) {
+		c.diagnostics().Error("render failed")
+		c.Status(StatusInternalServerError)
+	})
+	app.setupRouter()
+	app.router.Handler(newProxiedRequest(MethodGet, "/fail", "203.0.113.5", nil))
+
+	assert.Contains(t, diag.String(), "render failed")
+	assert.NotContains(t, access.String(), "render failed", "diagnostics must not reach the access log")
+	assert.Contains(t, access.String(), "/fail")
+}
+
+func TestDiagnosticsSilenced(t *testing.T) {
+	var diag bytes.Buffer
+	app := New()
+	app.Diagnostics().SetOutput(&diag)
+	app.Diagnostics().SetLevel(log.ErrorLevel)
+	app.GETOnly = true
+	app.POST("/orders", func(c *Context) {})
+	assert.Empty(t, diag.String(), "warnings should be filtered by the level")
+
+	other := New()
+	assert.NotSame(t, app.Diagnostics(), other.Diagnostics(), "every app has its own diagnostics logger")
+	assert.Equal(t, log.InfoLevel, other.Diagnostics().GetLevel())
+
+	app.SetDiagnosticsLogger(nil)
+	assert.NotNil(t, app.Diagnostics())
+}
@@ -0,0 +1,185 @@
+package gonoleks
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/valyala/fasthttp"
+)
+
+// DumpConfig defines the config for the Dump middleware
+type DumpConfig struct {
+	// Output receives the dumps, writes are serialized so it does not need to be safe for concurrent use
+	Output io.Writer // Default = os.Stderr
+
+	// MaxBodySize is the number of body bytes dumped for the request and the response,
+	// longer bodies are truncated and a negative value omits bodies
+	MaxBodySize int // Default = 4096
+
+	// Hexdump dumps bodies as hexdumps, binary bodies are always hexdumped
+	Hexdump bool
+
+	// Header triggers a dump for requests carrying this header, e.g. "X-Debug-Dump"
+	Header string
+
+	// Query triggers a dump for requests carrying this query parameter, e.g. "__dump"
+	Query string
+
+	// MinStatus triggers a dump for responses with at least this status, a negative value disables it
+	MinStatus int // Default = 500
+
+	// Trigger decides whether a request is dumped once it has been handled,
+	// it is checked in addition to Header, Query and MinStatus
+	Trigger func(c *Context) bool
+
+	// RedactHeaders lists additional headers whose values are hidden in dumps
+	// Authorization, Proxy-Authorization, Cookie and Set-Cookie are always redacted
+	RedactHeaders []string
+}
+
+// Dump instances a middleware writing the full request and response of matching requests
+// to a writer, for debugging in production without logging every request
+//
+//	app.Use(gonoleks.Dump(gonoleks.DumpConfig{
+//		Header: "X-Debug-Dump",
+//		Output: file,
+//	}))
+func Dump(config ...DumpConfig) handlerFunc {
+	var conf DumpConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if conf.Output == nil {
+		conf.Output = os.Stderr
+	}
+	if conf.MaxBodySize == 0 {
+		conf.MaxBodySize = 4096
+	}
+	if conf.MinStatus == 0 {
+		conf.MinStatus = StatusInternalServerError
+	}
+	redact := make(map[string]struct{}, len(redactedHeaders)+len(conf.RedactHeaders)+1)
+	for _, h := range redactedHeaders {
+		redact[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+	for _, h := range conf.RedactHeaders {
+		redact[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+	redact[HeaderSetCookie] = struct{}{}
+	var mu sync.Mutex
+	return func(c *Context) {
+		start := time.Now()
+		c.Next()
+		if !conf.triggered(c) {
+			return
+		}
+		dump := conf.dump(c, redact, time.Since(start))
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := conf.Output.Write(dump); err != nil {
+			c.diagnostics().Error("Failed to write dump", "error", err)
+		}
+	}
+}
+
+// triggered reports whether the handled request must be dumped
+func (conf *DumpConfig) triggered(c *Context) bool {
+	switch {
+	case conf.Header != "" && len(c.requestCtx.Request.Header.Peek(conf.Header)) > 0:
+		return true
+	case conf.Query != "" && c.requestCtx.QueryArgs().Has(conf.Query):
+		return true
+	case conf.MinStatus > 0 && c.requestCtx.Response.StatusCode() >= conf.MinStatus:
+		return true
+	}
+	return conf.Trigger != nil && conf.Trigger(c)
+}
+
+// dump formats the request and response of c
+func (conf *DumpConfig) dump(c *Context, redact map[string]struct{}, latency time.Duration) []byte {
+	req := &c.requestCtx.Request
+	resp := &c.requestCtx.Response
+	var b bytes.Buffer
+	b.WriteString("=== dump")
+	if id := c.requestID(); id != "" {
+		b.WriteString(" request_id=" + id)
+	}
+	b.WriteString(" client_ip=" + c.ClientIP())
+	b.WriteString(" latency=" + latency.String() + "\n")
+
+	b.WriteString("> " + string(req.Header.Method()) + " " + string(req.Header.RequestURI()) + " " + string(req.Header.Protocol()) + "\n")
+	for key, value := range req.Header.All() {
+		writeDumpHeader(&b, "> ", key, value, redact)
+	}
+	b.WriteString(">\n")
+	conf.writeBody(&b, "> ", req.Body())
+
+	b.WriteString("< " + strconv.Itoa(resp.StatusCode()) + " " + fasthttp.StatusMessage(resp.StatusCode()) + "\n")
+	for key, value := range resp.Header.All() {
+		writeDumpHeader(&b, "< ", key, value, redact)
+	}
+	b.WriteString("<\n")
+	if resp.IsBodyStream() {
+		b.WriteString("< [streamed body]\n")
+	} else {
+		conf.writeBody(&b, "< ", resp.Body())
+	}
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// writeDumpHeader writes a header line, hiding the value of redacted headers
+func writeDumpHeader(b *bytes.Buffer, prefix string, key, value []byte, redact map[string]struct{}) {
+	name := http.CanonicalHeaderKey(string(key))
+	if _, hidden := redact[name]; hidden {
+		value = []byte("[REDACTED]")
+	}
+	b.WriteString(prefix + name + ": " + string(value) + "\n")
+}
+
+// writeBody writes body up to MaxBodySize bytes, as a hexdump for binary bodies or when Hexdump is set
+func (conf *DumpConfig) writeBody(b *bytes.Buffer, prefix string, body []byte) {
+	if conf.MaxBodySize < 0 || len(body) == 0 {
+		return
+	}
+	truncated := 0
+	if len(body) > conf.MaxBodySize {
+		truncated = len(body) - conf.MaxBodySize
+		body = body[:conf.MaxBodySize]
+	}
+	text := string(body)
+	if conf.Hexdump || !isPrintableText(body) {
+		text = hex.Dump(body)
+	}
+	for line := range bytes.Lines([]byte(text)) {
+		b.WriteString(prefix)
+		b.Write(bytes.TrimRight(line, "\r\n"))
+		b.WriteByte('\n')
+	}
+	if truncated > 0 {
+		b.WriteString(prefix + "[" + strconv.Itoa(truncated) + " bytes truncated]\n")
+	}
+}
+
+// isPrintableText reports whether body is valid UTF-8 without control characters other than whitespace
+// A multi-byte rune cut off by truncation at the end is tolerated
+func isPrintableText(body []byte) bool {
+	for i := 0; i < len(body); {
+		r, size := utf8.DecodeRune(body[i:])
+		if r == utf8.RuneError && size == 1 {
+			return len(body)-i < utf8.UTFMax && !utf8.FullRune(body[i:])
+		}
+		if r < 0x20 && r != '\n' && r != '\r' && r != '\t' || r == 0x7f {
+			return false
+		}
+		i += size
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package gonoleks
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpTriggers(t *testing.T) {
+	var out bytes.Buffer
+	app := New()
+	app.Use(Dump(DumpConfig{Output: &out, Header: "X-Debug-Dump", Query: "__dump"}))
+	app.POST("/users/:id", func(c *Context) {
+		c.Header(HeaderSetCookie, "session=secret")
+		c.String(StatusCreated, "created")
+	})
+	app.GET("/fail", func(c *Context) { c.String(StatusInternalServerError, "boom") })
+	app.setupRouter()
+
+	// Not triggered
+	reqCtx := newProxiedRequest(MethodPost, "/users/1", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Empty(t, out.String())
+
+	// Triggered by header, sensitive headers are redacted
+	reqCtx = newProxiedRequest(MethodPost, "/users/1", "203.0.113.5", map[string]string{
+		"X-Debug-Dump":      "1",
+		HeaderAuthorization: "Bearer token",
+	})
+	reqCtx.Request.SetBodyString(`{"name":"gopher"}`)
+	app.router.Handler(reqCtx)
+	dump := out.String()
+	assert.Contains(t, dump, "> POST /users/1 HTTP/1.1\n")
+	assert.Contains(t, dump, "> Authorization: [REDACTED]\n")
+	assert.NotContains(t, dump, "Bearer token")
+	assert.Contains(t, dump, "> {\"name\":\"gopher\"}\n")
+	assert.Contains(t, dump, "< 201 Created\n")
+	assert.Contains(t, dump, "< Set-Cookie: [REDACTED]\n")
+	assert.Contains(t, dump, "< created\n")
+
+	// Triggered by query flag
+	out.Reset()
+	reqCtx = newProxiedRequest(MethodPost, "/users/1?__dump", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Contains(t, out.String(), "> POST /users/1?__dump HTTP/1.1\n")
+
+	// Triggered by server error
+	out.Reset()
+	reqCtx = newProxiedRequest(MethodGet, "/fail", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Contains(t, out.String(), "< 500 Internal Server Error\n")
+	assert.Contains(t, out.String(), "< boom\n")
+}
+
+func TestDumpBody(t *testing.T) {
+	var out bytes.Buffer
+	app := New()
+	app.Use(Dump(DumpConfig{Output: &out, MaxBodySize: 8, Trigger: func(*Context) bool { return true }}))
+	app.POST("/echo", func(c *Context) { c.Data(StatusOK, MIMEOctetStream, []byte{0x00, 0x01, 0xff}) })
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodPost, "/echo", "203.0.113.5", nil)
+	reqCtx.Request.SetBodyString("0123456789abcdef")
+	app.router.Handler(reqCtx)
+	dump := out.String()
+	assert.Contains(t, dump, "> 01234567\n")
+	assert.Contains(t, dump, "> [8 bytes truncated]\n")
+	// Binary bodies are hexdumped
+	assert.Contains(t, dump, "< 00000000  00 01 ff")
+
+	// Hexdump forces hexdumps of text bodies
+	out.Reset()
+	app = New()
+	app.Use(Dump(DumpConfig{Output: &out, Hexdump: true, MinStatus: -1, Query: "d"}))
+	app.GET("/text", func(c *Context) { c.String(StatusOK, "hi") })
+	app.setupRouter()
+	app.router.Handler(newProxiedRequest(MethodGet, "/text?d=1", "203.0.113.5", nil))
+	assert.Contains(t, out.String(), "< 00000000  68 69")
+	assert.False(t, strings.Contains(out.String(), "< hi\n"))
+}
+
+func TestIsPrintableText(t *testing.T) {
+	assert.True(t, isPrintableText([]byte("héllo\r\n\tworld")))
+	assert.True(t, isPrintableText([]byte("caf\xc3")))
+	assert.False(t, isPrintableText([]byte("a\x00b")))
+	assert.False(t, isPrintableText([]byte("\xff\xfeab")))
+}
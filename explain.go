@@ -0,0 +1,121 @@
+package gonoleks
+
+import (
+	"maps"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// RouteExplanation describes how the router resolves a request
+type RouteExplanation struct {
+	// Method and Path are the request method and path as seen by the router,
+	// i.e. lowercased when CaseInSensitive is enabled
+	Method string
+	Path   string
+
+	// Matched reports whether a registered route serves the request
+	Matched bool
+
+	// Pattern is the route pattern that matched, e.g. "/users/:id"
+	// Static routes resolved from the cache have their path as pattern
+	Pattern string
+
+	// CacheLevel is the FastRouter level that resolved a static route:
+	// "ultra", "route", "common" or "hash", or "tree" for a tree lookup
+	CacheLevel string
+
+	// Nodes lists the tree nodes traversed by a tree lookup
+	Nodes []string
+
+	// Params holds the extracted route params
+	Params map[string]string
+
+	// Handlers lists the names of the handlers that would run, middleware first
+	Handlers []string
+
+	// Fallback names the chain serving an unmatched request:
+	// "options", "noMethod" or "noRoute"
+	Fallback string
+
+	// Allow lists the methods registered for the path
+	Allow string
+}
+
+// ExplainRoute reports how a request for method and path would be routed,
+// including the cache level or tree nodes that matched, the extracted params
+// and the handler chain that would run
+// It is meant for debugging and must be called after the router is set up, e.g. in a debug endpoint
+func (g *Gonoleks) ExplainRoute(method, path string) RouteExplanation {
+	r := g.router
+	if g.CaseInSensitive {
+		method = strings.ToUpper(method)
+		path = strings.ToLower(path)
+	}
+	e := RouteExplanation{Method: method, Path: path, Params: make(map[string]string)}
+	ctx := &Context{paramValues: make(map[string]string)}
+	var handlers handlersChain
+	if g.enableLogging {
+		handlers = append(handlers, LoggerWithFormatter(DefaultLogFormatter))
+	}
+	if r.fastRouter != nil {
+		if level, chain := r.fastRouter.lookupLevel(method, path); chain != nil {
+			e.Matched, e.CacheLevel, e.Pattern = true, level, path
+			handlers = append(handlers, chain...)
+		}
+	}
+	if !e.Matched {
+		if root := r.trees[method]; root != nil {
+			var nodes []string
+			if chain := root.traceRoute(path, ctx, &nodes); chain != nil {
+				e.Matched, e.CacheLevel, e.Nodes = true, "tree", nodes
+				e.Pattern = "/" + strings.Join(nodes, "/")
+				handlers = append(handlers, chain...)
+			}
+		}
+	}
+	e.Allow = r.allowed(path, &Context{paramValues: make(map[string]string)})
+	if !e.Matched {
+		handlers = append(handlers, r.globalMiddleware...)
+		switch {
+		case g.HandleOPTIONS && method == MethodOptions && e.Allow != "":
+			e.Fallback = "options"
+		case g.HandleMethodNotAllowed && e.Allow != "":
+			e.Fallback = "noMethod"
+			handlers = append(handlers, r.noMethod...)
+		default:
+			e.Fallback = "noRoute"
+			handlers = append(handlers, r.noRoute...)
+		}
+	}
+	maps.Copy(e.Params, ctx.paramValues)
+	e.Handlers = make([]string, len(handlers))
+	for i, handler := range handlers {
+		e.Handlers[i] = nameOfFunction(handler)
+	}
+	return e
+}
+
+// lookupLevel resolves a static route like UltraFastLookup and reports the level that matched
+func (fr *FastRouter) lookupLevel(method, path string) (string, handlersChain) {
+	combinedHash := ultraFastCombinedHash(ultraFastStringHash(method), ultraFastStringHash(path))
+	hash32 := uint32(combinedHash)
+	if entry := &fr.ultraCache.entries[hash32&fr.ultraCache.hashMask]; entry.hash == hash32 && entry.handlers != nil {
+		return "ultra", entry.handlers
+	}
+	if entry := &fr.routeCache[hash32&255]; entry.hash == combinedHash && entry.handlers != nil {
+		return "route", entry.handlers
+	}
+	if common := &fr.commonRoutes[hash32&1023]; common.key == hash32 && common.handlers != nil {
+		return "common", common.handlers
+	}
+	if handlers, exists := fr.routeHashes[combinedHash]; exists {
+		return "hash", handlers
+	}
+	return "", nil
+}
+
+// nameOfFunction returns the fully qualified name of a function
+func nameOfFunction(f any) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
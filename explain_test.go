@@ -0,0 +1,61 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func explainTestHandler(c *Context) {}
+
+func TestExplainRoute(t *testing.T) {
+	app := New()
+	app.HandleMethodNotAllowed = true
+	app.Use(Recovery())
+	app.GET("/health", explainTestHandler)
+	app.GET("/users/:id/files/:name.:ext", explainTestHandler)
+	app.GET("/assets/*filepath", explainTestHandler)
+	app.setupRouter()
+
+	e := app.ExplainRoute(MethodGet, "/health")
+	assert.True(t, e.Matched)
+	assert.Equal(t, "ultra", e.CacheLevel)
+	assert.Equal(t, "/health", e.Pattern)
+	assert.Len(t, e.Handlers, 2)
+	assert.Contains(t, e.Handlers[0], "RecoveryWithConfig")
+	assert.Contains(t, e.Handlers[1], "explainTestHandler")
+
+	e = app.ExplainRoute(MethodGet, "/users/42/files/report.pdf")
+	assert.True(t, e.Matched)
+	assert.Equal(t, "tree", e.CacheLevel)
+	assert.Equal(t, []string{"users", ":id", "files", ":name.:ext"}, e.Nodes)
+	assert.Equal(t, "/users/:id/files/:name.:ext", e.Pattern)
+	assert.Equal(t, map[string]string{"id": "42", "name": "report", "ext": "pdf"}, e.Params)
+
+	e = app.ExplainRoute(MethodGet, "/assets/css/site.css")
+	assert.Equal(t, "/assets/*filepath", e.Pattern)
+	assert.Equal(t, "css/site.css", e.Params["filepath"])
+
+	e = app.ExplainRoute(MethodPost, "/health")
+	assert.False(t, e.Matched)
+	assert.Equal(t, "noMethod", e.Fallback)
+	assert.Equal(t, "GET, OPTIONS", e.Allow)
+	assert.Len(t, e.Handlers, 1)
+
+	e = app.ExplainRoute(MethodGet, "/missing")
+	assert.Equal(t, "noRoute", e.Fallback)
+	assert.Empty(t, e.Allow)
+}
+
+func TestExplainRouteCaseInsensitive(t *testing.T) {
+	app := New()
+	app.CaseInSensitive = true
+	app.GET("/Users/:id", explainTestHandler)
+	app.setupRouter()
+
+	e := app.ExplainRoute("get", "/USERS/Ab")
+	assert.Equal(t, MethodGet, e.Method)
+	assert.Equal(t, "/users/ab", e.Path)
+	assert.True(t, e.Matched)
+	assert.Equal(t, "ab", e.Params["id"])
+}
@@ -0,0 +1,187 @@
+package gonoleks
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"iter"
+	"path"
+	"strings"
+)
+
+// TableWriter writes a tabular export row by row
+type TableWriter interface {
+	// WriteRow writes a row of cells
+	WriteRow(row []string) error
+
+	// Flush writes the buffered rows to the underlying writer
+	Flush() error
+
+	// Close finishes the document, e.g. writes the archive directory of an xlsx file
+	Close() error
+}
+
+// ExportFormat is a file format for tabular exports
+// CSV is built in, other formats such as xlsx are plugged in with an adapter over a spreadsheet
+// library, e.g. the stream writer of excelize
+type ExportFormat interface {
+	// ContentType returns the media type of the format
+	ContentType() string
+
+	// Extension returns the file extension of the format, e.g. ".csv"
+	Extension() string
+
+	// NewWriter returns a TableWriter writing the format to w
+	NewWriter(w io.Writer) TableWriter
+}
+
+// CSVFormat is the CSV ExportFormat
+type CSVFormat struct {
+	// Comma is the field delimiter, e.g. ';' for spreadsheets in locales using a decimal comma
+	Comma rune // Default = ','
+
+	// BOM writes a UTF-8 byte order mark first, so Excel detects the encoding
+	BOM bool
+
+	// EscapeFormulas prefixes cells starting with =, +, - or @ with a single quote,
+	// so spreadsheets do not evaluate user supplied values as formulas
+	EscapeFormulas bool
+}
+
+// ContentType implements ExportFormat
+func (f CSVFormat) ContentType() string {
+	return MIMETextCSVCharsetUTF8
+}
+
+// Extension implements ExportFormat
+func (f CSVFormat) Extension() string {
+	return ".csv"
+}
+
+// NewWriter implements ExportFormat
+func (f CSVFormat) NewWriter(w io.Writer) TableWriter {
+	writer := csv.NewWriter(w)
+	if f.Comma != 0 {
+		writer.Comma = f.Comma
+	}
+	return &csvTableWriter{format: f, w: w, writer: writer}
+}
+
+// csvTableWriter is the TableWriter of CSVFormat
+type csvTableWriter struct {
+	format  CSVFormat
+	w       io.Writer
+	writer  *csv.Writer
+	started bool
+}
+
+// WriteRow implements TableWriter
+func (t *csvTableWriter) WriteRow(row []string) error {
+	if !t.started {
+		t.started = true
+		if t.format.BOM {
+			if _, err := io.WriteString(t.w, "\ufeff"); err != nil {
+				return err
+			}
+		}
+	}
+	if t.format.EscapeFormulas {
+		escaped := make([]string, len(row))
+		for i, cell := range row {
+			if cell != "" && strings.IndexByte("=+-@\t\r", cell[0]) >= 0 {
+				cell = "'" + cell
+			}
+			escaped[i] = cell
+		}
+		row = escaped
+	}
+	return t.writer.Write(row)
+}
+
+// Flush implements TableWriter
+func (t *csvTableWriter) Flush() error {
+	t.writer.Flush()
+	return t.writer.Error()
+}
+
+// Close implements TableWriter
+func (t *csvTableWriter) Close() error {
+	return t.Flush()
+}
+
+// exportFlushRows is the number of rows after which an export is flushed to the client
+const exportFlushRows = 100
+
+// ExportCSV streams rows as a CSV attachment named name, preceded by header unless empty,
+// with the options of format when given
+// Rows are produced lazily and flushed to the client as they come, so large back-office exports
+// do not have to fit in memory
+// The iterator runs after the handler has returned, so it must not access the Context,
+// and an error it yields ends the export early, as the status has already been sent
+//
+//	c.ExportCSV("orders", []string{"id", "total"}, func(yield func([]string, error) bool) {
+//	    rows, err := db.Query("SELECT id, total FROM orders")
+//	    ...
+//	})
+func (c *Context) ExportCSV(name string, header []string, rows iter.Seq2[[]string, error], format ...CSVFormat) {
+	var csvFormat CSVFormat
+	if len(format) > 0 {
+		csvFormat = format[0]
+	}
+	c.Export(csvFormat, name, header, rows)
+}
+
+// Export streams rows as an attachment in format, see ExportCSV
+//
+//	c.Export(xlsxFormat{}, "orders", header, rows)
+func (c *Context) Export(format ExportFormat, name string, header []string, rows iter.Seq2[[]string, error]) {
+	if ext := format.Extension(); ext != "" && !strings.EqualFold(path.Ext(name), ext) {
+		name += ext
+	}
+	c.requestCtx.SetContentType(format.ContentType())
+	c.requestCtx.Response.Header.Set(HeaderContentDisposition, contentDisposition("attachment", name))
+	c.requestCtx.Response.Header.Set(HeaderCacheControl, "no-store")
+	c.requestCtx.Response.Header.Set("X-Accel-Buffering", "no")
+	logger := c.diagnostics()
+	c.requestCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		table := format.NewWriter(w)
+		err := writeExport(table, w, header, rows)
+		if closeErr := table.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			logger.Error("Export failed", "name", name, "error", err)
+		}
+	})
+}
+
+// writeExport writes header and rows to table, flushing them to w every exportFlushRows rows
+func writeExport(table TableWriter, w *bufio.Writer, header []string, rows iter.Seq2[[]string, error]) error {
+	if len(header) > 0 {
+		if err := table.WriteRow(header); err != nil {
+			return err
+		}
+	}
+	n := 0
+	for row, err := range rows {
+		if err != nil {
+			return err
+		}
+		if err := table.WriteRow(row); err != nil {
+			return err
+		}
+		if n++; n%exportFlushRows == 0 {
+			if err := table.Flush(); err != nil {
+				return err
+			}
+			if err := w.Flush(); err != nil {
+				// The client went away, stop producing rows
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package gonoleks
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"iter"
+	"strconv"
+	"testing"
+
+	"charm.land/log/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportRows(rows [][]string, err error) iter.Seq2[[]string, error] {
+	return func(yield func([]string, error) bool) {
+		for _, row := range rows {
+			if !yield(row, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	c, requestCtx := createTestContext()
+	rows := make([][]string, 250)
+	for i := range rows {
+		rows[i] = []string{strconv.Itoa(i), "item, \"quoted\""}
+	}
+	c.ExportCSV("orders", []string{"id", "name"}, exportRows(rows, nil))
+
+	assert.Equal(t, MIMETextCSVCharsetUTF8, string(requestCtx.Response.Header.ContentType()))
+	assert.Equal(t, `attachment; filename="orders.csv"`, string(requestCtx.Response.Header.Peek(HeaderContentDisposition)))
+	assert.Equal(t, "no-store", string(requestCtx.Response.Header.Peek(HeaderCacheControl)))
+	records, err := csv.NewReader(bytes.NewReader(requestCtx.Response.Body())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 251)
+	assert.Equal(t, []string{"id", "name"}, records[0])
+	assert.Equal(t, []string{"249", "item, \"quoted\""}, records[250])
+}
+
+func TestExportCSVFormat(t *testing.T) {
+	c, requestCtx := createTestContext()
+	c.ExportCSV("report.CSV", nil, exportRows([][]string{{"=SUM(A1)", "-1", "ok"}}, nil),
+		CSVFormat{Comma: ';', BOM: true, EscapeFormulas: true})
+
+	assert.Equal(t, `attachment; filename="report.CSV"`, string(requestCtx.Response.Header.Peek(HeaderContentDisposition)))
+	assert.Equal(t, "\ufeff'=SUM(A1);'-1;ok\n", string(requestCtx.Response.Body()))
+}
+
+type upperFormat struct{}
+
+func (upperFormat) ContentType() string { return MIMETextPlainCharsetUTF8 }
+
+func (upperFormat) Extension() string { return ".txt" }
+
+func (upperFormat) NewWriter(w io.Writer) TableWriter { return &upperWriter{w: w} }
+
+type upperWriter struct {
+	w io.Writer
+}
+
+func (u *upperWriter) WriteRow(row []string) error {
+	for _, cell := range row {
+		if _, err := io.WriteString(u.w, cell+"|"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *upperWriter) Flush() error { return nil }
+
+func (u *upperWriter) Close() error {
+	_, err := io.WriteString(u.w, "END")
+	return err
+}
+
+func TestExportFormatError(t *testing.T) {
+	app := New()
+	var buf bytes.Buffer
+	app.SetDiagnosticsLogger(log.New(&buf))
+	c, requestCtx := createTestContext()
+	c.app = app
+	c.Export(upperFormat{}, "data", []string{"h"}, exportRows([][]string{{"a", "b"}}, errors.New("query failed")))
+
+	assert.Equal(t, `attachment; filename="data.txt"`, string(requestCtx.Response.Header.Peek(HeaderContentDisposition)))
+	assert.Equal(t, "h|a|b|END", string(requestCtx.Response.Body()))
+	assert.Contains(t, buf.String(), "Export failed")
+	assert.Contains(t, buf.String(), "query failed")
+}
@@ -0,0 +1,147 @@
+package gonoleks
+
+import (
+	"hash/fnv"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// FeatureFlagsKey is the context key under which ExposeFeatureFlags stores the evaluated flags
+const FeatureFlagsKey = "featureFlags"
+
+// FlagContext holds the request attributes a feature flag is evaluated against
+type FlagContext struct {
+	// UserID identifies the user, taken from the authenticated principal
+	UserID string
+
+	// IP is the client IP address
+	IP string
+
+	// Attributes holds additional targeting data, taken from the principal attributes
+	Attributes map[string]any
+}
+
+// FeatureFlags evaluates feature flags, e.g. backed by LaunchDarkly, Unleash or a config file
+type FeatureFlags interface {
+	// Enabled reports whether the flag is enabled for the given context
+	// Unknown flags must report false
+	Enabled(flag string, fc FlagContext) bool
+}
+
+// SetFeatureFlags sets the provider consulted by Context.FeatureEnabled
+func (g *Gonoleks) SetFeatureFlags(provider FeatureFlags) {
+	g.featureFlags = provider
+}
+
+// FeatureEnabled reports whether the flag is enabled for the current request
+// It returns false when no provider is set
+//
+//	if c.FeatureEnabled("new-checkout") {
+//		return newCheckout(c)
+//	}
+func (c *Context) FeatureEnabled(flag string) bool {
+	if c.app == nil || c.app.featureFlags == nil {
+		return false
+	}
+	return c.app.featureFlags.Enabled(flag, c.FlagContext())
+}
+
+// FlagContext returns the attributes feature flags are evaluated against for the current request
+func (c *Context) FlagContext() FlagContext {
+	fc := FlagContext{IP: c.ClientIP()}
+	if p := c.Principal(); p != nil {
+		fc.UserID = p.ID
+		fc.Attributes = p.Attributes
+	}
+	return fc
+}
+
+// ExposeFeatureFlags instances a middleware that evaluates the given flags once per request
+// and stores them as map[string]bool under FeatureFlagsKey, e.g. for use as template data
+//
+//	app.Use(gonoleks.ExposeFeatureFlags("new-checkout", "dark-mode"))
+func ExposeFeatureFlags(flags ...string) handlerFunc {
+	return func(c *Context) {
+		evaluated := make(map[string]bool, len(flags))
+		if c.app != nil && c.app.featureFlags != nil {
+			fc := c.FlagContext()
+			for _, flag := range flags {
+				evaluated[flag] = c.app.featureFlags.Enabled(flag, fc)
+			}
+		}
+		c.Set(FeatureFlagsKey, evaluated)
+		c.Next()
+	}
+}
+
+// FeatureFlag describes a flag of MemoryFeatureFlags
+type FeatureFlag struct {
+	// Enabled turns the flag on for everyone
+	Enabled bool
+
+	// Users turns the flag on for the listed user ids
+	Users []string
+
+	// Percentage turns the flag on for a stable share of users, from 0 to 100
+	// Users are bucketed by id, anonymous requests by IP
+	Percentage int
+}
+
+// MemoryFeatureFlags is an in-memory FeatureFlags provider safe for concurrent use
+type MemoryFeatureFlags struct {
+	mu    sync.RWMutex
+	flags map[string]FeatureFlag
+}
+
+// NewMemoryFeatureFlags returns an in-memory provider with the given flags
+func NewMemoryFeatureFlags(flags map[string]FeatureFlag) *MemoryFeatureFlags {
+	m := &MemoryFeatureFlags{flags: make(map[string]FeatureFlag, len(flags))}
+	maps.Copy(m.flags, flags)
+	return m
+}
+
+// Set adds or replaces a flag
+func (m *MemoryFeatureFlags) Set(name string, flag FeatureFlag) {
+	m.mu.Lock()
+	m.flags[name] = flag
+	m.mu.Unlock()
+}
+
+// Delete removes a flag, which then reports disabled
+func (m *MemoryFeatureFlags) Delete(name string) {
+	m.mu.Lock()
+	delete(m.flags, name)
+	m.mu.Unlock()
+}
+
+// Enabled implements FeatureFlags
+func (m *MemoryFeatureFlags) Enabled(name string, fc FlagContext) bool {
+	m.mu.RLock()
+	flag, ok := m.flags[name]
+	m.mu.RUnlock()
+	switch {
+	case !ok:
+		return false
+	case flag.Enabled:
+		return true
+	case fc.UserID != "" && slices.Contains(flag.Users, fc.UserID):
+		return true
+	case flag.Percentage <= 0:
+		return false
+	}
+	key := fc.UserID
+	if key == "" {
+		key = fc.IP
+	}
+	return rolloutBucket(name, key) < flag.Percentage
+}
+
+// rolloutBucket maps a flag and key to a stable bucket from 0 to 99
+func rolloutBucket(flag, key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(flag))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return int(h.Sum32() % 100)
+}
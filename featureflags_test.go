@@ -0,0 +1,67 @@
+package gonoleks
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestMemoryFeatureFlags(t *testing.T) {
+	flags := NewMemoryFeatureFlags(map[string]FeatureFlag{
+		"on":      {Enabled: true},
+		"beta":    {Users: []string{"alice"}},
+		"rollout": {Percentage: 30},
+	})
+
+	assert.True(t, flags.Enabled("on", FlagContext{}))
+	assert.False(t, flags.Enabled("missing", FlagContext{UserID: "alice"}))
+	assert.True(t, flags.Enabled("beta", FlagContext{UserID: "alice"}))
+	assert.False(t, flags.Enabled("beta", FlagContext{UserID: "bob"}))
+
+	enabled := 0
+	for i := range 1000 {
+		fc := FlagContext{UserID: fmt.Sprintf("user-%d", i)}
+		first := flags.Enabled("rollout", fc)
+		// Evaluation is stable for the same user
+		assert.Equal(t, first, flags.Enabled("rollout", fc))
+		if first {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 300, enabled, 60)
+
+	flags.Set("rollout", FeatureFlag{Percentage: 100})
+	assert.True(t, flags.Enabled("rollout", FlagContext{IP: "192.0.2.1"}))
+	flags.Delete("rollout")
+	assert.False(t, flags.Enabled("rollout", FlagContext{IP: "192.0.2.1"}))
+}
+
+func TestContextFeatureEnabled(t *testing.T) {
+	app := New()
+	app.SetFeatureFlags(NewMemoryFeatureFlags(map[string]FeatureFlag{
+		"new-checkout": {Users: []string{"alice"}},
+		"dark-mode":    {Enabled: true},
+	}))
+	app.Use(func(c *Context) {
+		c.SetPrincipal(&Principal{ID: c.Query("user")})
+		c.Next()
+	}, ExposeFeatureFlags("new-checkout", "dark-mode"))
+	app.GET("/", func(c *Context) {
+		flags := c.MustGet(FeatureFlagsKey).(map[string]bool)
+		c.String(StatusOK, "%t %t %t", c.FeatureEnabled("new-checkout"), flags["new-checkout"], flags["dark-mode"])
+	})
+	app.setupRouter()
+
+	for user, expected := range map[string]string{"alice": "true true true", "bob": "false false true"} {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI("/?user=" + user)
+		app.router.Handler(reqCtx)
+		assert.Equal(t, expected, string(reqCtx.Response.Body()))
+	}
+
+	// Without a provider every flag is disabled
+	ctx, _ := createTestContext()
+	assert.False(t, ctx.FeatureEnabled("dark-mode"))
+}
@@ -0,0 +1,368 @@
+package gonoleks
+
+import (
+	"encoding"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeFormatHTTP is the time_format tag value selecting HTTP date parsing,
+// accepting the IMF-fixdate, RFC 850 and ANSI C formats of RFC 9110 section 5.6.7
+const timeFormatHTTP = "http"
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// maxFormSliceIndex bounds the index of nested keys such as items[0].sku,
+// so a request cannot force the allocation of a huge slice
+const maxFormSliceIndex = 1000
+
+// formDecoder maps string values such as query parameters, form fields or headers
+// onto the fields of a struct
+// The key of a field is taken from its struct tag, or the field name when untagged,
+// and a tag of "-" skips the field
+// The tag may carry options after the key: "required" reports a missing or empty value
+// as an error, and "omitempty" ignores empty values so the field keeps its current value,
+// e.g. `form:"page,omitempty"` or `form:",required"`
+// Fields of embedded structs are decoded as if they belonged to the outer struct,
+// while other struct fields are decoded from nested keys such as address.city or
+// address[city], and slices of structs from indexed keys such as items[0].sku
+// Every field is attempted, and failures are reported together as BindErrors
+//
+// Supported field types are strings, booleans, integers, floats, time.Time,
+// time.Duration, encoding.TextUnmarshaler implementations, pointers to them,
+// and slices of them filled from repeated keys
+// time.Time fields honor a time_format tag holding a layout, "unix", "unixmilli" or "http"
+type formDecoder struct {
+	// tag names the struct tag holding the key of a field, e.g. "form" or "header"
+	tag string
+
+	// source names the origin of the values in a BindError, e.g. "query"
+	source string
+
+	// normalizeKey maps request keys and field keys to a common form before matching
+	normalizeKey func(key string) string // Default = normalizeFormKey
+
+	// timeFormat parses time.Time fields without a time_format tag
+	timeFormat string // Default = time.RFC3339
+
+	// location is used for time layouts without a zone
+	location *time.Location // Default = time.UTC
+}
+
+// Decode sets the fields of the struct obj points to from values
+func (d formDecoder) Decode(obj any, values url.Values) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrBindTarget
+	}
+	if d.normalizeKey == nil {
+		d.normalizeKey = normalizeFormKey
+	}
+	normalized := make(url.Values, len(values))
+	for key, vals := range values {
+		key = d.normalizeKey(key)
+		normalized[key] = append(normalized[key], vals...)
+	}
+	if errs := d.decodeStruct(rv.Elem(), "", normalized, nil); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// decodeStruct decodes the fields of a struct value whose keys start with prefix,
+// appending failures to errs
+func (d formDecoder) decodeStruct(v reflect.Value, prefix string, values url.Values, errs BindErrors) BindErrors {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get(d.tag), ",")
+		if name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if field.Anonymous && name == "" && isNestedStruct(field.Type) {
+			if field.Type.Kind() == reflect.Pointer {
+				if !field.IsExported() {
+					continue
+				}
+				if fv.IsNil() {
+					fv.Set(reflect.New(field.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			errs = d.decodeStruct(fv, prefix, values, errs)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		key := d.normalizeKey(prefix + name)
+		required := hasTagOption(options, "required")
+		switch {
+		case isNestedStruct(field.Type) || field.Type.Kind() == reflect.Slice && isNestedStruct(field.Type.Elem()):
+			if !hasKeyPrefix(values, key+".") {
+				if required {
+					errs = append(errs, newRequiredError(d.source, key))
+				}
+				continue
+			}
+			if field.Type.Kind() == reflect.Slice {
+				errs = d.decodeStructSlice(fv, key, values, errs)
+				continue
+			}
+			errs = d.decodeStruct(allocStruct(fv), key+".", values, errs)
+		default:
+			vals := values[key]
+			if hasTagOption(options, "omitempty") || required {
+				vals = slices.DeleteFunc(slices.Clone(vals), func(value string) bool { return value == "" })
+			}
+			if len(vals) == 0 {
+				if required {
+					errs = append(errs, newRequiredError(d.source, key))
+				}
+				continue
+			}
+			if value, err := d.decodeField(fv, field, vals); err != nil {
+				errs = append(errs, newBindError(d.source, key, field.Type, value, err))
+			}
+		}
+	}
+	return errs
+}
+
+// decodeStructSlice decodes a slice of structs from indexed keys such as items.0.sku
+func (d formDecoder) decodeStructSlice(v reflect.Value, key string, values url.Values, errs BindErrors) BindErrors {
+	prefix := key + "."
+	indexes := make(map[int]struct{})
+	length := 0
+	for k := range values {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok {
+			continue
+		}
+		segment, _, _ := strings.Cut(rest, ".")
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 {
+			continue
+		}
+		if index > maxFormSliceIndex {
+			return append(errs, newBindError(d.source, prefix+segment, v.Type(), segment, ErrBindIndexLimit))
+		}
+		indexes[index] = struct{}{}
+		length = max(length, index+1)
+	}
+	if length == 0 {
+		return errs
+	}
+	slice := reflect.MakeSlice(v.Type(), length, length)
+	for _, index := range slices.Sorted(maps.Keys(indexes)) {
+		elem := slice.Index(index)
+		if elem.Kind() == reflect.Pointer {
+			elem.Set(reflect.New(elem.Type().Elem()))
+		}
+		errs = d.decodeStruct(reflect.Indirect(elem), prefix+strconv.Itoa(index)+".", values, errs)
+	}
+	v.Set(slice)
+	return errs
+}
+
+// decodeField sets a field from its values, filling slices from every value
+// It returns the offending value along with the error
+func (d formDecoder) decodeField(v reflect.Value, field reflect.StructField, values []string) (string, error) {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 && !isScalarType(v.Type()) {
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := d.decodeValue(slice.Index(i), field, value); err != nil {
+				return value, err
+			}
+		}
+		v.Set(slice)
+		return "", nil
+	}
+	return values[0], d.decodeValue(v, field, values[0])
+}
+
+// decodeValue parses a single value into v
+func (d formDecoder) decodeValue(v reflect.Value, field reflect.StructField, value string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := d.decodeValue(ptr.Elem(), field, value); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+	switch v.Type() {
+	case timeType:
+		if value == "" {
+			return nil
+		}
+		t, err := d.parseTime(field, value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		if value == "" {
+			return nil
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(duration))
+		return nil
+	}
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+	if value == "" && v.Kind() != reflect.String {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		// []byte fields receive the raw value
+		v.SetBytes([]byte(value))
+	default:
+		return fmt.Errorf("%w: %s", ErrBindUnsupportedType, v.Type())
+	}
+	return nil
+}
+
+// parseTime parses a time.Time field honoring its time_format tag
+func (d formDecoder) parseTime(field reflect.StructField, value string) (time.Time, error) {
+	layout := field.Tag.Get("time_format")
+	if layout == "" {
+		layout = d.timeFormat
+	}
+	location := d.location
+	if location == nil {
+		location = time.UTC
+	}
+	switch layout {
+	case "":
+		return time.ParseInLocation(time.RFC3339, value, location)
+	case timeFormatHTTP:
+		return http.ParseTime(value)
+	case "unix", "unixmilli":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if layout == "unix" {
+			return time.Unix(n, 0).In(location), nil
+		}
+		return time.UnixMilli(n).In(location), nil
+	default:
+		return time.ParseInLocation(layout, value, location)
+	}
+}
+
+// hasTagOption reports whether a comma separated list of tag options contains option
+func hasTagOption(options, option string) bool {
+	for o := range strings.SplitSeq(options, ",") {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// allocStruct returns the struct v holds, allocating it when v is a nil pointer
+func allocStruct(v reflect.Value) reflect.Value {
+	if v.Kind() != reflect.Pointer {
+		return v
+	}
+	if v.IsNil() {
+		v.Set(reflect.New(v.Type().Elem()))
+	}
+	return v.Elem()
+}
+
+// hasKeyPrefix reports whether any key of values starts with prefix
+func hasKeyPrefix(values url.Values, prefix string) bool {
+	for key := range values {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeFormKey converts bracketed keys to dotted ones, e.g. address[city] to address.city
+// and items[0][sku] to items.0.sku, and drops the empty brackets of list keys such as tag[]
+func normalizeFormKey(key string) string {
+	if !strings.ContainsRune(key, '[') {
+		return key
+	}
+	var b strings.Builder
+	b.Grow(len(key))
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '[':
+			if i+1 < len(key) && key[i+1] == ']' {
+				i++
+				continue
+			}
+			b.WriteByte('.')
+		case ']':
+		default:
+			b.WriteByte(key[i])
+		}
+	}
+	return b.String()
+}
+
+// isNestedStruct reports whether t is a struct, or pointer to one, whose fields are decoded individually
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isScalarType(t)
+}
+
+// isScalarType reports whether t decodes from a single value despite its kind
+func isScalarType(t reflect.Type) bool {
+	return t == timeType || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
@@ -0,0 +1,217 @@
+package gonoleks
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decoderBase struct {
+	ID int `form:"id"`
+}
+
+type decoderTarget struct {
+	decoderBase
+	Name     string        `form:"name"`
+	Active   bool          `form:"active"`
+	Count    uint8         `form:"count"`
+	Ratio    float64       `form:"ratio"`
+	Tags     []string      `form:"tag"`
+	Scores   []int         `form:"score"`
+	Limit    *int          `form:"limit"`
+	Timeout  time.Duration `form:"timeout"`
+	Since    time.Time     `form:"since"`
+	Day      time.Time     `form:"day" time_format:"2006-01-02"`
+	Epoch    time.Time     `form:"epoch" time_format:"unix"`
+	Addr     net.IP        `form:"addr"`
+	Ignored  string        `form:"-"`
+	Untagged string
+	hidden   string
+}
+
+func TestFormDecoderDecode(t *testing.T) {
+	values := url.Values{
+		"id":       {"7"},
+		"name":     {"gopher"},
+		"active":   {"true"},
+		"count":    {"200"},
+		"ratio":    {"0.5"},
+		"tag":      {"a", "b"},
+		"score":    {"1", "2", "3"},
+		"limit":    {"10"},
+		"timeout":  {"1m30s"},
+		"since":    {"2024-03-05T10:30:00Z"},
+		"day":      {"2024-03-05"},
+		"epoch":    {"1700000000"},
+		"addr":     {"192.0.2.1"},
+		"Ignored":  {"x"},
+		"-":        {"x"},
+		"Untagged": {"plain"},
+		"hidden":   {"x"},
+	}
+	var target decoderTarget
+	require.NoError(t, formDecoder{tag: "form"}.Decode(&target, values))
+
+	assert.Equal(t, 7, target.ID, "Embedded struct fields should be decoded with flat keys")
+	assert.Equal(t, "gopher", target.Name)
+	assert.True(t, target.Active)
+	assert.Equal(t, uint8(200), target.Count)
+	assert.Equal(t, 0.5, target.Ratio)
+	assert.Equal(t, []string{"a", "b"}, target.Tags)
+	assert.Equal(t, []int{1, 2, 3}, target.Scores)
+	require.NotNil(t, target.Limit)
+	assert.Equal(t, 10, *target.Limit)
+	assert.Equal(t, 90*time.Second, target.Timeout)
+	assert.Equal(t, time.Date(2024, time.March, 5, 10, 30, 0, 0, time.UTC), target.Since)
+	assert.Equal(t, time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), target.Day)
+	assert.Equal(t, int64(1700000000), target.Epoch.Unix())
+	assert.Equal(t, "192.0.2.1", target.Addr.String())
+	assert.Empty(t, target.Ignored)
+	assert.Equal(t, "plain", target.Untagged)
+	assert.Empty(t, target.hidden)
+}
+
+func TestFormDecoderErrors(t *testing.T) {
+	decoder := formDecoder{tag: "form"}
+	values := url.Values{"count": {"300"}}
+
+	var target decoderTarget
+	assert.Error(t, decoder.Decode(&target, values), "Overflowing values should fail")
+	assert.ErrorIs(t, decoder.Decode(target, values), ErrBindTarget)
+	assert.ErrorIs(t, decoder.Decode(nil, values), ErrBindTarget)
+
+	var unsupported struct {
+		Values map[string]string `form:"values"`
+	}
+	err := decoder.Decode(&unsupported, url.Values{"values": {"x"}})
+	assert.ErrorIs(t, err, ErrBindUnsupportedType)
+}
+
+func TestFormDecoderEmptyValues(t *testing.T) {
+	var target decoderTarget
+	target.Count = 3
+	values := url.Values{"count": {""}, "name": {""}}
+	require.NoError(t, formDecoder{tag: "form"}.Decode(&target, values))
+	assert.Equal(t, uint8(3), target.Count, "Empty values should leave non-string fields untouched")
+	assert.Empty(t, target.Name)
+}
+
+type decoderAddress struct {
+	City string `form:"city"`
+	Zip  int    `form:"zip"`
+}
+
+type decoderItem struct {
+	SKU      string `form:"sku"`
+	Quantity int    `form:"qty"`
+}
+
+type decoderOrder struct {
+	Address  decoderAddress  `form:"address"`
+	Billing  *decoderAddress `form:"billing"`
+	Shipping *decoderAddress `form:"shipping"`
+	Items    []decoderItem   `form:"items"`
+	Extras   []*decoderItem  `form:"extras"`
+	Tags     []string        `form:"tag"`
+}
+
+func TestFormDecoderNestedKeys(t *testing.T) {
+	values := url.Values{
+		"address.city":   {"Berlin"},
+		"address[zip]":   {"10115"},
+		"billing[city]":  {"Hamburg"},
+		"items[0].sku":   {"A-1"},
+		"items[0][qty]":  {"2"},
+		"items.1.sku":    {"B-2"},
+		"extras[0][sku]": {"C-3"},
+		"tag[]":          {"x", "y"},
+	}
+	var order decoderOrder
+	require.NoError(t, formDecoder{tag: "form"}.Decode(&order, values))
+
+	assert.Equal(t, decoderAddress{City: "Berlin", Zip: 10115}, order.Address)
+	require.NotNil(t, order.Billing)
+	assert.Equal(t, "Hamburg", order.Billing.City)
+	assert.Nil(t, order.Shipping, "Pointers without nested keys should stay nil")
+	assert.Equal(t, []decoderItem{{SKU: "A-1", Quantity: 2}, {SKU: "B-2"}}, order.Items)
+	require.Len(t, order.Extras, 1)
+	assert.Equal(t, "C-3", order.Extras[0].SKU)
+	assert.Equal(t, []string{"x", "y"}, order.Tags)
+}
+
+func TestFormDecoderNestedErrors(t *testing.T) {
+	var order decoderOrder
+	err := formDecoder{tag: "form", source: "form"}.Decode(&order, url.Values{
+		"address[zip]":  {"abc"},
+		"items[1][qty]": {"many"},
+	})
+	var errs BindErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "address.zip", errs[0].Field)
+	assert.Equal(t, "items.1.qty", errs[1].Field)
+
+	err = formDecoder{tag: "form"}.Decode(&order, url.Values{"items[100000].sku": {"x"}})
+	assert.ErrorIs(t, err, ErrBindIndexLimit)
+}
+
+func TestNormalizeFormKey(t *testing.T) {
+	tests := map[string]string{
+		"name":          "name",
+		"address[city]": "address.city",
+		"items[0][sku]": "items.0.sku",
+		"items[0].sku":  "items.0.sku",
+		"tag[]":         "tag",
+		"a[b][c][]":     "a.b.c",
+		"address.city":  "address.city",
+	}
+	for key, expected := range tests {
+		assert.Equal(t, expected, normalizeFormKey(key), key)
+	}
+}
+
+func TestFormDecoderTagOptions(t *testing.T) {
+	type search struct {
+		Query   string          `form:"q,required"`
+		Page    int             `form:"page,omitempty"`
+		Sort    string          `form:"sort,omitempty"`
+		Limit   *int            `form:"limit,omitempty"`
+		Tags    []string        `form:"tag,omitempty"`
+		Token   string          `form:",required"`
+		Address decoderAddress  `form:"address,required"`
+		Items   []decoderItem   `form:"items,required"`
+		Owner   *decoderAddress `form:"owner"`
+	}
+
+	target := search{Page: 1, Sort: "name"}
+	values := url.Values{
+		"q":            {"gopher"},
+		"page":         {""},
+		"sort":         {""},
+		"limit":        {""},
+		"tag":          {"", "go", ""},
+		"Token":        {"secret"},
+		"address.city": {"Berlin"},
+		"items.0.sku":  {"A-1"},
+	}
+	require.NoError(t, formDecoder{tag: "form"}.Decode(&target, values))
+	assert.Equal(t, 1, target.Page, "Empty omitempty values should keep the current value")
+	assert.Equal(t, "name", target.Sort)
+	assert.Nil(t, target.Limit)
+	assert.Equal(t, []string{"go"}, target.Tags)
+
+	err := formDecoder{tag: "form", source: "query"}.Decode(&search{}, url.Values{"q": {""}})
+	var errs BindErrors
+	require.ErrorAs(t, err, &errs)
+	fields := make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field
+		assert.ErrorIs(t, e, ErrBindRequired)
+	}
+	assert.Equal(t, []string{"q", "Token", "address", "items"}, fields, "Missing required fields should be reported together")
+	assert.Equal(t, `query field "q": value is required`, errs[0].Error())
+}
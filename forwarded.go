@@ -0,0 +1,125 @@
+package gonoleks
+
+import (
+	"net"
+	"strings"
+)
+
+// ForwardedElement is one hop of the RFC 7239 Forwarded header
+type ForwardedElement struct {
+	// For identifies the node making the request to the proxy, usually the client IP
+	For string
+
+	// By identifies the interface where the proxy received the request
+	By string
+
+	// Host is the Host request header as received by the proxy
+	Host string
+
+	// Proto is the protocol used to make the request, e.g. "http" or "https"
+	Proto string
+}
+
+// ParseForwarded parses an RFC 7239 Forwarded header value into its elements,
+// ordered from the client to the nearest proxy
+// Quoted values may contain commas and semicolons, unknown parameters are ignored
+//
+//	ParseForwarded(`for="[2001:db8::17]:4711";proto=https, for=192.0.2.43`)
+func ParseForwarded(header string) []ForwardedElement {
+	var elements []ForwardedElement
+	var element ForwardedElement
+	var key, value strings.Builder
+	inValue, quoted, escaped, empty := false, false, false, true
+
+	flushPair := func() {
+		name := strings.ToLower(strings.TrimSpace(key.String()))
+		v := value.String()
+		if !quoted {
+			v = strings.TrimSpace(v)
+		}
+		switch name {
+		case "for":
+			element.For = v
+		case "by":
+			element.By = v
+		case "host":
+			element.Host = v
+		case "proto":
+			element.Proto = strings.ToLower(v)
+		}
+		if name != "" {
+			empty = false
+		}
+		key.Reset()
+		value.Reset()
+		inValue, quoted = false, false
+	}
+	flushElement := func() {
+		flushPair()
+		if !empty {
+			elements = append(elements, element)
+		}
+		element, empty = ForwardedElement{}, true
+	}
+
+	inQuotes := false
+	for i := 0; i < len(header); i++ {
+		ch := header[i]
+		switch {
+		case escaped:
+			value.WriteByte(ch)
+			escaped = false
+		case inQuotes && ch == '\\':
+			escaped = true
+		case inQuotes && ch == '"':
+			inQuotes = false
+		case inQuotes:
+			value.WriteByte(ch)
+		case ch == '"' && inValue && strings.TrimSpace(value.String()) == "":
+			value.Reset()
+			inQuotes, quoted = true, true
+		case ch == '=' && !inValue:
+			inValue = true
+		case ch == ';':
+			flushPair()
+		case ch == ',':
+			flushElement()
+		case inValue:
+			value.WriteByte(ch)
+		default:
+			key.WriteByte(ch)
+		}
+	}
+	flushElement()
+	return elements
+}
+
+// Forwarded returns the parsed Forwarded header, or nil when the request
+// does not come from a trusted proxy
+func (c *Context) Forwarded() []ForwardedElement {
+	header := c.GetHeader(HeaderForwarded)
+	if header == "" || !c.isTrustedProxy() {
+		return nil
+	}
+	return ParseForwarded(header)
+}
+
+// forwardedElement returns the Forwarded element describing the request as sent by the client
+// When Options.TrustedProxies is set, hops are walked from the nearest proxy and the element
+// added by the outermost trusted proxy is used, otherwise the first element is used
+func (c *Context) forwardedElement() (ForwardedElement, bool) {
+	elements := c.Forwarded()
+	if len(elements) == 0 {
+		return ForwardedElement{}, false
+	}
+	if c.app == nil || len(c.app.trustedPrefixes()) == 0 {
+		return elements[0], true
+	}
+	for i := len(elements) - 1; i > 0; i-- {
+		ip := normalizeIP(elements[i].For)
+		if ip == "" || !c.app.isTrustedAddr(net.ParseIP(ip)) {
+			return elements[i], true
+		}
+	}
+	return elements[0], true
+}
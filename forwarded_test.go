@@ -0,0 +1,67 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseForwarded(t *testing.T) {
+	elements := ParseForwarded(`for="[2001:db8:cafe::17]:4711";proto=HTTPS;host="example.com", For=192.0.2.43;by=10.0.0.1`)
+	assert.Equal(t, []ForwardedElement{
+		{For: "[2001:db8:cafe::17]:4711", Proto: "https", Host: "example.com"},
+		{For: "192.0.2.43", By: "10.0.0.1"},
+	}, elements)
+
+	// Quoted values may contain separators and escapes
+	elements = ParseForwarded(`for="_a,b;c";host="ex\"ample.com"`)
+	assert.Equal(t, []ForwardedElement{{For: "_a,b;c", Host: `ex"ample.com`}}, elements)
+
+	assert.Empty(t, ParseForwarded(""))
+	assert.Empty(t, ParseForwarded(" , ;"))
+	assert.Equal(t, []ForwardedElement{{For: "unknown"}}, ParseForwarded("for=unknown;foo=bar"))
+}
+
+func TestForwardedHeader(t *testing.T) {
+	headers := map[string]string{
+		HeaderForwarded: "for=198.51.100.17;proto=https;host=shop.example.com",
+	}
+
+	// Without TrustedProxies every peer is trusted for the client IP, but not for the scheme and host
+	c := &Context{requestCtx: newProxiedRequest(MethodGet, "/", "10.0.0.1", headers)}
+	assert.Equal(t, "http", c.Scheme())
+	assert.NotEqual(t, "shop.example.com", c.Host())
+	assert.Equal(t, "198.51.100.17", c.ClientIP())
+	assert.Len(t, c.Forwarded(), 1)
+
+	app := New()
+	app.TrustedProxies = []string{"10.0.0.0/8"}
+	app.setupRouter()
+	c = &Context{app: app, requestCtx: newProxiedRequest(MethodGet, "/", "203.0.113.1", headers)}
+	assert.Nil(t, c.Forwarded())
+	assert.Equal(t, "http", c.Scheme())
+	assert.NotEqual(t, "shop.example.com", c.Host())
+	assert.Equal(t, "203.0.113.1", c.ClientIP())
+}
+
+func TestForwardedMultiHop(t *testing.T) {
+	app := New()
+	app.TrustedProxies = []string{"10.0.0.0/8"}
+	app.setupRouter()
+
+	// The client forged the first element, the edge proxy at 10.0.0.5 appended the second one
+	c := &Context{app: app, requestCtx: newProxiedRequest(MethodGet, "/", "10.0.0.9", map[string]string{
+		HeaderForwarded: "for=1.2.3.4;host=evil.example;proto=http, for=198.51.100.17;host=shop.example.com;proto=https, for=10.0.0.5",
+	})}
+	assert.Equal(t, "198.51.100.17", c.ClientIP())
+	assert.Equal(t, "shop.example.com", c.Host())
+	assert.Equal(t, "https", c.Scheme())
+	assert.True(t, c.Secure())
+
+	// Obfuscated identifiers fall back to the peer address
+	c = &Context{app: app, requestCtx: newProxiedRequest(MethodGet, "/", "10.0.0.9", map[string]string{
+		HeaderForwarded: "for=_hidden;proto=https",
+	})}
+	assert.Equal(t, "10.0.0.9", c.ClientIP())
+	assert.Equal(t, "https", c.Scheme())
+}
@@ -0,0 +1,48 @@
+package gonoleks
+
+// IsHTMX reports whether the request was made by htmx, per its HX-Request header
+func (c *Context) IsHTMX() bool {
+	return c.GetHeader(HeaderHXRequest) == "true"
+}
+
+// IsFragmentRequest reports whether the request asks for a page fragment to swap into the
+// current page, as made by htmx, Unpoly and Turbo Frames
+// Boosted htmx requests expect whole pages, so they are not fragment requests
+func (c *Context) IsFragmentRequest() bool {
+	if c.IsHTMX() {
+		return c.GetHeader(HeaderHXBoosted) != "true"
+	}
+	return c.GetHeader(HeaderXUpTarget) != "" || c.GetHeader(HeaderTurboFrame) != ""
+}
+
+// FragmentTarget returns the element the fragment of the request is swapped into, from the
+// HX-Target, X-Up-Target or Turbo-Frame header, or an empty string
+func (c *Context) FragmentTarget() string {
+	for _, header := range []string{HeaderHXTarget, HeaderXUpTarget, HeaderTurboFrame} {
+		if target := c.GetHeader(header); target != "" {
+			return target
+		}
+	}
+	return ""
+}
+
+// HTMLFragment renders only the named block of a template, e.g. one defined with
+// {{block "rows" .}} or {{define "rows"}} by HTMLEngine, as a text/html response
+// Block names share the namespace of every template, so they should be unique
+func (c *Context) HTMLFragment(code int, block string, data any) error {
+	return c.HTML(code, block, data)
+}
+
+// HTMLPartial renders the block for fragment requests, see IsFragmentRequest, and the whole
+// page otherwise, so one handler serves both the first page load and later swaps
+// The response varies on the headers of the fragment requests, so caches keep both versions
+//
+//	// users.html: <table>{{block "rows" .}}...{{end}}</table>
+//	return c.HTMLPartial(gonoleks.StatusOK, "users.html", "rows", gonoleks.H{"Users": users})
+func (c *Context) HTMLPartial(code int, page, block string, data any) error {
+	c.Vary(HeaderHXRequest, HeaderXUpTarget, HeaderTurboFrame)
+	if c.IsFragmentRequest() {
+		return c.HTMLFragment(code, block, data)
+	}
+	return c.HTML(code, page, data)
+}
@@ -0,0 +1,54 @@
+package gonoleks
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsFragmentRequest(t *testing.T) {
+	tests := []struct {
+		headers  map[string]string
+		fragment bool
+		target   string
+	}{
+		{nil, false, ""},
+		{map[string]string{HeaderHXRequest: "true", HeaderHXTarget: "rows"}, true, "rows"},
+		{map[string]string{HeaderHXRequest: "true", HeaderHXBoosted: "true"}, false, ""},
+		{map[string]string{HeaderXUpTarget: ".list"}, true, ".list"},
+		{map[string]string{HeaderTurboFrame: "messages"}, true, "messages"},
+	}
+	for _, tt := range tests {
+		c, requestCtx := createTestContext()
+		for key, value := range tt.headers {
+			requestCtx.Request.Header.Set(key, value)
+		}
+		assert.Equal(t, tt.fragment, c.IsFragmentRequest(), tt.headers)
+		assert.Equal(t, tt.target, c.FragmentTarget(), tt.headers)
+	}
+}
+
+func TestHTMLPartial(t *testing.T) {
+	engine, err := NewHTMLEngine(HTMLEngineConfig{FS: fstest.MapFS{
+		"users.html": {Data: []byte(`<table>{{block "rows" .}}{{range .Users}}<tr>{{.}}</tr>{{end}}{{end}}</table>`)},
+	}})
+	require.NoError(t, err)
+	app := New()
+	app.SetTemplateEngine(engine)
+	app.GET("/users", func(c *Context) {
+		_ = c.HTMLPartial(StatusOK, "users.html", "rows", H{"Users": []string{"a", "b"}})
+	})
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/users", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, `<table><tr>a</tr><tr>b</tr></table>`, string(reqCtx.Response.Body()))
+	assert.Contains(t, string(reqCtx.Response.Header.Peek(HeaderVary)), "Hx-Request")
+
+	reqCtx = newProxiedRequest(MethodGet, "/users", "203.0.113.5", map[string]string{HeaderHXRequest: "true"})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, `<tr>a</tr><tr>b</tr>`, string(reqCtx.Response.Body()))
+	assert.Equal(t, MIMETextHTMLCharsetUTF8, string(reqCtx.Response.Header.ContentType()))
+}
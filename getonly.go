@@ -0,0 +1,111 @@
+package gonoleks
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// getOnlyKey is the user value key marking requests accepted by a GET-only listener
+const getOnlyKey = "gonoleksGETOnly"
+
+// ListenGETOnly registers an additional listener that Run and RunTLS serve alongside
+// the main address, answering requests on it as if GETOnly was set
+// It allows exposing a read-only port, e.g. to the public, next to a full internal one,
+// and is not supported in prefork mode
+//
+//	ln, _ := net.Listen("tcp", ":8081")
+//	app.ListenGETOnly(ln)
+//	app.Run(":8080")
+func (g *Gonoleks) ListenGETOnly(ln net.Listener) {
+	g.getOnlyListeners = append(g.getOnlyListeners, ln)
+}
+
+// serveGETOnlyListeners starts serving the listeners registered with ListenGETOnly
+func (g *Gonoleks) serveGETOnlyListeners(tlsConfig *tlsConfig) {
+	for _, ln := range g.getOnlyListeners {
+		server := g.newHTTPServer()
+		server.Handler = func(fctx *fasthttp.RequestCtx) {
+			fctx.SetUserValue(getOnlyKey, true)
+			g.router.Handler(fctx)
+		}
+		g.getOnlyServers = append(g.getOnlyServers, server)
+		go func() {
+			var err error
+			if tlsConfig != nil {
+				err = server.ServeTLS(ln, tlsConfig.certFile, tlsConfig.keyFile)
+			} else {
+				err = server.Serve(ln)
+			}
+			if err != nil {
+				g.diagnostics.Error("GET-only listener stopped", "address", ln.Addr().String(), "error", err)
+			}
+		}()
+	}
+}
+
+// shutdownGETOnlyServers gracefully shuts down the servers of the GET-only listeners
+func (g *Gonoleks) shutdownGETOnlyServers(ctx context.Context) {
+	for _, server := range g.getOnlyServers {
+		if err := server.ShutdownWithContext(ctx); err != nil {
+			g.diagnostics.Warn("Failed to shut down GET-only listener", "error", err)
+		}
+	}
+	g.getOnlyServers = nil
+}
+
+// isGETOnlyMethod reports whether method is accepted in GET-only mode
+func isGETOnlyMethod(method string) bool {
+	return method == MethodGet || method == MethodHead || method == MethodOptions
+}
+
+// getOnly reports whether the request falls under GET-only mode
+func (r *router) getOnly(fctx *fasthttp.RequestCtx) bool {
+	if r.app.GETOnly {
+		return true
+	}
+	return len(r.app.getOnlyListeners) > 0 && fctx.UserValue(getOnlyKey) != nil
+}
+
+// rejectNonGET answers a request rejected by GET-only mode
+// The Allow header lists the read-only methods registered for the path, and paths
+// without any are answered like unknown routes
+func (r *router) rejectNonGET(fctx *fasthttp.RequestCtx, path string, ctx *Context) {
+	ctx.handlers = append(ctx.handlers, r.globalMiddleware...)
+	allow := readOnlyAllow(r.allowed(path, ctx))
+	if allow == "" {
+		if r.noRoute != nil {
+			ctx.handlers = append(ctx.handlers, r.noRoute...)
+		} else {
+			fctx.Error(fasthttp.StatusMessage(StatusNotFound), StatusNotFound)
+		}
+		ctx.Next()
+		return
+	}
+	status := r.app.GETOnlyStatus
+	if status == 0 {
+		status = StatusMethodNotAllowed
+	}
+	fctx.Error(fasthttp.StatusMessage(status), status)
+	fctx.Response.Header.Set(HeaderAllow, allow)
+	ctx.Next()
+}
+
+// readOnlyAllow filters an Allow header value down to the methods accepted in GET-only mode,
+// returning an empty string when neither GET nor HEAD is registered
+func readOnlyAllow(allow string) string {
+	methods := make([]string, 0, 3)
+	readable := false
+	for method := range strings.SplitSeq(allow, ", ") {
+		if isGETOnlyMethod(method) {
+			methods = append(methods, method)
+			readable = readable || method != MethodOptions
+		}
+	}
+	if !readable {
+		return ""
+	}
+	return strings.Join(methods, ", ")
+}
@@ -0,0 +1,96 @@
+package gonoleks
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestGETOnly(t *testing.T) {
+	app := New()
+	app.GETOnly = true
+	app.GET("/articles", func(c *Context) { c.Status(StatusOK) })
+	app.POST("/articles", func(c *Context) { c.Status(StatusCreated) })
+	app.HEAD("/feed", func(c *Context) { c.Status(StatusOK) })
+	app.POST("/upload", func(c *Context) { c.Status(StatusCreated) })
+	app.setupRouter()
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		allow  string
+	}{
+		{MethodGet, "/articles", StatusOK, ""},
+		{MethodPost, "/articles", StatusMethodNotAllowed, "GET, OPTIONS"},
+		{MethodDelete, "/feed", StatusMethodNotAllowed, "HEAD, OPTIONS"},
+		{MethodPost, "/upload", StatusNotFound, ""},
+		{MethodPut, "/missing", StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			reqCtx := newProxiedRequest(tt.method, tt.path, "203.0.113.5", nil)
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.code, reqCtx.Response.StatusCode())
+			assert.Equal(t, tt.allow, string(reqCtx.Response.Header.Peek(HeaderAllow)))
+		})
+	}
+
+	app.GETOnlyStatus = StatusForbidden
+	reqCtx := newProxiedRequest(MethodPost, "/articles", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusForbidden, reqCtx.Response.StatusCode())
+	assert.Equal(t, "GET, OPTIONS", string(reqCtx.Response.Header.Peek(HeaderAllow)))
+}
+
+func TestGETOnlyRunsGlobalMiddleware(t *testing.T) {
+	app := New()
+	app.GETOnly = true
+	app.Use(func(c *Context) {
+		c.Header("X-Middleware", "ran")
+		c.Next()
+	})
+	app.GET("/articles", func(c *Context) { c.Status(StatusOK) })
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodDelete, "/articles", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusMethodNotAllowed, reqCtx.Response.StatusCode())
+	assert.Equal(t, "ran", string(reqCtx.Response.Header.Peek("X-Middleware")))
+}
+
+func TestListenGETOnly(t *testing.T) {
+	readOnly, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	main, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := main.Addr().String()
+	require.NoError(t, main.Close())
+
+	app := New()
+	app.ListenGETOnly(readOnly)
+	app.POST("/articles", func(c *Context) { c.Status(StatusCreated) })
+	app.GET("/articles", func(c *Context) { c.Status(StatusOK) })
+	go func() { _ = app.Run(addr) }()
+	defer func() { _ = app.Shutdown() }()
+
+	client := &fasthttp.Client{}
+	post := func(host string) int {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		resp := fasthttp.AcquireResponse()
+		defer fasthttp.ReleaseResponse(resp)
+		req.Header.SetMethod(MethodPost)
+		req.SetRequestURI("http://" + host + "/articles")
+		if err := client.DoTimeout(req, resp, time.Second); err != nil {
+			return 0
+		}
+		return resp.StatusCode()
+	}
+	require.Eventually(t, func() bool { return post(addr) == StatusCreated }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, StatusMethodNotAllowed, post(readOnly.Addr().String()))
+}
@@ -0,0 +1,23 @@
+// Package gonolekstest provides utilities for testing Gonoleks applications
+package gonolekstest
+
+import (
+	"time"
+
+	"github.com/gonoleks/gonoleks"
+)
+
+// Deterministic makes the time and randomness seen by the handlers of app reproducible:
+// Context.Now reads the returned clock, stopped at start, and Context.Rand is seeded with seed
+// Framework features reading them, such as Chaos, Split and BanIP, become deterministic as well
+// It must be called before the app serves requests
+//
+//	clock := gonolekstest.Deterministic(app, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 42)
+//	resp := app.Test(httptest.NewRequest("GET", "/", nil))
+//	clock.Advance(time.Hour)
+func Deterministic(app *gonoleks.Gonoleks, start time.Time, seed uint64) *gonoleks.ManualClock {
+	clock := gonoleks.NewManualClock(start)
+	app.SetClock(clock)
+	app.SetRandSeed(seed)
+	return clock
+}
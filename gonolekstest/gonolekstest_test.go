@@ -0,0 +1,40 @@
+package gonolekstest
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gonoleks/gonoleks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeterministic(t *testing.T) {
+	body := func(app *gonoleks.Gonoleks) string {
+		resp := app.Test(httptest.NewRequest(gonoleks.MethodGet, "/", nil))
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b)
+	}
+	newApp := func() (*gonoleks.Gonoleks, *gonoleks.ManualClock) {
+		app := gonoleks.New()
+		clock := Deterministic(app, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 42)
+		app.GET("/", func(c *gonoleks.Context) {
+			c.String(gonoleks.StatusOK, "%s", c.Now().Format(time.RFC3339)+" "+strconv.Itoa(c.Rand().IntN(1000000)))
+		})
+		return app, clock
+	}
+
+	app, clock := newApp()
+	first := body(app)
+	assert.Contains(t, first, "2024-01-01T00:00:00Z ")
+	assert.Equal(t, first, body(app))
+
+	other, _ := newApp()
+	assert.Equal(t, first, body(other))
+
+	clock.Advance(time.Hour)
+	assert.Contains(t, body(app), "2024-01-01T01:00:00Z ")
+}
@@ -0,0 +1,218 @@
+package gonoleks
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// HeaderHardening configures strict checks of the raw request headers
+// fasthttp parses headers more leniently than net/http, e.g. it unfolds continuation lines and
+// accepts bare LF line endings, which proxies in front of the app may interpret differently,
+// opening the door to request smuggling
+// Rejected requests are answered with 400 Bad Request and their connection is closed
+type HeaderHardening struct {
+	// RejectAmbiguousFraming rejects requests with both Transfer-Encoding and Content-Length,
+	// several Transfer-Encoding headers, or several Host headers
+	RejectAmbiguousFraming bool
+
+	// RejectObsFold rejects headers continued on the next line (obs-fold), deprecated by RFC 9112, 5.2
+	RejectObsFold bool
+
+	// RejectBareLF rejects header lines ending with LF instead of CRLF
+	RejectBareLF bool
+
+	// RejectInvalidNames rejects header names containing whitespace, e.g. "Transfer-Encoding :"
+	RejectInvalidNames bool
+
+	// MaxHeaders caps the number of request header fields, zero is unlimited
+	MaxHeaders int
+}
+
+// StrictHeaderHardening enables every header check with at most 100 header fields
+var StrictHeaderHardening = HeaderHardening{
+	RejectAmbiguousFraming: true,
+	RejectObsFold:          true,
+	RejectBareLF:           true,
+	RejectInvalidNames:     true,
+	MaxHeaders:             100,
+}
+
+// enabled reports whether any header check is enabled
+func (hh *HeaderHardening) enabled() bool {
+	return hh.RejectAmbiguousFraming || hh.RejectObsFold || hh.RejectBareLF || hh.RejectInvalidNames || hh.MaxHeaders > 0
+}
+
+// check returns why the request headers must be rejected, or an empty string
+func (hh *HeaderHardening) check(header *fasthttp.RequestHeader) string {
+	audit := auditHeaders(header.RawHeaders())
+	switch {
+	case hh.RejectAmbiguousFraming && audit.ambiguousFraming() != "":
+		return audit.ambiguousFraming()
+	case hh.RejectObsFold && audit.obsFold:
+		return "obs-fold"
+	case hh.RejectBareLF && audit.bareLF:
+		return "bare LF"
+	case hh.RejectInvalidNames && audit.invalidName:
+		return "invalid header name"
+	case hh.MaxHeaders > 0 && audit.headers > hh.MaxHeaders:
+		return "too many headers"
+	}
+	return ""
+}
+
+// checkHeaders logs and reports whether the request fails the header checks
+func (g *Gonoleks) checkHeaders(fctx *fasthttp.RequestCtx) bool {
+	reason := g.HeaderHardening.check(&fctx.Request.Header)
+	if reason == "" {
+		return false
+	}
+	g.diagnostics.Warn("Rejected request headers",
+		"reason", reason,
+		"method", string(fctx.Method()),
+		"path", string(fctx.Path()),
+		"ip", fctx.RemoteIP().String(),
+	)
+	return true
+}
+
+// headerAudit describes the raw request headers
+type headerAudit struct {
+	headers           int
+	transferEncodings int
+	contentLengths    int
+	hosts             int
+	obsFold           bool
+	bareLF            bool
+	invalidName       bool
+}
+
+// auditHeaders scans the raw request headers, as received before fasthttp normalized them
+func auditHeaders(raw []byte) headerAudit {
+	var audit headerAudit
+	for len(raw) > 0 {
+		line := raw
+		if i := bytes.IndexByte(raw, '\n'); i >= 0 {
+			line, raw = raw[:i], raw[i+1:]
+		} else {
+			raw = nil
+		}
+		if l := len(line); l > 0 && line[l-1] == '\r' {
+			line = line[:l-1]
+		} else {
+			audit.bareLF = true
+		}
+		if len(line) == 0 {
+			break
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			audit.obsFold = true
+			continue
+		}
+		audit.headers++
+		name, _, _ := bytes.Cut(line, []byte{':'})
+		if bytes.ContainsAny(name, " \t") {
+			audit.invalidName = true
+			name = bytes.TrimRight(name, " \t")
+		}
+		switch {
+		case bytes.EqualFold(name, []byte(HeaderTransferEncoding)):
+			audit.transferEncodings++
+		case bytes.EqualFold(name, []byte(HeaderContentLength)):
+			audit.contentLengths++
+		case bytes.EqualFold(name, []byte(HeaderHost)):
+			audit.hosts++
+		}
+	}
+	return audit
+}
+
+// ambiguousFraming returns why the message framing or target is ambiguous, or an empty string
+func (a headerAudit) ambiguousFraming() string {
+	switch {
+	case a.transferEncodings > 0 && a.contentLengths > 0:
+		return "Transfer-Encoding with Content-Length"
+	case a.transferEncodings > 1:
+		return "several Transfer-Encoding headers"
+	case a.hosts > 1:
+		return "several Host headers"
+	}
+	return ""
+}
+
+// SecurityAuditConfig defines the config for SecurityAuditWithConfig
+type SecurityAuditConfig struct {
+	// MaxHeaders is the number of header fields above which a request is suspicious
+	MaxHeaders int // Default = 100
+
+	// OnSuspicious is called with the reasons a request is suspicious, after it is logged
+	// It may abort the request
+	OnSuspicious func(c *Context, reasons []string)
+}
+
+// SecurityAudit instances a middleware logging suspicious requests without rejecting them,
+// e.g. to evaluate HeaderHardening before enabling it
+func SecurityAudit() handlerFunc {
+	return SecurityAuditWithConfig(SecurityAuditConfig{})
+}
+
+// SecurityAuditWithConfig instances a SecurityAudit middleware with config
+// Requests are suspicious for ambiguous framing, obs-fold, bare LF line endings, invalid header names,
+// too many headers, and path traversal attempts or encoded NUL bytes in the raw path
+//
+//	app.Use(gonoleks.SecurityAuditWithConfig(gonoleks.SecurityAuditConfig{
+//	    OnSuspicious: func(c *gonoleks.Context, reasons []string) { metrics.Inc("suspicious") },
+//	}))
+func SecurityAuditWithConfig(conf SecurityAuditConfig) handlerFunc {
+	if conf.MaxHeaders <= 0 {
+		conf.MaxHeaders = 100
+	}
+	return func(c *Context) {
+		if reasons := securityAudit(c, conf.MaxHeaders); len(reasons) > 0 {
+			c.diagnostics().Warn("Suspicious request",
+				"reasons", strings.Join(reasons, ", "),
+				"method", string(c.requestCtx.Method()),
+				"uri", string(c.requestCtx.RequestURI()),
+				"ip", c.ClientIP(),
+				"request_id", c.requestID(),
+			)
+			if conf.OnSuspicious != nil {
+				conf.OnSuspicious(c, reasons)
+				if c.IsAborted() {
+					return
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// securityAudit returns the reasons the request is suspicious
+func securityAudit(c *Context, maxHeaders int) []string {
+	var reasons []string
+	audit := auditHeaders(c.requestCtx.Request.Header.RawHeaders())
+	if reason := audit.ambiguousFraming(); reason != "" {
+		reasons = append(reasons, reason)
+	}
+	if audit.obsFold {
+		reasons = append(reasons, "obs-fold")
+	}
+	if audit.bareLF {
+		reasons = append(reasons, "bare LF")
+	}
+	if audit.invalidName {
+		reasons = append(reasons, "invalid header name")
+	}
+	if audit.headers > maxHeaders {
+		reasons = append(reasons, "too many headers")
+	}
+	rawPath := strings.ToLower(string(c.requestCtx.URI().PathOriginal()))
+	if hasUnsafePathSegment(rawPath) || strings.Contains(rawPath, "%2e") || strings.Contains(rawPath, "%5c") {
+		reasons = append(reasons, "path traversal")
+	}
+	if strings.Contains(rawPath, "%00") {
+		reasons = append(reasons, "encoded NUL in path")
+	}
+	return reasons
+}
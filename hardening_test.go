@@ -0,0 +1,109 @@
+package gonoleks
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"charm.land/log/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// serveRaw parses a raw HTTP request, so its raw headers are kept, and serves it through the router
+func serveRaw(t *testing.T, app *Gonoleks, raw string) *fasthttp.RequestCtx {
+	t.Helper()
+	var req fasthttp.Request
+	require.NoError(t, req.Read(bufio.NewReader(strings.NewReader(raw))))
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Init(&req, nil, nil)
+	app.router.Handler(reqCtx)
+	return reqCtx
+}
+
+func TestAuditHeaders(t *testing.T) {
+	audit := auditHeaders([]byte("Host: a\r\nTransfer-Encoding: chunked\r\nContent-Length: 4\r\n\r\n"))
+	assert.Equal(t, 3, audit.headers)
+	assert.Equal(t, "Transfer-Encoding with Content-Length", audit.ambiguousFraming())
+	assert.False(t, audit.obsFold || audit.bareLF || audit.invalidName)
+
+	audit = auditHeaders([]byte("Host: a\r\nX-Long: a\r\n b\r\nTransfer-Encoding : chunked\nHost: b\r\n\r\n"))
+	assert.Equal(t, 4, audit.headers)
+	assert.True(t, audit.obsFold)
+	assert.True(t, audit.bareLF)
+	assert.True(t, audit.invalidName)
+	assert.Equal(t, 1, audit.transferEncodings)
+	assert.Equal(t, "several Host headers", audit.ambiguousFraming())
+
+	assert.Equal(t, "several Transfer-Encoding headers", headerAudit{transferEncodings: 2}.ambiguousFraming())
+	assert.Empty(t, headerAudit{contentLengths: 1, hosts: 1}.ambiguousFraming())
+}
+
+func TestHeaderHardening(t *testing.T) {
+	var buf bytes.Buffer
+	app := New()
+	app.SetDiagnosticsLogger(log.New(&buf))
+	app.HeaderHardening = StrictHeaderHardening
+	app.POST("/", func(c *Context) { c.String(StatusOK, "%s", c.Body()) })
+	app.setupRouter()
+
+	reqCtx := serveRaw(t, app, "POST / HTTP/1.1\r\nHost: a\r\nContent-Length: 4\r\n\r\ntest")
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, "test", string(reqCtx.Response.Body()))
+
+	reqCtx = serveRaw(t, app, "POST / HTTP/1.1\r\nHost: a\r\nContent-Length: 4\r\nTransfer-Encoding: chunked\r\n\r\n4\r\ntest\r\n0\r\n\r\n")
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode())
+	assert.True(t, reqCtx.Response.ConnectionClose())
+	assert.Contains(t, buf.String(), "Rejected request headers")
+	assert.Contains(t, buf.String(), "Transfer-Encoding with Content-Length")
+
+	reqCtx = serveRaw(t, app, "POST / HTTP/1.1\r\nHost: a\r\nX-Folded: a\r\n b\r\nContent-Length: 0\r\n\r\n")
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode())
+
+	reqCtx = serveRaw(t, app, "POST / HTTP/1.1\r\nHost: a\nContent-Length: 0\r\n\r\n")
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode())
+
+	reqCtx = serveRaw(t, app, "POST / HTTP/1.1\r\nHost: a\r\n"+strings.Repeat("X-A: b\r\n", 100)+"Content-Length: 0\r\n\r\n")
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode())
+	assert.Contains(t, buf.String(), "too many headers")
+
+	// Each check is opt-in
+	app.HeaderHardening = HeaderHardening{RejectObsFold: true}
+	reqCtx = serveRaw(t, app, "POST / HTTP/1.1\r\nHost: a\nContent-Length: 0\r\n\r\n")
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+}
+
+func TestSecurityAudit(t *testing.T) {
+	var buf bytes.Buffer
+	var got []string
+	app := New()
+	app.SetDiagnosticsLogger(log.New(&buf))
+	app.Use(SecurityAuditWithConfig(SecurityAuditConfig{
+		MaxHeaders: 2,
+		OnSuspicious: func(c *Context, reasons []string) {
+			got = reasons
+			if c.Query("block") != "" {
+				c.AbortWithStatus(StatusForbidden)
+			}
+		},
+	}))
+	app.GET("/*path", func(c *Context) { c.String(StatusOK, "ok") })
+	app.setupRouter()
+
+	reqCtx := serveRaw(t, app, "GET /a HTTP/1.1\r\nHost: a\r\n\r\n")
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Nil(t, got)
+	assert.Empty(t, buf.String())
+
+	// Suspicious requests are logged but still served
+	reqCtx = serveRaw(t, app, "GET /a/%2e%2e/etc%00 HTTP/1.1\r\nHost: a\r\nHost: b\r\nX-A: a\r\n b\r\n\r\n")
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, []string{"several Host headers", "obs-fold", "too many headers", "path traversal", "encoded NUL in path"}, got)
+	assert.Contains(t, buf.String(), "Suspicious request")
+
+	reqCtx = serveRaw(t, app, "GET /a/../b?block=1 HTTP/1.1\r\nHost: a\nX-A: b\r\n\r\n")
+	assert.Equal(t, StatusForbidden, reqCtx.Response.StatusCode())
+	assert.Equal(t, []string{"bare LF", "path traversal"}, got)
+}
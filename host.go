@@ -0,0 +1,147 @@
+package gonoleks
+
+import (
+	"net"
+	"strings"
+)
+
+// Hostname returns the requested host without the port, lowercased
+// Internationalized domain names in their ASCII "xn--" form are decoded to Unicode,
+// so "xn--bcher-kva.example" is returned as "bücher.example"
+func (c *Context) Hostname() string {
+	host := c.Host()
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	} else if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if !strings.Contains(host, "xn--") {
+		return host
+	}
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		if encoded, ok := strings.CutPrefix(label, "xn--"); ok {
+			if decoded, ok := decodePunycode(encoded); ok {
+				labels[i] = decoded
+			}
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+// Subdomains returns the subdomains of the hostname, from left to right
+// The offset is the number of trailing labels forming the domain itself and defaults to 2,
+// so "tobi.ferrets.example.com" yields ["tobi", "ferrets"]
+// Use an offset of 3 for domains such as "example.co.uk"
+// IP addresses have no subdomains
+func (c *Context) Subdomains(offset ...int) []string {
+	n := 2
+	if len(offset) > 0 {
+		n = offset[0]
+	}
+	hostname := c.Hostname()
+	if hostname == "" || net.ParseIP(hostname) != nil {
+		return []string{}
+	}
+	labels := strings.Split(hostname, ".")
+	if n < 0 || n >= len(labels) {
+		return []string{}
+	}
+	return labels[:len(labels)-n]
+}
+
+// Punycode parameters from RFC 3492
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+)
+
+// decodePunycode decodes an RFC 3492 Punycode label without its "xn--" prefix
+func decodePunycode(encoded string) (string, bool) {
+	var output []rune
+	pos := 0
+	if i := strings.LastIndexByte(encoded, '-'); i >= 0 {
+		for _, r := range encoded[:i] {
+			if r >= 0x80 {
+				return "", false
+			}
+			output = append(output, r)
+		}
+		pos = i + 1
+	}
+	n, bias, i := punycodeInitialN, punycodeInitialBias, 0
+	for pos < len(encoded) {
+		oldI, w := i, 1
+		for k := punycodeBase; ; k += punycodeBase {
+			if pos >= len(encoded) {
+				return "", false
+			}
+			digit, ok := punycodeDigit(encoded[pos])
+			if !ok {
+				return "", false
+			}
+			pos++
+			i += digit * w
+			t := k - bias
+			if t < punycodeTMin {
+				t = punycodeTMin
+			} else if t > punycodeTMax {
+				t = punycodeTMax
+			}
+			if digit < t {
+				break
+			}
+			w *= punycodeBase - t
+			if i < 0 || w <= 0 {
+				return "", false
+			}
+		}
+		length := len(output) + 1
+		bias = punycodeAdapt(i-oldI, length, oldI == 0)
+		n += i / length
+		i %= length
+		if n > 0x10FFFF {
+			return "", false
+		}
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = rune(n)
+		i++
+	}
+	return string(output), true
+}
+
+// punycodeDigit returns the value of a Punycode digit
+func punycodeDigit(b byte) (int, bool) {
+	switch {
+	case b >= '0' && b <= '9':
+		return int(b-'0') + 26, true
+	case b >= 'a' && b <= 'z':
+		return int(b - 'a'), true
+	case b >= 'A' && b <= 'Z':
+		return int(b - 'A'), true
+	}
+	return 0, false
+}
+
+// punycodeAdapt is the bias adaptation function from RFC 3492 section 6.1
+func punycodeAdapt(delta, numPoints int, firstTime bool) int {
+	if firstTime {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
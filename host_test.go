@@ -0,0 +1,65 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextHostname(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"example.com", "example.com"},
+		{"Example.COM:8080", "example.com"},
+		{"example.com.", "example.com"},
+		{"[::1]:8080", "::1"},
+		{"[::1]", "::1"},
+		{"127.0.0.1:3000", "127.0.0.1"},
+		{"xn--bcher-kva.example", "bücher.example"},
+		{"shop.xn--mnchen-3ya.de", "shop.münchen.de"},
+		{"xn--!!.example", "xn--!!.example"},
+	}
+	for _, tt := range tests {
+		ctx, requestCtx := createTestContext()
+		requestCtx.Request.Header.Set(HeaderHost, tt.host)
+		assert.Equal(t, tt.expected, ctx.Hostname(), tt.host)
+	}
+}
+
+func TestContextSubdomains(t *testing.T) {
+	ctx, requestCtx := createTestContext()
+	requestCtx.Request.Header.Set(HeaderHost, "tobi.ferrets.example.com:8080")
+	assert.Equal(t, []string{"tobi", "ferrets"}, ctx.Subdomains())
+	assert.Equal(t, []string{"tobi"}, ctx.Subdomains(3))
+	assert.Equal(t, []string{"tobi", "ferrets", "example", "com"}, ctx.Subdomains(0))
+	assert.Empty(t, ctx.Subdomains(4))
+
+	ctx, requestCtx = createTestContext()
+	requestCtx.Request.Header.Set(HeaderHost, "acme.example.co.uk")
+	assert.Equal(t, []string{"acme"}, ctx.Subdomains(3))
+
+	ctx, requestCtx = createTestContext()
+	requestCtx.Request.Header.Set(HeaderHost, "192.168.1.10")
+	assert.Empty(t, ctx.Subdomains())
+
+	ctx, requestCtx = createTestContext()
+	requestCtx.Request.Header.Set(HeaderHost, "example.com")
+	assert.Empty(t, ctx.Subdomains())
+}
+
+func TestDecodePunycode(t *testing.T) {
+	decoded, ok := decodePunycode("bcher-kva")
+	assert.True(t, ok)
+	assert.Equal(t, "bücher", decoded)
+
+	decoded, ok = decodePunycode("wgv71a119e")
+	assert.True(t, ok)
+	assert.Equal(t, "日本語", decoded)
+
+	_, ok = decodePunycode("abc-é")
+	assert.False(t, ok)
+	_, ok = decodePunycode("zz")
+	assert.False(t, ok)
+}
@@ -0,0 +1,342 @@
+package gonoleks
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HubOverflow selects what happens when a client's send queue is full
+type HubOverflow int
+
+const (
+	// HubDropMessage drops the message for the slow client and counts it in HubClient.Dropped
+	HubDropMessage HubOverflow = iota
+
+	// HubDisconnectSlow closes the slow client, so it can reconnect and resynchronize
+	HubDisconnectSlow
+)
+
+// HubMessage is a message delivered through a Hub
+type HubMessage struct {
+	// Room is the room the message was broadcast to, empty for messages sent to all or one client
+	Room string
+
+	// Event names the message type, sent as the SSE event field
+	Event string
+
+	// ID is sent as the SSE id field, letting clients resume with Last-Event-ID
+	ID string
+
+	// Data is the message payload
+	Data []byte
+}
+
+// HubConfig defines the config for a Hub
+type HubConfig struct {
+	// QueueSize is the number of messages buffered per client
+	QueueSize int // Default = 64
+
+	// Overflow selects what happens when a client's queue is full
+	Overflow HubOverflow // Default = HubDropMessage
+
+	// Heartbeat is the interval of the keep-alive comments ServeSSE sends to idle clients
+	Heartbeat time.Duration // Default = 15 seconds
+}
+
+// Hub fans messages out to connected clients, e.g. WebSocket or SSE connections,
+// grouping them in rooms
+// Every client has its own bounded send queue, so a slow client never blocks a broadcast
+// A Hub is safe for concurrent use
+type Hub struct {
+	config  HubConfig
+	mu      sync.RWMutex
+	clients map[string]*HubClient
+	rooms   map[string]map[*HubClient]struct{}
+	closed  bool
+	nextID  atomic.Uint64
+}
+
+// HubClient is a client registered with a Hub
+type HubClient struct {
+	// ID identifies the client in the hub
+	ID string
+
+	hub       *Hub
+	send      chan HubMessage
+	done      chan struct{}
+	closeOnce sync.Once
+	rooms     map[string]struct{} // guarded by hub.mu
+	dropped   atomic.Uint64
+}
+
+// NewHub creates a Hub
+//
+//	hub := gonoleks.NewHub()
+//	app.GET("/events/:room", func(c *gonoleks.Context) {
+//		hub.ServeSSE(c, c.Param("room"))
+//	})
+//	app.POST("/events/:room", func(c *gonoleks.Context) {
+//		hub.Broadcast(c.Param("room"), gonoleks.HubMessage{Event: "message", Data: c.Body()})
+//	})
+func NewHub(config ...HubConfig) *Hub {
+	var conf HubConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if conf.QueueSize <= 0 {
+		conf.QueueSize = 64
+	}
+	if conf.Heartbeat <= 0 {
+		conf.Heartbeat = 15 * time.Second
+	}
+	return &Hub{
+		config:  conf,
+		clients: make(map[string]*HubClient),
+		rooms:   make(map[string]map[*HubClient]struct{}),
+	}
+}
+
+// Register adds a client with the given ID to the hub
+// It fails with ErrHubClientExists if the ID is taken and ErrHubClosed after Close
+func (h *Hub) Register(id string) (*HubClient, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrHubClosed
+	}
+	if _, ok := h.clients[id]; ok {
+		return nil, ErrHubClientExists
+	}
+	client := &HubClient{
+		ID:    id,
+		hub:   h,
+		send:  make(chan HubMessage, h.config.QueueSize),
+		done:  make(chan struct{}),
+		rooms: make(map[string]struct{}),
+	}
+	h.clients[id] = client
+	return client, nil
+}
+
+// Broadcast queues msg for every client in room and returns the number of clients it was queued for
+func (h *Hub) Broadcast(room string, msg HubMessage) int {
+	msg.Room = room
+	h.mu.RLock()
+	members := make([]*HubClient, 0, len(h.rooms[room]))
+	for client := range h.rooms[room] {
+		members = append(members, client)
+	}
+	h.mu.RUnlock()
+	return h.deliver(members, msg)
+}
+
+// BroadcastAll queues msg for every client of the hub and returns the number of clients it was queued for
+func (h *Hub) BroadcastAll(msg HubMessage) int {
+	h.mu.RLock()
+	clients := make([]*HubClient, 0, len(h.clients))
+	for _, client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+	return h.deliver(clients, msg)
+}
+
+// Send queues msg for the client with the given ID and reports whether it was queued
+func (h *Hub) Send(id string, msg HubMessage) bool {
+	h.mu.RLock()
+	client, ok := h.clients[id]
+	h.mu.RUnlock()
+	return ok && h.deliver([]*HubClient{client}, msg) == 1
+}
+
+// Clients returns the number of registered clients
+func (h *Hub) Clients() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// RoomSize returns the number of clients in room
+func (h *Hub) RoomSize(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Close disconnects every client and rejects further registrations
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	clients := h.clients
+	h.clients = make(map[string]*HubClient)
+	h.rooms = make(map[string]map[*HubClient]struct{})
+	h.mu.Unlock()
+	for _, client := range clients {
+		client.closeOnce.Do(func() { close(client.done) })
+	}
+}
+
+// deliver queues msg for clients, applying the overflow policy to full queues
+func (h *Hub) deliver(clients []*HubClient, msg HubMessage) int {
+	queued := 0
+	for _, client := range clients {
+		select {
+		case <-client.done:
+			continue
+		default:
+		}
+		select {
+		case client.send <- msg:
+			queued++
+		default:
+			client.dropped.Add(1)
+			if h.config.Overflow == HubDisconnectSlow {
+				client.Close()
+			}
+		}
+	}
+	return queued
+}
+
+// Join adds the client to room
+func (cl *HubClient) Join(room string) {
+	h := cl.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[cl.ID] != cl {
+		return
+	}
+	members, ok := h.rooms[room]
+	if !ok {
+		members = make(map[*HubClient]struct{})
+		h.rooms[room] = members
+	}
+	members[cl] = struct{}{}
+	cl.rooms[room] = struct{}{}
+}
+
+// Leave removes the client from room
+func (cl *HubClient) Leave(room string) {
+	h := cl.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cl.leave(room)
+}
+
+// leave removes the client from room, the hub lock must be held
+func (cl *HubClient) leave(room string) {
+	h := cl.hub
+	delete(cl.rooms, room)
+	if members, ok := h.rooms[room]; ok {
+		delete(members, cl)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+// Messages returns the queue of messages to deliver to the client
+// Consumers must also watch Done, as the channel is never closed
+func (cl *HubClient) Messages() <-chan HubMessage {
+	return cl.send
+}
+
+// Done returns a channel closed once the client is removed from the hub
+func (cl *HubClient) Done() <-chan struct{} {
+	return cl.done
+}
+
+// Dropped returns the number of messages dropped because the client's queue was full
+func (cl *HubClient) Dropped() uint64 {
+	return cl.dropped.Load()
+}
+
+// Close removes the client from its rooms and the hub, e.g. when its connection is gone
+func (cl *HubClient) Close() {
+	h := cl.hub
+	h.mu.Lock()
+	for room := range cl.rooms {
+		cl.leave(room)
+	}
+	if h.clients[cl.ID] == cl {
+		delete(h.clients, cl.ID)
+	}
+	h.mu.Unlock()
+	cl.closeOnce.Do(func() { close(cl.done) })
+}
+
+// ServeSSE streams the hub messages of rooms to the request as Server-Sent Events
+// The client is registered under a generated ID and removed once the connection is gone
+// or the hub is closed, and idle connections receive a keep-alive comment every Heartbeat
+func (h *Hub) ServeSSE(c *Context, rooms ...string) {
+	client, err := h.Register("sse-" + strconv.FormatUint(h.nextID.Add(1), 10))
+	if err != nil {
+		_ = c.AbortWithError(StatusServiceUnavailable, err)
+		return
+	}
+	for _, room := range rooms {
+		client.Join(room)
+	}
+	c.requestCtx.SetContentType(MIMETextEventStream)
+	c.requestCtx.Response.Header.Set(HeaderCacheControl, "no-cache")
+	c.requestCtx.Response.Header.Set("X-Accel-Buffering", "no")
+	heartbeat := h.config.Heartbeat
+	c.requestCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer client.Close()
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-client.done:
+				return
+			case msg := <-client.send:
+				writeSSE(w, msg)
+			case <-ticker.C:
+				_, _ = w.WriteString(": keep-alive\n\n")
+			}
+			if err := w.Flush(); err != nil {
+				// The client went away
+				return
+			}
+		}
+	})
+}
+
+// sseFieldReplacer strips line breaks from single line fields, which could otherwise inject fields or events
+var sseFieldReplacer = strings.NewReplacer("\r", "", "\n", "")
+
+// writeSSE writes a message in the text/event-stream format
+// Data is split on CRLF, CR and LF alike, since clients treat each as a line break
+func writeSSE(w *bufio.Writer, msg HubMessage) {
+	if msg.ID != "" {
+		_, _ = w.WriteString("id: " + sseFieldReplacer.Replace(msg.ID) + "\n")
+	}
+	if msg.Event != "" {
+		_, _ = w.WriteString("event: " + sseFieldReplacer.Replace(msg.Event) + "\n")
+	}
+	if len(msg.Data) == 0 {
+		_, _ = w.WriteString("data:\n")
+	}
+	for data := msg.Data; len(data) > 0; {
+		line := data
+		i := bytes.IndexAny(data, "\r\n")
+		if i >= 0 {
+			line = data[:i]
+			if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+				i++
+			}
+			data = data[i+1:]
+		} else {
+			data = nil
+		}
+		_, _ = w.WriteString("data: ")
+		_, _ = w.Write(line)
+		_ = w.WriteByte('\n')
+	}
+	_ = w.WriteByte('\n')
+}
@@ -0,0 +1,138 @@
+package gonoleks
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestHubRooms(t *testing.T) {
+	hub := NewHub()
+	alice, err := hub.Register("alice")
+	require.NoError(t, err)
+	bob, err := hub.Register("bob")
+	require.NoError(t, err)
+	_, err = hub.Register("alice")
+	require.ErrorIs(t, err, ErrHubClientExists)
+
+	alice.Join("general")
+	bob.Join("general")
+	bob.Join("random")
+	assert.Equal(t, 2, hub.RoomSize("general"))
+
+	assert.Equal(t, 2, hub.Broadcast("general", HubMessage{Event: "chat", Data: []byte("hi")}))
+	assert.Equal(t, 1, hub.Broadcast("random", HubMessage{Data: []byte("only bob")}))
+	assert.True(t, hub.Send("alice", HubMessage{Data: []byte("direct")}))
+	assert.False(t, hub.Send("carol", HubMessage{}))
+
+	msg := <-alice.Messages()
+	assert.Equal(t, "general", msg.Room)
+	assert.Equal(t, "hi", string(msg.Data))
+	assert.Equal(t, "direct", string((<-alice.Messages()).Data))
+	assert.Equal(t, "hi", string((<-bob.Messages()).Data))
+	assert.Equal(t, "only bob", string((<-bob.Messages()).Data))
+
+	bob.Leave("random")
+	assert.Equal(t, 0, hub.RoomSize("random"))
+	bob.Close()
+	assert.Equal(t, 1, hub.RoomSize("general"))
+	assert.Equal(t, 1, hub.Clients())
+	select {
+	case <-bob.Done():
+	default:
+		t.Fatal("closed client should be done")
+	}
+	assert.Equal(t, 1, hub.BroadcastAll(HubMessage{Data: []byte("all")}))
+
+	hub.Close()
+	select {
+	case <-alice.Done():
+	default:
+		t.Fatal("closing the hub should close its clients")
+	}
+	_, err = hub.Register("dave")
+	require.ErrorIs(t, err, ErrHubClosed)
+}
+
+func TestHubOverflow(t *testing.T) {
+	hub := NewHub(HubConfig{QueueSize: 1})
+	slow, err := hub.Register("slow")
+	require.NoError(t, err)
+	slow.Join("room")
+	assert.Equal(t, 1, hub.Broadcast("room", HubMessage{Data: []byte("1")}))
+	assert.Equal(t, 0, hub.Broadcast("room", HubMessage{Data: []byte("2")}))
+	assert.Equal(t, uint64(1), slow.Dropped())
+	assert.Equal(t, 1, hub.Clients(), "dropping keeps the client")
+
+	hub = NewHub(HubConfig{QueueSize: 1, Overflow: HubDisconnectSlow})
+	slow, err = hub.Register("slow")
+	require.NoError(t, err)
+	slow.Join("room")
+	hub.Broadcast("room", HubMessage{})
+	hub.Broadcast("room", HubMessage{})
+	assert.Equal(t, 0, hub.Clients(), "slow client should be disconnected")
+	<-slow.Done()
+}
+
+func TestHubServeSSE(t *testing.T) {
+	hub := NewHub(HubConfig{Heartbeat: time.Hour})
+	app := New()
+	app.GET("/events/:room", func(c *Context) { hub.ServeSSE(c, c.Param("room")) })
+	app.setupRouter()
+	server := app.newHTTPServer()
+	ln := fasthttputil.NewInmemoryListener()
+	go func() { _ = server.Serve(ln) }()
+	defer ln.Close()
+
+	conn, err := ln.Dial()
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /events/news HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return hub.RoomSize("news") == 1 }, time.Second, 5*time.Millisecond)
+
+	hub.Broadcast("news", HubMessage{ID: "7", Event: "update", Data: []byte("line one\nline two")})
+	body := readUntil(t, conn, "\n\n")
+	assert.Contains(t, body, "Content-Type: text/event-stream")
+	assert.Contains(t, body, "id: 7\nevent: update\ndata: line one\ndata: line two\n\n")
+
+	hub.Close()
+	require.Eventually(t, func() bool { return hub.Clients() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestWriteSSE(t *testing.T) {
+	format := func(msg HubMessage) string {
+		var b strings.Builder
+		w := bufio.NewWriter(&b)
+		writeSSE(w, msg)
+		require.NoError(t, w.Flush())
+		return b.String()
+	}
+	assert.Equal(t, "id: 1data: x\nevent: updateid: 2\ndata: a\ndata: b\ndata: c\ndata: \ndata: d\n\n",
+		format(HubMessage{ID: "1\ndata: x", Event: "update\r\nid: 2", Data: []byte("a\r\nb\rc\n\nd\n")}))
+	assert.Equal(t, "data:\n\n", format(HubMessage{}))
+}
+
+// readUntil reads from conn until the text read so far, past the response headers, contains suffix
+func readUntil(t *testing.T, conn net.Conn, suffix string) string {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	reader := bufio.NewReader(conn)
+	var b strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		b.WriteString(line)
+		if err != nil {
+			t.Fatalf("read %q: %v", b.String(), err)
+		}
+		if _, after, ok := strings.Cut(b.String(), "\r\n\r\n"); ok && strings.Contains(after, "data:") && strings.HasSuffix(b.String(), suffix) {
+			return b.String()
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package gonoleks
+
+import (
+	"bytes"
+	"encoding/hex"
+	"hash/fnv"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"sync"
+	"time"
+)
+
+// ImageOptions defines how PNG and JPEG encode an image and how clients may cache it
+type ImageOptions struct {
+	// Quality is the JPEG quality, from 1 to 100
+	Quality int // Default = 90
+
+	// Compression is the PNG compression level
+	Compression png.CompressionLevel // Default = png.DefaultCompression
+
+	// CacheControl sets the Cache-Control header unless zero
+	CacheControl CacheControlOptions
+
+	// ETag derives an ETag from the encoded image, so requests for an unchanged image,
+	// e.g. a generated QR code or chart, are answered with 304 Not Modified
+	ETag bool
+}
+
+// pngEncoderPool reuses the buffers of the PNG encoder between responses
+type pngEncoderPool struct {
+	pool sync.Pool
+}
+
+// Get implements png.EncoderBufferPool
+func (p *pngEncoderPool) Get() *png.EncoderBuffer {
+	buf, _ := p.pool.Get().(*png.EncoderBuffer)
+	return buf
+}
+
+// Put implements png.EncoderBufferPool
+func (p *pngEncoderPool) Put(buf *png.EncoderBuffer) {
+	p.pool.Put(buf)
+}
+
+// pngBuffers is shared by every PNG encoder
+var pngBuffers = &pngEncoderPool{}
+
+// PNG encodes img as a PNG image response
+//
+//	return c.PNG(gonoleks.StatusOK, chart, gonoleks.ImageOptions{
+//		CacheControl: gonoleks.CacheControlOptions{Public: true, MaxAge: time.Hour},
+//		ETag:         true,
+//	})
+func (c *Context) PNG(code int, img image.Image, opts ...ImageOptions) error {
+	var opt ImageOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: opt.Compression, BufferPool: pngBuffers}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return err
+	}
+	c.image(code, MIMEImagePNG, buf.Bytes(), opt)
+	return nil
+}
+
+// JPEG encodes img as a JPEG image response with the quality of opts
+func (c *Context) JPEG(code int, img image.Image, opts ...ImageOptions) error {
+	var opt ImageOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Quality <= 0 {
+		opt.Quality = 90
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: min(opt.Quality, 100)}); err != nil {
+		return err
+	}
+	c.image(code, MIMEImageJPEG, buf.Bytes(), opt)
+	return nil
+}
+
+// image sends an encoded image with the caching headers of opt
+func (c *Context) image(code int, contentType string, data []byte, opt ImageOptions) {
+	if opt.CacheControl != (CacheControlOptions{}) {
+		c.CacheControl(opt.CacheControl)
+	}
+	if opt.ETag && code == StatusOK {
+		hash := fnv.New64a()
+		_, _ = hash.Write(data)
+		if c.ServeConditional(time.Time{}, hex.EncodeToString(hash.Sum(nil))) {
+			return
+		}
+	}
+	c.Data(code, contentType, data)
+}
@@ -0,0 +1,67 @@
+package gonoleks
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for x := range 8 {
+		img.Set(x, x, color.RGBA{R: 255, A: 255})
+	}
+	return img
+}
+
+func TestPNG(t *testing.T) {
+	c, requestCtx := createTestContext()
+	require.NoError(t, c.PNG(StatusOK, testImage(), ImageOptions{
+		CacheControl: CacheControlOptions{Public: true, MaxAge: time.Hour},
+		ETag:         true,
+	}))
+	assert.Equal(t, StatusOK, requestCtx.Response.StatusCode())
+	assert.Equal(t, MIMEImagePNG, string(requestCtx.Response.Header.ContentType()))
+	assert.Equal(t, "public, max-age=3600", string(requestCtx.Response.Header.Peek(HeaderCacheControl)))
+	decoded, err := png.Decode(bytes.NewReader(requestCtx.Response.Body()))
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, color.RGBAModel.Convert(decoded.At(3, 3)))
+	etag := string(requestCtx.Response.Header.Peek(HeaderETag))
+	require.NotEmpty(t, etag)
+
+	c, requestCtx = createTestContext()
+	requestCtx.Request.Header.Set(HeaderIfNoneMatch, etag)
+	require.NoError(t, c.PNG(StatusOK, testImage(), ImageOptions{ETag: true}))
+	assert.Equal(t, StatusNotModified, requestCtx.Response.StatusCode())
+	assert.Empty(t, requestCtx.Response.Body())
+}
+
+func TestJPEG(t *testing.T) {
+	c, requestCtx := createTestContext()
+	require.NoError(t, c.JPEG(StatusOK, testImage(), ImageOptions{Quality: 10}))
+	low := len(requestCtx.Response.Body())
+	assert.Equal(t, MIMEImageJPEG, string(requestCtx.Response.Header.ContentType()))
+	assert.Empty(t, requestCtx.Response.Header.Peek(HeaderETag))
+	_, err := jpeg.Decode(bytes.NewReader(requestCtx.Response.Body()))
+	require.NoError(t, err)
+
+	c, requestCtx = createTestContext()
+	require.NoError(t, c.JPEG(StatusCreated, testImage(), ImageOptions{Quality: 1000, ETag: true}))
+	assert.Equal(t, StatusCreated, requestCtx.Response.StatusCode())
+	assert.Greater(t, len(requestCtx.Response.Body()), low)
+	// Only 200 responses are conditional
+	assert.Empty(t, requestCtx.Response.Header.Peek(HeaderETag))
+}
+
+func TestPNGEncodeError(t *testing.T) {
+	c, requestCtx := createTestContext()
+	assert.Error(t, c.PNG(StatusOK, image.NewRGBA(image.Rect(0, 0, 0, 0))))
+	assert.Empty(t, requestCtx.Response.Body())
+}
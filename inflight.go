@@ -0,0 +1,75 @@
+package gonoleks
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// InFlightRequest describes a request being served
+type InFlightRequest struct {
+	Method   string
+	Path     string
+	ClientIP string
+	Started  time.Time
+	Duration time.Duration
+}
+
+// ShutdownReport summarizes a graceful shutdown
+type ShutdownReport struct {
+	// Started is when the shutdown began
+	Started time.Time
+
+	// Duration is how long the shutdown took
+	Duration time.Duration
+
+	// InFlight lists the requests running when the shutdown began
+	InFlight []InFlightRequest
+
+	// TimedOut reports whether the deadline expired before all requests completed
+	TimedOut bool
+
+	// Stuck lists the requests still running when the deadline expired
+	Stuck []InFlightRequest
+}
+
+// InFlight returns the requests being served, longest running first
+// It is empty unless TrackInFlight is enabled
+func (g *Gonoleks) InFlight() []InFlightRequest {
+	now := time.Now()
+	requests := make([]InFlightRequest, 0)
+	g.inFlight.Range(func(_, value any) bool {
+		request := *value.(*InFlightRequest)
+		request.Duration = now.Sub(request.Started)
+		requests = append(requests, request)
+		return true
+	})
+	slices.SortFunc(requests, func(a, b InFlightRequest) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	return requests
+}
+
+// beginInFlight records a request as being served until it is deleted by its id
+func (g *Gonoleks) beginInFlight(fctx *fasthttp.RequestCtx) {
+	g.inFlight.Store(fctx.ID(), &InFlightRequest{
+		Method:   string(fctx.Method()),
+		Path:     string(fctx.Path()),
+		ClientIP: fctx.RemoteIP().String(),
+		Started:  time.Now(),
+	})
+}
+
+// logInFlight logs a summary line followed by each request
+func logInFlight(logger *log.Logger, msg string, requests []InFlightRequest) {
+	if len(requests) == 0 {
+		return
+	}
+	logger.Warn(msg, "count", len(requests))
+	for _, request := range requests {
+		logger.Warn("In-flight request", "method", request.Method, "path", request.Path, "ip", request.ClientIP, "duration", request.Duration)
+	}
+}
@@ -0,0 +1,69 @@
+package gonoleks
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestShutdownReportsStuckRequests(t *testing.T) {
+	app := New()
+	app.TrackInFlight = true
+	release := make(chan struct{})
+	app.GET("/slow", func(c *Context) {
+		<-release
+		c.String(StatusOK, "done")
+	})
+	app.setupRouter()
+	app.httpServer = app.newHTTPServer()
+
+	ln := fasthttputil.NewInmemoryListener()
+	go func() { _ = app.httpServer.Serve(ln) }()
+	client := &fasthttp.Client{Dial: func(addr string) (net.Conn, error) { return ln.Dial() }}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _, _ = client.Get(nil, "http://example.com/slow")
+	}()
+	require.Eventually(t, func() bool { return len(app.InFlight()) == 1 }, time.Second, time.Millisecond)
+	inFlight := app.InFlight()[0]
+	assert.Equal(t, MethodGet, inFlight.Method)
+	assert.Equal(t, "/slow", inFlight.Path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	report, err := app.ShutdownWithContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, report.TimedOut)
+	require.Len(t, report.InFlight, 1)
+	require.Len(t, report.Stuck, 1)
+	assert.Equal(t, "/slow", report.Stuck[0].Path)
+	assert.GreaterOrEqual(t, report.Stuck[0].Duration, 50*time.Millisecond)
+
+	close(release)
+	<-done
+	require.Eventually(t, func() bool { return len(app.InFlight()) == 0 }, time.Second, time.Millisecond)
+}
+
+func TestInFlightDisabled(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Context) {
+		assert.Empty(t, app.InFlight())
+	})
+	app.setupRouter()
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/")
+	app.router.Handler(reqCtx)
+
+	report, err := app.ShutdownWithContext(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, report.TimedOut)
+	assert.Empty(t, report.InFlight)
+}
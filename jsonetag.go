@@ -0,0 +1,63 @@
+package gonoleks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// jsonETagKey is the user value key enabling JSON ETags for the request
+const jsonETagKey = "gonoleksJSONETag"
+
+// canonicalJSON encodes JSON with sorted map keys, so equal values always encode to the same bytes
+var canonicalJSON = sonic.Config{SortMapKeys: true}.Froze()
+
+// JSONETag instances a middleware making JSON answer successful GET and HEAD requests with a
+// strong ETag computed over the canonical encoding of the value, with sorted map keys, and
+// with 304 Not Modified when it matches If-None-Match
+// An ETag already set by the handler, e.g. with ServeConditional, is kept
+//
+//	api.Use(gonoleks.JSONETag())
+func JSONETag() handlerFunc {
+	return func(c *Context) {
+		c.requestCtx.SetUserValue(jsonETagKey, true)
+		c.Next()
+	}
+}
+
+// JSONETag enables JSON ETags for the route, see the JSONETag middleware
+//
+//	app.GET("/products/:id", showProduct).JSONETag()
+func (r *Route) JSONETag() *Route {
+	r.Handlers = append(handlersChain{JSONETag()}, r.Handlers...)
+	return r
+}
+
+// jsonETagEnabled reports whether JSON should compute an ETag for a response with status code
+func (c *Context) jsonETagEnabled(code int) bool {
+	if code != StatusOK || c.requestCtx.UserValue(jsonETagKey) == nil {
+		return false
+	}
+	if !c.requestCtx.IsGet() && !c.requestCtx.IsHead() {
+		return false
+	}
+	return len(c.requestCtx.Response.Header.Peek(HeaderETag)) == 0
+}
+
+// jsonWithETag sends the canonical encoding of obj with a strong ETag, or 304 Not Modified
+func (c *Context) jsonWithETag(obj any) error {
+	body, err := canonicalJSON.Marshal(obj)
+	if err != nil {
+		c.diagnostics().Error(ErrJSONMarshalingFailed, "error", err)
+		return fmt.Errorf("%v: %w", ErrJSONMarshal, err)
+	}
+	sum := sha256.Sum256(body)
+	if c.ServeConditional(time.Time{}, hex.EncodeToString(sum[:16])) {
+		return nil
+	}
+	c.requestCtx.Response.SetBody(body)
+	return nil
+}
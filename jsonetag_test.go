@@ -0,0 +1,55 @@
+package gonoleks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONETag(t *testing.T) {
+	app := New()
+	app.GET("/products", func(c *Context) {
+		_ = c.JSON(StatusOK, H{"b": 2, "a": H{"z": true, "y": []int{1}}})
+	}).JSONETag()
+	app.GET("/plain", func(c *Context) { _ = c.JSON(StatusOK, H{"a": 1}) })
+	api := app.Group("/api", JSONETag())
+	api.GET("/created", func(c *Context) { _ = c.JSON(StatusCreated, H{"a": 1}) })
+	api.POST("/items", func(c *Context) { _ = c.JSON(StatusOK, H{"a": 1}) })
+	api.GET("/versioned", func(c *Context) {
+		if c.ServeConditional(time.Time{}, "v7") {
+			return
+		}
+		_ = c.JSON(StatusOK, H{"a": 1})
+	})
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/products", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	require.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, `{"a":{"y":[1],"z":true},"b":2}`, string(reqCtx.Response.Body()))
+	etag := string(reqCtx.Response.Header.Peek(HeaderETag))
+	require.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+
+	reqCtx = newProxiedRequest(MethodGet, "/products", "203.0.113.5", map[string]string{HeaderIfNoneMatch: etag})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusNotModified, reqCtx.Response.StatusCode())
+	assert.Empty(t, reqCtx.Response.Body())
+	assert.Equal(t, etag, string(reqCtx.Response.Header.Peek(HeaderETag)))
+
+	for _, tc := range []struct{ method, uri string }{
+		{MethodGet, "/plain"},
+		{MethodGet, "/api/created"},
+		{MethodPost, "/api/items"},
+	} {
+		reqCtx = newProxiedRequest(tc.method, tc.uri, "203.0.113.5", nil)
+		app.router.Handler(reqCtx)
+		assert.Empty(t, reqCtx.Response.Header.Peek(HeaderETag), tc.uri)
+		assert.Equal(t, `{"a":1}`, string(reqCtx.Response.Body()), tc.uri)
+	}
+
+	reqCtx = newProxiedRequest(MethodGet, "/api/versioned", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, `"v7"`, string(reqCtx.Response.Header.Peek(HeaderETag)))
+}
@@ -0,0 +1,281 @@
+package gonoleks
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// KVConfig defines the config for a KV store
+type KVConfig struct {
+	// Path is a file the store is loaded from and saved to, empty keeps the store in memory only
+	Path string
+
+	// PersistInterval is the period at which the store is saved to Path,
+	// zero saves it only on Close and Save
+	PersistInterval time.Duration
+
+	// SweepInterval is the period at which expired keys are removed
+	SweepInterval time.Duration // Default = 1 minute
+}
+
+// KV is a small in-process key-value store with per-key expiry, optionally persisted to disk,
+// so single-binary deployments can back stateful middleware without external services
+// It is safe for concurrent use
+//
+//	kv, err := gonoleks.NewKV(gonoleks.KVConfig{Path: "data/kv.gob", PersistInterval: time.Minute})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer kv.Close()
+type KV struct {
+	config    KVConfig
+	mu        sync.RWMutex
+	entries   map[string]kvEntry
+	saveMu    sync.Mutex
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// kvEntry is a stored value, exported fields are persisted
+type kvEntry struct {
+	Value []byte
+
+	// Expires is the expiry time in Unix nanoseconds, zero never expires
+	Expires int64
+}
+
+// expired reports whether the entry has expired at now
+func (e kvEntry) expired(now int64) bool {
+	return e.Expires != 0 && now >= e.Expires
+}
+
+// NewKV creates a KV store, loading it from config.Path when the file exists
+func NewKV(config ...KVConfig) (*KV, error) {
+	var conf KVConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if conf.SweepInterval <= 0 {
+		conf.SweepInterval = time.Minute
+	}
+	kv := &KV{config: conf, entries: make(map[string]kvEntry), done: make(chan struct{})}
+	if conf.Path != "" {
+		if err := kv.load(); err != nil {
+			return nil, err
+		}
+	}
+	kv.wg.Add(1)
+	go kv.run()
+	return kv, nil
+}
+
+// Get returns a copy of the value of key and whether it was found
+func (kv *KV) Get(key string) ([]byte, bool) {
+	kv.mu.RLock()
+	entry, ok := kv.entries[key]
+	kv.mu.RUnlock()
+	if !ok || entry.expired(time.Now().UnixNano()) {
+		return nil, false
+	}
+	return bytes.Clone(entry.Value), true
+}
+
+// Set stores a copy of value under key, a non-positive ttl never expires
+func (kv *KV) Set(key string, value []byte, ttl time.Duration) {
+	kv.mu.Lock()
+	kv.entries[key] = kvEntry{Value: bytes.Clone(value), Expires: expiresAt(ttl)}
+	kv.mu.Unlock()
+}
+
+// SetNX stores value under key unless it already holds an unexpired value,
+// and reports whether it was stored
+func (kv *KV) SetNX(key string, value []byte, ttl time.Duration) bool {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	if entry, ok := kv.entries[key]; ok && !entry.expired(time.Now().UnixNano()) {
+		return false
+	}
+	kv.entries[key] = kvEntry{Value: bytes.Clone(value), Expires: expiresAt(ttl)}
+	return true
+}
+
+// Delete removes key
+func (kv *KV) Delete(key string) {
+	kv.mu.Lock()
+	delete(kv.entries, key)
+	kv.mu.Unlock()
+}
+
+// Incr adds delta to the integer stored under key and returns the result
+// A missing or expired key starts from zero and expires after ttl, later increments keep its expiry,
+// which makes it suitable for fixed window counters
+// It fails with ErrKVNotInteger when the key holds a value that is not an integer
+func (kv *KV) Incr(key string, delta int64, ttl time.Duration) (int64, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	entry, ok := kv.entries[key]
+	var n int64
+	if ok && !entry.expired(time.Now().UnixNano()) {
+		var err error
+		if n, err = strconv.ParseInt(string(entry.Value), 10, 64); err != nil {
+			return 0, ErrKVNotInteger
+		}
+	} else {
+		entry = kvEntry{Expires: expiresAt(ttl)}
+	}
+	n += delta
+	entry.Value = strconv.AppendInt(entry.Value[:0:0], n, 10)
+	kv.entries[key] = entry
+	return n, nil
+}
+
+// TTL returns the time left before key expires, and whether the key exists
+// Keys without expiry report a zero duration
+func (kv *KV) TTL(key string) (time.Duration, bool) {
+	kv.mu.RLock()
+	entry, ok := kv.entries[key]
+	kv.mu.RUnlock()
+	now := time.Now().UnixNano()
+	if !ok || entry.expired(now) {
+		return 0, false
+	}
+	if entry.Expires == 0 {
+		return 0, true
+	}
+	return time.Duration(entry.Expires - now), true
+}
+
+// Len returns the number of keys, including expired keys not swept yet
+func (kv *KV) Len() int {
+	kv.mu.RLock()
+	defer kv.mu.RUnlock()
+	return len(kv.entries)
+}
+
+// Save writes the store to config.Path, replacing the previous file atomically
+func (kv *KV) Save() error {
+	if kv.config.Path == "" {
+		return nil
+	}
+	kv.saveMu.Lock()
+	defer kv.saveMu.Unlock()
+	var buf bytes.Buffer
+	now := time.Now().UnixNano()
+	kv.mu.RLock()
+	snapshot := make(map[string]kvEntry, len(kv.entries))
+	for key, entry := range kv.entries {
+		if !entry.expired(now) {
+			snapshot[key] = entry
+		}
+	}
+	err := gob.NewEncoder(&buf).Encode(snapshot)
+	kv.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(kv.config.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(kv.config.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), kv.config.Path)
+}
+
+// Close stops the background sweeps and saves the store to config.Path
+func (kv *KV) Close() error {
+	kv.closeOnce.Do(func() {
+		close(kv.done)
+		kv.wg.Wait()
+	})
+	return kv.Save()
+}
+
+// load reads the store from config.Path, a missing file leaves it empty
+func (kv *KV) load() error {
+	raw, err := os.ReadFile(kv.config.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&kv.entries); err != nil {
+		return err
+	}
+	if kv.entries == nil {
+		kv.entries = make(map[string]kvEntry)
+	}
+	kv.sweep()
+	return nil
+}
+
+// run sweeps expired keys and persists the store until Close
+func (kv *KV) run() {
+	defer kv.wg.Done()
+	sweep := time.NewTicker(kv.config.SweepInterval)
+	defer sweep.Stop()
+	var persist <-chan time.Time
+	if kv.config.Path != "" && kv.config.PersistInterval > 0 {
+		ticker := time.NewTicker(kv.config.PersistInterval)
+		defer ticker.Stop()
+		persist = ticker.C
+	}
+	for {
+		select {
+		case <-kv.done:
+			return
+		case <-sweep.C:
+			kv.sweep()
+		case <-persist:
+			if err := kv.Save(); err != nil {
+				defaultDiagnostics.Error("Failed to save KV store", "path", kv.config.Path, "error", err)
+			}
+		}
+	}
+}
+
+// sweep removes expired keys
+func (kv *KV) sweep() {
+	now := time.Now().UnixNano()
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	for key, entry := range kv.entries {
+		if entry.expired(now) {
+			delete(kv.entries, key)
+		}
+	}
+}
+
+// NonceStore returns a NonceStore keeping used nonces in the store, so they survive restarts
+// when the store is persisted
+//
+//	app.Use(gonoleks.NonceWithConfig(gonoleks.NonceConfig{Store: kv.NonceStore()}))
+func (kv *KV) NonceStore() NonceStore {
+	return StoreNonceStore(kv.Store())
+}
+
+// expiresAt returns the expiry time of a ttl in Unix nanoseconds, zero for non-positive ttls
+func expiresAt(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return time.Now().Add(ttl).UnixNano()
+}
@@ -0,0 +1,127 @@
+package gonoleks
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKV(t *testing.T) {
+	kv, err := NewKV()
+	require.NoError(t, err)
+	defer kv.Close()
+
+	value := []byte("v1")
+	kv.Set("a", value, 0)
+	value[0] = 'x'
+	got, ok := kv.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, "v1", string(got), "values are copied")
+
+	assert.False(t, kv.SetNX("a", []byte("v2"), 0))
+	assert.True(t, kv.SetNX("b", []byte("v2"), 20*time.Millisecond))
+	ttl, ok := kv.TTL("b")
+	assert.True(t, ok)
+	assert.Positive(t, ttl)
+	ttl, ok = kv.TTL("a")
+	assert.True(t, ok)
+	assert.Zero(t, ttl)
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = kv.Get("b")
+	assert.False(t, ok, "expired keys are not returned")
+	assert.True(t, kv.SetNX("b", []byte("v3"), 0), "expired keys can be set again")
+
+	kv.Delete("a")
+	_, ok = kv.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, kv.Len())
+}
+
+func TestKVIncr(t *testing.T) {
+	kv, err := NewKV()
+	require.NoError(t, err)
+	defer kv.Close()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Go(func() {
+			_, err := kv.Incr("hits", 1, time.Minute)
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+	n, err := kv.Incr("hits", 10, time.Minute)
+	require.NoError(t, err)
+	assert.EqualValues(t, 60, n)
+
+	kv.Set("name", []byte("gopher"), 0)
+	_, err = kv.Incr("name", 1, 0)
+	assert.ErrorIs(t, err, ErrKVNotInteger)
+
+	// Increments keep the expiry of the window
+	_, err = kv.Incr("window", 1, 20*time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	_, err = kv.Incr("window", 1, time.Hour)
+	require.NoError(t, err)
+	time.Sleep(15 * time.Millisecond)
+	n, err = kv.Incr("window", 1, time.Hour)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+}
+
+func TestKVPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "kv.gob")
+	kv, err := NewKV(KVConfig{Path: path})
+	require.NoError(t, err)
+	kv.Set("keep", []byte("forever"), 0)
+	kv.Set("session", []byte("abc"), time.Hour)
+	kv.Set("gone", []byte("soon"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, kv.Close())
+
+	kv, err = NewKV(KVConfig{Path: path, PersistInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	got, ok := kv.Get("keep")
+	assert.True(t, ok)
+	assert.Equal(t, "forever", string(got))
+	ttl, ok := kv.TTL("session")
+	assert.True(t, ok)
+	assert.Greater(t, ttl, 59*time.Minute)
+	_, ok = kv.Get("gone")
+	assert.False(t, ok)
+
+	// Periodic saves
+	kv.Set("later", []byte("1"), 0)
+	assert.Eventually(t, func() bool {
+		other, err := NewKV(KVConfig{Path: path})
+		if err != nil {
+			return false
+		}
+		defer other.Close()
+		_, ok := other.Get("later")
+		return ok
+	}, time.Second, 20*time.Millisecond)
+	require.NoError(t, kv.Close())
+}
+
+func TestKVSweepAndNonceStore(t *testing.T) {
+	kv, err := NewKV(KVConfig{SweepInterval: 5 * time.Millisecond})
+	require.NoError(t, err)
+	defer kv.Close()
+	kv.Set("a", nil, time.Millisecond)
+	assert.Eventually(t, func() bool { return kv.Len() == 0 }, time.Second, 5*time.Millisecond)
+
+	store := kv.NonceStore()
+	ok, err := store.Use("n1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = store.Use("n1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,284 @@
+package gonoleks
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FilterOp is a comparison operator of a filter query param
+type FilterOp string
+
+// Filter operators, given as filter[field][op]=value, a missing op means FilterEq
+const (
+	FilterEq  FilterOp = "eq"
+	FilterNe  FilterOp = "ne"
+	FilterGt  FilterOp = "gt"
+	FilterGte FilterOp = "gte"
+	FilterLt  FilterOp = "lt"
+	FilterLte FilterOp = "lte"
+	FilterIn  FilterOp = "in"
+)
+
+// filterSQL maps the filter operators to SQL
+var filterSQL = map[FilterOp]string{
+	FilterEq:  "=",
+	FilterNe:  "<>",
+	FilterGt:  ">",
+	FilterGte: ">=",
+	FilterLt:  "<",
+	FilterLte: "<=",
+	FilterIn:  "IN",
+}
+
+// ListQueryConfig defines the allow-listed fields of ParseListQuery
+type ListQueryConfig struct {
+	// SortParam is the query param of the sort fields
+	SortParam string // Default = "sort"
+
+	// FilterParam is the prefix of the filter query params
+	FilterParam string // Default = "filter"
+
+	// Sortable lists the fields the client may sort by
+	Sortable []string
+
+	// Filterable lists the fields the client may filter by with their allowed operators,
+	// an empty list allows only FilterEq
+	Filterable map[string][]FilterOp
+
+	// Columns maps field names to database columns, fields not listed keep their name
+	Columns map[string]string
+
+	// DefaultSort applies when the request does not sort, in the syntax of the sort param
+	DefaultSort string
+
+	// MaxSort caps the number of sort fields
+	MaxSort int // Default = 3
+}
+
+// SortField is a field of the sort query param
+type SortField struct {
+	// Field is the name given by the client
+	Field string
+
+	// Column is the database column of the field
+	Column string
+
+	// Desc reports whether the field sorts in descending order
+	Desc bool
+}
+
+// Filter is a filter query param
+type Filter struct {
+	// Field is the name given by the client
+	Field string
+
+	// Column is the database column of the field
+	Column string
+
+	// Op is the comparison operator
+	Op FilterOp
+
+	// Values holds the compared value, or the comma separated values of FilterIn
+	Values []string
+}
+
+// Value returns the first value of the filter
+func (f Filter) Value() string {
+	if len(f.Values) == 0 {
+		return ""
+	}
+	return f.Values[0]
+}
+
+// ListQuery is the sorting and filtering requested by the query params of a list endpoint
+// Fields and columns come from the allow list of the config, never from the raw request,
+// so they are safe to use in queries
+type ListQuery struct {
+	Sort    []SortField
+	Filters []Filter
+}
+
+// ParseListQuery parses the sort and filter query params of the request against the allow list
+// of config, e.g. ?sort=-created_at,name&filter[status]=active&filter[age][gte]=18
+// It fails with ErrInvalidListQuery for fields or operators that are not allowed
+//
+//	q, err := gonoleks.ParseListQuery(c, gonoleks.ListQueryConfig{
+//	    Sortable:   []string{"created_at", "name"},
+//	    Filterable: map[string][]gonoleks.FilterOp{"status": nil, "age": {gonoleks.FilterGte, gonoleks.FilterLte}},
+//	})
+//	if err != nil {
+//	    _ = c.AbortWithError(gonoleks.StatusBadRequest, err)
+//	    return
+//	}
+//	where, args := q.Where()
+//	rows, err := db.Query("SELECT * FROM users"+where+q.OrderBy(), args...)
+func ParseListQuery(c *Context, config ListQueryConfig) (*ListQuery, error) {
+	if config.SortParam == "" {
+		config.SortParam = "sort"
+	}
+	if config.FilterParam == "" {
+		config.FilterParam = "filter"
+	}
+	if config.MaxSort <= 0 {
+		config.MaxSort = 3
+	}
+	q := &ListQuery{}
+	sort := c.Query(config.SortParam)
+	if sort == "" {
+		sort = config.DefaultSort
+	}
+	if err := q.parseSort(sort, config); err != nil {
+		return nil, err
+	}
+	for key, values := range c.QueryValues() {
+		rest, ok := strings.CutPrefix(key, config.FilterParam+"[")
+		if !ok || len(values) == 0 {
+			continue
+		}
+		if err := q.parseFilter(rest, values[len(values)-1], config); err != nil {
+			return nil, err
+		}
+	}
+	slices.SortFunc(q.Filters, func(a, b Filter) int {
+		return cmp.Or(strings.Compare(a.Field, b.Field), strings.Compare(string(a.Op), string(b.Op)))
+	})
+	return q, nil
+}
+
+// parseSort parses the comma separated sort fields, prefixed with - for descending order
+func (q *ListQuery) parseSort(sort string, config ListQueryConfig) error {
+	if sort == "" {
+		return nil
+	}
+	for field := range strings.SplitSeq(sort, ",") {
+		field = strings.TrimSpace(field)
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimLeft(field, "+-")
+		if !slices.Contains(config.Sortable, field) {
+			return fmt.Errorf("%w: cannot sort by %q", ErrInvalidListQuery, field)
+		}
+		if slices.ContainsFunc(q.Sort, func(s SortField) bool { return s.Field == field }) {
+			return fmt.Errorf("%w: duplicate sort field %q", ErrInvalidListQuery, field)
+		}
+		if len(q.Sort) == config.MaxSort {
+			return fmt.Errorf("%w: at most %d sort fields", ErrInvalidListQuery, config.MaxSort)
+		}
+		q.Sort = append(q.Sort, SortField{Field: field, Column: listQueryColumn(field, config), Desc: desc})
+	}
+	return nil
+}
+
+// parseFilter parses a filter param, rest is the key after the filter prefix and bracket,
+// e.g. "status]" or "age][gte]"
+func (q *ListQuery) parseFilter(rest, value string, config ListQueryConfig) error {
+	field, rest, ok := strings.Cut(rest, "]")
+	op := FilterEq
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: malformed filter %q", ErrInvalidListQuery, config.FilterParam+"["+field)
+	case rest != "":
+		name, hasPrefix := strings.CutPrefix(rest, "[")
+		name, hasSuffix := strings.CutSuffix(name, "]")
+		if !hasPrefix || !hasSuffix {
+			return fmt.Errorf("%w: malformed filter on %q", ErrInvalidListQuery, field)
+		}
+		op = FilterOp(name)
+	}
+	allowed, ok := config.Filterable[field]
+	if !ok {
+		return fmt.Errorf("%w: cannot filter by %q", ErrInvalidListQuery, field)
+	}
+	if len(allowed) == 0 {
+		allowed = []FilterOp{FilterEq}
+	}
+	if !slices.Contains(allowed, op) {
+		return fmt.Errorf("%w: operator %q is not allowed on %q", ErrInvalidListQuery, op, field)
+	}
+	values := []string{value}
+	if op == FilterIn {
+		values = strings.Split(value, ",")
+	}
+	q.Filters = append(q.Filters, Filter{Field: field, Column: listQueryColumn(field, config), Op: op, Values: values})
+	return nil
+}
+
+// listQueryColumn returns the database column of field
+func listQueryColumn(field string, config ListQueryConfig) string {
+	if column, ok := config.Columns[field]; ok {
+		return column
+	}
+	return field
+}
+
+// Filter returns the first filter on field
+func (q *ListQuery) Filter(field string) (Filter, bool) {
+	for _, filter := range q.Filters {
+		if filter.Field == field {
+			return filter, true
+		}
+	}
+	return Filter{}, false
+}
+
+// OrderBy returns an SQL ORDER BY clause with a leading space, or an empty string without sort fields
+//
+//	" ORDER BY created_at DESC, name ASC"
+func (q *ListQuery) OrderBy() string {
+	if len(q.Sort) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(" ORDER BY ")
+	for i, field := range q.Sort {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(field.Column)
+		if field.Desc {
+			b.WriteString(" DESC")
+		} else {
+			b.WriteString(" ASC")
+		}
+	}
+	return b.String()
+}
+
+// Where returns an SQL WHERE clause with a leading space and its args, or an empty string without filters
+// Values are passed as args, never inlined, with "?" placeholders unless placeholder is given,
+// e.g. for PostgreSQL:
+//
+//	where, args := q.Where(func(n int) string { return "$" + strconv.Itoa(n) })
+func (q *ListQuery) Where(placeholder ...func(n int) string) (string, []any) {
+	if len(q.Filters) == 0 {
+		return "", nil
+	}
+	next := func(int) string { return "?" }
+	if len(placeholder) > 0 {
+		next = placeholder[0]
+	}
+	var b strings.Builder
+	var args []any
+	b.WriteString(" WHERE ")
+	for i, filter := range q.Filters {
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+		b.WriteString(filter.Column + " " + filterSQL[filter.Op] + " ")
+		if filter.Op == FilterIn {
+			b.WriteByte('(')
+		}
+		for j, value := range filter.Values {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			args = append(args, value)
+			b.WriteString(next(len(args)))
+		}
+		if filter.Op == FilterIn {
+			b.WriteByte(')')
+		}
+	}
+	return b.String(), args
+}
@@ -0,0 +1,77 @@
+package gonoleks
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testListQueryConfig = ListQueryConfig{
+	Sortable: []string{"created_at", "name"},
+	Filterable: map[string][]FilterOp{
+		"status": nil,
+		"age":    {FilterGte, FilterLte},
+		"role":   {FilterEq, FilterIn},
+	},
+	Columns:     map[string]string{"created_at": "users.created_at"},
+	DefaultSort: "-created_at",
+}
+
+func TestParseListQuery(t *testing.T) {
+	c := paginationContext("/users?sort=-created_at,+name&filter[status]=active&filter[age][gte]=18&filter[role][in]=admin,ops")
+	q, err := ParseListQuery(c, testListQueryConfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, []SortField{
+		{Field: "created_at", Column: "users.created_at", Desc: true},
+		{Field: "name", Column: "name"},
+	}, q.Sort)
+	assert.Equal(t, []Filter{
+		{Field: "age", Column: "age", Op: FilterGte, Values: []string{"18"}},
+		{Field: "role", Column: "role", Op: FilterIn, Values: []string{"admin", "ops"}},
+		{Field: "status", Column: "status", Op: FilterEq, Values: []string{"active"}},
+	}, q.Filters)
+	status, ok := q.Filter("status")
+	assert.True(t, ok)
+	assert.Equal(t, "active", status.Value())
+
+	assert.Equal(t, " ORDER BY users.created_at DESC, name ASC", q.OrderBy())
+	where, args := q.Where()
+	assert.Equal(t, " WHERE age >= ? AND role IN (?, ?) AND status = ?", where)
+	assert.Equal(t, []any{"18", "admin", "ops", "active"}, args)
+	where, _ = q.Where(func(n int) string { return "$" + strconv.Itoa(n) })
+	assert.Equal(t, " WHERE age >= $1 AND role IN ($2, $3) AND status = $4", where)
+}
+
+func TestParseListQueryDefaults(t *testing.T) {
+	q, err := ParseListQuery(paginationContext("/users"), testListQueryConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []SortField{{Field: "created_at", Column: "users.created_at", Desc: true}}, q.Sort)
+	where, args := q.Where()
+	assert.Empty(t, where)
+	assert.Nil(t, args)
+
+	q, err = ParseListQuery(paginationContext("/users"), ListQueryConfig{})
+	require.NoError(t, err)
+	assert.Empty(t, q.OrderBy())
+}
+
+func TestParseListQueryRejects(t *testing.T) {
+	for _, uri := range []string{
+		"/users?sort=password",
+		"/users?sort=name;DROP%20TABLE%20users",
+		"/users?sort=name,-name",
+		"/users?filter[password]=x",
+		"/users?filter[age][gt]=18",
+		"/users?filter[status][ne]=active",
+		"/users?filter[status",
+		"/users?filter[age]gte]=1",
+	} {
+		_, err := ParseListQuery(paginationContext(uri), testListQueryConfig)
+		assert.ErrorIs(t, err, ErrInvalidListQuery, uri)
+	}
+	_, err := ParseListQuery(paginationContext("/users?sort=a,b"), ListQueryConfig{Sortable: []string{"a", "b"}, MaxSort: 1})
+	assert.ErrorIs(t, err, ErrInvalidListQuery)
+}
@@ -0,0 +1,207 @@
+package gonoleks
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// User value keys under which the resolved locale and time zone are stored
+const (
+	localeKey   = "gonoleksLocale"
+	locationKey = "gonoleksLocation"
+)
+
+// LocaleResolver returns the supported locale a request asks for, or "" if it expresses no usable preference
+type LocaleResolver func(c *Context, supported []string) string
+
+// TimezoneResolver returns the IANA time zone name a request asks for, e.g. "Europe/Berlin", or ""
+type TimezoneResolver func(c *Context) string
+
+// LocaleConfig defines the config for LocaleWithConfig
+type LocaleConfig struct {
+	// Supported lists the locales the application offers, e.g. "en", "de" or "pt-BR"
+	Supported []string
+
+	// Default is used when no resolver yields a supported locale
+	Default string // Default = Supported[0]
+
+	// Resolvers are tried in order until one yields a supported locale
+	// Default = LocaleFromQuery("lang"), LocaleFromCookie("locale"), LocaleFromAcceptLanguage()
+	Resolvers []LocaleResolver
+
+	// TimezoneResolvers are tried in order until one yields a known time zone
+	// Default = TimezoneFromHeader("X-Timezone"), TimezoneFromCookie("tz")
+	TimezoneResolvers []TimezoneResolver
+
+	// DefaultLocation is used when no time zone resolver succeeds
+	DefaultLocation *time.Location // Default = time.UTC
+}
+
+// Locale instances a middleware that resolves the locale and time zone of each request
+// from the query string, cookies and headers, see LocaleWithConfig
+func Locale(supported ...string) handlerFunc {
+	return LocaleWithConfig(LocaleConfig{Supported: supported})
+}
+
+// LocaleWithConfig instances a locale and time zone resolution middleware with config
+// The results are available through Context.Locale and Context.Location,
+// and are used by the form, query and header bindings to parse times
+//
+//	app.Use(gonoleks.LocaleWithConfig(gonoleks.LocaleConfig{
+//		Supported: []string{"en", "de"},
+//		Resolvers: []gonoleks.LocaleResolver{
+//			gonoleks.LocaleFromCookie("locale"),
+//			gonoleks.LocaleFromAcceptLanguage(),
+//		},
+//	}))
+func LocaleWithConfig(conf LocaleConfig) handlerFunc {
+	if conf.Default == "" && len(conf.Supported) > 0 {
+		conf.Default = conf.Supported[0]
+	}
+	if conf.Resolvers == nil {
+		conf.Resolvers = []LocaleResolver{LocaleFromQuery("lang"), LocaleFromCookie("locale"), LocaleFromAcceptLanguage()}
+	}
+	if conf.TimezoneResolvers == nil {
+		conf.TimezoneResolvers = []TimezoneResolver{TimezoneFromHeader(HeaderXTimezone), TimezoneFromCookie("tz")}
+	}
+	if conf.DefaultLocation == nil {
+		conf.DefaultLocation = time.UTC
+	}
+	return func(c *Context) {
+		locale := conf.Default
+		for _, resolve := range conf.Resolvers {
+			if l := resolve(c, conf.Supported); l != "" {
+				locale = l
+				break
+			}
+		}
+		location := conf.DefaultLocation
+		for _, resolve := range conf.TimezoneResolvers {
+			if l, ok := loadLocation(resolve(c)); ok {
+				location = l
+				break
+			}
+		}
+		c.SetLocale(locale)
+		c.SetLocation(location)
+		c.Next()
+	}
+}
+
+// LocaleFromQuery resolves the locale from a query parameter, e.g. ?lang=de
+func LocaleFromQuery(key string) LocaleResolver {
+	return func(c *Context, supported []string) string {
+		return supportedLocale(c.Query(key), supported)
+	}
+}
+
+// LocaleFromCookie resolves the locale from a cookie, e.g. one set by a language switcher
+func LocaleFromCookie(name string) LocaleResolver {
+	return func(c *Context, supported []string) string {
+		value, err := c.Cookie(name)
+		if err != nil {
+			return ""
+		}
+		return supportedLocale(value, supported)
+	}
+}
+
+// LocaleFromAcceptLanguage resolves the supported locale best matching the Accept-Language header
+func LocaleFromAcceptLanguage() LocaleResolver {
+	return func(c *Context, supported []string) string {
+		if c.GetHeader(HeaderAcceptLanguage) == "" {
+			return ""
+		}
+		return c.AcceptsLanguages(supported...)
+	}
+}
+
+// TimezoneFromHeader resolves the time zone from a request header
+func TimezoneFromHeader(name string) TimezoneResolver {
+	return func(c *Context) string {
+		return c.GetHeader(name)
+	}
+}
+
+// TimezoneFromCookie resolves the time zone from a cookie
+func TimezoneFromCookie(name string) TimezoneResolver {
+	return func(c *Context) string {
+		value, _ := c.Cookie(name)
+		return value
+	}
+}
+
+// Locale returns the locale of the request
+// It is resolved by the Locale middleware or set with SetLocale, and otherwise
+// falls back to the most preferred language of the Accept-Language header
+func (c *Context) Locale() string {
+	if locale, ok := c.requestCtx.UserValue(localeKey).(string); ok {
+		return locale
+	}
+	for _, language := range parseAcceptHeader(c.GetHeader(HeaderAcceptLanguage)) {
+		if language != "*" {
+			return language
+		}
+	}
+	return ""
+}
+
+// SetLocale overrides the locale of the request, e.g. with a preference from the user profile
+func (c *Context) SetLocale(locale string) {
+	c.requestCtx.SetUserValue(localeKey, locale)
+}
+
+// Location returns the time zone of the request
+// It is resolved by the Locale middleware or set with SetLocation, and defaults to UTC
+func (c *Context) Location() *time.Location {
+	if location, ok := c.requestCtx.UserValue(locationKey).(*time.Location); ok {
+		return location
+	}
+	return time.UTC
+}
+
+// SetLocation overrides the time zone of the request, e.g. with a preference from the user profile
+func (c *Context) SetLocation(location *time.Location) {
+	if location == nil {
+		location = time.UTC
+	}
+	c.requestCtx.SetUserValue(locationKey, location)
+}
+
+// supportedLocale returns the supported locale matching value, or "" if there is none
+// Any locale is accepted when supported is empty
+func supportedLocale(value string, supported []string) string {
+	if value == "" || len(supported) == 0 {
+		return value
+	}
+	for _, locale := range supported {
+		if matchLanguage(value, locale) {
+			return locale
+		}
+	}
+	return ""
+}
+
+// locationCache caches loaded time zones, as time.LoadLocation reads the zone database on every call
+var locationCache sync.Map
+
+// loadLocation loads an IANA time zone by name
+func loadLocation(name string) (*time.Location, bool) {
+	if name == "" {
+		return nil, false
+	}
+	if cached, ok := locationCache.Load(name); ok {
+		location, ok := cached.(*time.Location)
+		return location, ok
+	}
+	// The name may alias a request buffer that is reused once the request is served,
+	// and both the cache key and the location keep it
+	name = strings.Clone(name)
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	locationCache.Store(name, location)
+	return location, true
+}
@@ -0,0 +1,105 @@
+package gonoleks
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocaleMiddleware(t *testing.T) {
+	app := New()
+	app.Use(Locale("en", "de", "pt-BR"))
+	var locale, zone string
+	app.GET("/page", func(c *Context) {
+		locale = c.Locale()
+		zone = c.Location().String()
+	})
+	app.setupRouter()
+
+	tests := []struct {
+		name     string
+		uri      string
+		headers  map[string]string
+		locale   string
+		location string
+	}{
+		{"Default", "/page", nil, "en", "UTC"},
+		{"Accept-Language", "/page", map[string]string{HeaderAcceptLanguage: "fr, de-AT;q=0.8"}, "de", "UTC"},
+		{"Cookie beats header", "/page", map[string]string{HeaderCookie: "locale=pt-BR", HeaderAcceptLanguage: "de"}, "pt-BR", "UTC"},
+		{"Query beats cookie", "/page?lang=de", map[string]string{HeaderCookie: "locale=en"}, "de", "UTC"},
+		{"Unsupported query", "/page?lang=fr", map[string]string{HeaderAcceptLanguage: "de"}, "de", "UTC"},
+		{"Timezone header", "/page", map[string]string{HeaderXTimezone: "Europe/Berlin"}, "en", "Europe/Berlin"},
+		{"Timezone cookie", "/page", map[string]string{HeaderCookie: "tz=Asia/Tokyo"}, "en", "Asia/Tokyo"},
+		{"Unknown timezone", "/page", map[string]string{HeaderXTimezone: "Mars/Olympus"}, "en", "UTC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqCtx := newProxiedRequest(MethodGet, tt.uri, "203.0.113.5", tt.headers)
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.locale, locale)
+			assert.Equal(t, tt.location, zone)
+		})
+	}
+}
+
+func TestLocaleKeepAlive(t *testing.T) {
+	app := New()
+	app.Use(Locale("en"))
+	var zone string
+	app.GET("/page", func(c *Context) {
+		zone = c.Location().String()
+	})
+	client := keepAliveClient(t, app)
+
+	names := []string{"Australia/Perth", "America/Phoenix"}
+	for _, name := range append(names, names...) {
+		assert.Equal(t, StatusOK, keepAliveRequest(t, client, MethodGet, "/page", map[string]string{HeaderXTimezone: name}))
+		assert.Equal(t, name, zone)
+	}
+
+	// Cached names must not change once the connection reuses its request buffer
+	var cached []string
+	locationCache.Range(func(key, value any) bool {
+		if slices.Contains(names, key.(string)) {
+			cached = append(cached, key.(string))
+			assert.Equal(t, key, value.(*time.Location).String())
+		}
+		return true
+	})
+	assert.ElementsMatch(t, names, cached)
+}
+
+func TestContextLocaleDefaults(t *testing.T) {
+	c, requestCtx := createTestContext()
+	assert.Empty(t, c.Locale())
+	assert.Equal(t, time.UTC, c.Location())
+
+	requestCtx.Request.Header.Set(HeaderAcceptLanguage, "*, nl;q=0.9")
+	assert.Equal(t, "nl", c.Locale())
+
+	c.SetLocale("fr")
+	assert.Equal(t, "fr", c.Locale())
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	c.SetLocation(tokyo)
+	assert.Equal(t, tokyo, c.Location())
+}
+
+func TestBindingUsesLocation(t *testing.T) {
+	c, requestCtx := createTestContext()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	c.SetLocation(berlin)
+	requestCtx.Request.SetRequestURI("/events?start=2024-07-01T09:00")
+
+	var query struct {
+		Start time.Time `form:"start" time_format:"2006-01-02T15:04"`
+	}
+	require.NoError(t, c.ShouldBindQuery(&query))
+	assert.Equal(t, berlin, query.Start.Location())
+	assert.Equal(t, 7, query.Start.UTC().Hour())
+}
@@ -0,0 +1,173 @@
+package gonoleks
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// Preset access log formats for LoggerConfig.Format
+const (
+	// LogFormatCommon is the NCSA Common Log Format
+	LogFormatCommon = "common"
+
+	// LogFormatCombined is the Apache Combined Log Format, adding the referer and user agent to LogFormatCommon
+	LogFormatCombined = "combined"
+
+	// LogFormatJSON writes one JSON object per request
+	LogFormatJSON = "json"
+)
+
+// clfTimeFormat is the timestamp layout of the Common Log Format
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// CommonLogFormatter formats requests in the NCSA Common Log Format, e.g.
+//
+//	203.0.113.5 - alice [10/Oct/2026:13:55:36 +0000] "GET /users?page=2 HTTP/1.1" 200 2326
+var CommonLogFormatter LogFormatter = func(param LogFormatterParams) string {
+	var b strings.Builder
+	writeCommonLog(&b, param)
+	return b.String()
+}
+
+// CombinedLogFormatter formats requests in the Apache Combined Log Format, e.g.
+//
+//	203.0.113.5 - - [10/Oct/2026:13:55:36 +0000] "GET / HTTP/1.1" 200 2326 "https://example.com/" "curl/8.5.0"
+var CombinedLogFormatter LogFormatter = func(param LogFormatterParams) string {
+	var b strings.Builder
+	writeCommonLog(&b, param)
+	var referer, userAgent string
+	if param.Request != nil {
+		referer = string(param.Request.Header.Referer())
+		userAgent = string(param.Request.Header.UserAgent())
+	}
+	b.WriteString(" ")
+	b.WriteString(clfQuote(referer))
+	b.WriteString(" ")
+	b.WriteString(clfQuote(userAgent))
+	return b.String()
+}
+
+// jsonLogEntry is the object written by JSONLogFormatter
+type jsonLogEntry struct {
+	Time      string  `json:"time"`
+	Status    int     `json:"status"`
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	Route     string  `json:"route,omitempty"`
+	ClientIP  string  `json:"client_ip"`
+	User      string  `json:"user,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+	QueueMs   float64 `json:"queue_ms,omitempty"`
+	BytesIn   int     `json:"bytes_in"`
+	BytesOut  int     `json:"bytes_out"`
+	RequestID string  `json:"request_id,omitempty"`
+	TraceID   string  `json:"trace_id,omitempty"`
+	Referer   string  `json:"referer,omitempty"`
+	UserAgent string  `json:"user_agent,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// JSONLogFormatter formats requests as single line JSON objects, e.g.
+//
+//	{"time":"2026-10-10T13:55:36Z","status":200,"method":"GET","path":"/users/7","route":"/users/:id",...}
+var JSONLogFormatter LogFormatter = func(param LogFormatterParams) string {
+	entry := jsonLogEntry{
+		Time:      param.TimeStamp.UTC().Format(time.RFC3339Nano),
+		Status:    param.StatusCode,
+		Method:    param.Method,
+		Path:      param.Path,
+		Route:     param.Route,
+		ClientIP:  param.ClientIP,
+		User:      param.User,
+		LatencyMs: float64(param.Latency) / float64(time.Millisecond),
+		QueueMs:   float64(param.QueueTime) / float64(time.Millisecond),
+		BytesIn:   param.BytesRead,
+		BytesOut:  param.BodySize,
+		RequestID: param.RequestID,
+		TraceID:   param.TraceID,
+		Error:     param.ErrorMessage,
+	}
+	if param.Request != nil {
+		entry.Referer = string(param.Request.Header.Referer())
+		entry.UserAgent = string(param.Request.Header.UserAgent())
+	}
+	out, err := sonic.Marshal(entry)
+	if err != nil {
+		return `{"error":` + strconv.Quote(err.Error()) + `}`
+	}
+	return string(out)
+}
+
+// presetLogFormatter returns the formatter of a LoggerConfig.Format value,
+// falling back to DefaultLogFormatter
+func presetLogFormatter(format string) LogFormatter {
+	switch strings.ToLower(format) {
+	case LogFormatCommon, "clf":
+		return CommonLogFormatter
+	case LogFormatCombined:
+		return CombinedLogFormatter
+	case LogFormatJSON:
+		return JSONLogFormatter
+	default:
+		return DefaultLogFormatter
+	}
+}
+
+// writeCommonLog writes the Common Log Format fields of a request
+func writeCommonLog(b *strings.Builder, param LogFormatterParams) {
+	b.WriteString(clfField(param.ClientIP))
+	b.WriteString(" - ")
+	b.WriteString(clfField(param.User))
+	b.WriteString(" [")
+	b.WriteString(param.TimeStamp.Format(clfTimeFormat))
+	b.WriteString("] ")
+	requestLine := param.Method + " " + param.Path
+	if param.Request != nil {
+		requestLine = param.Method + " " + string(param.Request.RequestURI()) + " " + string(param.Request.Header.Protocol())
+	}
+	b.WriteString(clfQuote(requestLine))
+	b.WriteString(" ")
+	b.WriteString(strconv.Itoa(param.StatusCode))
+	b.WriteString(" ")
+	if param.BodySize > 0 {
+		b.WriteString(strconv.Itoa(param.BodySize))
+	} else {
+		b.WriteString("-")
+	}
+}
+
+// clfField returns value, or "-" when it is empty, with spaces escaped
+func clfField(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(value, " ", "%20")
+}
+
+// clfQuote quotes value for the Common Log Format, escaping quotes, backslashes and control characters
+func clfQuote(value string) string {
+	if value == "" {
+		return `"-"`
+	}
+	var b strings.Builder
+	b.Grow(len(value) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		switch ch := value[i]; {
+		case ch == '"' || ch == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(ch)
+		case ch < 0x20 || ch == 0x7f:
+			b.WriteString(`\x`)
+			b.WriteString(strconv.FormatUint(uint64(ch)>>4, 16))
+			b.WriteString(strconv.FormatUint(uint64(ch)&0xf, 16))
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
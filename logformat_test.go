@@ -0,0 +1,76 @@
+package gonoleks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func testLogParams() LogFormatterParams {
+	req := &fasthttp.Request{}
+	req.Header.SetMethod(MethodGet)
+	req.SetRequestURI("/users?page=2")
+	req.Header.SetReferer("https://example.com/")
+	req.Header.SetUserAgent(`curl/8.5.0 "quoted"`)
+	return LogFormatterParams{
+		TimeStamp:  time.Date(2026, 10, 10, 13, 55, 36, 0, time.UTC),
+		Request:    req,
+		ClientIP:   "203.0.113.5",
+		Method:     MethodGet,
+		Path:       "/users",
+		StatusCode: StatusOK,
+		Latency:    1500 * time.Microsecond,
+		BodySize:   2326,
+		Route:      "/users",
+		RequestID:  "req-1",
+		User:       "alice",
+	}
+}
+
+func TestCommonLogFormatter(t *testing.T) {
+	assert.Equal(t,
+		`203.0.113.5 - alice [10/Oct/2026:13:55:36 +0000] "GET /users?page=2 HTTP/1.1" 200 2326`,
+		CommonLogFormatter(testLogParams()))
+
+	param := testLogParams()
+	param.User, param.BodySize = "", 0
+	assert.Equal(t,
+		`203.0.113.5 - - [10/Oct/2026:13:55:36 +0000] "GET /users?page=2 HTTP/1.1" 200 -`,
+		CommonLogFormatter(param))
+}
+
+func TestCombinedLogFormatter(t *testing.T) {
+	assert.Equal(t,
+		`203.0.113.5 - alice [10/Oct/2026:13:55:36 +0000] "GET /users?page=2 HTTP/1.1" 200 2326 "https://example.com/" "curl/8.5.0 \"quoted\""`,
+		CombinedLogFormatter(testLogParams()))
+}
+
+func TestJSONLogFormatter(t *testing.T) {
+	assert.JSONEq(t, `{
+		"time": "2026-10-10T13:55:36Z",
+		"status": 200,
+		"method": "GET",
+		"path": "/users",
+		"route": "/users",
+		"client_ip": "203.0.113.5",
+		"user": "alice",
+		"latency_ms": 1.5,
+		"bytes_in": 0,
+		"bytes_out": 2326,
+		"request_id": "req-1",
+		"referer": "https://example.com/",
+		"user_agent": "curl/8.5.0 \"quoted\""
+	}`, JSONLogFormatter(testLogParams()))
+}
+
+func TestPresetLogFormatter(t *testing.T) {
+	params := testLogParams()
+	assert.Equal(t, CommonLogFormatter(params), presetLogFormatter("clf")(params))
+	assert.Equal(t, CommonLogFormatter(params), presetLogFormatter(LogFormatCommon)(params))
+	assert.Equal(t, CombinedLogFormatter(params), presetLogFormatter(LogFormatCombined)(params))
+	assert.Equal(t, JSONLogFormatter(params), presetLogFormatter("JSON")(params))
+	assert.Equal(t, DefaultLogFormatter(params), presetLogFormatter("")(params))
+	assert.Equal(t, `"a\x0ab"`, clfQuote("a\nb"))
+}
@@ -3,6 +3,8 @@ package gonoleks
 import (
 	"fmt"
 	"io"
+	"net/http"
+	"strings"
 	"time"
 
 	"charm.land/lipgloss/v2"
@@ -38,10 +40,35 @@ type LogFormatterParams struct {
 	StatusCode int
 
 	// Latency is how much time the server cost to process a certain request (auto-styled with faint)
+	// It is measured from the Logger middleware, see TotalLatency for the time since the request arrived
 	Latency time.Duration
 
 	// BodySize is the size of the Response Body
 	BodySize int
+
+	// Route is the pattern of the matched route, e.g. "/users/:id", empty when no route matched
+	Route string
+
+	// RequestID is the X-Request-ID of the request or response
+	RequestID string
+
+	// TraceID is the trace id of the W3C traceparent request header
+	TraceID string
+
+	// BytesRead is the size of the request body
+	BytesRead int
+
+	// TotalLatency is the time since the server received the request, i.e. QueueTime plus Latency
+	TotalLatency time.Duration
+
+	// QueueTime is the time between the server receiving the request and the Logger middleware running
+	QueueTime time.Duration
+
+	// InFlight is the number of requests being served when the request completed, including it
+	InFlight int
+
+	// User is the ID of the authenticated principal, empty for anonymous requests
+	User string
 }
 
 // LoggerConfig defines the config for Logger middleware
@@ -49,6 +76,10 @@ type LoggerConfig struct {
 	// Formatter is the log format function
 	Formatter LogFormatter // Default = DefaultLogFormatter
 
+	// Format selects a preset formatter when Formatter is nil:
+	// LogFormatCommon, LogFormatCombined or LogFormatJSON
+	Format string
+
 	// Output is a writer where logs are written
 	Output io.Writer // Default = os.Stdout
 
@@ -94,6 +125,7 @@ func DisableConsoleColor() {
 	profile := colorprofile.Ascii
 	lipgloss.Writer.Profile = profile
 	log.SetColorProfile(profile)
+	defaultDiagnostics.SetColorProfile(profile)
 }
 
 // ForceConsoleColor forces color output in the console
@@ -101,6 +133,7 @@ func ForceConsoleColor() {
 	profile := colorprofile.TrueColor
 	lipgloss.Writer.Profile = profile
 	log.SetColorProfile(profile)
+	defaultDiagnostics.SetColorProfile(profile)
 }
 
 // getStatusStyle returns the appropriate pre-created style for the status code
@@ -162,7 +195,6 @@ func LoggerWithFormatter(f LogFormatter) handlerFunc {
 // LoggerWithWriter instances a Logger middleware with the specified writer buffer
 // For example: os.Stdout, a file opened in write mode, or a socket
 func LoggerWithWriter(out io.Writer, notlogged ...string) handlerFunc {
-	log.SetOutput(out)
 	return LoggerWithConfig(LoggerConfig{
 		Output:    out,
 		SkipPaths: notlogged,
@@ -173,10 +205,15 @@ func LoggerWithWriter(out io.Writer, notlogged ...string) handlerFunc {
 func LoggerWithConfig(conf LoggerConfig) handlerFunc {
 	formatter := conf.Formatter
 	if formatter == nil {
-		formatter = DefaultLogFormatter
+		formatter = presetLogFormatter(conf.Format)
 	}
 	// Check if using DefaultLogFormatter
 	usingDefaultLogFormatter := formatter == nil || fmt.Sprintf("%p", formatter) == fmt.Sprintf("%p", DefaultLogFormatter)
+	// Lines are written to Output when set, otherwise through the global logger
+	var output *log.Logger
+	if conf.Output != nil {
+		output = log.NewWithOptions(conf.Output, log.Options{ReportTimestamp: usingDefaultLogFormatter, Level: log.DebugLevel})
+	}
 	notlogged := conf.SkipPaths
 	var skip map[string]struct{}
 	if length := len(notlogged); length > 0 {
@@ -197,16 +234,33 @@ func LoggerWithConfig(conf LoggerConfig) handlerFunc {
 		// Convert to string only for map lookup
 		pathStr := string(path)
 		if _, ok := skip[pathStr]; !ok {
+			now := time.Now()
 			param := LogFormatterParams{
 				Request:      &c.requestCtx.Request,
-				TimeStamp:    time.Now(),
-				Latency:      time.Since(start),
+				TimeStamp:    now,
+				Latency:      now.Sub(start),
 				ClientIP:     c.ClientIP(),
 				Method:       string(c.requestCtx.Method()),
 				StatusCode:   c.requestCtx.Response.StatusCode(),
 				ErrorMessage: "",
 				BodySize:     len(c.requestCtx.Response.Body()),
 				Keys:         nil,
+				Route:        c.fullPath,
+				RequestID:    c.requestID(),
+				TraceID:      traceID(c.GetHeader(HeaderTraceparent)),
+				BytesRead:    len(c.requestCtx.Request.Body()),
+				TotalLatency: now.Sub(start),
+			}
+			// Contexts created outside a server carry no receive time
+			if received := c.requestCtx.Time(); !received.IsZero() && received.Before(start) {
+				param.QueueTime = start.Sub(received)
+				param.TotalLatency += param.QueueTime
+			}
+			if principal := c.Principal(); principal != nil {
+				param.User = principal.ID
+			}
+			if c.app != nil {
+				param.InFlight = int(c.app.inFlightCount.Load())
 			}
 			// Set path - avoid redundant string conversion
 			if len(raw) > 0 {
@@ -227,7 +281,13 @@ func LoggerWithConfig(conf LoggerConfig) handlerFunc {
 				}
 			}
 			logMessage := formatter(param)
-			if usingDefaultLogFormatter {
+			if output != nil {
+				if usingDefaultLogFormatter {
+					output.Debug(logMessage)
+				} else {
+					output.Print(logMessage)
+				}
+			} else if usingDefaultLogFormatter {
 				// Use Debug log level with timestamp for DefaultLogFormatter
 				log.SetReportTimestamp(true)
 				log.SetLevel(log.DebugLevel)
@@ -244,10 +304,39 @@ func LoggerWithConfig(conf LoggerConfig) handlerFunc {
 // Recovery catches any panics that occur during request processing
 // It logs the error and returns a 500 Internal Server Error response
 func Recovery() handlerFunc {
+	return RecoveryWithConfig(RecoveryConfig{})
+}
+
+// RecoveryWithSink instances a Recovery middleware that also sends crash reports to the given sink
+func RecoveryWithSink(sink CrashSink) handlerFunc {
+	return RecoveryWithConfig(RecoveryConfig{Sink: sink})
+}
+
+// RecoveryWithConfig instances a Recovery middleware with config
+func RecoveryWithConfig(conf RecoveryConfig) handlerFunc {
+	redact := make(map[string]struct{}, len(redactedHeaders)+len(conf.RedactHeaders))
+	for _, h := range redactedHeaders {
+		redact[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+	for _, h := range conf.RedactHeaders {
+		redact[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
 	return func(c *Context) {
 		defer func() {
 			if rcv := recover(); rcv != nil {
-				log.Error("Recovered from error", "error", rcv)
+				logger := c.diagnostics()
+				report := newCrashReport(c, rcv, redact, !conf.DisableStackTrace)
+				if c.app != nil && c.app.debugErrorPageEnabled() {
+					c.requestCtx.SetUserValue(debugPanicKey, capturePanic(rcv))
+				}
+				logger.Error("Recovered from error", "error", rcv, "request_id", report.RequestID)
+				if conf.Sink != nil {
+					go func() {
+						if err := conf.Sink.Report(report); err != nil {
+							logger.Error("Failed to send crash report", "error", err, "request_id", report.RequestID)
+						}
+					}()
+				}
 				c.requestCtx.Error(fasthttp.StatusMessage(StatusInternalServerError), StatusInternalServerError)
 				c.Abort()
 			}
@@ -255,3 +344,12 @@ func Recovery() handlerFunc {
 		c.Next()
 	}
 }
+
+// traceID extracts the trace id of a W3C traceparent header, e.g. "00-<trace-id>-<parent-id>-01"
+func traceID(traceparent string) string {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	return parts[1]
+}
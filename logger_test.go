@@ -0,0 +1,67 @@
+package gonoleks
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"charm.land/log/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerFormatterParams(t *testing.T) {
+	var params []LogFormatterParams
+	app := New()
+	app.Use(LoggerWithFormatter(func(p LogFormatterParams) string {
+		params = append(params, p)
+		return ""
+	}))
+	app.POST("/users/:id", func(c *Context) { c.String(StatusOK, "ok") })
+	app.GET("/static", func(c *Context) { c.Status(StatusNoContent) })
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodPost, "/users/42", "203.0.113.5", map[string]string{
+		HeaderXRequestID:  "req-1",
+		HeaderTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+	reqCtx.Request.SetBodyString(`{"name":"gopher"}`)
+	app.router.Handler(reqCtx)
+
+	reqCtx = newProxiedRequest(MethodGet, "/static", "203.0.113.5", map[string]string{HeaderTraceparent: "garbage"})
+	app.router.Handler(reqCtx)
+
+	require.Len(t, params, 2)
+	p := params[0]
+	assert.Equal(t, "/users/:id", p.Route)
+	assert.Equal(t, "req-1", p.RequestID)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", p.TraceID)
+	assert.Equal(t, 17, p.BytesRead)
+	assert.GreaterOrEqual(t, p.TotalLatency, p.Latency)
+	assert.Equal(t, p.TotalLatency-p.Latency, p.QueueTime)
+	assert.Equal(t, 1, p.InFlight, "only the request itself is in flight")
+	assert.Zero(t, app.inFlightCount.Load())
+
+	assert.Equal(t, "/static", params[1].Route)
+	assert.Empty(t, params[1].TraceID)
+	assert.Empty(t, params[1].RequestID)
+}
+
+func TestLoggerWithConfigOutput(t *testing.T) {
+	var out, global bytes.Buffer
+	log.SetOutput(&global)
+	defer log.SetOutput(os.Stderr)
+
+	app := New()
+	app.Use(LoggerWithConfig(LoggerConfig{Format: LogFormatCombined, Output: &out}))
+	app.GET("/users", func(c *Context) { c.String(StatusOK, "ok") })
+	app.setupRouter()
+	app.router.Handler(newProxiedRequest(MethodGet, "/users?page=2", "203.0.113.5", nil))
+
+	assert.Contains(t, out.String(), `203.0.113.5 - - [`)
+	assert.Contains(t, out.String(), `"GET /users?page=2 HTTP/1.1" 200 2`)
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+	assert.Empty(t, global.String(), "the global logger should be left alone")
+}
@@ -0,0 +1,358 @@
+package gonoleks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AsyncWriterConfig defines the config for AsyncWriter
+type AsyncWriterConfig struct {
+	// BufferSize is the size of the buffer collecting writes before they reach the output
+	BufferSize int // Default = 64 KiB
+
+	// QueueSize is the number of writes queued for the background goroutine
+	QueueSize int // Default = 1024
+
+	// FlushInterval is the period at which buffered writes are flushed to the output
+	FlushInterval time.Duration // Default = 1 second
+
+	// DropOnFull drops writes while the queue is full instead of blocking the caller,
+	// trading completeness for latency under bursts
+	DropOnFull bool
+}
+
+// AsyncWriter moves writes off the request path: Write copies the data to a queue drained by
+// a background goroutine, which buffers it and flushes periodically
+// It suits the Logger middleware at high request rates, where synchronous writes to stdout or
+// a file add measurable latency to every request
+// Close must be called before exiting to flush pending writes
+//
+//	out := gonoleks.NewAsyncWriter(os.Stdout)
+//	defer out.Close()
+//	app.Use(gonoleks.LoggerWithConfig(gonoleks.LoggerConfig{Format: gonoleks.LogFormatCombined, Output: out}))
+type AsyncWriter struct {
+	out       io.Writer
+	config    AsyncWriterConfig
+	queue     chan []byte
+	flush     chan chan error
+	done      chan struct{}
+	closeOnce sync.Once
+	closeMu   sync.RWMutex
+	closed    bool
+	dropped   atomic.Uint64
+	lastErr   atomic.Pointer[error]
+}
+
+// NewAsyncWriter creates an AsyncWriter writing to out and starts its background goroutine
+func NewAsyncWriter(out io.Writer, config ...AsyncWriterConfig) *AsyncWriter {
+	var conf AsyncWriterConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if conf.BufferSize <= 0 {
+		conf.BufferSize = 64 << 10
+	}
+	if conf.QueueSize <= 0 {
+		conf.QueueSize = 1024
+	}
+	if conf.FlushInterval <= 0 {
+		conf.FlushInterval = time.Second
+	}
+	w := &AsyncWriter{
+		out:    out,
+		config: conf,
+		queue:  make(chan []byte, conf.QueueSize),
+		flush:  make(chan chan error),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write queues a copy of p for writing
+// Errors of the output are reported by Flush and Close rather than by Write
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.closeMu.RLock()
+	defer w.closeMu.RUnlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	data := slices.Clone(p)
+	if w.config.DropOnFull {
+		select {
+		case w.queue <- data:
+		default:
+			w.dropped.Add(1)
+		}
+		return len(p), nil
+	}
+	w.queue <- data
+	return len(p), nil
+}
+
+// Flush writes the queued and buffered data to the output and waits for it to complete
+func (w *AsyncWriter) Flush() error {
+	w.closeMu.RLock()
+	if w.closed {
+		w.closeMu.RUnlock()
+		return os.ErrClosed
+	}
+	result := make(chan error, 1)
+	w.flush <- result
+	w.closeMu.RUnlock()
+	return <-result
+}
+
+// Close flushes pending writes and stops the background goroutine
+// The output is closed too when it implements io.Closer and is not os.Stdout or os.Stderr
+func (w *AsyncWriter) Close() error {
+	w.closeOnce.Do(func() {
+		w.closeMu.Lock()
+		w.closed = true
+		close(w.queue)
+		w.closeMu.Unlock()
+		<-w.done
+		if closer, ok := w.out.(io.Closer); ok && w.out != os.Stdout && w.out != os.Stderr {
+			if err := closer.Close(); err != nil {
+				w.lastErr.Store(&err)
+			}
+		}
+	})
+	if err := w.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// Dropped returns the number of writes dropped because the queue was full
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// run drains the queue into the buffer until Close
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	buf := bufio.NewWriterSize(w.out, w.config.BufferSize)
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+	write := func(data []byte) {
+		if _, err := buf.Write(data); err != nil {
+			w.lastErr.Store(&err)
+			// A failed bufio.Writer keeps its error, start over so later writes can succeed
+			buf.Reset(w.out)
+		}
+	}
+	flush := func() error {
+		err := buf.Flush()
+		if err != nil {
+			w.lastErr.Store(&err)
+			buf.Reset(w.out)
+		}
+		return err
+	}
+	for {
+		select {
+		case data, ok := <-w.queue:
+			if !ok {
+				_ = flush()
+				return
+			}
+			write(data)
+		case result := <-w.flush:
+			// Drain what was queued before the flush request
+			for drained := false; !drained; {
+				select {
+				case data, ok := <-w.queue:
+					if ok {
+						write(data)
+					} else {
+						drained = true
+					}
+				default:
+					drained = true
+				}
+			}
+			result <- flush()
+		case <-ticker.C:
+			_ = flush()
+		}
+	}
+}
+
+// RotatingFileConfig defines the config for RotatingFile
+type RotatingFileConfig struct {
+	// Filename is the file written to, rotated files are kept next to it
+	// with a timestamp inserted before the extension, e.g. access-20260102T150405.000.log
+	Filename string
+
+	// MaxSize rotates the file before a write would make it exceed this many bytes, zero disables it
+	MaxSize int64
+
+	// RotateEvery rotates the file when it is older than this, e.g. 24 * time.Hour, zero disables it
+	RotateEvery time.Duration
+
+	// MaxBackups is the number of rotated files kept, zero keeps all of them
+	MaxBackups int
+
+	// Perm is the permission of created files
+	Perm os.FileMode // Default = 0o644
+}
+
+// RotatingFile is an io.WriteCloser appending to a file that is rotated by size or age
+// It is safe for concurrent use and can be wrapped in an AsyncWriter
+//
+//	file, err := gonoleks.NewRotatingFile(gonoleks.RotatingFileConfig{
+//		Filename:   "logs/access.log",
+//		MaxSize:    100 << 20,
+//		MaxBackups: 7,
+//	})
+type RotatingFile struct {
+	config  RotatingFileConfig
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	opened  time.Time
+	nowFunc func() time.Time
+}
+
+// NewRotatingFile opens or creates the file of config, creating its directory if needed
+func NewRotatingFile(config RotatingFileConfig) (*RotatingFile, error) {
+	if config.Filename == "" {
+		return nil, fmt.Errorf("rotating file: %w", os.ErrInvalid)
+	}
+	if config.Perm == 0 {
+		config.Perm = 0o644
+	}
+	r := &RotatingFile{config: config, nowFunc: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write appends p to the file, rotating it first when a limit is reached
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file right away, e.g. on SIGHUP
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return os.ErrClosed
+	}
+	return r.rotate()
+}
+
+// Close closes the file
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// shouldRotate reports whether the file must be rotated before writing n bytes
+func (r *RotatingFile) shouldRotate(n int64) bool {
+	if r.config.MaxSize > 0 && r.size > 0 && r.size+n > r.config.MaxSize {
+		return true
+	}
+	return r.config.RotateEvery > 0 && r.nowFunc().Sub(r.opened) >= r.config.RotateEvery
+}
+
+// open opens the file for appending
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.config.Filename), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(r.config.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, r.config.Perm)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	r.file, r.size, r.opened = file, info.Size(), r.nowFunc()
+	return nil
+}
+
+// rotate renames the current file to its backup name, reopens the file and prunes old backups
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+	// Rotations within the same millisecond move on to the next free timestamp
+	t := r.nowFunc()
+	backup := r.backupName(t)
+	for {
+		if _, err := os.Lstat(backup); os.IsNotExist(err) {
+			break
+		}
+		t = t.Add(time.Millisecond)
+		backup = r.backupName(t)
+	}
+	if err := os.Rename(r.config.Filename, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	return r.prune()
+}
+
+// backupName returns the name of a file rotated at t
+func (r *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(r.config.Filename)
+	base := strings.TrimSuffix(r.config.Filename, ext)
+	return base + "-" + t.Format("20060102T150405.000") + ext
+}
+
+// prune removes the oldest backups beyond MaxBackups
+func (r *RotatingFile) prune() error {
+	if r.config.MaxBackups <= 0 {
+		return nil
+	}
+	ext := filepath.Ext(r.config.Filename)
+	pattern := strings.TrimSuffix(r.config.Filename, ext) + "-*" + ext
+	backups, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	// The timestamp format sorts lexically in chronological order
+	slices.Sort(backups)
+	for len(backups) > r.config.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package gonoleks
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAsyncWriter(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewAsyncWriter(out, AsyncWriterConfig{FlushInterval: time.Hour})
+
+	line := []byte("GET /users 200\n")
+	n, err := w.Write(line)
+	require.NoError(t, err)
+	assert.Equal(t, len(line), n)
+	line[0] = 'X' // the writer keeps its own copy
+	assert.Empty(t, out.String(), "writes are buffered until flushed")
+
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "GET /users 200\n", out.String())
+
+	_, _ = w.Write([]byte("POST /users 201\n"))
+	require.NoError(t, w.Close())
+	assert.Equal(t, "GET /users 200\nPOST /users 201\n", out.String())
+	_, err = w.Write([]byte("late"))
+	require.ErrorIs(t, err, os.ErrClosed)
+	require.NoError(t, w.Close())
+}
+
+func TestAsyncWriterPeriodicFlush(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewAsyncWriter(out, AsyncWriterConfig{FlushInterval: 10 * time.Millisecond})
+	defer w.Close()
+	_, _ = w.Write([]byte("tick\n"))
+	assert.Eventually(t, func() bool { return out.String() == "tick\n" }, time.Second, 5*time.Millisecond)
+}
+
+// blockingWriter blocks every write until released
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (b *blockingWriter) Write(p []byte) (int, error) {
+	<-b.release
+	return len(p), nil
+}
+
+func TestAsyncWriterDropOnFull(t *testing.T) {
+	out := &blockingWriter{release: make(chan struct{})}
+	w := NewAsyncWriter(out, AsyncWriterConfig{BufferSize: 1, QueueSize: 1, DropOnFull: true})
+	for range 10 {
+		_, err := w.Write([]byte("line\n"))
+		require.NoError(t, err)
+	}
+	assert.Positive(t, w.Dropped())
+	close(out.release)
+	require.NoError(t, w.Close())
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestAsyncWriterReportsErrors(t *testing.T) {
+	w := NewAsyncWriter(failingWriter{})
+	_, err := w.Write([]byte("line\n"))
+	require.NoError(t, err)
+	require.EqualError(t, w.Flush(), "disk full")
+	require.EqualError(t, w.Close(), "disk full")
+}
+
+func TestRotatingFileBySize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "logs", "access.log")
+	f, err := NewRotatingFile(RotatingFileConfig{Filename: filename, MaxSize: 10, MaxBackups: 2})
+	require.NoError(t, err)
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	f.nowFunc = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	current, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "fourth\n", string(current))
+	backups, err := filepath.Glob(filepath.Join(dir, "logs", "access-*.log"))
+	require.NoError(t, err)
+	require.Len(t, backups, 2, "only MaxBackups rotated files should be kept")
+	last, err := os.ReadFile(backups[1])
+	require.NoError(t, err)
+	assert.Equal(t, "third\n", string(last))
+	assert.True(t, strings.HasPrefix(filepath.Base(backups[0]), "access-20260102T"))
+}
+
+func TestRotatingFileByAge(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	f, err := NewRotatingFile(RotatingFileConfig{Filename: filename, RotateEvery: time.Hour})
+	require.NoError(t, err)
+	defer f.Close()
+	now := time.Now()
+	f.nowFunc = func() time.Time { return now }
+	f.opened = now
+
+	_, _ = f.Write([]byte("before\n"))
+	now = now.Add(2 * time.Hour)
+	_, _ = f.Write([]byte("after\n"))
+
+	current, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "after\n", string(current))
+	backups, _ := filepath.Glob(filepath.Join(filepath.Dir(filename), "app-*.log"))
+	assert.Len(t, backups, 1)
+
+	require.NoError(t, f.Rotate())
+	backups, _ = filepath.Glob(filepath.Join(filepath.Dir(filename), "app-*.log"))
+	assert.Len(t, backups, 2)
+}
@@ -0,0 +1,79 @@
+package gonoleks
+
+import (
+	"net"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// longPollMinInterval and longPollMaxInterval bound the backoff between two polls
+	longPollMinInterval = 10 * time.Millisecond
+	longPollMaxInterval = 250 * time.Millisecond
+)
+
+// LongPoll holds the request until poll reports data or timeout elapses, for clients that
+// cannot use Server-Sent Events or WebSockets
+// poll is called right away and then with a growing backoff of up to 250 milliseconds, and its
+// data is rendered as JSON with 200 OK as soon as it returns true
+// The request is answered with 204 No Content on timeout or server shutdown, so the client polls again
+// If the client disconnects while waiting, polling stops, nothing is written and
+// ErrClientDisconnected is returned
+//
+//	app.GET("/inbox", func(c *gonoleks.Context) {
+//		_ = c.LongPoll(30*time.Second, func() (any, bool) {
+//			msgs := inbox.Since(c.Query("cursor"))
+//			return msgs, len(msgs) > 0
+//		})
+//	})
+func (c *Context) LongPoll(timeout time.Duration, poll func() (any, bool)) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	interval := longPollMinInterval
+	shutdown := serverDone(c.requestCtx)
+	for {
+		if data, ok := poll(); ok {
+			return c.JSON(StatusOK, data)
+		}
+		wait := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+			wait.Stop()
+			c.Status(StatusNoContent)
+			return nil
+		case <-shutdown:
+			wait.Stop()
+			c.Status(StatusNoContent)
+			return nil
+		case <-wait.C:
+		}
+		if !connAlive(c.requestCtx.Conn()) {
+			c.requestCtx.SetConnectionClose()
+			return ErrClientDisconnected
+		}
+		interval = min(interval*2, longPollMaxInterval)
+	}
+}
+
+// serverDone returns the channel closed when the server serving fctx shuts down,
+// or nil for contexts created outside a server, e.g. in tests or by NativeHandler
+func serverDone(fctx *fasthttp.RequestCtx) (done <-chan struct{}) {
+	defer func() {
+		if recover() != nil {
+			done = nil
+		}
+	}()
+	return fctx.Done()
+}
+
+// netConn unwraps connections such as *tls.Conn to the underlying network connection
+func netConn(conn net.Conn) net.Conn {
+	for {
+		wrapper, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			return conn
+		}
+		conn = wrapper.NetConn()
+	}
+}
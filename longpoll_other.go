@@ -0,0 +1,11 @@
+//go:build !unix
+
+package gonoleks
+
+import "net"
+
+// connAlive reports whether the peer of conn is still connected
+// Disconnects cannot be detected on this platform, so connections are assumed alive
+func connAlive(net.Conn) bool {
+	return true
+}
@@ -0,0 +1,84 @@
+package gonoleks
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongPoll(t *testing.T) {
+	t.Run("data", func(t *testing.T) {
+		c, reqCtx := createTestContext()
+		var calls atomic.Int32
+		err := c.LongPoll(time.Second, func() (any, bool) {
+			return map[string]int{"calls": int(calls.Add(1))}, calls.Load() == 3
+		})
+		require.NoError(t, err)
+		assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+		assert.JSONEq(t, `{"calls":3}`, string(reqCtx.Response.Body()))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		c, reqCtx := createTestContext()
+		start := time.Now()
+		err := c.LongPoll(50*time.Millisecond, func() (any, bool) { return nil, false })
+		require.NoError(t, err)
+		assert.Equal(t, StatusNoContent, reqCtx.Response.StatusCode())
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+}
+
+func TestLongPollClientDisconnect(t *testing.T) {
+	result := make(chan error, 1)
+	app := New()
+	app.GET("/poll", func(c *Context) {
+		result <- c.LongPoll(5*time.Second, func() (any, bool) { return nil, false })
+	})
+	app.setupRouter()
+	server := app.newHTTPServer()
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(ln) }()
+	defer func() { _ = server.Shutdown() }()
+
+	conn, err := net.Dial("tcp4", ln.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("GET /poll HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, conn.Close())
+
+	select {
+	case err := <-result:
+		require.ErrorIs(t, err, ErrClientDisconnected)
+	case <-time.After(2 * time.Second):
+		t.Fatal("long poll did not notice the disconnect")
+	}
+}
+
+func TestConnAlivePipelined(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	client, err := net.Dial("tcp4", ln.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+	server, err := ln.Accept()
+	require.NoError(t, err)
+	defer server.Close()
+
+	assert.True(t, connAlive(server))
+	_, err = client.Write([]byte("GET / HTTP/1.1\r\n\r\n"))
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, connAlive(server), "pending request data means the client is alive")
+
+	buf := make([]byte, 64)
+	n, err := server.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "GET / HTTP/1.1\r\n\r\n", string(buf[:n]), "peeking must not consume the data")
+}
@@ -0,0 +1,38 @@
+//go:build unix
+
+package gonoleks
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// connAlive reports whether the peer of conn is still connected, by peeking at the socket
+// without consuming any pipelined request data
+// Connections that cannot be inspected are assumed alive
+func connAlive(conn net.Conn) bool {
+	sc, ok := netConn(conn).(syscall.Conn)
+	if !ok {
+		return true
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return true
+	}
+	alive := true
+	var buf [1]byte
+	_ = raw.Read(func(fd uintptr) bool {
+		n, _, err := syscall.Recvfrom(int(fd), buf[:], syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		switch {
+		case err == nil:
+			// A zero byte read is the orderly shutdown of the peer
+			alive = n > 0
+		case errors.Is(err, syscall.EAGAIN), errors.Is(err, syscall.EWOULDBLOCK), errors.Is(err, syscall.EINTR):
+		default:
+			alive = false
+		}
+		return true
+	})
+	return alive
+}
@@ -0,0 +1,66 @@
+package gonoleks
+
+import (
+	"maps"
+	"strings"
+)
+
+// RouteInfo describes the registered route a request resolves to
+type RouteInfo struct {
+	// Method is the HTTP method of the route
+	Method string `json:"method"`
+
+	// Path is the route pattern, e.g. "/users/:id"
+	Path string `json:"path"`
+
+	// Handlers lists the names of the route's handlers, middleware first
+	Handlers []string `json:"handlers"`
+
+	// Metadata holds the values attached to the route, e.g. by Register
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// Lookup reports which route would serve a request for method and path, along with
+// the route params it would extract, without running any handler
+// Unlike ExplainRoute it ignores fallbacks such as NoRoute and reports only registered routes
+// It must be called after the router is set up, and is safe for concurrent use
+//
+//	if info, params, ok := app.Lookup("GET", "/users/42"); ok {
+//		fmt.Println(info.Path, params["id"]) // /users/:id 42
+//	}
+func (g *Gonoleks) Lookup(method, path string) (RouteInfo, map[string]string, bool) {
+	r := g.router
+	if g.CaseInSensitive {
+		method = strings.ToUpper(method)
+		path = strings.ToLower(path)
+	}
+	params := make(map[string]string)
+	var pattern string
+	var chain handlersChain
+	if r.fastRouter != nil {
+		if _, handlers := r.fastRouter.lookupLevel(method, path); handlers != nil {
+			pattern, chain = path, handlers
+		}
+	}
+	if chain == nil {
+		root := r.trees[method]
+		if root == nil {
+			return RouteInfo{}, nil, false
+		}
+		ctx := &Context{paramValues: make(map[string]string)}
+		var nodes []string
+		if chain = root.traceRoute(path, ctx, &nodes); chain == nil {
+			return RouteInfo{}, nil, false
+		}
+		pattern = "/" + strings.Join(nodes, "/")
+		maps.Copy(params, ctx.paramValues)
+	}
+	info := RouteInfo{Method: method, Path: pattern, Handlers: make([]string, len(chain))}
+	for i, handler := range chain {
+		info.Handlers[i] = nameOfFunction(handler)
+	}
+	if route, ok := g.routeIndex[method+" "+pattern]; ok {
+		info.Metadata = route.Metadata
+	}
+	return info, params, true
+}
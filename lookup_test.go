@@ -0,0 +1,44 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	app := New()
+	app.RegisterHandler("users.show", func(c *Context) {})
+	_, err := app.Register([]RouteSpec{
+		{Method: MethodGet, Path: "/users/:id", Handler: "users.show", Metadata: map[string]any{"owner": "accounts"}},
+	})
+	require.NoError(t, err)
+	app.GET("/health", func(c *Context) {})
+	app.GET("/files/*path", func(c *Context) {})
+	app.setupRouter()
+
+	info, params, ok := app.Lookup(MethodGet, "/users/42")
+	require.True(t, ok)
+	assert.Equal(t, MethodGet, info.Method)
+	assert.Equal(t, "/users/:id", info.Path)
+	assert.Equal(t, map[string]string{"id": "42"}, params)
+	assert.Equal(t, "accounts", info.Metadata["owner"])
+	require.Len(t, info.Handlers, 1)
+	assert.Contains(t, info.Handlers[0], "TestLookup")
+
+	info, params, ok = app.Lookup(MethodGet, "/health")
+	require.True(t, ok)
+	assert.Equal(t, "/health", info.Path)
+	assert.Empty(t, params)
+	assert.Nil(t, info.Metadata)
+
+	info, params, ok = app.Lookup(MethodGet, "/files/css/site.css")
+	require.True(t, ok)
+	assert.Equal(t, "css/site.css", params["path"])
+
+	_, _, ok = app.Lookup(MethodPost, "/users/42")
+	assert.False(t, ok)
+	_, _, ok = app.Lookup(MethodGet, "/missing")
+	assert.False(t, ok)
+}
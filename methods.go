@@ -0,0 +1,62 @@
+package gonoleks
+
+import (
+	"fmt"
+	"slices"
+)
+
+// standardMethods lists the methods registered by Any without RegisterMethod
+var standardMethods = []string{
+	MethodGet, MethodHead, MethodPost, MethodPut, MethodPatch,
+	MethodDelete, MethodConnect, MethodOptions, MethodTrace,
+}
+
+// RegisterMethod registers custom HTTP methods, e.g. the WebDAV methods PROPFIND or MKCOL,
+// so they are included in routes registered with Any
+// Handle accepts any valid method without registration
+// Methods are case-sensitive and must be valid tokens, it panics otherwise
+//
+//	app.RegisterMethod(gonoleks.MethodPropfind, gonoleks.MethodMkcol)
+//	app.Handle(gonoleks.MethodPropfind, "/dav/*path", propfind)
+func (g *Gonoleks) RegisterMethod(methods ...string) {
+	for _, method := range methods {
+		if !isValidMethod(method) {
+			panic(fmt.Sprintf("http method %q is not valid", method))
+		}
+		if slices.Contains(standardMethods, method) || slices.Contains(g.customMethods, method) {
+			continue
+		}
+		g.customMethods = append(g.customMethods, method)
+	}
+}
+
+// Methods returns the standard HTTP methods followed by the registered custom methods
+func (g *Gonoleks) Methods() []string {
+	return append(slices.Clone(standardMethods), g.customMethods...)
+}
+
+// isValidMethod reports whether method is a non-empty RFC 9110 token
+func isValidMethod(method string) bool {
+	if method == "" {
+		return false
+	}
+	for i := 0; i < len(method); i++ {
+		if !isTokenChar(method[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isTokenChar reports whether b is a tchar as defined in RFC 9110 section 5.6.2
+func isTokenChar(b byte) bool {
+	switch {
+	case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9':
+		return true
+	}
+	switch b {
+	case '!', '#', '$', '%', '&', '\'', '*', '+', '-', '.', '^', '_', '`', '|', '~':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRegisterMethod(t *testing.T) {
+	app := New()
+	app.RegisterMethod(MethodPropfind, MethodMkcol, MethodGet, MethodPropfind)
+	assert.Equal(t, []string{MethodPropfind, MethodMkcol}, app.customMethods)
+	assert.Equal(t, append(standardMethods, MethodPropfind, MethodMkcol), app.Methods())
+
+	assert.Panics(t, func() { app.RegisterMethod("BAD METHOD") })
+	assert.Panics(t, func() { app.RegisterMethod("") })
+	assert.Panics(t, func() { app.Handle("GET\r\n", "/", func(c *Context) {}) })
+
+	routes := app.Any("/dav", func(c *Context) { c.String(StatusOK, "%s", c.requestCtx.Method()) })
+	assert.Len(t, routes, 11)
+	app.setupRouter()
+
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.Header.SetMethod(MethodMkcol)
+	reqCtx.Request.SetRequestURI("/dav")
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, MethodMkcol, string(reqCtx.Response.Body()))
+
+	// Handle routes any valid method without registration
+	unregistered := New()
+	unregistered.Handle(MethodLock, "/dav", func(c *Context) { c.Status(StatusNoContent) })
+	unregistered.setupRouter()
+	reqCtx = &fasthttp.RequestCtx{}
+	reqCtx.Request.Header.SetMethod(MethodLock)
+	reqCtx.Request.SetRequestURI("/dav")
+	unregistered.router.Handler(reqCtx)
+	assert.Equal(t, StatusNoContent, reqCtx.Response.StatusCode())
+
+	ctx := &Context{paramValues: make(map[string]string)}
+	assert.Equal(t, "GET, HEAD, POST, PUT, PATCH, DELETE, CONNECT, TRACE, MKCOL, PROPFIND, OPTIONS", app.router.allowed("/dav", ctx))
+}
+
+func TestIsValidMethod(t *testing.T) {
+	assert.True(t, isValidMethod(MethodGet))
+	assert.True(t, isValidMethod("VERSION-CONTROL"))
+	assert.False(t, isValidMethod(""))
+	assert.False(t, isValidMethod("GET /"))
+	assert.False(t, isValidMethod("PROP(FIND)"))
+}
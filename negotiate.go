@@ -0,0 +1,159 @@
+package gonoleks
+
+import (
+	"mime"
+	"strings"
+)
+
+// Negotiate contains the data rendered by Context.Negotiate for each format
+type Negotiate struct {
+	// Offered lists the MIME types the handler can produce, in order of server preference
+	Offered []string
+
+	// Data is rendered when no format specific data is set
+	Data any
+
+	// JSONData is rendered for application/json
+	JSONData any
+
+	// XMLData is rendered for application/xml and text/xml
+	XMLData any
+
+	// YAMLData is rendered for application/x-yaml
+	YAMLData any
+
+	// ProtoBufData is rendered for application/x-protobuf
+	ProtoBufData any
+}
+
+// Accepts returns the offer that best matches the Accept request header, or "" if none is acceptable
+// Offers may be MIME types or file extensions such as "json" or "html"
+// The first offer is returned when the request has no Accept header
+//
+//	switch c.Accepts("json", "html") {
+//	case "json":
+//		c.JSON(http.StatusOK, data)
+//	case "html":
+//		c.HTML(http.StatusOK, "page.html", data)
+//	}
+func (c *Context) Accepts(offers ...string) string {
+	return negotiate(c.GetHeader(HeaderAccept), offers, func(accepted, offer string) bool {
+		return matchMediaRange(accepted, offerMediaType(offer))
+	})
+}
+
+// AcceptsEncodings returns the offer that best matches the Accept-Encoding request header
+func (c *Context) AcceptsEncodings(offers ...string) string {
+	return negotiate(c.GetHeader(HeaderAcceptEncoding), offers, matchToken)
+}
+
+// AcceptsCharsets returns the offer that best matches the Accept-Charset request header
+func (c *Context) AcceptsCharsets(offers ...string) string {
+	return negotiate(c.GetHeader(HeaderAcceptCharset), offers, matchToken)
+}
+
+// AcceptsLanguages returns the offer that best matches the Accept-Language request header
+// A language range matches more specific offers, e.g. "en" matches "en-US",
+// and a regional language falls back to its primary language, e.g. "de-AT" matches "de"
+func (c *Context) AcceptsLanguages(offers ...string) string {
+	return negotiate(c.GetHeader(HeaderAcceptLanguage), offers, matchLanguage)
+}
+
+// NegotiateFormat returns the offered MIME type that best matches the Accept request header
+func (c *Context) NegotiateFormat(offered ...string) string {
+	return c.Accepts(offered...)
+}
+
+// Negotiate renders the data in the format that best matches the Accept request header
+// It responds with 406 Not Acceptable when none of the offered formats is acceptable
+//
+//	c.Negotiate(http.StatusOK, gonoleks.Negotiate{
+//		Offered: []string{gonoleks.MIMEApplicationJSON, gonoleks.MIMEApplicationXML},
+//		Data:    user,
+//	})
+func (c *Context) Negotiate(code int, config Negotiate) error {
+	switch c.NegotiateFormat(config.Offered...) {
+	case MIMEApplicationJSON:
+		return c.JSON(code, negotiatedData(config.JSONData, config.Data))
+	case MIMEApplicationXML, MIMETextXML:
+		return c.XML(code, negotiatedData(config.XMLData, config.Data))
+	case MIMEApplicationYAML:
+		return c.YAML(code, negotiatedData(config.YAMLData, config.Data))
+	case MIMEApplicationProtoBuf:
+		return c.ProtoBuf(code, negotiatedData(config.ProtoBufData, config.Data))
+	default:
+		return c.AbortWithError(StatusNotAcceptable, ErrNotAcceptable)
+	}
+}
+
+// negotiatedData returns the format specific data if set, otherwise the generic data
+func negotiatedData(specific, fallback any) any {
+	if specific != nil {
+		return specific
+	}
+	return fallback
+}
+
+// negotiate picks the offer matching the most preferred accepted value
+// Values are tried in order of quality, offers in the order given
+func negotiate(header string, offers []string, match func(accepted, offer string) bool) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if header == "" {
+		return offers[0]
+	}
+	for _, accepted := range parseAcceptHeader(header) {
+		for _, offer := range offers {
+			if match(accepted, offer) {
+				return offer
+			}
+		}
+	}
+	return ""
+}
+
+// offerMediaType resolves a file extension such as "json" to its MIME type
+func offerMediaType(offer string) string {
+	if strings.Contains(offer, "/") {
+		return offer
+	}
+	if mediaType := mime.TypeByExtension("." + offer); mediaType != "" {
+		return mediaType
+	}
+	return offer
+}
+
+// matchMediaRange reports whether a media range such as "text/*" matches a MIME type
+// Parameters of the MIME type are ignored
+func matchMediaRange(mediaRange, mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	if mediaRange == "*/*" || strings.EqualFold(mediaRange, mediaType) {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(mediaRange, "/*"); ok {
+		typ, _, _ := strings.Cut(mediaType, "/")
+		return strings.EqualFold(prefix, typ)
+	}
+	return false
+}
+
+// matchToken reports whether an accepted token, or the "*" wildcard, matches an offer
+func matchToken(accepted, offer string) bool {
+	return accepted == "*" || strings.EqualFold(accepted, offer)
+}
+
+// matchLanguage reports whether an accepted language range matches an offered language tag
+func matchLanguage(accepted, offer string) bool {
+	if accepted == "*" || strings.EqualFold(accepted, offer) {
+		return true
+	}
+	// The range "en" matches the tag "en-US"
+	if len(offer) > len(accepted) && offer[len(accepted)] == '-' && strings.EqualFold(offer[:len(accepted)], accepted) {
+		return true
+	}
+	// The regional range "en-US" falls back to the primary tag "en"
+	primary, _, found := strings.Cut(accepted, "-")
+	return found && strings.EqualFold(primary, offer)
+}
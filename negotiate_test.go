@@ -0,0 +1,64 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextAccepts(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		offers   []string
+		expected string
+	}{
+		{"No header", "", []string{"json", "html"}, "json"},
+		{"Browser", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", []string{"json", "html"}, "html"},
+		{"Quality order", "text/html;q=0.5, application/json", []string{"html", "json"}, "json"},
+		{"Type wildcard", "text/*", []string{MIMEApplicationJSON, MIMETextPlain}, MIMETextPlain},
+		{"Full wildcard", "*/*", []string{"xml", "json"}, "xml"},
+		{"Not acceptable", "image/png", []string{"json", "html"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, requestCtx := createTestContext()
+			requestCtx.Request.Header.Set(HeaderAccept, tt.accept)
+			assert.Equal(t, tt.expected, ctx.Accepts(tt.offers...))
+		})
+	}
+}
+
+func TestContextAcceptsEncodingsAndLanguages(t *testing.T) {
+	ctx, requestCtx := createTestContext()
+	requestCtx.Request.Header.Set(HeaderAcceptEncoding, "gzip;q=0.5, br")
+	requestCtx.Request.Header.Set(HeaderAcceptCharset, "iso-8859-1;q=0.2, UTF-8")
+	requestCtx.Request.Header.Set(HeaderAcceptLanguage, "de-AT, en;q=0.8")
+	assert.Equal(t, "br", ctx.AcceptsEncodings("gzip", "br"))
+	assert.Equal(t, "gzip", ctx.AcceptsEncodings("gzip", "zstd"))
+	assert.Equal(t, "", ctx.AcceptsEncodings("zstd"))
+	assert.Equal(t, "utf-8", ctx.AcceptsCharsets("iso-8859-1", "utf-8"))
+	assert.Equal(t, "de", ctx.AcceptsLanguages("en-US", "de"), "Regional range should fall back to the primary language")
+	assert.Equal(t, "en-US", ctx.AcceptsLanguages("fr", "en-US"), "Primary range should match regional tags")
+	assert.Equal(t, "", ctx.AcceptsLanguages("fr"))
+}
+
+func TestContextNegotiate(t *testing.T) {
+	data := H{"name": "gonoleks"}
+
+	ctx, requestCtx := createTestContext()
+	requestCtx.Request.Header.Set(HeaderAccept, "application/xml;q=0.5, application/json")
+	assert.NoError(t, ctx.Negotiate(StatusOK, Negotiate{Offered: []string{MIMEApplicationXML, MIMEApplicationJSON}, Data: data}))
+	assert.Equal(t, MIMEApplicationJSONCharsetUTF8, string(requestCtx.Response.Header.ContentType()))
+	assert.JSONEq(t, `{"name":"gonoleks"}`, string(requestCtx.Response.Body()))
+
+	ctx, requestCtx = createTestContext()
+	requestCtx.Request.Header.Set(HeaderAccept, "text/xml")
+	assert.NoError(t, ctx.Negotiate(StatusOK, Negotiate{Offered: []string{MIMEApplicationJSON, MIMETextXML}, XMLData: data}))
+	assert.Contains(t, string(requestCtx.Response.Body()), "<name>gonoleks</name>")
+
+	ctx, requestCtx = createTestContext()
+	requestCtx.Request.Header.Set(HeaderAccept, "image/png")
+	assert.ErrorIs(t, ctx.Negotiate(StatusOK, Negotiate{Offered: []string{MIMEApplicationJSON}, Data: data}), ErrNotAcceptable)
+	assert.Equal(t, StatusNotAcceptable, requestCtx.Response.StatusCode())
+}
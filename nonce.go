@@ -0,0 +1,142 @@
+package gonoleks
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxNonceLength bounds the nonces accepted by the Nonce middleware to limit store memory
+const maxNonceLength = 256
+
+// NonceStore remembers used nonces for replay protection
+// Implementations shared between instances, e.g. backed by Redis SET NX, protect a whole cluster
+type NonceStore interface {
+	// Use records nonce for ttl and reports whether it had not been used before
+	// It must be atomic, so only one of several concurrent requests with the same nonce succeeds
+	Use(nonce string, ttl time.Duration) (bool, error)
+}
+
+// MemoryNonceStore is an in-process NonceStore
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	nextSweep time.Time
+}
+
+// NewMemoryNonceStore returns an empty in-process nonce store
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: make(map[string]time.Time)}
+}
+
+// Use implements NonceStore
+// Expired nonces are swept at most once a minute
+func (s *MemoryNonceStore) Use(nonce string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.After(s.nextSweep) {
+		for n, expiry := range s.nonces {
+			if now.After(expiry) {
+				delete(s.nonces, n)
+			}
+		}
+		s.nextSweep = now.Add(time.Minute)
+	}
+	if expiry, ok := s.nonces[nonce]; ok && !now.After(expiry) {
+		return false, nil
+	}
+	// The nonce may alias a request buffer that is reused once the request is served
+	s.nonces[strings.Clone(nonce)] = now.Add(ttl)
+	return true, nil
+}
+
+// NonceConfig defines the config for NonceWithConfig
+type NonceConfig struct {
+	// Store remembers used nonces
+	Store NonceStore // Default = NewMemoryNonceStore()
+
+	// Header carries the nonce
+	Header string // Default = "X-Nonce"
+
+	// Query names a query parameter carrying the nonce when the header is absent,
+	// e.g. a claim of a URL signed with SignURL to make the link single-use
+	Query string
+
+	// TimestampHeader carries the Unix time the request was created at
+	// Requests whose timestamp is further than TTL from now are rejected,
+	// so nonces only need to be remembered for TTL
+	TimestampHeader string // Default = "X-Timestamp"
+
+	// RequireTimestamp rejects requests without a timestamp
+	RequireTimestamp bool
+
+	// TTL is how long a nonce is remembered and how far a timestamp may deviate from now
+	TTL time.Duration // Default = 5m
+
+	// Scope returns a namespace for the nonce, e.g. the API key or webhook sender,
+	// so clients cannot exhaust each other's nonces
+	Scope func(c *Context) string
+}
+
+// Nonce instances a replay protection middleware requiring a unique X-Nonce header per request
+// See NonceWithConfig
+func Nonce() handlerFunc {
+	return NonceWithConfig(NonceConfig{})
+}
+
+// NonceWithConfig instances a replay protection middleware with config
+// Requests without a nonce or with a stale timestamp are rejected with 400 Bad Request,
+// and requests reusing a nonce with 409 Conflict
+// Place it after signature verification, so only authentic requests consume nonces
+//
+//	app.POST("/webhooks/billing", verifySignature, gonoleks.NonceWithConfig(gonoleks.NonceConfig{
+//		Header:           "X-Webhook-Id",
+//		RequireTimestamp: true,
+//	}), handleBilling)
+func NonceWithConfig(conf NonceConfig) handlerFunc {
+	if conf.Store == nil {
+		conf.Store = NewMemoryNonceStore()
+	}
+	if conf.Header == "" {
+		conf.Header = HeaderXNonce
+	}
+	if conf.TimestampHeader == "" {
+		conf.TimestampHeader = HeaderXTimestamp
+	}
+	if conf.TTL <= 0 {
+		conf.TTL = 5 * time.Minute
+	}
+	return func(c *Context) {
+		nonce := c.GetHeader(conf.Header)
+		if nonce == "" && conf.Query != "" {
+			nonce = c.Query(conf.Query)
+		}
+		if nonce == "" || len(nonce) > maxNonceLength {
+			_ = c.AbortWithError(StatusBadRequest, ErrNonceMissing)
+			return
+		}
+		if raw := c.GetHeader(conf.TimestampHeader); raw != "" || conf.RequireTimestamp {
+			timestamp, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || math.Abs(float64(c.Now().Unix()-timestamp)) > conf.TTL.Seconds() {
+				_ = c.AbortWithError(StatusBadRequest, ErrNonceStale)
+				return
+			}
+		}
+		if conf.Scope != nil {
+			nonce = conf.Scope(c) + ":" + nonce
+		}
+		fresh, err := conf.Store.Use(nonce, conf.TTL)
+		if err != nil {
+			_ = c.AbortWithError(StatusInternalServerError, err)
+			return
+		}
+		if !fresh {
+			_ = c.AbortWithError(StatusConflict, ErrNonceReplayed)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,163 @@
+package gonoleks
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestNonceMiddleware(t *testing.T) {
+	app := New()
+	app.POST("/hooks", NonceWithConfig(NonceConfig{Scope: func(c *Context) string { return c.GetHeader("X-Sender") }}), func(c *Context) {
+		c.Status(StatusNoContent)
+	})
+	app.setupRouter()
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		code    int
+	}{
+		{"Missing nonce", nil, StatusBadRequest},
+		{"Oversized nonce", map[string]string{HeaderXNonce: strings.Repeat("n", maxNonceLength+1)}, StatusBadRequest},
+		{"Fresh nonce", map[string]string{HeaderXNonce: "n1", "X-Sender": "a"}, StatusNoContent},
+		{"Replayed nonce", map[string]string{HeaderXNonce: "n1", "X-Sender": "a"}, StatusConflict},
+		{"Same nonce from another sender", map[string]string{HeaderXNonce: "n1", "X-Sender": "b"}, StatusNoContent},
+		{"Current timestamp", map[string]string{HeaderXNonce: "n2", HeaderXTimestamp: now}, StatusNoContent},
+		{"Stale timestamp", map[string]string{HeaderXNonce: "n3", HeaderXTimestamp: stale}, StatusBadRequest},
+		{"Malformed timestamp", map[string]string{HeaderXNonce: "n4", HeaderXTimestamp: "yesterday"}, StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqCtx := newProxiedRequest(MethodPost, "/hooks", "203.0.113.5", tt.headers)
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.code, reqCtx.Response.StatusCode())
+		})
+	}
+}
+
+func TestNonceFromQueryAndRequiredTimestamp(t *testing.T) {
+	app := New()
+	app.GET("/download", NonceWithConfig(NonceConfig{Query: "nonce", RequireTimestamp: true}), func(c *Context) {
+		c.Status(StatusOK)
+	})
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/download?nonce=once", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode(), "A timestamp should be required")
+
+	headers := map[string]string{HeaderXTimestamp: strconv.FormatInt(time.Now().Unix(), 10)}
+	reqCtx = newProxiedRequest(MethodGet, "/download?nonce=once", "203.0.113.5", headers)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+
+	reqCtx = newProxiedRequest(MethodGet, "/download?nonce=once", "203.0.113.5", headers)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusConflict, reqCtx.Response.StatusCode())
+}
+
+type failingNonceStore struct{}
+
+func (failingNonceStore) Use(string, time.Duration) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func TestNonceStoreFailure(t *testing.T) {
+	c, requestCtx := createTestContext()
+	requestCtx.Request.Header.Set(HeaderXNonce, "n")
+	NonceWithConfig(NonceConfig{Store: failingNonceStore{}})(c)
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, StatusInternalServerError, requestCtx.Response.StatusCode())
+}
+
+func TestMemoryNonceStore(t *testing.T) {
+	store := NewMemoryNonceStore()
+	fresh, err := store.Use("a", 20*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, fresh)
+	fresh, _ = store.Use("a", 20*time.Millisecond)
+	assert.False(t, fresh)
+
+	time.Sleep(30 * time.Millisecond)
+	fresh, _ = store.Use("a", time.Minute)
+	assert.True(t, fresh, "Expired nonces should be usable again")
+
+	var wg sync.WaitGroup
+	var accepted atomic.Int32
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := store.Use("race", time.Minute); ok {
+				accepted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), accepted.Load(), "Only one concurrent use should succeed")
+}
+
+// keepAliveClient serves app on an in-memory listener and returns a client sending every request
+// over a single keep-alive connection, so request buffers are reused between requests
+func keepAliveClient(t *testing.T, app *Gonoleks) *fasthttp.HostClient {
+	t.Helper()
+	app.setupRouter()
+	ln := fasthttputil.NewInmemoryListener()
+	go func() { _ = app.newHTTPServer().Serve(ln) }()
+	t.Cleanup(func() { _ = ln.Close() })
+	return &fasthttp.HostClient{
+		Addr:     "example.com",
+		MaxConns: 1,
+		Dial:     func(string) (net.Conn, error) { return ln.Dial() },
+	}
+}
+
+// keepAliveRequest sends a request with headers through client and returns the status code
+func keepAliveRequest(t *testing.T, client *fasthttp.HostClient, method, uri string, headers map[string]string) int {
+	t.Helper()
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+	req.Header.SetMethod(method)
+	req.SetRequestURI("http://example.com" + uri)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	require.NoError(t, client.Do(req, resp))
+	return resp.StatusCode()
+}
+
+func TestNonceKeepAlive(t *testing.T) {
+	store := NewMemoryNonceStore()
+	app := New()
+	app.POST("/hooks", NonceWithConfig(NonceConfig{Store: store}), func(c *Context) {
+		c.Status(StatusNoContent)
+	})
+	client := keepAliveClient(t, app)
+
+	for _, nonce := range []string{"aaaaaaaa", "bbbbbbbb"} {
+		assert.Equal(t, StatusNoContent, keepAliveRequest(t, client, MethodPost, "/hooks", map[string]string{HeaderXNonce: nonce}))
+	}
+	// Stored nonces must not be overwritten by later requests on the same connection
+	for _, nonce := range []string{"aaaaaaaa", "bbbbbbbb"} {
+		assert.Equal(t, StatusConflict, keepAliveRequest(t, client, MethodPost, "/hooks", map[string]string{HeaderXNonce: nonce}), nonce)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.Contains(t, store.nonces, "aaaaaaaa")
+	assert.Contains(t, store.nonces, "bbbbbbbb")
+}
@@ -0,0 +1,357 @@
+package gonoleks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// defaultOAuth2CookieName is the cookie that carries the state, PKCE verifier and nonce between login and callback
+	defaultOAuth2CookieName = "gonoleks_oauth2"
+
+	// oauth2CookieMaxAge bounds how long a login attempt may take
+	oauth2CookieMaxAge = 600
+
+	// oauth2TokenKey is the user value key under which the exchanged token is stored
+	oauth2TokenKey = "gonoleksOAuth2Token"
+)
+
+// OAuth2Config holds the settings of an OAuth2 / OpenID Connect provider
+// using the authorization code flow with PKCE
+type OAuth2Config struct {
+	// ClientID is the application's client id
+	ClientID string
+
+	// ClientSecret is the application's client secret, empty for public clients
+	ClientSecret string
+
+	// AuthURL is the provider's authorization endpoint
+	AuthURL string
+
+	// TokenURL is the provider's token endpoint
+	TokenURL string
+
+	// RedirectURL is the absolute URL of the callback route
+	RedirectURL string
+
+	// Scopes lists the requested scopes; including "openid" enables the nonce parameter
+	Scopes []string
+
+	// CookieName overrides the name of the cookie used to carry login state
+	CookieName string // Default = "gonoleks_oauth2"
+
+	// SecureCookie marks the login state cookie as Secure
+	SecureCookie bool
+
+	// CookieKey is the HMAC key signing the login state cookie, so clients cannot forge or alter it
+	// A random key is generated when empty, which requires the callback to reach the instance that served the login
+	CookieKey []byte
+
+	// OnToken is called after a successful code exchange and resolves the principal of the signed in user
+	// The ID token (if any) must be verified here, including the nonce found in token.Nonce
+	OnToken func(c *Context, token *OAuth2Token) (*Principal, error)
+
+	// Client performs the token requests; a default client is used when nil
+	Client *fasthttp.Client
+
+	// Timeout bounds token endpoint requests
+	Timeout time.Duration // Default = 10s
+}
+
+// OAuth2Token holds the result of a token endpoint request
+type OAuth2Token struct {
+	// AccessToken is the token used to call protected APIs
+	AccessToken string `json:"access_token"`
+
+	// TokenType is the type of the access token, usually "Bearer"
+	TokenType string `json:"token_type"`
+
+	// RefreshToken can be used to obtain a new access token
+	RefreshToken string `json:"refresh_token,omitempty"`
+
+	// IDToken is the OpenID Connect ID token, if requested
+	IDToken string `json:"id_token,omitempty"`
+
+	// ExpiresIn is the lifetime of the access token in seconds as reported by the provider
+	ExpiresIn int64 `json:"expires_in,omitempty"`
+
+	// Expiry is the absolute expiration time computed from ExpiresIn
+	Expiry time.Time `json:"-"`
+
+	// Nonce is the nonce sent with the authorization request, to be compared with the ID token claims
+	Nonce string `json:"-"`
+}
+
+// Valid reports whether the token has an access token that has not expired yet
+func (t *OAuth2Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Before(t.Expiry))
+}
+
+// LoginHandler returns a handler that starts the authorization code flow
+// It stores the state, PKCE verifier and nonce in a short-lived signed cookie and redirects to the provider
+//
+//	app.GET("/login", cfg.LoginHandler())
+func (cfg *OAuth2Config) LoginHandler() handlerFunc {
+	return func(c *Context) {
+		state, err := randomToken()
+		if err != nil {
+			_ = c.AbortWithError(StatusInternalServerError, err)
+			return
+		}
+		verifier, err := randomToken()
+		if err != nil {
+			_ = c.AbortWithError(StatusInternalServerError, err)
+			return
+		}
+		var nonce string
+		if slices.Contains(cfg.Scopes, "openid") {
+			if nonce, err = randomToken(); err != nil {
+				_ = c.AbortWithError(StatusInternalServerError, err)
+				return
+			}
+		}
+		cookie, err := cfg.signState(state, verifier, nonce, c.Now().Add(oauth2CookieMaxAge*time.Second))
+		if err != nil {
+			_ = c.AbortWithError(StatusInternalServerError, err)
+			return
+		}
+		c.SetCookie(cfg.cookieName(), cookie, oauth2CookieMaxAge, "/", "", cfg.SecureCookie, true)
+		c.Redirect(StatusFound, cfg.AuthCodeURL(state, verifier, nonce))
+		c.Abort()
+	}
+}
+
+// CallbackHandler returns a middleware that completes the authorization code flow
+// It validates the state, exchanges the code for a token and sets the principal resolved by OnToken
+// Handlers registered after it run only on success and decide what to do with the signed in user
+//
+//	app.GET("/callback", cfg.CallbackHandler(), func(c *gonoleks.Context) {
+//	    c.Redirect(gonoleks.StatusFound, "/")
+//	})
+func (cfg *OAuth2Config) CallbackHandler() handlerFunc {
+	return func(c *Context) {
+		cookie, err := c.Cookie(cfg.cookieName())
+		// Clear the login state cookie regardless of the outcome
+		c.SetCookie(cfg.cookieName(), "", -1, "/", "", cfg.SecureCookie, true)
+		if err != nil {
+			_ = c.AbortWithError(StatusBadRequest, ErrOAuth2StateMismatch)
+			return
+		}
+		parts, ok := cfg.verifyState(cookie, c.Now())
+		if !ok || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(c.Query("state"))) != 1 {
+			_ = c.AbortWithError(StatusBadRequest, ErrOAuth2StateMismatch)
+			return
+		}
+		if providerErr := c.Query("error"); providerErr != "" {
+			_ = c.AbortWithError(StatusUnauthorized, fmt.Errorf("%w: %s", ErrOAuth2AuthorizationDenied, providerErr))
+			return
+		}
+		code := c.Query("code")
+		if code == "" {
+			_ = c.AbortWithError(StatusBadRequest, ErrOAuth2MissingCode)
+			return
+		}
+		token, err := cfg.Exchange(code, parts[1])
+		if err != nil {
+			_ = c.AbortWithError(StatusBadGateway, err)
+			return
+		}
+		token.Nonce = parts[2]
+		c.requestCtx.SetUserValue(oauth2TokenKey, token)
+		if cfg.OnToken != nil {
+			principal, err := cfg.OnToken(c, token)
+			if err != nil {
+				_ = c.AbortWithError(StatusUnauthorized, err)
+				return
+			}
+			c.SetPrincipal(principal)
+		}
+		c.Next()
+	}
+}
+
+// AuthCodeURL builds the provider authorization URL for the given state, PKCE verifier and nonce
+func (cfg *OAuth2Config) AuthCodeURL(state, verifier, nonce string) string {
+	challenge := sha256.Sum256([]byte(verifier))
+	v := url.Values{}
+	v.Set("response_type", "code")
+	v.Set("client_id", cfg.ClientID)
+	v.Set("redirect_uri", cfg.RedirectURL)
+	v.Set("state", state)
+	v.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	v.Set("code_challenge_method", "S256")
+	if len(cfg.Scopes) > 0 {
+		v.Set("scope", strings.Join(cfg.Scopes, " "))
+	}
+	if nonce != "" {
+		v.Set("nonce", nonce)
+	}
+	separator := "?"
+	if strings.Contains(cfg.AuthURL, "?") {
+		separator = "&"
+	}
+	return cfg.AuthURL + separator + v.Encode()
+}
+
+// Exchange trades an authorization code and its PKCE verifier for a token
+func (cfg *OAuth2Config) Exchange(code, verifier string) (*OAuth2Token, error) {
+	v := url.Values{}
+	v.Set("grant_type", "authorization_code")
+	v.Set("code", code)
+	v.Set("redirect_uri", cfg.RedirectURL)
+	v.Set("code_verifier", verifier)
+	return cfg.requestToken(v)
+}
+
+// Refresh obtains a new token using a refresh token
+// Providers may omit the refresh token in the response, in which case the given one is kept
+func (cfg *OAuth2Config) Refresh(refreshToken string) (*OAuth2Token, error) {
+	v := url.Values{}
+	v.Set("grant_type", "refresh_token")
+	v.Set("refresh_token", refreshToken)
+	token, err := cfg.requestToken(v)
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+// OAuth2Token returns the token obtained by CallbackHandler for the current request, or nil
+func (c *Context) OAuth2Token() *OAuth2Token {
+	token, _ := c.requestCtx.UserValue(oauth2TokenKey).(*OAuth2Token)
+	return token
+}
+
+// requestToken posts the given form to the token endpoint and decodes the response
+func (cfg *OAuth2Config) requestToken(form url.Values) (*OAuth2Token, error) {
+	form.Set("client_id", cfg.ClientID)
+	if cfg.ClientSecret != "" {
+		form.Set("client_secret", cfg.ClientSecret)
+	}
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI(cfg.TokenURL)
+	req.Header.SetMethod(MethodPost)
+	req.Header.SetContentType(MIMEApplicationForm)
+	req.Header.Set(HeaderAccept, MIMEApplicationJSON)
+	req.SetBodyString(form.Encode())
+	client := cfg.Client
+	if client == nil {
+		client = defaultOAuth2Client
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	if err := client.DoTimeout(req, resp, timeout); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOAuth2TokenExchange, err)
+	}
+	if resp.StatusCode() != StatusOK {
+		return nil, fmt.Errorf("%w: token endpoint returned status %d", ErrOAuth2TokenExchange, resp.StatusCode())
+	}
+	token := &OAuth2Token{}
+	if err := sonic.Unmarshal(resp.Body(), token); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOAuth2TokenExchange, err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("%w: response has no access_token", ErrOAuth2TokenExchange)
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// signState encodes the login state as "state.verifier.nonce.expires.signature"
+// The signature covers the expiry, which bounds the attempt even if the client keeps the cookie
+func (cfg *OAuth2Config) signState(state, verifier, nonce string, expires time.Time) (string, error) {
+	payload := state + "." + verifier + "." + nonce + "." + strconv.FormatInt(expires.Unix(), 10)
+	signature, err := cfg.stateSignature(payload)
+	if err != nil {
+		return "", err
+	}
+	return payload + "." + signature, nil
+}
+
+// verifyState checks the signature and expiry of a login state cookie and returns its state, verifier and nonce
+func (cfg *OAuth2Config) verifyState(cookie string, now time.Time) ([]string, bool) {
+	i := strings.LastIndexByte(cookie, '.')
+	if i < 0 {
+		return nil, false
+	}
+	payload := cookie[:i]
+	expected, err := cfg.stateSignature(payload)
+	if err != nil || !hmac.Equal([]byte(cookie[i+1:]), []byte(expected)) {
+		return nil, false
+	}
+	parts := strings.Split(payload, ".")
+	if len(parts) != 4 {
+		return nil, false
+	}
+	expires, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return nil, false
+	}
+	return parts[:3], true
+}
+
+// stateSignature computes the base64url encoded HMAC-SHA256 signature of a login state
+func (cfg *OAuth2Config) stateSignature(payload string) (string, error) {
+	key := cfg.CookieKey
+	if len(key) == 0 {
+		var err error
+		if key, err = oauth2CookieKey(); err != nil {
+			return "", err
+		}
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// oauth2CookieKey is the random key signing the login state of configs without a CookieKey
+var oauth2CookieKey = sync.OnceValues(func() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+})
+
+// cookieName returns the configured login state cookie name or the default
+func (cfg *OAuth2Config) cookieName() string {
+	if cfg.CookieName != "" {
+		return cfg.CookieName
+	}
+	return defaultOAuth2CookieName
+}
+
+// defaultOAuth2Client is shared by configs that do not provide their own client
+var defaultOAuth2Client = &fasthttp.Client{}
+
+// randomToken returns 32 bytes of cryptographically secure randomness encoded as base64url
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,168 @@
+package gonoleks
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestOAuth2AuthCodeURL(t *testing.T) {
+	cfg := &OAuth2Config{
+		ClientID:    "client",
+		AuthURL:     "https://idp.example.com/authorize",
+		RedirectURL: "https://app.example.com/callback",
+		Scopes:      []string{"openid", "email"},
+	}
+	raw := cfg.AuthCodeURL("state123", "verifier", "nonce456")
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	q := u.Query()
+	assert.Equal(t, "code", q.Get("response_type"))
+	assert.Equal(t, "client", q.Get("client_id"))
+	assert.Equal(t, "state123", q.Get("state"))
+	assert.Equal(t, "nonce456", q.Get("nonce"))
+	assert.Equal(t, "openid email", q.Get("scope"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+	// SHA-256 of "verifier" encoded as base64url without padding
+	assert.Equal(t, "iMnq5o6zALKXGivsnlom_0F5_WYda32GHkxlV7mq7hQ", q.Get("code_challenge"))
+}
+
+func TestOAuth2LoginAndCallback(t *testing.T) {
+	var receivedVerifier string
+	client := startTestUpstream(t, func(ctx *fasthttp.RequestCtx) {
+		args := ctx.PostArgs()
+		if string(args.Peek("code")) != "good-code" {
+			ctx.SetStatusCode(StatusBadRequest)
+			return
+		}
+		receivedVerifier = string(args.Peek("code_verifier"))
+		ctx.SetContentType(MIMEApplicationJSON)
+		ctx.SetBodyString(`{"access_token":"at","token_type":"Bearer","refresh_token":"rt","expires_in":3600}`)
+	})
+	cfg := &OAuth2Config{
+		ClientID:    "client",
+		AuthURL:     "https://idp.example.com/authorize",
+		TokenURL:    "http://idp/token",
+		RedirectURL: "https://app.example.com/callback",
+		Scopes:      []string{"openid"},
+		Client:      client,
+		OnToken: func(c *Context, token *OAuth2Token) (*Principal, error) {
+			return &Principal{ID: "user-" + token.AccessToken}, nil
+		},
+	}
+
+	clock := NewManualClock(time.Now())
+	app := New()
+	app.SetClock(clock)
+	app.GET("/login", cfg.LoginHandler())
+	app.GET("/callback", cfg.CallbackHandler(), func(c *Context) {
+		c.String(StatusOK, "%s %v", c.CurrentUser().ID, c.OAuth2Token().Valid())
+	})
+	app.setupRouter()
+
+	// Start the login flow
+	loginCtx := &fasthttp.RequestCtx{}
+	loginCtx.Request.SetRequestURI("/login")
+	loginCtx.Request.Header.SetMethod(MethodGet)
+	app.router.Handler(loginCtx)
+	assert.Equal(t, StatusFound, loginCtx.Response.StatusCode())
+	location, err := url.Parse(string(loginCtx.Response.Header.Peek(HeaderLocation)))
+	require.NoError(t, err)
+	state := location.Query().Get("state")
+	assert.NotEmpty(t, state)
+	assert.NotEmpty(t, location.Query().Get("nonce"))
+
+	cookie := &fasthttp.Cookie{}
+	cookie.SetKey(defaultOAuth2CookieName)
+	require.True(t, loginCtx.Response.Header.Cookie(cookie))
+	cookieValue := string(cookie.Value())
+
+	// Complete the flow with a matching state
+	callbackCtx := &fasthttp.RequestCtx{}
+	callbackCtx.Request.SetRequestURI("/callback?code=good-code&state=" + state)
+	callbackCtx.Request.Header.SetMethod(MethodGet)
+	callbackCtx.Request.Header.SetCookie(defaultOAuth2CookieName, cookieValue)
+	app.router.Handler(callbackCtx)
+	assert.Equal(t, StatusOK, callbackCtx.Response.StatusCode())
+	assert.Equal(t, "user-at true", string(callbackCtx.Response.Body()))
+	assert.Equal(t, strings.Split(cookieValue, ".")[1], receivedVerifier, "PKCE verifier should be sent to the token endpoint")
+
+	// A forged state is rejected
+	forgedCtx := &fasthttp.RequestCtx{}
+	forgedCtx.Request.SetRequestURI("/callback?code=good-code&state=forged")
+	forgedCtx.Request.Header.SetMethod(MethodGet)
+	forgedCtx.Request.Header.SetCookie(defaultOAuth2CookieName, cookieValue)
+	app.router.Handler(forgedCtx)
+	assert.Equal(t, StatusBadRequest, forgedCtx.Response.StatusCode())
+
+	callback := func(cookieValue string) int {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI("/callback?code=good-code&state=" + state)
+		reqCtx.Request.Header.SetMethod(MethodGet)
+		reqCtx.Request.Header.SetCookie(defaultOAuth2CookieName, cookieValue)
+		app.router.Handler(reqCtx)
+		return reqCtx.Response.StatusCode()
+	}
+
+	// A cookie that was not signed by the app is rejected
+	parts := strings.Split(cookieValue, ".")
+	assert.Equal(t, StatusBadRequest, callback(state+".attacker-verifier."+parts[2]))
+	parts[1] = "attacker-verifier"
+	assert.Equal(t, StatusBadRequest, callback(strings.Join(parts, ".")))
+
+	// So is an expired one
+	clock.Advance((oauth2CookieMaxAge + 1) * time.Second)
+	assert.Equal(t, StatusBadRequest, callback(cookieValue))
+}
+
+func TestOAuth2StateCookie(t *testing.T) {
+	now := time.Now()
+	cfg := &OAuth2Config{CookieKey: []byte("key")}
+	cookie, err := cfg.signState("state", "verifier", "", now.Add(time.Minute))
+	require.NoError(t, err)
+	parts, ok := cfg.verifyState(cookie, now)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"state", "verifier", ""}, parts)
+
+	_, ok = (&OAuth2Config{CookieKey: []byte("other")}).verifyState(cookie, now)
+	assert.False(t, ok)
+	_, ok = cfg.verifyState(cookie, now.Add(2*time.Minute))
+	assert.False(t, ok)
+	_, ok = cfg.verifyState("state.verifier.", now)
+	assert.False(t, ok)
+
+	// Configs without a key share a random one
+	cookie, err = (&OAuth2Config{}).signState("state", "verifier", "nonce", now.Add(time.Minute))
+	require.NoError(t, err)
+	_, ok = (&OAuth2Config{}).verifyState(cookie, now)
+	assert.True(t, ok)
+}
+
+func TestOAuth2Refresh(t *testing.T) {
+	client := startTestUpstream(t, func(ctx *fasthttp.RequestCtx) {
+		if string(ctx.PostArgs().Peek("grant_type")) != "refresh_token" {
+			ctx.SetStatusCode(StatusBadRequest)
+			return
+		}
+		ctx.SetBodyString(`{"access_token":"new","token_type":"Bearer"}`)
+	})
+	cfg := &OAuth2Config{ClientID: "client", TokenURL: "http://idp/token", Client: client}
+
+	token, err := cfg.Refresh("rt")
+	require.NoError(t, err)
+	assert.Equal(t, "new", token.AccessToken)
+	assert.Equal(t, "rt", token.RefreshToken, "Refresh token should be kept when not rotated")
+
+	cfg.TokenURL = "http://idp/other"
+	client2 := startTestUpstream(t, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(StatusUnauthorized)
+	})
+	cfg.Client = client2
+	_, err = cfg.Refresh("rt")
+	assert.ErrorIs(t, err, ErrOAuth2TokenExchange)
+}
@@ -0,0 +1,601 @@
+package gonoleks
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/valyala/fasthttp"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPISpec is the subset of an OpenAPI 3 document used for request validation
+type OpenAPISpec struct {
+	Paths      map[string]*OpenAPIPathItem `yaml:"paths"`
+	Components struct {
+		Schemas map[string]*OpenAPISchema `yaml:"schemas"`
+	} `yaml:"components"`
+	// compiled holds the path templates split into segments for matching
+	compiled []openAPIRoute
+}
+
+// OpenAPIPathItem describes the operations available on a single path
+type OpenAPIPathItem struct {
+	Parameters []*OpenAPIParameter `yaml:"parameters"`
+	Get        *OpenAPIOperation   `yaml:"get"`
+	Put        *OpenAPIOperation   `yaml:"put"`
+	Post       *OpenAPIOperation   `yaml:"post"`
+	Delete     *OpenAPIOperation   `yaml:"delete"`
+	Options    *OpenAPIOperation   `yaml:"options"`
+	Head       *OpenAPIOperation   `yaml:"head"`
+	Patch      *OpenAPIOperation   `yaml:"patch"`
+	Trace      *OpenAPIOperation   `yaml:"trace"`
+	operations map[string]*OpenAPIOperation
+}
+
+// OpenAPIOperation describes a single API operation on a path
+type OpenAPIOperation struct {
+	OperationID string                      `yaml:"operationId"`
+	Parameters  []*OpenAPIParameter         `yaml:"parameters"`
+	RequestBody *OpenAPIRequestBody         `yaml:"requestBody"`
+	Responses   map[string]*OpenAPIResponse `yaml:"responses"`
+}
+
+// OpenAPIParameter describes a path, query, header or cookie parameter
+type OpenAPIParameter struct {
+	Name     string         `yaml:"name"`
+	In       string         `yaml:"in"`
+	Required bool           `yaml:"required"`
+	Schema   *OpenAPISchema `yaml:"schema"`
+}
+
+// OpenAPIRequestBody describes the expected request body
+type OpenAPIRequestBody struct {
+	Required bool                         `yaml:"required"`
+	Content  map[string]*OpenAPIMediaType `yaml:"content"`
+}
+
+// OpenAPIResponse describes a response of an operation
+type OpenAPIResponse struct {
+	Content map[string]*OpenAPIMediaType `yaml:"content"`
+}
+
+// OpenAPIMediaType holds the schema of a media type
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `yaml:"schema"`
+}
+
+// OpenAPISchema is the subset of JSON Schema supported by the validator
+type OpenAPISchema struct {
+	Ref                  string                       `yaml:"$ref"`
+	Type                 string                       `yaml:"type"`
+	Format               string                       `yaml:"format"`
+	Nullable             bool                         `yaml:"nullable"`
+	Enum                 []any                        `yaml:"enum"`
+	Required             []string                     `yaml:"required"`
+	Properties           map[string]*OpenAPISchema    `yaml:"properties"`
+	AdditionalProperties *OpenAPIAdditionalProperties `yaml:"additionalProperties"`
+	Items                *OpenAPISchema               `yaml:"items"`
+	Minimum              *float64                     `yaml:"minimum"`
+	Maximum              *float64                     `yaml:"maximum"`
+	MinLength            *int                         `yaml:"minLength"`
+	MaxLength            *int                         `yaml:"maxLength"`
+	MinItems             *int                         `yaml:"minItems"`
+	MaxItems             *int                         `yaml:"maxItems"`
+	Pattern              string                       `yaml:"pattern"`
+	pattern              *regexp.Regexp
+}
+
+// OpenAPIAdditionalProperties is the additionalProperties keyword of an object schema,
+// written in documents as either a boolean or a schema for the values of unlisted properties
+type OpenAPIAdditionalProperties struct {
+	// Allowed reports whether properties missing from Properties are accepted
+	Allowed bool
+
+	// Schema validates the values of the additional properties when set
+	Schema *OpenAPISchema
+}
+
+// UnmarshalYAML decodes either a boolean or a schema
+func (ap *OpenAPIAdditionalProperties) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!bool" {
+		*ap = OpenAPIAdditionalProperties{}
+		return node.Decode(&ap.Allowed)
+	}
+	schema := &OpenAPISchema{}
+	if err := node.Decode(schema); err != nil {
+		return err
+	}
+	*ap = OpenAPIAdditionalProperties{Allowed: true, Schema: schema}
+	return nil
+}
+
+// UnmarshalJSON decodes either a boolean or a schema
+func (ap *OpenAPIAdditionalProperties) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if sonic.Unmarshal(data, &allowed) == nil {
+		*ap = OpenAPIAdditionalProperties{Allowed: allowed}
+		return nil
+	}
+	schema := &OpenAPISchema{}
+	if err := sonic.Unmarshal(data, schema); err != nil {
+		return err
+	}
+	*ap = OpenAPIAdditionalProperties{Allowed: true, Schema: schema}
+	return nil
+}
+
+// MarshalJSON encodes the schema when set, and the boolean otherwise
+func (ap OpenAPIAdditionalProperties) MarshalJSON() ([]byte, error) {
+	if ap.Schema != nil {
+		return sonic.Marshal(ap.Schema)
+	}
+	return sonic.Marshal(ap.Allowed)
+}
+
+// OpenAPIConfig defines the config for the OpenAPI validator middleware
+type OpenAPIConfig struct {
+	// Spec is the loaded OpenAPI document
+	Spec *OpenAPISpec
+
+	// ValidateResponses additionally validates JSON response bodies and logs mismatches
+	// It is meant for development since it decodes every response
+	ValidateResponses bool
+
+	// AllowUnknownRoutes lets requests that match no documented path through instead of rejecting them with 404
+	AllowUnknownRoutes bool
+}
+
+// ValidationProblem describes a single validation failure
+type ValidationProblem struct {
+	// In is the location of the invalid value: path, query, header, cookie or body
+	In string `json:"in"`
+
+	// Name is the parameter name or the JSON pointer of the invalid body value
+	Name string `json:"name"`
+
+	// Message explains why the value is invalid
+	Message string `json:"message"`
+}
+
+// openAPIRoute is a compiled path template
+type openAPIRoute struct {
+	item     *OpenAPIPathItem
+	segments []string
+}
+
+// LoadOpenAPISpec parses an OpenAPI 3 document in YAML or JSON format
+func LoadOpenAPISpec(data []byte) (*OpenAPISpec, error) {
+	spec := &OpenAPISpec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOpenAPIInvalidSpec, err)
+	}
+	for template, item := range spec.Paths {
+		if item == nil {
+			continue
+		}
+		item.operations = map[string]*OpenAPIOperation{
+			MethodGet: item.Get, MethodPut: item.Put, MethodPost: item.Post, MethodDelete: item.Delete,
+			MethodOptions: item.Options, MethodHead: item.Head, MethodPatch: item.Patch, MethodTrace: item.Trace,
+		}
+		spec.compiled = append(spec.compiled, openAPIRoute{
+			item:     item,
+			segments: strings.Split(strings.Trim(template, "/"), "/"),
+		})
+	}
+	if err := spec.compilePatterns(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// OpenAPIValidator returns a middleware validating requests against the given spec
+func OpenAPIValidator(spec *OpenAPISpec) handlerFunc {
+	return OpenAPIValidatorWithConfig(OpenAPIConfig{Spec: spec})
+}
+
+// OpenAPIValidatorWithConfig returns a middleware validating requests against the configured spec
+// Invalid requests are rejected with 400 and an application/problem+json body listing every problem
+func OpenAPIValidatorWithConfig(conf OpenAPIConfig) handlerFunc {
+	spec := conf.Spec
+	return func(c *Context) {
+		method := getString(c.requestCtx.Method())
+		item, pathParams := spec.findPath(getString(c.requestCtx.Path()))
+		var op *OpenAPIOperation
+		if item != nil {
+			op = item.operations[method]
+		}
+		if op == nil {
+			if conf.AllowUnknownRoutes {
+				c.Next()
+				return
+			}
+			c.requestCtx.Error(fasthttp.StatusMessage(StatusNotFound), StatusNotFound)
+			c.Abort()
+			return
+		}
+		problems := spec.validateRequest(c, item, op, pathParams)
+		if len(problems) > 0 {
+			_ = c.AbortWithStatusProblem(StatusBadRequest, "request validation failed", problems)
+			return
+		}
+		c.Next()
+		if conf.ValidateResponses {
+			for _, p := range spec.validateResponse(c, op) {
+				c.diagnostics().Warn("Response does not match OpenAPI spec", "method", method, "path", string(c.requestCtx.Path()), "in", p.In, "name", p.Name, "message", p.Message)
+			}
+		}
+	}
+}
+
+// findPath matches a request path against the documented path templates
+func (spec *OpenAPISpec) findPath(path string) (*OpenAPIPathItem, map[string]string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var best *openAPIRoute
+	var bestParams map[string]string
+	bestStatic := -1
+	for i := range spec.compiled {
+		route := &spec.compiled[i]
+		if len(route.segments) != len(segments) {
+			continue
+		}
+		params := map[string]string{}
+		static := 0
+		matched := true
+		for j, segment := range route.segments {
+			if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+				params[segment[1:len(segment)-1]] = segments[j]
+				continue
+			}
+			if segment != segments[j] {
+				matched = false
+				break
+			}
+			static++
+		}
+		// Prefer the template with the most literal segments, like the router does
+		if matched && static > bestStatic {
+			best, bestParams, bestStatic = route, params, static
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return best.item, bestParams
+}
+
+// validateRequest checks parameters and body of the request against the operation
+func (spec *OpenAPISpec) validateRequest(c *Context, item *OpenAPIPathItem, op *OpenAPIOperation, pathParams map[string]string) []ValidationProblem {
+	var problems []ValidationProblem
+	params := make(map[string]*OpenAPIParameter, len(item.Parameters)+len(op.Parameters))
+	// Operation level parameters override path level ones with the same name and location
+	for _, p := range item.Parameters {
+		params[p.In+":"+p.Name] = p
+	}
+	for _, p := range op.Parameters {
+		params[p.In+":"+p.Name] = p
+	}
+	for _, p := range params {
+		var values []string
+		switch p.In {
+		case "path":
+			if v, ok := pathParams[p.Name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			values = c.QueryArray(p.Name)
+		case "header":
+			if v := c.requestCtx.Request.Header.Peek(p.Name); v != nil {
+				values = []string{string(v)}
+			}
+		case "cookie":
+			if v := c.requestCtx.Request.Header.Cookie(p.Name); v != nil {
+				values = []string{string(v)}
+			}
+		}
+		if len(values) == 0 {
+			if p.Required || p.In == "path" {
+				problems = append(problems, ValidationProblem{In: p.In, Name: p.Name, Message: "is required"})
+			}
+			continue
+		}
+		if p.Schema == nil {
+			continue
+		}
+		schema := spec.resolve(p.Schema)
+		var value any
+		if schema.Type == "array" {
+			items := make([]any, 0, len(values))
+			for _, v := range values {
+				items = append(items, coerceParam(spec.resolve(schema.Items), v))
+			}
+			value = items
+		} else {
+			value = coerceParam(schema, values[0])
+		}
+		problems = spec.validateValue(schema, value, p.In, p.Name, problems)
+	}
+	if op.RequestBody != nil {
+		problems = spec.validateBody(c, op.RequestBody, problems)
+	}
+	return problems
+}
+
+// validateBody checks the request body against the JSON schema of the request body definition
+func (spec *OpenAPISpec) validateBody(c *Context, rb *OpenAPIRequestBody, problems []ValidationProblem) []ValidationProblem {
+	body := c.requestCtx.Request.Body()
+	if len(body) == 0 {
+		if rb.Required {
+			problems = append(problems, ValidationProblem{In: "body", Name: "", Message: "is required"})
+		}
+		return problems
+	}
+	contentType := c.ContentType()
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.TrimSpace(contentType)
+	media, ok := rb.Content[contentType]
+	if !ok {
+		return append(problems, ValidationProblem{In: "header", Name: HeaderContentType, Message: fmt.Sprintf("unsupported media type %q", contentType)})
+	}
+	if media == nil || media.Schema == nil || !isJSONMediaType(contentType) {
+		return problems
+	}
+	var value any
+	if err := sonic.Unmarshal(body, &value); err != nil {
+		return append(problems, ValidationProblem{In: "body", Name: "", Message: "malformed JSON"})
+	}
+	return spec.validateValue(spec.resolve(media.Schema), value, "body", "", problems)
+}
+
+// validateResponse checks a JSON response body against the documented response schema
+func (spec *OpenAPISpec) validateResponse(c *Context, op *OpenAPIOperation) []ValidationProblem {
+	status := c.requestCtx.Response.StatusCode()
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		if resp, ok = op.Responses[strconv.Itoa(status/100)+"XX"]; !ok {
+			resp, ok = op.Responses["default"]
+		}
+	}
+	if !ok || resp == nil {
+		return []ValidationProblem{{In: "response", Name: "status", Message: fmt.Sprintf("status %d is not documented", status)}}
+	}
+	contentType := getString(c.requestCtx.Response.Header.ContentType())
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	media, ok := resp.Content[strings.TrimSpace(contentType)]
+	if !ok || media == nil || media.Schema == nil || !isJSONMediaType(contentType) {
+		return nil
+	}
+	var value any
+	if err := sonic.Unmarshal(c.requestCtx.Response.Body(), &value); err != nil {
+		return []ValidationProblem{{In: "response", Name: "", Message: "malformed JSON"}}
+	}
+	return spec.validateValue(spec.resolve(media.Schema), value, "response", "", nil)
+}
+
+// validateValue validates a decoded value against a schema, appending every problem found
+func (spec *OpenAPISpec) validateValue(schema *OpenAPISchema, value any, in, name string, problems []ValidationProblem) []ValidationProblem {
+	if schema == nil {
+		return problems
+	}
+	fail := func(format string, args ...any) []ValidationProblem {
+		return append(problems, ValidationProblem{In: in, Name: name, Message: fmt.Sprintf(format, args...)})
+	}
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return problems
+		}
+		return fail("must not be null")
+	}
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		return fail("must be one of %v", schema.Enum)
+	}
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return fail("must be an object")
+		}
+		for _, required := range schema.Required {
+			if _, exists := obj[required]; !exists {
+				problems = append(problems, ValidationProblem{In: in, Name: name + "/" + required, Message: "is required"})
+			}
+		}
+		for key, v := range obj {
+			child, known := schema.Properties[key]
+			if !known {
+				if additional := schema.AdditionalProperties; additional != nil {
+					if !additional.Allowed {
+						problems = append(problems, ValidationProblem{In: in, Name: name + "/" + key, Message: "is not allowed"})
+					} else {
+						problems = spec.validateValue(spec.resolve(additional.Schema), v, in, name+"/"+key, problems)
+					}
+				}
+				continue
+			}
+			problems = spec.validateValue(spec.resolve(child), v, in, name+"/"+key, problems)
+		}
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			return fail("must be an array")
+		}
+		if schema.MinItems != nil && len(arr) < *schema.MinItems {
+			return fail("must contain at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(arr) > *schema.MaxItems {
+			return fail("must contain at most %d items", *schema.MaxItems)
+		}
+		for i, v := range arr {
+			problems = spec.validateValue(spec.resolve(schema.Items), v, in, name+"/"+strconv.Itoa(i), problems)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fail("must be a string")
+		}
+		length := len([]rune(s))
+		if schema.MinLength != nil && length < *schema.MinLength {
+			return fail("must be at least %d characters long", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			return fail("must be at most %d characters long", *schema.MaxLength)
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(s) {
+			return fail("must match pattern %q", schema.Pattern)
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			return fail("must be a %s", schema.Type)
+		}
+		if schema.Type == "integer" && n != math.Trunc(n) {
+			return fail("must be an integer")
+		}
+		if schema.Minimum != nil && n < *schema.Minimum {
+			return fail("must be greater than or equal to %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			return fail("must be less than or equal to %v", *schema.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fail("must be a boolean")
+		}
+	}
+	return problems
+}
+
+// resolve follows local component references such as "#/components/schemas/User"
+func (spec *OpenAPISpec) resolve(schema *OpenAPISchema) *OpenAPISchema {
+	for depth := 0; schema != nil && schema.Ref != "" && depth < 32; depth++ {
+		name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		if !ok {
+			return nil
+		}
+		schema = spec.Components.Schemas[name]
+	}
+	return schema
+}
+
+// compilePatterns pre-compiles every string pattern of the document
+func (spec *OpenAPISpec) compilePatterns() error {
+	seen := map[*OpenAPISchema]bool{}
+	var walk func(s *OpenAPISchema) error
+	walk = func(s *OpenAPISchema) error {
+		if s == nil || seen[s] {
+			return nil
+		}
+		seen[s] = true
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrOpenAPIInvalidSpec, err)
+			}
+			s.pattern = re
+		}
+		for _, p := range s.Properties {
+			if err := walk(p); err != nil {
+				return err
+			}
+		}
+		if s.AdditionalProperties != nil {
+			if err := walk(s.AdditionalProperties.Schema); err != nil {
+				return err
+			}
+		}
+		return walk(s.Items)
+	}
+	for _, s := range spec.Components.Schemas {
+		if err := walk(s); err != nil {
+			return err
+		}
+	}
+	for _, item := range spec.Paths {
+		if item == nil {
+			continue
+		}
+		for _, p := range item.Parameters {
+			if err := walk(p.Schema); err != nil {
+				return err
+			}
+		}
+		for _, op := range item.operations {
+			if op == nil {
+				continue
+			}
+			for _, p := range op.Parameters {
+				if err := walk(p.Schema); err != nil {
+					return err
+				}
+			}
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					if media != nil {
+						if err := walk(media.Schema); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			for _, resp := range op.Responses {
+				if resp == nil {
+					continue
+				}
+				for _, media := range resp.Content {
+					if media != nil {
+						if err := walk(media.Schema); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// coerceParam converts a raw parameter string into the JSON type declared by the schema
+// Values that cannot be converted are returned unchanged so that validation reports them
+func coerceParam(schema *OpenAPISchema, raw string) any {
+	if schema == nil {
+		return raw
+	}
+	switch schema.Type {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// enumContains reports whether value equals one of the enum entries
+func enumContains(enum []any, value any) bool {
+	for _, e := range enum {
+		// YAML decodes integers as int while JSON bodies decode numbers as float64
+		if i, ok := e.(int); ok {
+			e = float64(i)
+		}
+		if e == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isJSONMediaType reports whether the media type carries JSON
+func isJSONMediaType(contentType string) bool {
+	contentType = strings.TrimSpace(contentType)
+	return contentType == MIMEApplicationJSON || strings.HasSuffix(contentType, "+json")
+}
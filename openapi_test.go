@@ -0,0 +1,195 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOpenAPISpec = `
+openapi: 3.0.3
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+          minimum: 1
+    get:
+      parameters:
+        - name: fields
+          in: query
+          schema:
+            type: string
+            enum: [name, email]
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/User"
+  /users:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/User"
+components:
+  schemas:
+    User:
+      type: object
+      required: [name]
+      additionalProperties: false
+      properties:
+        name:
+          type: string
+          minLength: 2
+        email:
+          type: string
+          pattern: "^[^@]+@[^@]+$"
+        tags:
+          type: array
+          items:
+            type: string
+        labels:
+          type: object
+          additionalProperties:
+            type: integer
+`
+
+func TestLoadOpenAPISpec(t *testing.T) {
+	spec, err := LoadOpenAPISpec([]byte(testOpenAPISpec))
+	require.NoError(t, err)
+	assert.Len(t, spec.Paths, 2)
+	assert.NotNil(t, spec.Components.Schemas["User"])
+
+	item, params := spec.findPath("/users/42")
+	require.NotNil(t, item)
+	assert.Equal(t, "42", params["id"])
+	item, _ = spec.findPath("/unknown")
+	assert.Nil(t, item)
+
+	user := spec.Components.Schemas["User"]
+	require.NotNil(t, user.AdditionalProperties)
+	assert.False(t, user.AdditionalProperties.Allowed)
+	labels := user.Properties["labels"].AdditionalProperties
+	require.NotNil(t, labels)
+	assert.True(t, labels.Allowed)
+	assert.Equal(t, "integer", labels.Schema.Type)
+
+	var additional OpenAPIAdditionalProperties
+	require.NoError(t, sonic.Unmarshal([]byte(`{"Type":"string"}`), &additional))
+	assert.Equal(t, OpenAPIAdditionalProperties{Allowed: true, Schema: &OpenAPISchema{Type: "string"}}, additional)
+	require.NoError(t, sonic.Unmarshal([]byte(`false`), &additional))
+	assert.Equal(t, OpenAPIAdditionalProperties{}, additional)
+	encoded, err := sonic.Marshal(OpenAPIAdditionalProperties{Allowed: true})
+	require.NoError(t, err)
+	assert.Equal(t, "true", string(encoded))
+
+	_, err = LoadOpenAPISpec([]byte("paths: [unclosed"))
+	assert.ErrorIs(t, err, ErrOpenAPIInvalidSpec)
+	_, err = LoadOpenAPISpec([]byte("components:\n  schemas:\n    Bad:\n      type: string\n      pattern: \"(\"\n"))
+	assert.ErrorIs(t, err, ErrOpenAPIInvalidSpec)
+}
+
+func TestOpenAPIValidator(t *testing.T) {
+	spec, err := LoadOpenAPISpec([]byte(testOpenAPISpec))
+	require.NoError(t, err)
+
+	app := New()
+	app.Use(OpenAPIValidator(spec))
+	app.GET("/users/:id", func(c *Context) {
+		c.String(StatusOK, "user %s", c.Param("id"))
+	})
+	app.POST("/users", func(c *Context) {
+		c.String(StatusCreated, "created")
+	})
+	app.GET("/undocumented", func(c *Context) {
+		c.String(StatusOK, "ok")
+	})
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/users/7?fields=name", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+
+	reqCtx = newProxiedRequest(MethodGet, "/users/abc?fields=phone", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode())
+	assert.Equal(t, MIMEApplicationProblemJSON, string(reqCtx.Response.Header.ContentType()))
+	var problem struct {
+		Status int                 `json:"status"`
+		Errors []ValidationProblem `json:"errors"`
+	}
+	require.NoError(t, sonic.Unmarshal(reqCtx.Response.Body(), &problem))
+	assert.Equal(t, StatusBadRequest, problem.Status)
+	assert.Len(t, problem.Errors, 2, "Both the path and the query parameter should be reported")
+
+	reqCtx = newProxiedRequest(MethodPost, "/users", "203.0.113.5", map[string]string{HeaderContentType: MIMEApplicationJSON})
+	reqCtx.Request.SetBodyString(`{"name":"Jo","email":"jo@example.com","tags":["a"],"labels":{"a":1}}`)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusCreated, reqCtx.Response.StatusCode())
+
+	reqCtx = newProxiedRequest(MethodPost, "/users", "203.0.113.5", map[string]string{HeaderContentType: MIMEApplicationJSON})
+	reqCtx.Request.SetBodyString(`{"email":"invalid","tags":[1],"extra":true,"labels":{"a":1,"b":"x"}}`)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode())
+	require.NoError(t, sonic.Unmarshal(reqCtx.Response.Body(), &problem))
+	names := make([]string, 0, len(problem.Errors))
+	for _, p := range problem.Errors {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"/name", "/email", "/tags/0", "/extra", "/labels/b"}, names)
+
+	reqCtx = newProxiedRequest(MethodPost, "/users", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode(), "Missing required body should be rejected")
+
+	reqCtx = newProxiedRequest(MethodPost, "/users", "203.0.113.5", map[string]string{HeaderContentType: MIMETextPlain})
+	reqCtx.Request.SetBodyString("name")
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode(), "Undocumented media type should be rejected")
+
+	reqCtx = newProxiedRequest(MethodGet, "/undocumented", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusNotFound, reqCtx.Response.StatusCode(), "Undocumented routes should be rejected by default")
+}
+
+func TestOpenAPIValidatorWithConfig(t *testing.T) {
+	spec, err := LoadOpenAPISpec([]byte(testOpenAPISpec))
+	require.NoError(t, err)
+
+	app := New()
+	app.Use(OpenAPIValidatorWithConfig(OpenAPIConfig{
+		Spec:               spec,
+		ValidateResponses:  true,
+		AllowUnknownRoutes: true,
+	}))
+	app.GET("/users/:id", func(c *Context) {
+		_ = c.JSON(StatusOK, H{"name": 1})
+	})
+	app.GET("/undocumented", func(c *Context) {
+		c.String(StatusOK, "ok")
+	})
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/undocumented", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+
+	// Response mismatches are only logged
+	reqCtx = newProxiedRequest(MethodGet, "/users/1", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	op := spec.Paths["/users/{id}"].Get
+	ctx := &Context{requestCtx: reqCtx}
+	problems := spec.validateResponse(ctx, op)
+	require.Len(t, problems, 1)
+	assert.Equal(t, "/name", problems[0].Name)
+}
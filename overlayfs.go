@@ -0,0 +1,157 @@
+package gonoleks
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// OverlayFS composes several file systems into one
+// Layers are searched in order, so files in earlier layers override files in later ones,
+// and directory listings merge the entries of every layer
+// The layers can be replaced at runtime, e.g. to switch between disk and embedded assets
+type OverlayFS struct {
+	mu     sync.RWMutex
+	layers []fs.FS
+}
+
+// NewOverlayFS returns a file system serving files from the first layer that has them
+//
+//	//go:embed assets
+//	var embedded embed.FS
+//
+//	assets, _ := fs.Sub(embedded, "assets")
+//	app.StaticFS("/assets", gonoleks.NewOverlayFS(os.DirFS("./overrides"), assets))
+func NewOverlayFS(layers ...fs.FS) *OverlayFS {
+	return &OverlayFS{layers: slices.Clone(layers)}
+}
+
+// SetLayers replaces the layers of the overlay
+// Note that StaticFS caches open files for a few seconds, so changes may not be visible immediately
+//
+//	if devMode {
+//		assets.SetLayers(os.DirFS("./assets"))
+//	} else {
+//		assets.SetLayers(embeddedAssets)
+//	}
+func (o *OverlayFS) SetLayers(layers ...fs.FS) {
+	o.mu.Lock()
+	o.layers = slices.Clone(layers)
+	o.mu.Unlock()
+}
+
+// Layers returns the current layers of the overlay
+func (o *OverlayFS) Layers() []fs.FS {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return slices.Clone(o.layers)
+}
+
+// Open implements fs.FS
+func (o *OverlayFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	for _, layer := range o.Layers() {
+		f, err := layer.Open(name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		if !info.IsDir() {
+			return f, nil
+		}
+		// Directories list the merged entries of every layer
+		entries, err := o.ReadDir(name)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return &overlayDir{File: f, entries: entries}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Stat implements fs.StatFS
+func (o *OverlayFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	for _, layer := range o.Layers() {
+		info, err := fs.Stat(layer, name)
+		if err == nil {
+			return info, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir implements fs.ReadDirFS
+// Entries from every layer are merged, earlier layers winning on name conflicts
+func (o *OverlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	seen := make(map[string]struct{})
+	var entries []fs.DirEntry
+	found := false
+	for _, layer := range o.Layers() {
+		layerEntries, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, entry := range layerEntries {
+			if _, ok := seen[entry.Name()]; ok {
+				continue
+			}
+			seen[entry.Name()] = struct{}{}
+			entries = append(entries, entry)
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// overlayDir is an open directory of an OverlayFS
+type overlayDir struct {
+	fs.File
+	entries []fs.DirEntry
+	offset  int
+}
+
+// ReadDir implements fs.ReadDirFile over the merged entries
+func (d *overlayDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(remaining))
+	d.offset += n
+	return remaining[:n], nil
+}
@@ -0,0 +1,70 @@
+package gonoleks
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestOverlayFS(t *testing.T) {
+	local := fstest.MapFS{
+		"app.css":      {Data: []byte("local css")},
+		"img/logo.svg": {Data: []byte("local logo")},
+	}
+	embedded := fstest.MapFS{
+		"app.css":      {Data: []byte("embedded css")},
+		"app.js":       {Data: []byte("embedded js")},
+		"img/icon.svg": {Data: []byte("embedded icon")},
+	}
+	overlay := NewOverlayFS(local, embedded)
+
+	data, err := fs.ReadFile(overlay, "app.css")
+	require.NoError(t, err)
+	assert.Equal(t, "local css", string(data), "Earlier layers should override later ones")
+	data, err = fs.ReadFile(overlay, "app.js")
+	require.NoError(t, err)
+	assert.Equal(t, "embedded js", string(data))
+
+	_, err = overlay.Open("missing.txt")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = overlay.Open("../escape")
+	assert.ErrorIs(t, err, fs.ErrInvalid)
+
+	entries, err := fs.ReadDir(overlay, "img")
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"icon.svg", "logo.svg"}, names)
+
+	// Switching layers at runtime
+	overlay.SetLayers(embedded)
+	data, err = fs.ReadFile(overlay, "app.css")
+	require.NoError(t, err)
+	assert.Equal(t, "embedded css", string(data))
+
+	require.NoError(t, fstest.TestFS(NewOverlayFS(local, embedded), "app.css", "app.js", "img/logo.svg", "img/icon.svg"))
+}
+
+func TestOverlayFSWithStaticFS(t *testing.T) {
+	app := New()
+	app.StaticFS("/assets", NewOverlayFS(
+		fstest.MapFS{"app.css": {Data: []byte("local css")}},
+		fstest.MapFS{"app.css": {Data: []byte("embedded css")}, "app.js": {Data: []byte("embedded js")}},
+	))
+	app.setupRouter()
+
+	for path, expected := range map[string]string{"/assets/app.css": "local css", "/assets/app.js": "embedded js"} {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(path)
+		reqCtx.Request.Header.SetMethod(MethodGet)
+		app.router.Handler(reqCtx)
+		assert.Equal(t, StatusOK, reqCtx.Response.StatusCode(), path)
+		assert.Equal(t, expected, string(reqCtx.Response.Body()), path)
+	}
+}
@@ -0,0 +1,247 @@
+package gonoleks
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PaginationConfig defines the query params, defaults and caps of Paginate
+type PaginationConfig struct {
+	// PageParam is the query param of the 1-based page number
+	PageParam string // Default = "page"
+
+	// LimitParam is the query param of the page size
+	LimitParam string // Default = "limit"
+
+	// CursorParam is the query param of an opaque cursor, which switches to cursor pagination
+	CursorParam string // Default = "cursor"
+
+	// DefaultLimit is the page size when the request does not give one
+	DefaultLimit int // Default = 20
+
+	// MaxLimit caps the page size, larger limits are lowered to it
+	MaxLimit int // Default = 100
+}
+
+// Pagination is the page of a list endpoint requested with query params, see Paginate
+type Pagination struct {
+	// Page is the 1-based page number, zero with cursor pagination
+	Page int
+
+	// Limit is the page size
+	Limit int
+
+	// Cursor is the cursor of the request, empty with page pagination
+	Cursor string
+
+	// NextCursor is the cursor of the next page, set with SetNextCursor
+	NextCursor string
+
+	// Total is the number of items of the list, negative when unknown, set with SetTotal
+	Total int
+
+	// HasMore reports whether pages follow, set by SetTotal and SetNextCursor,
+	// or directly, e.g. after fetching Limit+1 items
+	HasMore bool
+
+	config PaginationConfig
+	path   string
+	query  url.Values
+}
+
+// DefaultPaginationConfig is the default config of Paginate
+var DefaultPaginationConfig = PaginationConfig{
+	PageParam:    "page",
+	LimitParam:   "limit",
+	CursorParam:  "cursor",
+	DefaultLimit: 20,
+	MaxLimit:     100,
+}
+
+// Paginate parses the page, limit and cursor query params of the request,
+// so list endpoints share one pagination convention
+// A request with a cursor uses cursor pagination, otherwise page pagination
+// It fails with ErrInvalidPagination when a param is not a positive integer
+//
+//	p, err := gonoleks.Paginate(c)
+//	if err != nil {
+//	    _ = c.AbortWithError(gonoleks.StatusBadRequest, err)
+//	    return
+//	}
+//	users, total := db.ListUsers(p.Offset(), p.Limit)
+//	p.SetTotal(total).SetHeaders(c)
+//	_ = c.JSON(gonoleks.StatusOK, gonoleks.H{"data": users, "meta": p.Meta()})
+func Paginate(c *Context, defaults ...PaginationConfig) (*Pagination, error) {
+	config := DefaultPaginationConfig
+	if len(defaults) > 0 {
+		config = paginationConfigDefault(defaults[0])
+	}
+	p := &Pagination{
+		Page:   1,
+		Limit:  config.DefaultLimit,
+		Total:  -1,
+		config: config,
+		path:   getString(c.requestCtx.URI().Path()),
+		query:  c.QueryValues(),
+	}
+	if value := c.Query(config.LimitParam); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("%w: %s must be a positive integer", ErrInvalidPagination, config.LimitParam)
+		}
+		p.Limit = limit
+	}
+	p.Limit = min(p.Limit, config.MaxLimit)
+	if cursor := c.Query(config.CursorParam); cursor != "" {
+		p.Page = 0
+		p.Cursor = cursor
+		return p, nil
+	}
+	if value := c.Query(config.PageParam); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 || page-1 > math.MaxInt32/p.Limit {
+			return nil, fmt.Errorf("%w: %s must be a positive integer", ErrInvalidPagination, config.PageParam)
+		}
+		p.Page = page
+	}
+	return p, nil
+}
+
+// paginationConfigDefault fills the zero fields of config with DefaultPaginationConfig
+func paginationConfigDefault(config PaginationConfig) PaginationConfig {
+	if config.PageParam == "" {
+		config.PageParam = DefaultPaginationConfig.PageParam
+	}
+	if config.LimitParam == "" {
+		config.LimitParam = DefaultPaginationConfig.LimitParam
+	}
+	if config.CursorParam == "" {
+		config.CursorParam = DefaultPaginationConfig.CursorParam
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = DefaultPaginationConfig.MaxLimit
+	}
+	if config.DefaultLimit <= 0 {
+		config.DefaultLimit = DefaultPaginationConfig.DefaultLimit
+	}
+	config.DefaultLimit = min(config.DefaultLimit, config.MaxLimit)
+	return config
+}
+
+// Offset returns the number of items before the page, zero with cursor pagination
+func (p *Pagination) Offset() int {
+	if p.Page < 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.Limit
+}
+
+// TotalPages returns the number of pages, or -1 when the total is unknown
+func (p *Pagination) TotalPages() int {
+	if p.Total < 0 {
+		return -1
+	}
+	return (p.Total + p.Limit - 1) / p.Limit
+}
+
+// SetTotal sets the number of items of the list and whether pages follow
+func (p *Pagination) SetTotal(total int) *Pagination {
+	p.Total = total
+	if p.Page > 0 {
+		p.HasMore = p.Page < p.TotalPages()
+	}
+	return p
+}
+
+// SetNextCursor sets the cursor of the next page, empty on the last page
+func (p *Pagination) SetNextCursor(cursor string) *Pagination {
+	p.NextCursor = cursor
+	p.HasMore = cursor != ""
+	return p
+}
+
+// Links returns the URLs of the neighbouring pages by relation: first, prev, next and last
+// with page pagination, first and next with cursor pagination
+// The URLs are relative to the host and keep the other query params of the request
+func (p *Pagination) Links() map[string]string {
+	links := make(map[string]string, 4)
+	if p.Page == 0 {
+		links["first"] = p.link("", "")
+		if p.HasMore {
+			links["next"] = p.link(p.config.CursorParam, p.NextCursor)
+		}
+		return links
+	}
+	page := func(n int) string { return p.link(p.config.PageParam, strconv.Itoa(n)) }
+	links["first"] = page(1)
+	if p.Page > 1 {
+		links["prev"] = page(p.Page - 1)
+	}
+	if p.HasMore {
+		links["next"] = page(p.Page + 1)
+	}
+	if last := p.TotalPages(); last > 0 {
+		links["last"] = page(last)
+	}
+	return links
+}
+
+// link returns the URL of the request with the page and cursor params replaced by key=value
+func (p *Pagination) link(key, value string) string {
+	query := make(url.Values, len(p.query)+1)
+	for k, v := range p.query {
+		if k != p.config.PageParam && k != p.config.CursorParam {
+			query[k] = v
+		}
+	}
+	if p.query.Has(p.config.LimitParam) {
+		query.Set(p.config.LimitParam, strconv.Itoa(p.Limit))
+	}
+	if key != "" {
+		query.Set(key, value)
+	}
+	if len(query) == 0 {
+		return p.path
+	}
+	return p.path + "?" + query.Encode()
+}
+
+// SetHeaders sets the Link header with the page links and, when the total is known,
+// the X-Total-Count header
+func (p *Pagination) SetHeaders(c *Context) {
+	links := p.Links()
+	var header strings.Builder
+	for _, rel := range [...]string{"first", "prev", "next", "last"} {
+		if link, ok := links[rel]; ok {
+			if header.Len() > 0 {
+				header.WriteString(", ")
+			}
+			header.WriteString("<" + link + `>; rel="` + rel + `"`)
+		}
+	}
+	c.requestCtx.Response.Header.Set(HeaderLink, header.String())
+	if p.Total >= 0 {
+		c.requestCtx.Response.Header.Set(HeaderXTotalCount, strconv.Itoa(p.Total))
+	}
+}
+
+// Meta returns the pagination metadata to embed in a JSON response
+//
+//	{"page": 2, "limit": 20, "total": 95, "total_pages": 5, "has_more": true, "links": {...}}
+func (p *Pagination) Meta() H {
+	meta := H{"limit": p.Limit, "has_more": p.HasMore, "links": p.Links()}
+	if p.Page > 0 {
+		meta["page"] = p.Page
+	}
+	if p.NextCursor != "" {
+		meta["next_cursor"] = p.NextCursor
+	}
+	if p.Total >= 0 {
+		meta["total"] = p.Total
+		meta["total_pages"] = p.TotalPages()
+	}
+	return meta
+}
@@ -0,0 +1,83 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func paginationContext(uri string) *Context {
+	c, requestCtx := createTestContext()
+	requestCtx.Request.SetRequestURI(uri)
+	return c
+}
+
+func TestPaginate(t *testing.T) {
+	p, err := Paginate(paginationContext("/users"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.Page)
+	assert.Equal(t, 20, p.Limit)
+	assert.Equal(t, 0, p.Offset())
+	assert.Equal(t, -1, p.TotalPages())
+
+	p, err = Paginate(paginationContext("/users?page=3&limit=500"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, p.Page)
+	assert.Equal(t, 100, p.Limit)
+	assert.Equal(t, 200, p.Offset())
+
+	p, err = Paginate(paginationContext("/users?p=2&size=50"), PaginationConfig{PageParam: "p", LimitParam: "size", MaxLimit: 30})
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.Page)
+	assert.Equal(t, 30, p.Limit)
+
+	for _, uri := range []string{"/users?page=0", "/users?page=x", "/users?limit=-1", "/users?page=99999999999"} {
+		_, err = Paginate(paginationContext(uri))
+		assert.ErrorIs(t, err, ErrInvalidPagination, uri)
+	}
+}
+
+func TestPaginationLinks(t *testing.T) {
+	c := paginationContext("/users?page=2&limit=10&sort=name")
+	p, err := Paginate(c)
+	require.NoError(t, err)
+	p.SetTotal(35).SetHeaders(c)
+
+	assert.True(t, p.HasMore)
+	assert.Equal(t, 4, p.TotalPages())
+	assert.Equal(t, map[string]string{
+		"first": "/users?limit=10&page=1&sort=name",
+		"prev":  "/users?limit=10&page=1&sort=name",
+		"next":  "/users?limit=10&page=3&sort=name",
+		"last":  "/users?limit=10&page=4&sort=name",
+	}, p.Links())
+	assert.Equal(t, `</users?limit=10&page=1&sort=name>; rel="first", </users?limit=10&page=1&sort=name>; rel="prev", `+
+		`</users?limit=10&page=3&sort=name>; rel="next", </users?limit=10&page=4&sort=name>; rel="last"`,
+		string(c.requestCtx.Response.Header.Peek(HeaderLink)))
+	assert.Equal(t, "35", string(c.requestCtx.Response.Header.Peek(HeaderXTotalCount)))
+	assert.Equal(t, H{
+		"page": 2, "limit": 10, "total": 35, "total_pages": 4, "has_more": true, "links": p.Links(),
+	}, p.Meta())
+
+	p.SetTotal(20)
+	assert.False(t, p.HasMore)
+	assert.NotContains(t, p.Links(), "next")
+}
+
+func TestPaginationCursor(t *testing.T) {
+	c := paginationContext("/events?cursor=abc&page=4")
+	p, err := Paginate(c)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p.Page)
+	assert.Equal(t, "abc", p.Cursor)
+	assert.Equal(t, 0, p.Offset())
+
+	p.SetNextCursor("def").SetHeaders(c)
+	assert.Equal(t, `</events>; rel="first", </events?cursor=def>; rel="next"`, string(c.requestCtx.Response.Header.Peek(HeaderLink)))
+	assert.Empty(t, c.requestCtx.Response.Header.Peek(HeaderXTotalCount))
+	assert.Equal(t, H{"limit": 20, "has_more": true, "next_cursor": "def", "links": p.Links()}, p.Meta())
+
+	p.SetNextCursor("")
+	assert.Equal(t, map[string]string{"first": "/events"}, p.Links())
+}
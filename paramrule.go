@@ -0,0 +1,118 @@
+package gonoleks
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParamRule constrains the values of a path param, see Gonoleks.ParamRule
+type ParamRule struct {
+	// Message explains the expected value in the problem details, e.g. "must be a UUID"
+	Message string
+
+	// Validate reports whether a param value is valid
+	Validate func(value string) bool
+}
+
+// Built-in param rules
+var (
+	// UUIDRule accepts UUIDs in their canonical 8-4-4-4-12 hex form, in any case
+	UUIDRule = ParamRule{Message: "must be a UUID", Validate: isUUID}
+
+	// IntRule accepts decimal integers fitting in 64 bits, e.g. for strconv.ParseInt
+	IntRule = ParamRule{Message: "must be an integer", Validate: func(value string) bool {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	}}
+
+	// UintRule accepts non-negative decimal integers fitting in 64 bits, e.g. for strconv.ParseUint
+	UintRule = ParamRule{Message: "must be a non-negative integer", Validate: func(value string) bool {
+		_, err := strconv.ParseUint(value, 10, 64)
+		return err == nil
+	}}
+
+	// SlugRule accepts lowercase letters and digits separated by single hyphens, e.g. "hello-world-2"
+	SlugRule = PatternRule(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+)
+
+// PatternRule returns a rule accepting values matching the regular expression pattern
+// It panics if pattern does not compile
+func PatternRule(pattern string) ParamRule {
+	re := regexp.MustCompile(pattern)
+	return ParamRule{Message: "must match " + pattern, Validate: re.MatchString}
+}
+
+// OneOfRule returns a rule accepting only the given values
+func OneOfRule(values ...string) ParamRule {
+	allowed := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		allowed[value] = struct{}{}
+	}
+	return ParamRule{
+		Message: "must be one of " + strings.Join(values, ", "),
+		Validate: func(value string) bool {
+			_, ok := allowed[value]
+			return ok
+		},
+	}
+}
+
+// paramRule is a rule registered for a param name
+type paramRule struct {
+	name string
+	rule ParamRule
+}
+
+// ParamRule constrains the param name in every route path, e.g. ":id" in "/users/:id"
+// The rule is checked once a route matched, and a request with an invalid value is answered
+// with 400 and a problem details body listing every invalid param, without running the route handlers
+// Global middleware such as Logger and Recovery still wraps the answer
+// Registering a name again replaces its rule; rules must be registered before the server starts
+//
+//	app.ParamRule("id", gonoleks.UUIDRule)
+//	app.ParamRule("page", gonoleks.UintRule)
+//	app.GET("/users/:id", showUser) // GET /users/42 -> 400, "id" must be a UUID
+func (g *Gonoleks) ParamRule(name string, rule ParamRule) {
+	if rule.Validate == nil {
+		panic("param rule " + name + ": nil Validate")
+	}
+	for i := range g.paramRules {
+		if g.paramRules[i].name == name {
+			g.paramRules[i].rule = rule
+			return
+		}
+	}
+	g.paramRules = append(g.paramRules, paramRule{name: name, rule: rule})
+}
+
+// checkParams returns the problems of the path params matched for the request
+func (g *Gonoleks) checkParams(c *Context) []ValidationProblem {
+	var problems []ValidationProblem
+	for _, pr := range g.paramRules {
+		if value, ok := c.paramValues[pr.name]; ok && !pr.rule.Validate(value) {
+			problems = append(problems, ValidationProblem{In: "path", Name: pr.name, Message: pr.rule.Message})
+		}
+	}
+	return problems
+}
+
+// isUUID reports whether s is a UUID in the canonical 8-4-4-4-12 hex form
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := range len(s) {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
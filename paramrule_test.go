@@ -0,0 +1,83 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParamRule(t *testing.T) {
+	app := New()
+	app.ParamRule("id", UUIDRule)
+	app.ParamRule("n", IntRule)
+	app.ParamRule("id", UUIDRule) // Replaces the rule
+	var served bool
+	app.Use(func(c *Context) {
+		c.Header("X-Global", "1")
+		c.Next()
+	})
+	app.GET("/users/:id", func(c *Context) {
+		served = true
+		c.String(StatusOK, "%s", c.Param("id"))
+	})
+	app.GET("/users/:id/items/:n", func(c *Context) { c.String(StatusOK, "ok") })
+	app.GET("/static", func(c *Context) { c.String(StatusOK, "static") })
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/users/123e4567-E89B-12d3-a456-426614174000", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.True(t, served)
+
+	served = false
+	reqCtx = newProxiedRequest(MethodGet, "/users/42", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode())
+	assert.False(t, served)
+	assert.Equal(t, MIMEApplicationProblemJSON, string(reqCtx.Response.Header.ContentType()))
+	assert.Equal(t, "1", string(reqCtx.Response.Header.Peek("X-Global")))
+	var body struct {
+		Errors []ValidationProblem `json:"errors"`
+	}
+	require.NoError(t, sonic.Unmarshal(reqCtx.Response.Body(), &body))
+	assert.Equal(t, []ValidationProblem{{In: "path", Name: "id", Message: "must be a UUID"}}, body.Errors)
+
+	// Every invalid param is listed
+	reqCtx = newProxiedRequest(MethodGet, "/users/x/items/y", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	require.NoError(t, sonic.Unmarshal(reqCtx.Response.Body(), &body))
+	assert.Equal(t, []ValidationProblem{
+		{In: "path", Name: "id", Message: "must be a UUID"},
+		{In: "path", Name: "n", Message: "must be an integer"},
+	}, body.Errors)
+
+	reqCtx = newProxiedRequest(MethodGet, "/static", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "static", string(reqCtx.Response.Body()))
+	reqCtx = newProxiedRequest(MethodGet, "/missing", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusNotFound, reqCtx.Response.StatusCode())
+}
+
+func TestParamRules(t *testing.T) {
+	assert.True(t, UUIDRule.Validate("123e4567-e89b-12d3-a456-426614174000"))
+	assert.False(t, UUIDRule.Validate("123e4567e89b12d3a456426614174000"))
+	assert.False(t, UUIDRule.Validate("123e4567-e89b-12d3-a456-42661417400g"))
+	assert.True(t, IntRule.Validate("-12"))
+	assert.False(t, IntRule.Validate("99999999999999999999"))
+	assert.True(t, UintRule.Validate("12"))
+	assert.False(t, UintRule.Validate("-12"))
+	assert.True(t, SlugRule.Validate("hello-world-2"))
+	assert.False(t, SlugRule.Validate("Hello--world"))
+	assert.True(t, PatternRule(`^[A-Z]{2}$`).Validate("DE"))
+	assert.Equal(t, "must match ^[A-Z]{2}$", PatternRule(`^[A-Z]{2}$`).Message)
+
+	status := OneOfRule("open", "closed")
+	assert.True(t, status.Validate("open"))
+	assert.False(t, status.Validate("pending"))
+	assert.Equal(t, "must be one of open, closed", status.Message)
+
+	assert.Panics(t, func() { New().ParamRule("id", ParamRule{}) })
+}
@@ -0,0 +1,126 @@
+package gonoleks
+
+import (
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/valyala/fasthttp"
+)
+
+// PathNormalization configures how request paths are normalized before routing
+// The same normalized path is used for the route tree and the FastRouter cache
+// By default the path is percent-decoded and duplicate slashes and dot segments are removed
+type PathNormalization struct {
+	// DecodeUnreservedOnly decodes only unreserved characters (letters, digits, "-", ".", "_" and "~"),
+	// so reserved ones such as %2F stay encoded and do not split path segments or params
+	// Duplicate slashes and dot segments are still removed
+	DecodeUnreservedOnly bool
+
+	// RejectInvalidEncoding responds with 400 Bad Request to paths with malformed
+	// percent-encodings, e.g. "%zz", or that do not decode to valid UTF-8
+	RejectInvalidEncoding bool
+
+	// Unicode normalizes the decoded path, e.g. norm.NFC.String from golang.org/x/text/unicode/norm,
+	// so "café" matches whether the client sent a precomposed or a combining accent
+	// It is also applied to registered route paths
+	Unicode func(string) string
+}
+
+// enabled reports whether normalization differs from the default decoding
+func (pn *PathNormalization) enabled() bool {
+	return pn.DecodeUnreservedOnly || pn.RejectInvalidEncoding || pn.Unicode != nil
+}
+
+// normalize returns the path to route on, or false if the path must be rejected
+func (pn *PathNormalization) normalize(uri *fasthttp.URI) (string, bool) {
+	raw := string(uri.PathOriginal())
+	if pn.RejectInvalidEncoding && !validPercentEncoding(raw) {
+		return "", false
+	}
+	var p string
+	if pn.DecodeUnreservedOnly {
+		p = cleanRoutePath(decodeUnreserved(raw))
+	} else {
+		p = string(uri.Path())
+	}
+	if pn.RejectInvalidEncoding && !utf8.ValidString(p) {
+		return "", false
+	}
+	if pn.Unicode != nil {
+		p = pn.Unicode(p)
+	}
+	return p, true
+}
+
+// validPercentEncoding reports whether every "%" in s starts a valid escape
+func validPercentEncoding(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			return false
+		}
+		i += 2
+	}
+	return true
+}
+
+// decodeUnreserved decodes percent-encoded unreserved characters and uppercases the remaining escapes
+func decodeUnreserved(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			c := unhex(s[i+1])<<4 | unhex(s[i+2])
+			if isUnreserved(c) {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('%')
+				b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+			}
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// cleanRoutePath collapses duplicate slashes and resolves dot segments, keeping a trailing slash
+func cleanRoutePath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// isUnreserved reports whether c is an unreserved URI character as defined in RFC 3986 section 2.3
+func isUnreserved(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// isHex reports whether c is a hexadecimal digit
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// unhex returns the value of a hexadecimal digit
+func unhex(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	}
+	return c - 'A' + 10
+}
@@ -0,0 +1,77 @@
+package gonoleks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathNormalizationDecodeUnreservedOnly(t *testing.T) {
+	app := New()
+	app.PathNormalization.DecodeUnreservedOnly = true
+	app.GET("/files/:name", func(c *Context) { c.String(StatusOK, "%s", c.Param("name")) })
+	app.GET("/docs", func(c *Context) { c.String(StatusOK, "docs") })
+	app.setupRouter()
+
+	// Encoded slashes stay inside the param
+	reqCtx := newProxiedRequest(MethodGet, "/files/a%2fb", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "a%2Fb", string(reqCtx.Response.Body()))
+	// Unreserved characters are decoded for both the tree and the static cache
+	reqCtx = newProxiedRequest(MethodGet, "/%64ocs", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "docs", string(reqCtx.Response.Body()))
+	reqCtx = newProxiedRequest(MethodGet, "/files//a", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "a", string(reqCtx.Response.Body()))
+	reqCtx = newProxiedRequest(MethodGet, "/files/../docs", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "docs", string(reqCtx.Response.Body()))
+}
+
+func TestPathNormalizationRejectInvalidEncoding(t *testing.T) {
+	app := New()
+	app.PathNormalization.RejectInvalidEncoding = true
+	app.GET("/files/:name", func(c *Context) { c.String(StatusOK, "%s", c.Param("name")) })
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/files/%zz", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode())
+	reqCtx = newProxiedRequest(MethodGet, "/files/%2", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode())
+	reqCtx = newProxiedRequest(MethodGet, "/files/%ff", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode())
+	reqCtx = newProxiedRequest(MethodGet, "/files/caf%C3%A9", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, "café", string(reqCtx.Response.Body()))
+}
+
+func TestPathNormalizationUnicode(t *testing.T) {
+	app := New()
+	// Stand-in for norm.NFC.String
+	app.PathNormalization.Unicode = func(s string) string { return strings.ReplaceAll(s, "é", "é") }
+	app.GET("/café", func(c *Context) { c.String(StatusOK, "menu") })
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/caf%C3%A9", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "menu", string(reqCtx.Response.Body()))
+	reqCtx = newProxiedRequest(MethodGet, "/cafe%CC%81", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "menu", string(reqCtx.Response.Body()))
+}
+
+func TestPathNormalizationHelpers(t *testing.T) {
+	assert.True(t, validPercentEncoding("/a%20b"))
+	assert.False(t, validPercentEncoding("/a%2"))
+	assert.False(t, validPercentEncoding("/a%g0"))
+	assert.Equal(t, "/a-b%2F%3Fc", decodeUnreserved("/a%2db%2f%3fc"))
+	assert.Equal(t, "/a/", cleanRoutePath("//a//"))
+	assert.Equal(t, "/", cleanRoutePath(""))
+	assert.Equal(t, "/b", cleanRoutePath("/a/../b"))
+}
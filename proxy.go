@@ -0,0 +1,140 @@
+package gonoleks
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+
+	"charm.land/log/v2"
+)
+
+// parseTrustedProxies parses IP addresses and CIDR ranges
+// Invalid entries are skipped with a warning
+func parseTrustedProxies(proxies []string, logger *log.Logger) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				logger.Warn("Invalid trusted proxy", "proxy", proxy, "error", err)
+				continue
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			logger.Warn("Invalid trusted proxy", "proxy", proxy, "error", err)
+			continue
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes
+}
+
+// trustedProxySet holds the parsed trusted proxies, replaced as a whole when they are reloaded
+type trustedProxySet struct {
+	// configured is set when trusted proxies were given, even if none of them is valid
+	configured bool
+	proxies    []string
+	prefixes   []netip.Prefix
+}
+
+// setTrustedProxies parses and installs the trusted proxies
+func (g *Gonoleks) setTrustedProxies(proxies []string) {
+	g.trustedProxies.Store(&trustedProxySet{
+		configured: len(proxies) > 0,
+		proxies:    proxies,
+		prefixes:   parseTrustedProxies(proxies, g.diagnostics),
+	})
+}
+
+// trustedPrefixes returns the parsed trusted proxy ranges
+func (g *Gonoleks) trustedPrefixes() []netip.Prefix {
+	if set := g.trustedProxies.Load(); set != nil {
+		return set.prefixes
+	}
+	return nil
+}
+
+// isTrustedProxy reports whether forwarding headers sent by the direct peer may be used
+// Every peer is trusted when Options.TrustedProxies is empty, as ClientIP always did
+func (c *Context) isTrustedProxy() bool {
+	if c.app == nil {
+		return true
+	}
+	if set := c.app.trustedProxies.Load(); set == nil || !set.configured {
+		return true
+	}
+	return c.app.isTrustedAddr(c.requestCtx.RemoteIP())
+}
+
+// isConfiguredProxy reports whether the direct peer is one of Options.TrustedProxies
+// Unlike isTrustedProxy no peer qualifies while none is configured: the scheme and host build
+// redirects and absolute URLs, which spoofed forwarding headers must not point elsewhere
+func (c *Context) isConfiguredProxy() bool {
+	if c.app == nil {
+		return false
+	}
+	if set := c.app.trustedProxies.Load(); set == nil || !set.configured {
+		return false
+	}
+	return c.app.isTrustedAddr(c.requestCtx.RemoteIP())
+}
+
+// isTrustedAddr reports whether ip belongs to one of the trusted proxy ranges
+func (g *Gonoleks) isTrustedAddr(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range g.trustedPrefixes() {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scheme returns the request scheme, either "http" or "https"
+// The Forwarded and X-Forwarded-Proto headers are honored when the request comes from one of
+// Options.TrustedProxies, they are ignored while none is configured
+func (c *Context) Scheme() string {
+	if c.requestCtx.IsTLS() {
+		return "https"
+	}
+	if c.isConfiguredProxy() {
+		if element, ok := c.forwardedElement(); ok && element.Proto != "" {
+			return element.Proto
+		}
+		if proto := firstHeaderValue(c.GetHeader(HeaderXForwardedProto)); proto != "" {
+			return strings.ToLower(proto)
+		}
+	}
+	return "http"
+}
+
+// Host returns the host requested by the client, including the port if present
+// The Forwarded and X-Forwarded-Host headers are honored when the request comes from one of
+// Options.TrustedProxies, they are ignored while none is configured
+func (c *Context) Host() string {
+	if c.isConfiguredProxy() {
+		if element, ok := c.forwardedElement(); ok && element.Host != "" {
+			return element.Host
+		}
+		if host := firstHeaderValue(c.GetHeader(HeaderXForwardedHost)); host != "" {
+			return host
+		}
+	}
+	return string(c.requestCtx.Host())
+}
+
+// firstHeaderValue returns the first element of a comma-separated header value
+func firstHeaderValue(value string) string {
+	if i := strings.IndexByte(value, ','); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
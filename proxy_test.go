@@ -0,0 +1,38 @@
+package gonoleks
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustedProxies(t *testing.T) {
+	app := New()
+	app.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32", "not-an-ip"}
+	app.setupRouter()
+	assert.Len(t, app.trustedPrefixes(), 3, "Invalid entries should be skipped")
+
+	assert.True(t, app.isTrustedAddr(net.ParseIP("10.20.30.40")))
+	assert.True(t, app.isTrustedAddr(net.ParseIP("192.168.1.10")))
+	assert.True(t, app.isTrustedAddr(net.ParseIP("2001:db8::1")))
+	assert.False(t, app.isTrustedAddr(net.ParseIP("192.168.1.11")))
+
+	reqCtx := newProxiedRequest(MethodGet, "/", "192.168.1.10", map[string]string{
+		HeaderHost:            "internal",
+		HeaderXForwardedHost:  "example.com, proxy.local",
+		HeaderXForwardedProto: "HTTPS",
+	})
+	c := &Context{app: app, requestCtx: reqCtx}
+	assert.Equal(t, "https", c.Scheme())
+	assert.Equal(t, "example.com", c.Host())
+
+	reqCtx = newProxiedRequest(MethodGet, "/", "192.168.1.11", map[string]string{
+		HeaderHost:            "internal",
+		HeaderXForwardedHost:  "example.com",
+		HeaderXForwardedProto: "https",
+	})
+	c = &Context{app: app, requestCtx: reqCtx}
+	assert.Equal(t, "http", c.Scheme())
+	assert.Equal(t, "internal", c.Host())
+}
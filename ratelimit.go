@@ -0,0 +1,135 @@
+package gonoleks
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"charm.land/log/v2"
+)
+
+// syncedRateLimiter counts requests per client IP locally and pushes the counts to a Store
+// in the background, see Options.RateLimitSyncInterval
+type syncedRateLimiter struct {
+	counters sync.Map // client IP -> *syncedCounter
+	lastSync atomic.Int64
+	syncing  atomic.Bool
+}
+
+// syncedCounter counts the requests of a client IP in the current window
+type syncedCounter struct {
+	mu      sync.Mutex
+	window  int64
+	size    time.Duration
+	cluster int64 // Requests of the whole cluster as of the last sync
+	pending int64 // Requests admitted since the last sync
+	removed bool
+}
+
+// rateLimitWindow returns the index and size of the fixed window of burst/rate seconds
+// containing now, aligned on the Unix epoch so every instance agrees on it
+func rateLimitWindow(now time.Time, rate float64, burst int) (int64, time.Duration) {
+	size := max(time.Duration(float64(burst)/rate*float64(time.Second)), time.Millisecond)
+	return now.UnixNano() / int64(size), size
+}
+
+// rateLimitWait returns the time from now until the window ends
+func rateLimitWait(now time.Time, index int64, size time.Duration) time.Duration {
+	return time.Unix(0, (index+1)*int64(size)).Sub(now)
+}
+
+// rateLimitKey returns the store key counting the requests of ip in a window
+func rateLimitKey(ip string, index int64) string {
+	return "ratelimit:" + ip + ":" + strconv.FormatInt(index, 36)
+}
+
+// take admits a request of ip while both the cluster count and the requests admitted since
+// the last sync stay within their budgets, and returns the time until the window ends otherwise
+func (l *syncedRateLimiter) take(ip string, rate float64, burst, localBurst int) time.Duration {
+	if burst < 1 {
+		burst = int(math.Ceil(rate))
+	}
+	if localBurst < 1 || localBurst > burst {
+		localBurst = burst
+	}
+	now := time.Now()
+	index, size := rateLimitWindow(now, rate, burst)
+	for {
+		value, ok := l.counters.Load(ip)
+		if !ok {
+			value, _ = l.counters.LoadOrStore(ip, &syncedCounter{window: index, size: size})
+		}
+		counter := value.(*syncedCounter)
+		counter.mu.Lock()
+		if counter.removed {
+			// Swept concurrently, count in a new counter
+			counter.mu.Unlock()
+			continue
+		}
+		if counter.window != index || counter.size != size {
+			*counter = syncedCounter{window: index, size: size}
+		}
+		allowed := counter.pending < int64(localBurst) && counter.cluster+counter.pending < int64(burst)
+		if allowed {
+			counter.pending++
+		}
+		counter.mu.Unlock()
+		if allowed {
+			return 0
+		}
+		return rateLimitWait(now, index, size)
+	}
+}
+
+// syncDue reports whether a sync should start, and marks it as started
+func (l *syncedRateLimiter) syncDue(interval time.Duration) bool {
+	now := time.Now().UnixNano()
+	last := l.lastSync.Load()
+	if last == 0 {
+		// Nothing to push yet
+		l.lastSync.CompareAndSwap(0, now)
+		return false
+	}
+	if now-last < int64(interval) || !l.syncing.CompareAndSwap(false, true) {
+		return false
+	}
+	l.lastSync.Store(now)
+	return true
+}
+
+// sync pushes the pending counts to store and learns the cluster counts in return,
+// removing the counters of ended windows
+// It stops at the first store error, keeping the counts pending for the next sync
+func (l *syncedRateLimiter) sync(store Store, logger *log.Logger) {
+	defer l.syncing.Store(false)
+	now := time.Now().UnixNano()
+	l.counters.Range(func(ip, value any) bool {
+		counter := value.(*syncedCounter)
+		counter.mu.Lock()
+		window, size, pending := counter.window, counter.size, counter.pending
+		if now/int64(size) != window {
+			counter.removed = true
+			l.counters.Delete(ip)
+			counter.mu.Unlock()
+			return true
+		}
+		counter.mu.Unlock()
+		if pending == 0 {
+			return true
+		}
+		total, err := store.Incr(rateLimitKey(ip.(string), window), pending, size+time.Second)
+		if err != nil {
+			logger.Warn("Rate limit store sync failed", "error", err)
+			return false
+		}
+		counter.mu.Lock()
+		if counter.window == window {
+			counter.pending -= pending
+			counter.cluster = total
+		}
+		counter.mu.Unlock()
+		return true
+	})
+}
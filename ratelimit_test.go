@@ -0,0 +1,108 @@
+package gonoleks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncedRateLimiter(t *testing.T) {
+	kv, err := NewKV()
+	require.NoError(t, err)
+	defer kv.Close()
+	store := kv.Store()
+
+	// A window of 4000 seconds, so the test never crosses one
+	const rate, burst = 0.001, 4
+	var a, b syncedRateLimiter
+	for range 3 {
+		assert.Zero(t, a.take("203.0.113.5", rate, burst, 0))
+		assert.Zero(t, b.take("203.0.113.5", rate, burst, 0))
+	}
+	a.sync(store, defaultDiagnostics)
+	b.sync(store, defaultDiagnostics)
+
+	// a only knows of its own requests until it syncs
+	assert.Zero(t, a.take("203.0.113.5", rate, burst, 0))
+	assert.Positive(t, b.take("203.0.113.5", rate, burst, 0))
+	a.sync(store, defaultDiagnostics)
+	assert.Positive(t, a.take("203.0.113.5", rate, burst, 0))
+
+	// Other clients are counted separately
+	assert.Zero(t, a.take("198.51.100.1", rate, burst, 0))
+
+	index, _ := rateLimitWindow(time.Now(), rate, burst)
+	value, ok := kv.Get(rateLimitKey("203.0.113.5", index))
+	require.True(t, ok)
+	assert.Equal(t, "7", string(value))
+}
+
+func TestSyncedRateLimiterLocalBurst(t *testing.T) {
+	kv, err := NewKV()
+	require.NoError(t, err)
+	defer kv.Close()
+
+	var l syncedRateLimiter
+	assert.Zero(t, l.take("203.0.113.5", 0.001, 10, 2))
+	assert.Zero(t, l.take("203.0.113.5", 0.001, 10, 2))
+	assert.Positive(t, l.take("203.0.113.5", 0.001, 10, 2))
+	l.sync(kv.Store(), defaultDiagnostics)
+	assert.Zero(t, l.take("203.0.113.5", 0.001, 10, 2))
+
+	// Counts stay pending while the store is unreachable
+	unreachable := NewRedisStore(RedisStoreConfig{Addr: "127.0.0.1:1", DialTimeout: 50 * time.Millisecond})
+	l.sync(unreachable, defaultDiagnostics)
+	assert.Zero(t, l.take("203.0.113.5", 0.001, 10, 2))
+	assert.Positive(t, l.take("203.0.113.5", 0.001, 10, 2))
+}
+
+func TestSyncedRateLimiterSweep(t *testing.T) {
+	kv, err := NewKV()
+	require.NoError(t, err)
+	defer kv.Close()
+
+	var l syncedRateLimiter
+	// A window of one millisecond has ended by the time of the sync
+	assert.Zero(t, l.take("203.0.113.5", 1000, 1, 0))
+	time.Sleep(2 * time.Millisecond)
+	l.sync(kv.Store(), defaultDiagnostics)
+	_, ok := l.counters.Load("203.0.113.5")
+	assert.False(t, ok)
+}
+
+func TestSyncedRateLimiterSyncDue(t *testing.T) {
+	var l syncedRateLimiter
+	assert.False(t, l.syncDue(time.Millisecond))
+	time.Sleep(2 * time.Millisecond)
+	assert.True(t, l.syncDue(time.Millisecond))
+	time.Sleep(2 * time.Millisecond)
+	// The previous sync is still running
+	assert.False(t, l.syncDue(time.Millisecond))
+	l.syncing.Store(false)
+	assert.False(t, l.syncDue(time.Hour))
+}
+
+func TestRateLimitSyncInterval(t *testing.T) {
+	kv, err := NewKV()
+	require.NoError(t, err)
+	defer kv.Close()
+
+	app := New()
+	app.RateLimit = 0.001
+	app.RateLimitBurst = 2
+	app.RateLimitStore = kv.Store()
+	app.RateLimitSyncInterval = time.Hour
+	app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	app.setupRouter()
+	statuses := make([]int, 3)
+	for i := range statuses {
+		reqCtx := newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+		app.router.Handler(reqCtx)
+		statuses[i] = reqCtx.Response.StatusCode()
+	}
+	assert.Equal(t, []int{StatusOK, StatusOK, StatusTooManyRequests}, statuses)
+	// Nothing was pushed before the first interval
+	assert.Zero(t, kv.Len())
+}
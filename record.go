@@ -0,0 +1,463 @@
+package gonoleks
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+// redactedValue replaces redacted values in fixtures, and matches any value on replay
+const redactedValue = "[REDACTED]"
+
+// Fixture is a recorded request and response pair, see Record and LoadFixtures
+type Fixture struct {
+	// Name identifies the fixture, e.g. its file name without extension
+	Name string `json:"name"`
+
+	Request  FixtureMessage `json:"request"`
+	Response FixtureMessage `json:"response"`
+}
+
+// FixtureMessage is a recorded request or response
+type FixtureMessage struct {
+	// Method and URI are set for requests
+	Method string `json:"method,omitempty"`
+	URI    string `json:"uri,omitempty"`
+
+	// Status is set for responses
+	Status int `json:"status,omitempty"`
+
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// JSON holds JSON bodies, Body other text bodies, and Base64 binary bodies
+	JSON   json.RawMessage `json:"json,omitempty"`
+	Body   string          `json:"body,omitempty"`
+	Base64 string          `json:"base64,omitempty"`
+}
+
+// RecordConfig defines the config for the Record middleware
+type RecordConfig struct {
+	// Dir is the directory the fixtures are written to, one JSON file per request
+	Dir string // Default = "testdata/fixtures"
+
+	// SampleRate is the fraction of requests recorded, between 0 and 1
+	SampleRate float64 // Default = 1
+
+	// Filter decides whether a handled request is recorded, in addition to SampleRate
+	Filter func(c *Context) bool
+
+	// MaxBodySize skips requests whose request or response body is larger
+	MaxBodySize int // Default = 1 MB
+
+	// RedactHeaders lists additional headers whose values are redacted
+	// Authorization, Proxy-Authorization, Cookie and Set-Cookie are always redacted
+	RedactHeaders []string
+
+	// RedactQuery lists query params whose values are redacted, in the URI and in form bodies
+	RedactQuery []string
+
+	// RedactFields lists JSON object keys whose values are redacted at any depth of the
+	// request and response bodies, e.g. "password" or "token", compared case-insensitively
+	// The fields of URL-encoded and multipart form bodies are redacted as well
+	RedactFields []string
+
+	// Redact is called with every fixture before it is written, to apply other redaction rules
+	Redact func(f *Fixture)
+}
+
+// Record instances a middleware writing sampled request and response pairs to disk as fixtures,
+// with sensitive values redacted, to build regression suites from real traffic
+// Recorded fixtures are replayed with LoadFixtures and Fixture.Replay
+// Streamed responses are not recorded
+//
+//	if os.Getenv("RECORD_FIXTURES") != "" {
+//	    app.Use(gonoleks.Record(gonoleks.RecordConfig{
+//	        SampleRate:   0.1,
+//	        RedactFields: []string{"password", "token"},
+//	    }))
+//	}
+func Record(config ...RecordConfig) handlerFunc {
+	var conf RecordConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if conf.Dir == "" {
+		conf.Dir = filepath.Join("testdata", "fixtures")
+	}
+	if conf.SampleRate == 0 {
+		conf.SampleRate = 1
+	}
+	if conf.MaxBodySize == 0 {
+		conf.MaxBodySize = 1 << 20
+	}
+	redact := make(map[string]struct{}, len(redactedHeaders)+len(conf.RedactHeaders)+1)
+	for _, h := range redactedHeaders {
+		redact[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+	for _, h := range conf.RedactHeaders {
+		redact[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+	redact[HeaderSetCookie] = struct{}{}
+	var mkdir sync.Once
+	return func(c *Context) {
+		c.Next()
+		if conf.SampleRate < 1 && c.Rand().Float64() >= conf.SampleRate {
+			return
+		}
+		resp := &c.requestCtx.Response
+		if resp.IsBodyStream() || len(resp.Body()) > conf.MaxBodySize || len(c.requestCtx.Request.Body()) > conf.MaxBodySize {
+			return
+		}
+		if conf.Filter != nil && !conf.Filter(c) {
+			return
+		}
+		f := conf.fixture(c, redact)
+		if conf.Redact != nil {
+			conf.Redact(&f)
+		}
+		data, err := json.MarshalIndent(f, "", "  ")
+		if err == nil {
+			mkdir.Do(func() { err = os.MkdirAll(conf.Dir, 0o755) })
+		}
+		if err == nil {
+			err = os.WriteFile(filepath.Join(conf.Dir, f.Name+".json"), append(data, '\n'), 0o644)
+		}
+		if err != nil {
+			c.diagnostics().Error("Failed to write fixture", "name", f.Name, "error", err)
+		}
+	}
+}
+
+// fixture captures the request and response of c with the redaction rules applied
+func (conf *RecordConfig) fixture(c *Context, redact map[string]struct{}) Fixture {
+	req := &c.requestCtx.Request
+	resp := &c.requestCtx.Response
+	uri := req.URI()
+	if len(conf.RedactQuery) > 0 {
+		redactedURI := &fasthttp.URI{}
+		uri.CopyTo(redactedURI)
+		uri = redactedURI
+		for _, name := range conf.RedactQuery {
+			if uri.QueryArgs().Has(name) {
+				uri.QueryArgs().Set(name, redactedValue)
+			}
+		}
+	}
+	method := string(req.Header.Method())
+	f := Fixture{
+		Name: c.Now().UTC().Format("20060102T150405.000000000") + "-" + method + "-" + fixtureSlug(string(uri.Path())),
+		Request: FixtureMessage{
+			Method:  method,
+			URI:     string(uri.RequestURI()),
+			Headers: map[string]string{},
+		},
+		Response: FixtureMessage{
+			Status:  resp.StatusCode(),
+			Headers: map[string]string{},
+		},
+	}
+	for key, value := range req.Header.All() {
+		f.Request.Headers[http.CanonicalHeaderKey(string(key))] = redactedHeaderValue(key, value, redact)
+	}
+	for key, value := range resp.Header.All() {
+		name := http.CanonicalHeaderKey(string(key))
+		if name != HeaderDate && name != HeaderContentLength {
+			f.Response.Headers[name] = redactedHeaderValue(key, value, redact)
+		}
+	}
+	conf.setBody(&f.Request, getString(req.Header.ContentType()), req.Body())
+	conf.setBody(&f.Response, getString(resp.Header.ContentType()), resp.Body())
+	return f
+}
+
+// setBody stores body in the field of m matching its content, with JSON and form fields redacted
+func (conf *RecordConfig) setBody(m *FixtureMessage, contentType string, body []byte) {
+	if mediaType, params, err := mime.ParseMediaType(contentType); err == nil && len(body) > 0 {
+		switch mediaType {
+		case MIMEApplicationForm:
+			body = conf.redactForm(body)
+		case MIMEMultipartForm:
+			body = conf.redactMultipart(body, params["boundary"])
+		}
+	}
+	contentType, _, _ = strings.Cut(contentType, ";")
+	switch {
+	case len(body) == 0:
+	case isJSONMediaType(contentType) && json.Valid(body):
+		if len(conf.RedactFields) > 0 {
+			body = redactJSON(body, conf.RedactFields)
+		}
+		var compact bytes.Buffer
+		if json.Compact(&compact, body) == nil {
+			body = compact.Bytes()
+		}
+		m.JSON = body
+	case isPrintableText(body):
+		m.Body = string(body)
+	default:
+		m.Base64 = base64.StdEncoding.EncodeToString(body)
+	}
+}
+
+// redactsField reports whether the values of the form field name are redacted
+func (conf *RecordConfig) redactsField(name string) bool {
+	return slices.Contains(conf.RedactQuery, name) ||
+		slices.ContainsFunc(conf.RedactFields, func(f string) bool { return strings.EqualFold(f, name) })
+}
+
+// redactForm redacts the fields of a URL-encoded form body, which is returned as is when none is redacted
+func (conf *RecordConfig) redactForm(body []byte) []byte {
+	args := fasthttp.AcquireArgs()
+	defer fasthttp.ReleaseArgs(args)
+	redacted := fasthttp.AcquireArgs()
+	defer fasthttp.ReleaseArgs(redacted)
+	args.ParseBytes(body)
+	changed := false
+	for key, value := range args.All() {
+		if conf.redactsField(string(key)) {
+			value = []byte(redactedValue)
+			changed = true
+		}
+		redacted.AddBytesKV(key, value)
+	}
+	if !changed {
+		return body
+	}
+	return redacted.QueryString()
+}
+
+// redactMultipart redacts the non-file fields of a multipart form body, keeping its boundary
+// The body is returned as is when it cannot be parsed
+func (conf *RecordConfig) redactMultipart(body []byte, boundary string) []byte {
+	if boundary == "" {
+		return body
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var out bytes.Buffer
+	writer := multipart.NewWriter(&out)
+	if writer.SetBoundary(boundary) != nil {
+		return body
+	}
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return body
+		}
+		w, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return body
+		}
+		if part.FileName() == "" && conf.redactsField(part.FormName()) {
+			_, err = io.WriteString(w, redactedValue)
+		} else {
+			_, err = io.Copy(w, part)
+		}
+		if err != nil {
+			return body
+		}
+	}
+	if writer.Close() != nil {
+		return body
+	}
+	return out.Bytes()
+}
+
+// body returns the raw body of m
+func (m *FixtureMessage) body() []byte {
+	switch {
+	case len(m.JSON) > 0:
+		return m.JSON
+	case m.Base64 != "":
+		body, _ := base64.StdEncoding.DecodeString(m.Base64)
+		return body
+	}
+	return []byte(m.Body)
+}
+
+// redactedHeaderValue returns value, or the redacted placeholder for redacted headers
+func redactedHeaderValue(key, value []byte, redact map[string]struct{}) string {
+	if _, hidden := redact[http.CanonicalHeaderKey(string(key))]; hidden {
+		return redactedValue
+	}
+	return string(value)
+}
+
+// redactJSON replaces the values of the given object keys at any depth of a JSON document
+func redactJSON(body []byte, fields []string) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if decoder.Decode(&value) != nil {
+		return body
+	}
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for key, child := range v {
+				if slices.ContainsFunc(fields, func(f string) bool { return strings.EqualFold(f, key) }) {
+					v[key] = redactedValue
+					continue
+				}
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(value)
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// fixtureSlug turns a request path into a file name part, e.g. "/users/42" into "users_42"
+func fixtureSlug(path string) string {
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '_'
+	}, strings.Trim(path, "/"))
+	if slug == "" {
+		slug = "root"
+	}
+	if len(slug) > 60 {
+		slug = slug[:60]
+	}
+	return slug
+}
+
+// LoadFixtures reads the fixtures of the JSON files in dir, sorted by file name
+func LoadFixtures(dir string) ([]Fixture, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	fixtures := make([]Fixture, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var f Fixture
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", file, err)
+		}
+		if f.Name == "" {
+			f.Name = strings.TrimSuffix(filepath.Base(file), ".json")
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
+
+// HTTPRequest returns the recorded request, without its redacted headers
+func (f *Fixture) HTTPRequest() *http.Request {
+	req, err := http.NewRequest(f.Request.Method, f.Request.URI, bytes.NewReader(f.Request.body()))
+	if err != nil {
+		req, _ = http.NewRequest(MethodGet, "/", http.NoBody)
+	}
+	for name, value := range f.Request.Headers {
+		switch {
+		case value == redactedValue || name == HeaderContentLength:
+		case name == HeaderHost:
+			req.Host = value
+		default:
+			req.Header.Set(name, value)
+		}
+	}
+	return req
+}
+
+// Replay serves the recorded request with app.Test and fails with ErrFixtureMismatch
+// when the status, Content-Type or body of the response differ from the recording
+// JSON bodies are compared by value, and redacted values match anything
+//
+//	fixtures, err := gonoleks.LoadFixtures("testdata/fixtures")
+//	require.NoError(t, err)
+//	for _, f := range fixtures {
+//	    t.Run(f.Name, func(t *testing.T) {
+//	        assert.NoError(t, f.Replay(app))
+//	    })
+//	}
+func (f *Fixture) Replay(app *Gonoleks) error {
+	resp := app.Test(f.HTTPRequest())
+	defer resp.Body.Close()
+	if resp.StatusCode != f.Response.Status {
+		return fmt.Errorf("%w %s: status %d, recorded %d", ErrFixtureMismatch, f.Name, resp.StatusCode, f.Response.Status)
+	}
+	if contentType := resp.Header.Get(HeaderContentType); contentType != f.Response.Headers[HeaderContentType] {
+		return fmt.Errorf("%w %s: Content-Type %q, recorded %q", ErrFixtureMismatch, f.Name, contentType, f.Response.Headers[HeaderContentType])
+	}
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		return err
+	}
+	if len(f.Response.JSON) > 0 {
+		var recorded, replayed any
+		if json.Unmarshal(f.Response.JSON, &recorded) != nil || json.Unmarshal(body.Bytes(), &replayed) != nil ||
+			!jsonMatches(recorded, replayed) {
+			return fmt.Errorf("%w %s: body %s, recorded %s", ErrFixtureMismatch, f.Name, body.Bytes(), f.Response.JSON)
+		}
+		return nil
+	}
+	if !bytes.Equal(body.Bytes(), f.Response.body()) {
+		return fmt.Errorf("%w %s: body %q, recorded %q", ErrFixtureMismatch, f.Name, body.Bytes(), f.Response.body())
+	}
+	return nil
+}
+
+// jsonMatches reports whether a decoded JSON value equals the recorded one,
+// where redacted values match anything
+func jsonMatches(recorded, replayed any) bool {
+	if recorded == redactedValue {
+		return true
+	}
+	switch r := recorded.(type) {
+	case map[string]any:
+		v, ok := replayed.(map[string]any)
+		if !ok || len(v) != len(r) {
+			return false
+		}
+		for key, child := range r {
+			other, ok := v[key]
+			if !ok || !jsonMatches(child, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		v, ok := replayed.([]any)
+		if !ok || len(v) != len(r) {
+			return false
+		}
+		for i := range r {
+			if !jsonMatches(r[i], v[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(recorded, replayed)
+}
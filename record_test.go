@@ -0,0 +1,163 @@
+package gonoleks
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordApp returns an app answering login requests with a token, and greeting with name
+func recordApp(greeting string, middlewares ...handlerFunc) *Gonoleks {
+	app := New()
+	app.Use(middlewares...)
+	app.POST("/login", func(c *Context) {
+		_ = c.JSON(StatusOK, H{"user": H{"name": "ada", "token": c.Query("nonce")}, "roles": []string{"admin"}})
+	})
+	app.GET("/hello/:name", func(c *Context) {
+		c.String(StatusOK, "%s", greeting+" "+c.Param("name"))
+	})
+	app.GET("/logo", func(c *Context) {
+		c.Data(StatusOK, MIMEImagePNG, []byte{0x89, 'P', 'N', 'G', 0})
+	})
+	return app
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	app := recordApp("hello", Record(RecordConfig{
+		Dir:           filepath.Join(dir, "fixtures"),
+		RedactHeaders: []string{"X-Api-Key"},
+		RedactQuery:   []string{"nonce"},
+		RedactFields:  []string{"password", "Token"},
+	}))
+
+	req := httptest.NewRequest(MethodPost, "/login?nonce=n1&page=1", strings.NewReader(`{"login":"ada","password":"secret"}`))
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	req.Header.Set(HeaderAuthorization, "Bearer x")
+	req.Header.Set("X-Api-Key", "key")
+	assert.Equal(t, StatusOK, app.Test(req).StatusCode)
+	assert.Equal(t, StatusOK, app.Test(httptest.NewRequest(MethodGet, "/hello/bob", nil)).StatusCode)
+	assert.Equal(t, StatusOK, app.Test(httptest.NewRequest(MethodGet, "/logo", nil)).StatusCode)
+
+	fixtures, err := LoadFixtures(filepath.Join(dir, "fixtures"))
+	require.NoError(t, err)
+	require.Len(t, fixtures, 3)
+	byPath := map[string]Fixture{}
+	for _, f := range fixtures {
+		byPath[strings.SplitN(f.Request.URI, "?", 2)[0]] = f
+	}
+
+	login := byPath["/login"]
+	assert.Contains(t, login.Name, "-POST-login")
+	assert.Equal(t, "/login?nonce=%5BREDACTED%5D&page=1", login.Request.URI)
+	assert.Equal(t, redactedValue, login.Request.Headers[HeaderAuthorization])
+	assert.Equal(t, redactedValue, login.Request.Headers["X-Api-Key"])
+	assert.JSONEq(t, `{"login":"ada","password":"[REDACTED]"}`, string(login.Request.JSON))
+	assert.Equal(t, StatusOK, login.Response.Status)
+	assert.JSONEq(t, `{"user":{"name":"ada","token":"[REDACTED]"},"roles":["admin"]}`, string(login.Response.JSON))
+	assert.NotContains(t, login.Response.Headers, HeaderDate)
+	assert.Equal(t, "hello bob", byPath["/hello/bob"].Response.Body)
+	assert.NotEmpty(t, byPath["/logo"].Response.Base64)
+
+	// Replaying against the same behavior passes, redacted values match anything
+	replay := recordApp("hello")
+	for _, f := range fixtures {
+		assert.NoError(t, f.Replay(replay), f.Name)
+	}
+
+	// A changed behavior is reported
+	changed := recordApp("hi")
+	hello := byPath["/hello/bob"]
+	err = hello.Replay(changed)
+	assert.ErrorIs(t, err, ErrFixtureMismatch)
+	assert.Contains(t, err.Error(), `"hi bob"`)
+
+	login.Response.JSON = []byte(`{"user":{"name":"bob","token":"[REDACTED]"},"roles":["admin"]}`)
+	assert.ErrorIs(t, login.Replay(replay), ErrFixtureMismatch)
+	login.Response.Status = StatusCreated
+	assert.ErrorIs(t, login.Replay(replay), ErrFixtureMismatch)
+}
+
+func TestRecordRedactForm(t *testing.T) {
+	dir := t.TempDir()
+	app := recordApp("hello", Record(RecordConfig{
+		Dir:          dir,
+		RedactQuery:  []string{"nonce"},
+		RedactFields: []string{"Password"},
+	}))
+
+	req := httptest.NewRequest(MethodPost, "/login", strings.NewReader("login=ada&password=secret&nonce=n1"))
+	req.Header.Set(HeaderContentType, MIMEApplicationForm)
+	assert.Equal(t, StatusOK, app.Test(req).StatusCode)
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	require.NoError(t, writer.WriteField("login", "ada"))
+	require.NoError(t, writer.WriteField("password", "secret"))
+	require.NoError(t, writer.Close())
+	req = httptest.NewRequest(MethodPost, "/login", &form)
+	req.Header.Set(HeaderContentType, writer.FormDataContentType())
+	assert.Equal(t, StatusOK, app.Test(req).StatusCode)
+
+	fixtures, err := LoadFixtures(dir)
+	require.NoError(t, err)
+	require.Len(t, fixtures, 2)
+	var bodies []string
+	for _, f := range fixtures {
+		assert.NotContains(t, f.Request.Body, "secret")
+		bodies = append(bodies, f.Request.Body)
+	}
+	assert.Contains(t, bodies, "login=ada&password=%5BREDACTED%5D&nonce=%5BREDACTED%5D")
+	assert.True(t, slices.ContainsFunc(bodies, func(b string) bool {
+		return strings.Contains(b, `name="login"`+"\r\n\r\nada\r\n") && strings.Contains(b, `name="password"`+"\r\n\r\n"+redactedValue+"\r\n")
+	}))
+}
+
+func TestRecordSampling(t *testing.T) {
+	dir := t.TempDir()
+	app := recordApp("hello", Record(RecordConfig{
+		Dir:    dir,
+		Filter: func(c *Context) bool { return c.Param("name") != "skip" },
+		Redact: func(f *Fixture) { f.Name = "custom-" + f.Request.Method + strings.ReplaceAll(f.Request.URI, "/", "-") },
+	}))
+	app.Test(httptest.NewRequest(MethodGet, "/hello/skip", nil))
+	app.Test(httptest.NewRequest(MethodGet, "/hello/ada", nil))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "custom-GET-hello-ada.json", entries[0].Name())
+
+	skipped := t.TempDir()
+	app = recordApp("hello", Record(RecordConfig{Dir: skipped, SampleRate: 0.0001}))
+	for range 10 {
+		app.Test(httptest.NewRequest(MethodGet, "/hello/ada", nil))
+	}
+	entries, err = os.ReadDir(skipped)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(entries), 1)
+}
+
+func TestFixtureHelpers(t *testing.T) {
+	assert.Equal(t, "users_42_avatar-png", fixtureSlug("/users/42/avatar-png"))
+	assert.Equal(t, "root", fixtureSlug("/"))
+	assert.True(t, jsonMatches(map[string]any{"a": []any{"[REDACTED]", 1.0}}, map[string]any{"a": []any{"x", 1.0}}))
+	assert.False(t, jsonMatches(map[string]any{"a": 1.0}, map[string]any{"a": 1.0, "b": 2.0}))
+	assert.False(t, jsonMatches([]any{1.0}, map[string]any{}))
+
+	f := Fixture{Request: FixtureMessage{Method: MethodGet, URI: "/a", Headers: map[string]string{HeaderHost: "example.com", HeaderCookie: redactedValue, "X-A": "1"}}}
+	req := f.HTTPRequest()
+	assert.Equal(t, "example.com", req.Host)
+	assert.Empty(t, req.Header.Get(HeaderCookie))
+	assert.Equal(t, "1", req.Header.Get("X-A"))
+
+	_, err := LoadFixtures(filepath.Join(t.TempDir(), "missing"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,162 @@
+package gonoleks
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/valyala/fasthttp"
+)
+
+// CrashReport describes a panic recovered while processing a request
+type CrashReport struct {
+	// Time is when the panic was recovered
+	Time time.Time `json:"time"`
+
+	// RequestID is taken from the X-Request-ID request or response header, if any
+	RequestID string `json:"request_id,omitempty"`
+
+	// Method is the HTTP method of the request
+	Method string `json:"method"`
+
+	// URI is the request URI including the query string
+	URI string `json:"uri"`
+
+	// Route is the matched route path, if known
+	Route string `json:"route,omitempty"`
+
+	// ClientIP equals Context.ClientIP()
+	ClientIP string `json:"client_ip"`
+
+	// Headers holds the request headers with sensitive values redacted
+	Headers map[string]string `json:"headers"`
+
+	// Error is the recovered panic value formatted as a string
+	Error string `json:"error"`
+
+	// Stack is the goroutine stack trace at the time of the panic
+	Stack string `json:"stack"`
+}
+
+// CrashSink receives crash reports
+// Sinks are called asynchronously and must be safe for concurrent use
+type CrashSink interface {
+	Report(report *CrashReport) error
+}
+
+// CrashSinkFunc adapts an ordinary function to the CrashSink interface
+type CrashSinkFunc func(report *CrashReport) error
+
+// Report calls f(report)
+func (f CrashSinkFunc) Report(report *CrashReport) error {
+	return f(report)
+}
+
+// RecoveryConfig defines the config for Recovery middleware
+type RecoveryConfig struct {
+	// Sink receives a structured report for every recovered panic
+	Sink CrashSink
+
+	// RedactHeaders lists additional request headers whose values are hidden in reports
+	// Authorization, Proxy-Authorization and Cookie are always redacted
+	RedactHeaders []string
+
+	// DisableStackTrace omits the stack trace from reports
+	DisableStackTrace bool
+}
+
+// redactedHeaders are never included verbatim in crash reports
+var redactedHeaders = []string{HeaderAuthorization, HeaderProxyAuthorization, HeaderCookie}
+
+// newCrashReport builds a crash report from the current request
+// Everything is copied so the report remains valid after the request context is released
+func newCrashReport(c *Context, rcv any, redact map[string]struct{}, withStack bool) *CrashReport {
+	report := &CrashReport{
+		Time:     c.Now(),
+		Method:   string(c.requestCtx.Method()),
+		URI:      string(c.requestCtx.RequestURI()),
+		Route:    c.fullPath,
+		ClientIP: c.ClientIP(),
+		Headers:  make(map[string]string),
+		Error:    fmt.Sprint(rcv),
+	}
+	if withStack {
+		report.Stack = string(debug.Stack())
+	}
+	report.RequestID = c.requestID()
+	for key, value := range c.requestCtx.Request.Header.All() {
+		name := http.CanonicalHeaderKey(string(key))
+		if _, hidden := redact[name]; hidden {
+			report.Headers[name] = "[REDACTED]"
+			continue
+		}
+		report.Headers[name] = string(value)
+	}
+	return report
+}
+
+// NewWriterCrashSink returns a sink writing every report as a JSON line to w
+// Writes are serialized, so w does not need to be safe for concurrent use
+func NewWriterCrashSink(w io.Writer) CrashSink {
+	var mu sync.Mutex
+	return CrashSinkFunc(func(report *CrashReport) error {
+		raw, err := sonic.Marshal(report)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, err = w.Write(append(raw, '\n'))
+		return err
+	})
+}
+
+// WebhookCrashSink posts every report as JSON to a webhook URL
+type WebhookCrashSink struct {
+	// URL is the webhook endpoint
+	URL string
+
+	// Timeout bounds each webhook request
+	Timeout time.Duration // Default = 5s
+
+	// Client performs the webhook requests; a default client is used when nil
+	Client *fasthttp.Client
+}
+
+// defaultWebhookClient is shared by webhook sinks that do not provide their own client
+var defaultWebhookClient = &fasthttp.Client{}
+
+// Report implements the CrashSink interface
+func (s *WebhookCrashSink) Report(report *CrashReport) error {
+	raw, err := sonic.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI(s.URL)
+	req.Header.SetMethod(MethodPost)
+	req.Header.SetContentType(MIMEApplicationJSON)
+	req.SetBodyRaw(raw)
+	client := s.Client
+	if client == nil {
+		client = defaultWebhookClient
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	if err := client.DoTimeout(req, resp, timeout); err != nil {
+		return err
+	}
+	if resp.StatusCode() >= StatusBadRequest {
+		return fmt.Errorf("%w: webhook returned status %d", ErrCrashReportRejected, resp.StatusCode())
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package gonoleks
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRecoveryWithSink(t *testing.T) {
+	reports := make(chan *CrashReport, 1)
+	app := New()
+	app.Use(RecoveryWithConfig(RecoveryConfig{
+		Sink: CrashSinkFunc(func(report *CrashReport) error {
+			reports <- report
+			return nil
+		}),
+		RedactHeaders: []string{"X-Api-Key"},
+	}))
+	app.GET("/panic", func(c *Context) {
+		panic("boom")
+	})
+	app.setupRouter()
+
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/panic?debug=1")
+	reqCtx.Request.Header.SetMethod(MethodGet)
+	reqCtx.Request.Header.Set(HeaderXRequestID, "req-123")
+	reqCtx.Request.Header.Set(HeaderAuthorization, "Bearer secret")
+	reqCtx.Request.Header.Set("X-Api-Key", "secret")
+	reqCtx.Request.Header.Set(HeaderUserAgent, "test-agent")
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusInternalServerError, reqCtx.Response.StatusCode())
+
+	select {
+	case report := <-reports:
+		assert.Equal(t, "req-123", report.RequestID)
+		assert.Equal(t, MethodGet, report.Method)
+		assert.Equal(t, "/panic?debug=1", report.URI)
+		assert.Equal(t, "boom", report.Error)
+		assert.Contains(t, report.Stack, "goroutine")
+		assert.Equal(t, "[REDACTED]", report.Headers[HeaderAuthorization])
+		assert.Equal(t, "[REDACTED]", report.Headers["X-Api-Key"])
+		assert.Equal(t, "test-agent", report.Headers[HeaderUserAgent])
+	case <-time.After(time.Second):
+		t.Fatal("Crash report should be delivered to the sink")
+	}
+}
+
+func TestWriterCrashSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterCrashSink(&buf)
+	require.NoError(t, sink.Report(&CrashReport{RequestID: "abc", Error: "boom"}))
+
+	var decoded CrashReport
+	require.NoError(t, sonic.Unmarshal(bytes.TrimSpace(buf.Bytes()), &decoded))
+	assert.Equal(t, "abc", decoded.RequestID)
+	assert.Equal(t, "boom", decoded.Error)
+}
+
+func TestWebhookCrashSink(t *testing.T) {
+	received := make(chan []byte, 1)
+	client := startTestUpstream(t, func(ctx *fasthttp.RequestCtx) {
+		received <- append([]byte(nil), ctx.Request.Body()...)
+		if string(ctx.Path()) == "/reject" {
+			ctx.SetStatusCode(StatusServiceUnavailable)
+		}
+	})
+	sink := &WebhookCrashSink{URL: "http://hooks/crash", Client: client}
+	require.NoError(t, sink.Report(&CrashReport{Error: "boom"}))
+	assert.Contains(t, string(<-received), `"error":"boom"`)
+
+	sink.URL = "http://hooks/reject"
+	err := sink.Report(&CrashReport{Error: "boom"})
+	assert.ErrorIs(t, err, ErrCrashReportRejected)
+	<-received
+}
@@ -0,0 +1,213 @@
+package gonoleks
+
+import (
+	"net"
+	"path"
+	"strings"
+)
+
+// RedirectToHTTPS instances a middleware that redirects plain HTTP requests to HTTPS
+// The path and query string are preserved, and X-Forwarded-Proto is honored for requests
+// from Options.TrustedProxies so TLS terminated at a load balancer is detected
+// Permanent redirects use 301 or 308, temporary ones 302 or 307, keeping the method for non-GET requests
+func RedirectToHTTPS(permanent bool) handlerFunc {
+	return func(c *Context) {
+		if c.Scheme() == "https" {
+			c.Next()
+			return
+		}
+		host := c.Host()
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		c.redirectTo(permanent, "https://"+host+string(c.requestCtx.RequestURI()))
+	}
+}
+
+// RedirectToHost instances a middleware that permanently redirects requests for any other host
+// to the canonical one, preserving the path and query string
+// The canonical host may include a scheme, e.g. "https://example.com", to enforce it as well
+//
+//	app.Use(gonoleks.RedirectToHost("example.com"))
+func RedirectToHost(canonical string) handlerFunc {
+	scheme, host, found := strings.Cut(canonical, "://")
+	if !found {
+		scheme, host = "", canonical
+	}
+	host = strings.TrimSuffix(host, "/")
+	return func(c *Context) {
+		requestScheme := c.Scheme()
+		if strings.EqualFold(c.Host(), host) && (scheme == "" || strings.EqualFold(requestScheme, scheme)) {
+			c.Next()
+			return
+		}
+		target := scheme
+		if target == "" {
+			target = requestScheme
+		}
+		c.redirectTo(true, target+"://"+host+string(c.requestCtx.RequestURI()))
+	}
+}
+
+// redirectTo redirects to location and aborts the handler chain
+// GET and HEAD requests get 301 or 302, other methods 308 or 307 so the method and body are kept
+func (c *Context) redirectTo(permanent bool, location string) {
+	method := string(c.requestCtx.Method())
+	safe := method == MethodGet || method == MethodHead
+	code := StatusFound
+	switch {
+	case permanent && safe:
+		code = StatusMovedPermanently
+	case permanent:
+		code = StatusPermanentRedirect
+	case !safe:
+		code = StatusTemporaryRedirect
+	}
+	c.Redirect(code, location)
+	c.Abort()
+}
+
+// WWWRedirectConfig defines the config for RedirectWWWWithConfig
+type WWWRedirectConfig struct {
+	// WWW redirects the apex domain to the www subdomain when true,
+	// and the www subdomain to the apex domain otherwise
+	WWW bool
+
+	// Code is the redirect status code, e.g. 301, 302 or 308
+	Code int // Default = 301
+
+	// Exclude lists request paths that are never redirected
+	// Patterns use path.Match syntax, and a trailing "*" matches any remaining path
+	Exclude []string
+}
+
+// RedirectToWWW instances a middleware that permanently redirects example.com to www.example.com
+func RedirectToWWW() handlerFunc {
+	return RedirectWWWWithConfig(WWWRedirectConfig{WWW: true})
+}
+
+// RedirectToApex instances a middleware that permanently redirects www.example.com to example.com
+func RedirectToApex() handlerFunc {
+	return RedirectWWWWithConfig(WWWRedirectConfig{})
+}
+
+// RedirectWWWWithConfig instances a www/apex host normalization middleware with config
+// Requests for IP addresses and localhost are never redirected
+func RedirectWWWWithConfig(conf WWWRedirectConfig) handlerFunc {
+	code := conf.Code
+	if code == 0 {
+		code = StatusMovedPermanently
+	}
+	return func(c *Context) {
+		if matchPathPatterns(conf.Exclude, string(c.requestCtx.Path())) {
+			c.Next()
+			return
+		}
+		host := c.Host()
+		hostname := host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			hostname = h
+		}
+		if hostname == "localhost" || net.ParseIP(hostname) != nil {
+			c.Next()
+			return
+		}
+		hasWWW := len(host) > 4 && strings.EqualFold(host[:4], "www.")
+		switch {
+		case conf.WWW && !hasWWW:
+			host = "www." + host
+		case !conf.WWW && hasWWW:
+			host = host[4:]
+		default:
+			c.Next()
+			return
+		}
+		c.Redirect(code, c.Scheme()+"://"+host+string(c.requestCtx.RequestURI()))
+		c.Abort()
+	}
+}
+
+// LocaleRedirectConfig defines the config for LocaleRedirectWithConfig
+type LocaleRedirectConfig struct {
+	// Locales lists the supported locale prefixes, e.g. "en", "de" or "pt-br"
+	Locales []string
+
+	// Default is used when the client prefers none of the supported locales
+	Default string // Default = Locales[0]
+
+	// Detect picks the locale for a request without a locale prefix
+	Detect func(c *Context) string // Default = best match of the Accept-Language header
+
+	// Code is the redirect status code, e.g. 301, 302 or 308
+	Code int // Default = 302
+
+	// Exclude lists request paths that are never redirected, e.g. "/api/*" or "/favicon.ico"
+	// Patterns use path.Match syntax, and a trailing "*" matches any remaining path
+	Exclude []string
+}
+
+// LocaleRedirect instances a middleware that inserts a locale prefix into paths without one,
+// e.g. /pricing becomes /de/pricing for a client preferring German
+func LocaleRedirect(locales ...string) handlerFunc {
+	return LocaleRedirectWithConfig(LocaleRedirectConfig{Locales: locales})
+}
+
+// LocaleRedirectWithConfig instances a locale prefix redirect middleware with config
+func LocaleRedirectWithConfig(conf LocaleRedirectConfig) handlerFunc {
+	supported := make(map[string]struct{}, len(conf.Locales))
+	for _, locale := range conf.Locales {
+		supported[strings.ToLower(locale)] = struct{}{}
+	}
+	fallback := conf.Default
+	if fallback == "" && len(conf.Locales) > 0 {
+		fallback = conf.Locales[0]
+	}
+	code := conf.Code
+	if code == 0 {
+		code = StatusFound
+	}
+	detect := conf.Detect
+	if detect == nil {
+		detect = func(c *Context) string {
+			if c.GetHeader(HeaderAcceptLanguage) == "" {
+				return ""
+			}
+			return c.AcceptsLanguages(conf.Locales...)
+		}
+	}
+	return func(c *Context) {
+		requestPath := string(c.requestCtx.Path())
+		segment, _, _ := strings.Cut(strings.TrimPrefix(requestPath, "/"), "/")
+		if _, ok := supported[strings.ToLower(segment)]; ok || matchPathPatterns(conf.Exclude, requestPath) {
+			c.Next()
+			return
+		}
+		locale := detect(c)
+		if locale == "" {
+			locale = fallback
+		}
+		location := "/" + locale + requestPath
+		if query := c.requestCtx.URI().QueryString(); len(query) > 0 {
+			location += "?" + string(query)
+		}
+		c.Redirect(code, location)
+		c.Abort()
+	}
+}
+
+// matchPathPatterns reports whether p matches any of the patterns
+// A trailing "*" matches any remaining path, other patterns use path.Match syntax
+func matchPathPatterns(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && !strings.ContainsAny(prefix, "*?[") {
+			if strings.HasPrefix(p, prefix) {
+				return true
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, p); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package gonoleks
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// newProxiedRequest builds a request arriving from remoteIP with the given headers
+func newProxiedRequest(method, uri, remoteIP string, headers map[string]string) *fasthttp.RequestCtx {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI(uri)
+	req.Header.SetMethod(method)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Init(req, &net.TCPAddr{IP: net.ParseIP(remoteIP), Port: 4321}, nil)
+	return reqCtx
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	app := New()
+	app.TrustedProxies = []string{"10.0.0.0/8"}
+	app.Use(RedirectToHTTPS(true))
+	app.Any("/orders", func(c *Context) {
+		c.String(StatusOK, "ok")
+	})
+	app.setupRouter()
+
+	tests := []struct {
+		name     string
+		method   string
+		remoteIP string
+		proto    string
+		code     int
+		location string
+	}{
+		{"Plain GET", MethodGet, "203.0.113.5", "", StatusMovedPermanently, "https://shop.example.com/orders?page=2"},
+		{"Plain POST keeps the method", MethodPost, "203.0.113.5", "", StatusPermanentRedirect, "https://shop.example.com/orders?page=2"},
+		{"TLS at trusted proxy", MethodGet, "10.1.2.3", "https", StatusOK, ""},
+		{"Forged header from untrusted peer", MethodGet, "203.0.113.5", "https", StatusMovedPermanently, "https://shop.example.com/orders?page=2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{HeaderHost: "shop.example.com:8080"}
+			if tt.proto != "" {
+				headers[HeaderXForwardedProto] = tt.proto
+			}
+			reqCtx := newProxiedRequest(tt.method, "/orders?page=2", tt.remoteIP, headers)
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.code, reqCtx.Response.StatusCode())
+			assert.Equal(t, tt.location, string(reqCtx.Response.Header.Peek(HeaderLocation)))
+		})
+	}
+}
+
+func TestRedirectToHost(t *testing.T) {
+	app := New()
+	app.Use(RedirectToHost("https://example.com"))
+	app.GET("/docs", func(c *Context) {
+		c.String(StatusOK, "docs")
+	})
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/docs?q=1", "203.0.113.5", map[string]string{HeaderHost: "www.example.com"})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusMovedPermanently, reqCtx.Response.StatusCode())
+	assert.Equal(t, "https://example.com/docs?q=1", string(reqCtx.Response.Header.Peek(HeaderLocation)))
+
+	// Without trusted proxies configured the forwarding headers are ignored
+	reqCtx = newProxiedRequest(MethodGet, "/docs", "203.0.113.5", map[string]string{
+		HeaderHost:            "internal:8080",
+		HeaderXForwardedHost:  "example.com",
+		HeaderXForwardedProto: "https",
+	})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusMovedPermanently, reqCtx.Response.StatusCode())
+	assert.Equal(t, "https://example.com/docs", string(reqCtx.Response.Header.Peek(HeaderLocation)))
+}
+
+func TestRedirectToHTTPSWithoutTrustedProxies(t *testing.T) {
+	app := New()
+	app.Use(RedirectToHTTPS(true))
+	app.GET("/orders", func(c *Context) {
+		c.String(StatusOK, "ok")
+	})
+	app.setupRouter()
+
+	// Forwarding headers cannot point the redirect at another host
+	reqCtx := newProxiedRequest(MethodGet, "/orders", "203.0.113.5", map[string]string{
+		HeaderHost:            "shop.example.com",
+		HeaderXForwardedHost:  "evil.example",
+		HeaderXForwardedProto: "https",
+		HeaderForwarded:       "host=evil.example;proto=https",
+	})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusMovedPermanently, reqCtx.Response.StatusCode())
+	assert.Equal(t, "https://shop.example.com/orders", string(reqCtx.Response.Header.Peek(HeaderLocation)))
+}
+
+func TestRedirectWWW(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     WWWRedirectConfig
+		host     string
+		uri      string
+		code     int
+		location string
+	}{
+		{"Apex to www", WWWRedirectConfig{WWW: true}, "example.com", "/a?b=1", StatusMovedPermanently, "http://www.example.com/a?b=1"},
+		{"Already www", WWWRedirectConfig{WWW: true}, "www.example.com", "/a", StatusOK, ""},
+		{"www to apex with port", WWWRedirectConfig{Code: StatusPermanentRedirect}, "WWW.example.com:8080", "/a", StatusPermanentRedirect, "http://example.com:8080/a"},
+		{"Excluded path", WWWRedirectConfig{Exclude: []string{"/.well-known/*"}}, "www.example.com", "/.well-known/acme-challenge/x", StatusOK, ""},
+		{"IP address", WWWRedirectConfig{WWW: true}, "127.0.0.1:8080", "/a", StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := New()
+			app.Use(RedirectWWWWithConfig(tt.conf))
+			app.GET("/*path", func(c *Context) {
+				c.String(StatusOK, "ok")
+			})
+			app.setupRouter()
+			reqCtx := newProxiedRequest(MethodGet, tt.uri, "203.0.113.5", map[string]string{HeaderHost: tt.host})
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.code, reqCtx.Response.StatusCode())
+			assert.Equal(t, tt.location, string(reqCtx.Response.Header.Peek(HeaderLocation)))
+		})
+	}
+}
+
+func TestLocaleRedirect(t *testing.T) {
+	app := New()
+	app.Use(LocaleRedirectWithConfig(LocaleRedirectConfig{
+		Locales: []string{"en", "de", "pt-BR"},
+		Exclude: []string{"/api/*", "/favicon.ico"},
+	}))
+	app.GET("/*path", func(c *Context) {
+		c.String(StatusOK, "ok")
+	})
+	app.setupRouter()
+
+	tests := []struct {
+		name           string
+		uri            string
+		acceptLanguage string
+		code           int
+		location       string
+	}{
+		{"Preferred locale", "/pricing?plan=pro", "fr;q=0.9, de-AT, en;q=0.5", StatusFound, "/de/pricing?plan=pro"},
+		{"Region specific locale", "/", "pt-br", StatusFound, "/pt-BR/"},
+		{"Default locale", "/pricing", "ja", StatusFound, "/en/pricing"},
+		{"Already prefixed", "/de/pricing", "en", StatusOK, ""},
+		{"Excluded path", "/api/users", "de", StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqCtx := newProxiedRequest(MethodGet, tt.uri, "203.0.113.5", map[string]string{HeaderAcceptLanguage: tt.acceptLanguage})
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.code, reqCtx.Response.StatusCode())
+			assert.Equal(t, tt.location, string(reqCtx.Response.Header.Peek(HeaderLocation)))
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package gonoleks
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisStoreConfig defines the config for RedisStore
+type RedisStoreConfig struct {
+	// Addr is the host:port of the Redis server
+	Addr string // Default = "127.0.0.1:6379"
+
+	// Username and Password authenticate the connections, Username requires Redis 6 ACLs
+	Username string
+	Password string
+
+	// DB selects the database
+	DB int
+
+	// Prefix is prepended to every key, e.g. "myapp:"
+	Prefix string
+
+	// PoolSize is the maximum number of idle connections kept open
+	PoolSize int // Default = 10
+
+	// DialTimeout bounds connecting to the server
+	DialTimeout time.Duration // Default = 5 seconds
+
+	// Timeout bounds every command
+	Timeout time.Duration // Default = 3 seconds
+
+	// TLS enables TLS with the given config
+	TLS *tls.Config
+}
+
+// RedisStore is a Store backed by Redis, sharing middleware state such as nonces and
+// rate limits across instances
+// It speaks the Redis protocol itself, so no client library is needed, and Incr requires Redis 7
+// It is safe for concurrent use
+type RedisStore struct {
+	config RedisStoreConfig
+	pool   chan *redisConn
+	closed chan struct{}
+	once   sync.Once
+}
+
+// redisConn is a connection to the Redis server
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// redisError is an error reply of the Redis server
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// NewRedisStore creates a RedisStore, connections are opened on first use
+func NewRedisStore(config RedisStoreConfig) *RedisStore {
+	if config.Addr == "" {
+		config.Addr = "127.0.0.1:6379"
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = 10
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 3 * time.Second
+	}
+	return &RedisStore{
+		config: config,
+		pool:   make(chan *redisConn, config.PoolSize),
+		closed: make(chan struct{}),
+	}
+}
+
+// Get implements Store
+func (s *RedisStore) Get(key string) ([]byte, bool, error) {
+	reply, err := s.do("GET", s.config.Prefix+key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected reply %T to GET", reply)
+	}
+	return value, true, nil
+}
+
+// Set implements Store
+func (s *RedisStore) Set(key string, value []byte, ttl time.Duration) error {
+	args := []any{"SET", s.config.Prefix + key, value}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+	_, err := s.do(args...)
+	return err
+}
+
+// SetNX implements Store
+func (s *RedisStore) SetNX(key string, value []byte, ttl time.Duration) (bool, error) {
+	args := []any{"SET", s.config.Prefix + key, value, "NX"}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+	reply, err := s.do(args...)
+	return err == nil && reply != nil, err
+}
+
+// Delete implements Store
+func (s *RedisStore) Delete(key string) error {
+	_, err := s.do("DEL", s.config.Prefix+key)
+	return err
+}
+
+// Incr implements Store
+// The expiry is set with PEXPIRE NX in the same round trip, so it applies only to new keys
+func (s *RedisStore) Incr(key string, delta int64, ttl time.Duration) (int64, error) {
+	key = s.config.Prefix + key
+	commands := [][]any{{"INCRBY", key, delta}}
+	if ttl > 0 {
+		commands = append(commands, []any{"PEXPIRE", key, ttl.Milliseconds(), "NX"})
+	}
+	replies, err := s.pipeline(commands...)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := replies[0].(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected reply %T to INCRBY", replies[0])
+	}
+	return n, nil
+}
+
+// Ping checks the connection to the server
+func (s *RedisStore) Ping() error {
+	_, err := s.do("PING")
+	return err
+}
+
+// Close closes the idle connections, the store must not be used afterwards
+func (s *RedisStore) Close() error {
+	s.once.Do(func() { close(s.closed) })
+	for {
+		select {
+		case conn := <-s.pool:
+			_ = conn.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do sends a command and returns its reply
+func (s *RedisStore) do(args ...any) (any, error) {
+	replies, err := s.pipeline(args)
+	if err != nil {
+		return nil, err
+	}
+	return replies[0], nil
+}
+
+// pipeline sends commands in a single round trip and returns their replies
+// An error reply to any command is returned as the error
+func (s *RedisStore) pipeline(commands ...[]any) ([]any, error) {
+	conn, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.conn.SetDeadline(time.Now().Add(s.config.Timeout))
+	replies, err := conn.roundTrip(commands)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection is in an unknown state
+		_ = conn.conn.Close()
+		return nil, err
+	}
+	s.release(conn)
+	return replies, err
+}
+
+// acquire returns an idle connection or dials a new one
+func (s *RedisStore) acquire() (*redisConn, error) {
+	select {
+	case <-s.closed:
+		return nil, net.ErrClosed
+	case conn := <-s.pool:
+		return conn, nil
+	default:
+	}
+	dialer := &net.Dialer{Timeout: s.config.DialTimeout}
+	var conn net.Conn
+	var err error
+	if s.config.TLS != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.config.Addr, s.config.TLS)
+	} else {
+		conn, err = dialer.Dial("tcp", s.config.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rc := &redisConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+	var setup [][]any
+	if s.config.Password != "" {
+		if s.config.Username != "" {
+			setup = append(setup, []any{"AUTH", s.config.Username, s.config.Password})
+		} else {
+			setup = append(setup, []any{"AUTH", s.config.Password})
+		}
+	}
+	if s.config.DB != 0 {
+		setup = append(setup, []any{"SELECT", s.config.DB})
+	}
+	if len(setup) > 0 {
+		_ = conn.SetDeadline(time.Now().Add(s.config.Timeout))
+		if _, err := rc.roundTrip(setup); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return rc, nil
+}
+
+// release returns a connection to the pool, closing it when the pool is full or closed
+func (s *RedisStore) release(conn *redisConn) {
+	select {
+	case <-s.closed:
+		_ = conn.conn.Close()
+		return
+	default:
+	}
+	select {
+	case s.pool <- conn:
+	default:
+		_ = conn.conn.Close()
+	}
+}
+
+// roundTrip writes commands and reads one reply per command
+func (rc *redisConn) roundTrip(commands [][]any) ([]any, error) {
+	for _, args := range commands {
+		writeRedisCommand(rc.w, args)
+	}
+	if err := rc.w.Flush(); err != nil {
+		return nil, err
+	}
+	replies := make([]any, len(commands))
+	var replyErr error
+	for i := range commands {
+		reply, err := readRedisReply(rc.r)
+		var redisErr redisError
+		if errors.As(err, &redisErr) {
+			// Keep reading so the connection stays in sync
+			if replyErr == nil {
+				replyErr = err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		replies[i] = reply
+	}
+	return replies, replyErr
+}
+
+// writeRedisCommand writes a command as an array of bulk strings
+func writeRedisCommand(w *bufio.Writer, args []any) {
+	_, _ = w.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		var value []byte
+		switch v := arg.(type) {
+		case string:
+			value = []byte(v)
+		case []byte:
+			value = v
+		case int:
+			value = strconv.AppendInt(nil, int64(v), 10)
+		case int64:
+			value = strconv.AppendInt(nil, v, 10)
+		default:
+			value = fmt.Append(nil, v)
+		}
+		_, _ = w.WriteString("$" + strconv.Itoa(len(value)) + "\r\n")
+		_, _ = w.Write(value)
+		_, _ = w.WriteString("\r\n")
+	}
+}
+
+// readRedisReply reads a RESP2 reply
+// Nil replies are returned as nil, error replies as a redisError
+func readRedisReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, payload := line[0], string(line[1:len(line)-2])
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		value := make([]byte, n+2)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return nil, err
+		}
+		return value[:n], nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		values := make([]any, n)
+		for i := range values {
+			value, err := readRedisReply(r)
+			var redisErr redisError
+			if err != nil && !errors.As(err, &redisErr) {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+}
@@ -0,0 +1,192 @@
+package gonoleks
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis is a minimal Redis server supporting the commands used by RedisStore
+type fakeRedis struct {
+	mu       sync.Mutex
+	values   map[string][]byte
+	expires  map[string]time.Time
+	commands []string
+}
+
+func startFakeRedis(t *testing.T) (*fakeRedis, string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	f := &fakeRedis{values: make(map[string][]byte), expires: make(map[string]time.Time)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f, ln.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	for {
+		reply, err := readRedisReply(r)
+		if err != nil {
+			return
+		}
+		items := reply.([]any)
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i] = string(item.([]byte))
+		}
+		_, _ = w.WriteString(f.exec(args))
+		if r.Buffered() == 0 {
+			_ = w.Flush()
+		}
+	}
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commands = append(f.commands, strings.Join(args, " "))
+	key := ""
+	if len(args) > 1 {
+		key = args[1]
+		if expiry, ok := f.expires[key]; ok && time.Now().After(expiry) {
+			delete(f.values, key)
+			delete(f.expires, key)
+		}
+	}
+	switch strings.ToUpper(args[0]) {
+	case "PING", "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		value, ok := f.values[key]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return "$" + strconv.Itoa(len(value)) + "\r\n" + string(value) + "\r\n"
+	case "SET":
+		_, exists := f.values[key]
+		for i := 3; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "NX":
+				if exists {
+					return "$-1\r\n"
+				}
+			case "PX":
+				ms, _ := strconv.Atoi(args[i+1])
+				f.expires[key] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+				i++
+			}
+		}
+		f.values[key] = []byte(args[2])
+		return "+OK\r\n"
+	case "DEL":
+		delete(f.values, key)
+		delete(f.expires, key)
+		return ":1\r\n"
+	case "INCRBY":
+		n, err := strconv.ParseInt(string(f.values[key]), 10, 64)
+		if err != nil && f.values[key] != nil {
+			return "-ERR value is not an integer or out of range\r\n"
+		}
+		delta, _ := strconv.ParseInt(args[2], 10, 64)
+		n += delta
+		f.values[key] = []byte(strconv.FormatInt(n, 10))
+		return ":" + strconv.FormatInt(n, 10) + "\r\n"
+	case "PEXPIRE":
+		if _, ok := f.expires[key]; ok {
+			return ":0\r\n"
+		}
+		ms, _ := strconv.Atoi(args[2])
+		f.expires[key] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		return ":1\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
+
+func TestRedisStore(t *testing.T) {
+	fake, addr := startFakeRedis(t)
+	store := NewRedisStore(RedisStoreConfig{Addr: addr, Prefix: "app:", Password: "pw", DB: 2})
+	defer store.Close()
+
+	require.NoError(t, store.Ping())
+	assert.Equal(t, []string{"AUTH pw", "SELECT 2", "PING"}, fake.commands)
+
+	_, ok, err := store.Get("missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.Set("greeting", []byte("hello\r\nworld"), time.Minute))
+	value, ok, err := store.Get("greeting")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "hello\r\nworld", string(value))
+	assert.Contains(t, fake.commands, "SET app:greeting hello\r\nworld PX 60000")
+
+	stored, err := store.SetNX("lock", []byte("1"), time.Second)
+	require.NoError(t, err)
+	assert.True(t, stored)
+	stored, err = store.SetNX("lock", []byte("1"), time.Second)
+	require.NoError(t, err)
+	assert.False(t, stored)
+
+	n, err := store.Incr("hits", 2, time.Minute)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	n, err = store.Incr("hits", 3, time.Minute)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, n)
+	assert.Contains(t, fake.commands, "PEXPIRE app:hits 60000 NX")
+
+	_, err = store.Incr("greeting", 1, 0)
+	var replyErr redisError
+	assert.ErrorAs(t, err, &replyErr)
+	// The connection stays usable after an error reply
+	require.NoError(t, store.Delete("greeting"))
+	_, ok, err = store.Get("greeting")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRedisStoreConcurrent(t *testing.T) {
+	_, addr := startFakeRedis(t)
+	store := NewRedisStore(RedisStoreConfig{Addr: addr, PoolSize: 2})
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			_, err := store.Incr("counter", 1, 0)
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+	value, _, err := store.Get("counter")
+	require.NoError(t, err)
+	assert.Equal(t, "20", string(value))
+
+	require.NoError(t, store.Close())
+	assert.ErrorIs(t, store.Ping(), net.ErrClosed)
+}
+
+func TestRedisStoreUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+	store := NewRedisStore(RedisStoreConfig{Addr: addr, DialTimeout: 100 * time.Millisecond})
+	assert.Error(t, store.Ping())
+}
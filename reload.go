@@ -0,0 +1,359 @@
+package gonoleks
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/bytedance/sonic"
+	"github.com/valyala/fasthttp"
+	"gopkg.in/yaml.v3"
+)
+
+// RuntimeConfig holds the options that can be changed while the server runs, without
+// restarting its listeners
+// Nil fields are left unchanged by Reload, so a config may update a single option
+type RuntimeConfig struct {
+	// LogLevel is the level of the diagnostics logger, e.g. "debug" or "warn"
+	LogLevel *string `json:"log_level,omitempty" yaml:"log_level,omitempty"`
+
+	// TrustedProxies replaces Options.TrustedProxies, an empty list trusts no proxy for the scheme and host
+	TrustedProxies []string `json:"trusted_proxies,omitempty" yaml:"trusted_proxies,omitempty"`
+
+	// Maintenance answers every request with 503 Service Unavailable while set
+	Maintenance *bool `json:"maintenance,omitempty" yaml:"maintenance,omitempty"`
+
+	// RateLimit is the number of requests per second allowed per client IP, zero disables it
+	RateLimit *float64 `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+
+	// RateLimitBurst is the number of requests a client IP may make at once
+	RateLimitBurst *int `json:"rate_limit_burst,omitempty" yaml:"rate_limit_burst,omitempty"`
+}
+
+// liveOptions holds the current values of the runtime options enforced per request
+type liveOptions struct {
+	maintenance bool
+	rateLimit   float64
+	rateBurst   int
+}
+
+// ipRateLimiter keeps a token bucket per client IP
+type ipRateLimiter struct {
+	buckets   sync.Map // client IP -> *tokenBucket
+	lastSweep atomic.Int64
+}
+
+// rateLimitSweepInterval is how often idle client buckets are removed
+const rateLimitSweepInterval = time.Minute
+
+// Reload applies the non-nil fields of config to the running app
+// The whole config is validated first, so an invalid config changes nothing
+func (g *Gonoleks) Reload(config RuntimeConfig) error {
+	var errs []error
+	var level log.Level
+	if config.LogLevel != nil {
+		var err error
+		if level, err = log.ParseLevel(*config.LogLevel); err != nil {
+			errs = append(errs, fmt.Errorf("log_level: %w", err))
+		}
+	}
+	for _, proxy := range config.TrustedProxies {
+		if err := validateTrustedProxy(proxy); err != nil {
+			errs = append(errs, fmt.Errorf("trusted_proxies: %w", err))
+		}
+	}
+	if config.RateLimit != nil && (*config.RateLimit < 0 || math.IsNaN(*config.RateLimit) || math.IsInf(*config.RateLimit, 0)) {
+		errs = append(errs, fmt.Errorf("rate_limit: %w", ErrInvalidRuntimeConfig))
+	}
+	if config.RateLimitBurst != nil && *config.RateLimitBurst < 0 {
+		errs = append(errs, fmt.Errorf("rate_limit_burst: %w", ErrInvalidRuntimeConfig))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	if config.LogLevel != nil {
+		g.diagnostics.SetLevel(level)
+	}
+	if config.TrustedProxies != nil {
+		g.setTrustedProxies(config.TrustedProxies)
+	}
+	g.liveMu.Lock()
+	live := g.liveOptions()
+	if config.Maintenance != nil {
+		live.maintenance = *config.Maintenance
+	}
+	if config.RateLimit != nil {
+		live.rateLimit = *config.RateLimit
+	}
+	if config.RateLimitBurst != nil {
+		live.rateBurst = *config.RateLimitBurst
+	}
+	g.live.Store(&live)
+	g.liveMu.Unlock()
+	current := g.RuntimeConfig()
+	g.diagnostics.Info("Runtime config reloaded",
+		"log_level", *current.LogLevel,
+		"trusted_proxies", len(current.TrustedProxies),
+		"maintenance", *current.Maintenance,
+		"rate_limit", *current.RateLimit,
+		"rate_limit_burst", *current.RateLimitBurst,
+	)
+	return nil
+}
+
+// RuntimeConfig returns the current values of the runtime options
+func (g *Gonoleks) RuntimeConfig() RuntimeConfig {
+	live := g.liveOptions()
+	level := g.diagnostics.GetLevel().String()
+	var proxies []string
+	if set := g.trustedProxies.Load(); set != nil {
+		proxies = set.proxies
+	}
+	return RuntimeConfig{
+		LogLevel:       &level,
+		TrustedProxies: proxies,
+		Maintenance:    &live.maintenance,
+		RateLimit:      &live.rateLimit,
+		RateLimitBurst: &live.rateBurst,
+	}
+}
+
+// SetMaintenance turns maintenance mode on or off
+func (g *Gonoleks) SetMaintenance(on bool) {
+	g.liveMu.Lock()
+	defer g.liveMu.Unlock()
+	live := g.liveOptions()
+	live.maintenance = on
+	g.live.Store(&live)
+}
+
+// SetRateLimit changes the per client IP rate limit, a zero rate disables it
+func (g *Gonoleks) SetRateLimit(rate float64, burst int) {
+	g.liveMu.Lock()
+	defer g.liveMu.Unlock()
+	live := g.liveOptions()
+	live.rateLimit, live.rateBurst = rate, burst
+	g.live.Store(&live)
+}
+
+// ReloadOnSignal reloads the runtime config returned by load whenever the process receives
+// one of the signals, SIGHUP by default
+// Failed loads and invalid configs are logged and leave the running config unchanged
+// The returned function stops listening for the signals
+//
+//	stop := app.ReloadOnSignal(func() (gonoleks.RuntimeConfig, error) {
+//		return gonoleks.LoadRuntimeConfig("/etc/myapp/runtime.yaml")
+//	})
+//	defer stop()
+func (g *Gonoleks) ReloadOnSignal(load func() (RuntimeConfig, error), sig ...os.Signal) func() {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, sig...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case received := <-signals:
+				config, err := load()
+				if err == nil {
+					err = g.Reload(config)
+				}
+				if err != nil {
+					g.diagnostics.Error("Failed to reload runtime config", "signal", received, "error", err)
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+// LoadRuntimeConfig reads a runtime config from a YAML file, or a JSON file when its extension is .json
+func LoadRuntimeConfig(path string) (RuntimeConfig, error) {
+	var config RuntimeConfig
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = sonic.Unmarshal(raw, &config)
+	} else {
+		err = yaml.Unmarshal(raw, &config)
+	}
+	if err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ReloadHandler returns a handler exposing the runtime config, e.g. on an admin route
+// GET answers with the current config, other methods apply the JSON config of the body
+// and answer with the resulting config
+// The handler performs no authorization, so it must only be reachable by operators
+//
+//	admin := app.Group("/admin", gonoleks.Authorize(operatorsOnly))
+//	admin.Match([]string{"GET", "PATCH"}, "/config", app.ReloadHandler())
+func (g *Gonoleks) ReloadHandler() handlerFunc {
+	return func(c *Context) {
+		if method := string(c.requestCtx.Method()); method != MethodGet && method != MethodHead {
+			var config RuntimeConfig
+			if err := sonic.Unmarshal(c.requestCtx.Request.Body(), &config); err != nil {
+				_ = c.AbortWithStatusProblem(StatusBadRequest, "invalid runtime config: "+err.Error(), nil)
+				return
+			}
+			if err := g.Reload(config); err != nil {
+				_ = c.AbortWithStatusProblem(StatusUnprocessableEntity, err.Error(), nil)
+				return
+			}
+		}
+		c.requestCtx.Response.Header.Set(HeaderCacheControl, "no-store")
+		_ = c.JSON(StatusOK, g.RuntimeConfig())
+	}
+}
+
+// liveOptions returns a copy of the current runtime options, initialized from Options
+func (g *Gonoleks) liveOptions() liveOptions {
+	if live := g.live.Load(); live != nil {
+		return *live
+	}
+	return liveOptions{maintenance: g.Maintenance, rateLimit: g.RateLimit, rateBurst: g.RateLimitBurst}
+}
+
+// shedRequest answers requests during maintenance or over the rate limit and reports whether it did
+// Requests to the admin endpoints are exempt from maintenance, so it can be turned off again,
+// but not from the rate limit, which runs before they are authenticated and throttles token guessing
+func (g *Gonoleks) shedRequest(c *Context) bool {
+	live := g.live.Load()
+	if live == nil {
+		return false
+	}
+	if live.maintenance && !g.isAdminPath(c) {
+		c.requestCtx.Error(fasthttp.StatusMessage(StatusServiceUnavailable), StatusServiceUnavailable)
+		c.Abort()
+		return true
+	}
+	if live.rateLimit > 0 {
+		if wait := g.takeRateLimit(c.rateLimitKey(), live.rateLimit, live.rateBurst); wait > 0 {
+			c.requestCtx.Error(fasthttp.StatusMessage(StatusTooManyRequests), StatusTooManyRequests)
+			c.requestCtx.Response.Header.Set(HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.Abort()
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitKey returns the address requests are rate limited by
+// The client IP is only used behind Options.TrustedProxies, since clients could otherwise
+// rotate forwarding headers to escape the limit or spend the budget of another address
+func (c *Context) rateLimitKey() string {
+	if c.isConfiguredProxy() {
+		return c.ClientIP()
+	}
+	return c.RemoteIP()
+}
+
+// takeRateLimit counts a request of ip against the rate limit and returns the time until
+// the next request is allowed, zero when this one is
+// Requests are counted in RateLimitStore when set, and allowed when the store fails
+func (g *Gonoleks) takeRateLimit(ip string, rate float64, burst int) time.Duration {
+	if g.RateLimitStore == nil {
+		return g.rateLimiter.take(ip, rate, burst)
+	}
+	if g.RateLimitSyncInterval > 0 {
+		wait := g.syncedRateLimiter.take(ip, rate, burst, g.RateLimitLocalBurst)
+		if g.syncedRateLimiter.syncDue(g.RateLimitSyncInterval) {
+			go g.syncedRateLimiter.sync(g.RateLimitStore, g.diagnostics)
+		}
+		return wait
+	}
+	wait, err := takeStoreRateLimit(g.RateLimitStore, ip, rate, burst)
+	if err != nil {
+		g.diagnostics.Warn("Rate limit store failed", "error", err)
+		return 0
+	}
+	return wait
+}
+
+// takeStoreRateLimit counts a request of ip in a fixed window of store, allowing burst requests
+// per window of burst/rate seconds
+// Windows are aligned on the clock, so instances sharing the store count in the same window
+func takeStoreRateLimit(store Store, ip string, rate float64, burst int) (time.Duration, error) {
+	if burst < 1 {
+		burst = int(math.Ceil(rate))
+	}
+	now := time.Now()
+	index, size := rateLimitWindow(now, rate, burst)
+	n, err := store.Incr(rateLimitKey(ip, index), 1, size+time.Second)
+	if err != nil {
+		return 0, err
+	}
+	if n > int64(burst) {
+		return rateLimitWait(now, index, size), nil
+	}
+	return 0, nil
+}
+
+// take consumes a token of the bucket of ip, see tokenBucket.take
+// A burst below one allows the rate rounded up
+func (l *ipRateLimiter) take(ip string, rate float64, burst int) time.Duration {
+	if burst < 1 {
+		burst = int(math.Ceil(rate))
+	}
+	now := time.Now()
+	if last := l.lastSweep.Load(); now.UnixNano()-last > int64(rateLimitSweepInterval) && l.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		l.sweep(now, rate, burst)
+	}
+	bucket, ok := l.buckets.Load(ip)
+	if !ok {
+		bucket, _ = l.buckets.LoadOrStore(ip, &tokenBucket{})
+	}
+	return bucket.(*tokenBucket).take(rate, burst)
+}
+
+// sweep removes the buckets that have refilled completely, as they are equivalent to new ones
+func (l *ipRateLimiter) sweep(now time.Time, rate float64, burst int) {
+	refill := time.Duration(float64(burst) / rate * float64(time.Second))
+	l.buckets.Range(func(ip, bucket any) bool {
+		b := bucket.(*tokenBucket)
+		b.mu.Lock()
+		idle := now.Sub(b.last) > refill
+		b.mu.Unlock()
+		if idle {
+			l.buckets.Delete(ip)
+		}
+		return true
+	})
+}
+
+// validateTrustedProxy reports whether proxy is an IP address or CIDR range
+func validateTrustedProxy(proxy string) error {
+	proxy = strings.TrimSpace(proxy)
+	if strings.Contains(proxy, "/") {
+		_, err := netip.ParsePrefix(proxy)
+		return err
+	}
+	_, err := netip.ParseAddr(proxy)
+	return err
+}
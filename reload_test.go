@@ -0,0 +1,167 @@
+package gonoleks
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	app := New()
+	app.TrustedProxies = []string{"10.0.0.0/8"}
+	app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	app.setupRouter()
+
+	level, maintenance, rate, burst := "warn", true, 5.0, 2
+	require.NoError(t, app.Reload(RuntimeConfig{
+		LogLevel:       &level,
+		TrustedProxies: []string{"192.0.2.0/24", "2001:db8::1"},
+		Maintenance:    &maintenance,
+		RateLimit:      &rate,
+		RateLimitBurst: &burst,
+	}))
+	assert.Equal(t, log.WarnLevel, app.Diagnostics().GetLevel())
+	assert.Len(t, app.trustedPrefixes(), 2)
+
+	current := app.RuntimeConfig()
+	assert.Equal(t, "warn", *current.LogLevel)
+	assert.Equal(t, []string{"192.0.2.0/24", "2001:db8::1"}, current.TrustedProxies)
+	assert.True(t, *current.Maintenance)
+	assert.Equal(t, 5.0, *current.RateLimit)
+	assert.Equal(t, 2, *current.RateLimitBurst)
+
+	// Nil fields are left unchanged
+	maintenance = false
+	require.NoError(t, app.Reload(RuntimeConfig{Maintenance: &maintenance}))
+	current = app.RuntimeConfig()
+	assert.False(t, *current.Maintenance)
+	assert.Equal(t, "warn", *current.LogLevel)
+	assert.Len(t, current.TrustedProxies, 2)
+
+	// Invalid configs change nothing
+	level, rate = "loud", -1
+	err := app.Reload(RuntimeConfig{LogLevel: &level, RateLimit: &rate, TrustedProxies: []string{"nope"}})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidRuntimeConfig)
+	assert.Contains(t, err.Error(), "log_level")
+	assert.Contains(t, err.Error(), "trusted_proxies")
+	assert.Equal(t, log.WarnLevel, app.Diagnostics().GetLevel())
+	assert.Len(t, app.trustedPrefixes(), 2)
+	assert.Equal(t, 5.0, *app.RuntimeConfig().RateLimit)
+}
+
+func TestMaintenanceAndRateLimit(t *testing.T) {
+	app := New()
+	app.RateLimit = 1
+	app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	reqCtx = newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusTooManyRequests, reqCtx.Response.StatusCode())
+	assert.Equal(t, "1", string(reqCtx.Response.Header.Peek(HeaderRetryAfter)))
+	// Other clients have their own budget
+	reqCtx = newProxiedRequest(MethodGet, "/", "203.0.113.6", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+
+	app.SetRateLimit(0, 0)
+	app.SetMaintenance(true)
+	reqCtx = newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusServiceUnavailable, reqCtx.Response.StatusCode())
+
+	app.SetMaintenance(false)
+	reqCtx = newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+}
+
+func TestRateLimitForwardedFor(t *testing.T) {
+	app := New()
+	app.RateLimit = 1
+	app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	app.setupRouter()
+
+	// Rotating X-Forwarded-For does not give a peer a new budget
+	for i, status := range []int{StatusOK, StatusTooManyRequests, StatusTooManyRequests} {
+		reqCtx := newProxiedRequest(MethodGet, "/", "203.0.113.5", map[string]string{HeaderXForwardedFor: "198.51.100." + strconv.Itoa(i)})
+		app.router.Handler(reqCtx)
+		assert.Equal(t, status, reqCtx.Response.StatusCode())
+	}
+	// Nor can a peer spend the budget of the address it claims
+	reqCtx := newProxiedRequest(MethodGet, "/", "203.0.113.6", map[string]string{HeaderXForwardedFor: "203.0.113.7"})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	reqCtx = newProxiedRequest(MethodGet, "/", "203.0.113.7", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+
+	// Behind a trusted proxy, clients are told apart by the forwarded address
+	app.setTrustedProxies([]string{"10.0.0.0/8"})
+	for _, ip := range []string{"198.51.100.1", "198.51.100.2"} {
+		reqCtx = newProxiedRequest(MethodGet, "/", "10.0.0.1", map[string]string{HeaderXForwardedFor: ip})
+		app.router.Handler(reqCtx)
+		assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	}
+}
+
+func TestIPRateLimiterSweep(t *testing.T) {
+	var l ipRateLimiter
+	l.lastSweep.Store(time.Now().UnixNano())
+	assert.Zero(t, l.take("192.0.2.1", 100, 1))
+	assert.Positive(t, l.take("192.0.2.1", 100, 1))
+	time.Sleep(20 * time.Millisecond)
+	l.sweep(time.Now(), 100, 1)
+	_, ok := l.buckets.Load("192.0.2.1")
+	assert.False(t, ok, "refilled buckets are removed")
+}
+
+func TestReloadHandler(t *testing.T) {
+	app := New()
+	app.Match([]string{MethodGet, MethodPatch}, "/admin/config", app.ReloadHandler())
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodPatch, "/admin/config", "203.0.113.5", nil)
+	reqCtx.Request.SetBodyString(`{"log_level":"debug","rate_limit":10}`)
+	app.router.Handler(reqCtx)
+	require.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	var body map[string]any
+	require.NoError(t, sonic.Unmarshal(reqCtx.Response.Body(), &body))
+	assert.Equal(t, "debug", body["log_level"])
+	assert.EqualValues(t, 10, body["rate_limit"])
+	assert.Equal(t, false, body["maintenance"])
+
+	reqCtx = newProxiedRequest(MethodPatch, "/admin/config", "203.0.113.5", nil)
+	reqCtx.Request.SetBodyString(`{"rate_limit_burst":-1}`)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusUnprocessableEntity, reqCtx.Response.StatusCode())
+
+	reqCtx = newProxiedRequest(MethodPatch, "/admin/config", "203.0.113.5", nil)
+	reqCtx.Request.SetBodyString(`{`)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode())
+}
+
+func TestLoadRuntimeConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"log_level":"error","trusted_proxies":[]}`), 0o600))
+	config, err := LoadRuntimeConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "error", *config.LogLevel)
+	assert.NotNil(t, config.TrustedProxies, "an empty list clears the trusted proxies")
+	assert.Nil(t, config.Maintenance)
+
+	_, err = LoadRuntimeConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
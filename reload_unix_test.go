@@ -0,0 +1,30 @@
+//go:build unix
+
+package gonoleks
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("maintenance: true\nrate_limit: 3\n"), 0o600))
+	config, err := LoadRuntimeConfig(path)
+	require.NoError(t, err)
+	assert.True(t, *config.Maintenance)
+	assert.Equal(t, 3.0, *config.RateLimit)
+
+	app := New()
+	app.setupRouter()
+	stop := app.ReloadOnSignal(func() (RuntimeConfig, error) { return LoadRuntimeConfig(path) }, syscall.SIGUSR1)
+	defer stop()
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool { return *app.RuntimeConfig().Maintenance }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,132 @@
+package gonoleks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Resource actions, used in route names and by ResourceMiddleware
+const (
+	ResourceIndex  = "index"
+	ResourceShow   = "show"
+	ResourceCreate = "create"
+	ResourceUpdate = "update"
+	ResourceDelete = "delete"
+)
+
+// ResourceIndexer is implemented by resource controllers listing the collection
+type ResourceIndexer interface {
+	Index(c *Context)
+}
+
+// ResourceShower is implemented by resource controllers showing a member
+type ResourceShower interface {
+	Show(c *Context)
+}
+
+// ResourceCreator is implemented by resource controllers creating members
+type ResourceCreator interface {
+	Create(c *Context)
+}
+
+// ResourceUpdater is implemented by resource controllers updating a member
+type ResourceUpdater interface {
+	Update(c *Context)
+}
+
+// ResourceDeleter is implemented by resource controllers deleting a member
+type ResourceDeleter interface {
+	Delete(c *Context)
+}
+
+// ResourceMiddleware is implemented by resource controllers running middleware before some actions,
+// e.g. an authorization check before create, update and delete
+type ResourceMiddleware interface {
+	Middleware(action string) []handlerFunc
+}
+
+// ResourceConfig defines the config for ResourceWithConfig
+type ResourceConfig struct {
+	// Param is the name of the path param identifying a member
+	Param string // Default = "id"
+
+	// Name prefixes the route names, e.g. "users" names the routes "users.index", "users.show", ...
+	Name string // Default = the static path segments joined with dots, e.g. "api.users"
+
+	// Middlewares run before every action of the resource
+	Middlewares []handlerFunc
+}
+
+// Resource registers the RESTful routes of a controller, for every action it implements
+//
+//	GET    /users      Index   users.index
+//	POST   /users      Create  users.create
+//	GET    /users/:id  Show    users.show
+//	PUT    /users/:id  Update  users.update
+//	PATCH  /users/:id  Update
+//	DELETE /users/:id  Delete  users.delete
+//
+// Routes are named after the path, see ResourceConfig.Name, and middlewares run before every action
+// It panics if controller implements none of the actions
+//
+//	app.Resource("/users", &UserController{db: db}, requireLogin)
+func (rh *RouteHandler) Resource(relativePath string, controller any, middlewares ...handlerFunc) []*Route {
+	return rh.ResourceWithConfig(relativePath, controller, ResourceConfig{Middlewares: middlewares})
+}
+
+// ResourceWithConfig registers the RESTful routes of a controller with config, see Resource
+func (rh *RouteHandler) ResourceWithConfig(relativePath string, controller any, config ResourceConfig) []*Route {
+	if config.Param == "" {
+		config.Param = "id"
+	}
+	if config.Name == "" {
+		config.Name = resourceName(rh.prefix + relativePath)
+	}
+	collection := strings.TrimSuffix(relativePath, "/")
+	member := collection + "/:" + config.Param
+	hooks, _ := controller.(ResourceMiddleware)
+	var routes []*Route
+	register := func(method, path, action string, handler handlerFunc, named bool) {
+		handlers := append(handlersChain{}, config.Middlewares...)
+		if hooks != nil {
+			handlers = append(handlers, hooks.Middleware(action)...)
+		}
+		route := rh.Handle(method, path, append(handlers, handler)...)
+		if named {
+			route.Name(config.Name + "." + action)
+		}
+		routes = append(routes, route)
+	}
+	if c, ok := controller.(ResourceIndexer); ok {
+		register(MethodGet, collection, ResourceIndex, c.Index, true)
+	}
+	if c, ok := controller.(ResourceCreator); ok {
+		register(MethodPost, collection, ResourceCreate, c.Create, true)
+	}
+	if c, ok := controller.(ResourceShower); ok {
+		register(MethodGet, member, ResourceShow, c.Show, true)
+	}
+	if c, ok := controller.(ResourceUpdater); ok {
+		register(MethodPut, member, ResourceUpdate, c.Update, true)
+		register(MethodPatch, member, ResourceUpdate, c.Update, false)
+	}
+	if c, ok := controller.(ResourceDeleter); ok {
+		register(MethodDelete, member, ResourceDelete, c.Delete, true)
+	}
+	if len(routes) == 0 {
+		panic(fmt.Sprintf("resource %s: %T implements none of Index, Show, Create, Update and Delete", relativePath, controller))
+	}
+	return routes
+}
+
+// resourceName derives the route name prefix of a resource from its path,
+// e.g. "/api/users" becomes "api.users" and "/users/:user_id/posts" becomes "users.posts"
+func resourceName(path string) string {
+	var segments []string
+	for segment := range strings.SplitSeq(path, "/") {
+		if segment != "" && segment[0] != ':' && segment[0] != '*' {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, ".")
+}
@@ -0,0 +1,103 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type userController struct {
+	calls []string
+}
+
+func (u *userController) Index(c *Context)  { c.String(StatusOK, "index") }
+func (u *userController) Show(c *Context)   { c.String(StatusOK, "show %s", c.Param("id")) }
+func (u *userController) Create(c *Context) { c.String(StatusCreated, "create") }
+func (u *userController) Update(c *Context) { c.String(StatusOK, "update %s", c.Param("id")) }
+func (u *userController) Delete(c *Context) { c.Status(StatusNoContent) }
+
+func (u *userController) Middleware(action string) []handlerFunc {
+	if action == ResourceIndex || action == ResourceShow {
+		return nil
+	}
+	return []handlerFunc{func(c *Context) {
+		u.calls = append(u.calls, action)
+		if c.GetHeader(HeaderAuthorization) == "" {
+			c.AbortWithStatus(StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}}
+}
+
+type readOnlyController struct{}
+
+func (readOnlyController) Index(c *Context) { c.String(StatusOK, "posts") }
+
+func resourceRequest(app *Gonoleks, method, uri string, headers map[string]string) (int, string) {
+	reqCtx := newProxiedRequest(method, uri, "203.0.113.5", headers)
+	app.router.Handler(reqCtx)
+	return reqCtx.Response.StatusCode(), string(reqCtx.Response.Body())
+}
+
+func TestResource(t *testing.T) {
+	app := New()
+	controller := &userController{}
+	var seen int
+	routes := app.Group("/api").Resource("/users", controller, func(c *Context) { seen++; c.Next() })
+	require.Len(t, routes, 6)
+	app.setupRouter()
+
+	auth := map[string]string{HeaderAuthorization: "Bearer x"}
+	for _, tc := range []struct {
+		method, uri string
+		headers     map[string]string
+		status      int
+		body        string
+	}{
+		{MethodGet, "/api/users", nil, StatusOK, "index"},
+		{MethodGet, "/api/users/7", nil, StatusOK, "show 7"},
+		{MethodPost, "/api/users", nil, StatusUnauthorized, ""},
+		{MethodPost, "/api/users", auth, StatusCreated, "create"},
+		{MethodPut, "/api/users/7", auth, StatusOK, "update 7"},
+		{MethodPatch, "/api/users/7", auth, StatusOK, "update 7"},
+		{MethodDelete, "/api/users/7", auth, StatusNoContent, ""},
+	} {
+		status, body := resourceRequest(app, tc.method, tc.uri, tc.headers)
+		assert.Equal(t, tc.status, status, tc.method+" "+tc.uri)
+		if tc.body != "" {
+			assert.Equal(t, tc.body, body, tc.method+" "+tc.uri)
+		}
+	}
+	assert.Equal(t, 7, seen)
+	assert.Equal(t, []string{ResourceCreate, ResourceCreate, ResourceUpdate, ResourceUpdate, ResourceDelete}, controller.calls)
+
+	url, err := app.URLFor("api.users.show", "id", 7)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/users/7", url)
+	url, err = app.URLFor("api.users.index")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/users", url)
+}
+
+func TestResourceWithConfig(t *testing.T) {
+	app := New()
+	routes := app.ResourceWithConfig("/users/:user_id/posts/", readOnlyController{}, ResourceConfig{Param: "post_id"})
+	require.Len(t, routes, 1)
+	assert.Equal(t, "/users/:user_id/posts", routes[0].Path)
+	app.ResourceWithConfig("/articles", &userController{}, ResourceConfig{Param: "slug", Name: "blog"})
+	app.setupRouter()
+
+	status, body := resourceRequest(app, MethodGet, "/users/1/posts", nil)
+	assert.Equal(t, StatusOK, status)
+	assert.Equal(t, "posts", body)
+	url, err := app.URLFor("users.posts.index", "user_id", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "/users/1/posts", url)
+	url, err = app.URLFor("blog.show", "slug", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "/articles/hello", url)
+
+	assert.Panics(t, func() { app.Resource("/nothing", struct{}{}) })
+}
@@ -0,0 +1,189 @@
+package gonoleks
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// ResponseSchema declares the body of the successful responses of the route, to catch contract drift
+// When CheckResponseSchemas or Debug is enabled, 2xx JSON bodies are decoded and validated against it,
+// and every mismatch is logged as a warning; the response itself is left untouched
+// schema is an *OpenAPISchema, or a value or pointer of the Go type the route is meant to answer with,
+// whose schema is derived from its JSON encoding: struct fields without omitempty are required,
+// unknown fields are not allowed, and pointers, slices and maps may be null
+// It panics on an *OpenAPISchema with an invalid pattern
+//
+//	app.GET("/users/:id", showUser).ResponseSchema(UserResponse{})
+func (r *Route) ResponseSchema(schema any) *Route {
+	s, ok := schema.(*OpenAPISchema)
+	if ok {
+		spec := &OpenAPISpec{}
+		spec.Components.Schemas = map[string]*OpenAPISchema{"response": s}
+		if err := spec.compilePatterns(); err != nil {
+			panic(fmt.Sprintf("response schema of %s %s: %v", r.Method, r.Path, err))
+		}
+	} else {
+		s = schemaOf(reflect.TypeOf(schema), map[reflect.Type]*OpenAPISchema{})
+	}
+	r.Handlers = append(handlersChain{checkResponseSchema(s)}, r.Handlers...)
+	return r
+}
+
+// checkResponseSchema instances a middleware validating successful JSON responses against schema
+func checkResponseSchema(schema *OpenAPISchema) handlerFunc {
+	spec := &OpenAPISpec{}
+	return func(c *Context) {
+		c.Next()
+		if c.app == nil || !(c.app.CheckResponseSchemas || c.app.Debug) {
+			return
+		}
+		resp := &c.requestCtx.Response
+		if resp.StatusCode() < 200 || resp.StatusCode() > 299 || resp.IsBodyStream() || len(resp.Body()) == 0 {
+			return
+		}
+		contentType, _, _ := strings.Cut(getString(resp.Header.ContentType()), ";")
+		if !isJSONMediaType(contentType) {
+			return
+		}
+		var value any
+		problems := []ValidationProblem{{In: "response", Name: "", Message: "malformed JSON"}}
+		if err := sonic.Unmarshal(resp.Body(), &value); err == nil {
+			problems = spec.validateValue(schema, value, "response", "", nil)
+		}
+		for _, p := range problems {
+			c.diagnostics().Warn("Response does not match schema",
+				"method", string(c.requestCtx.Method()),
+				"route", c.FullPath(),
+				"name", p.Name,
+				"message", p.Message,
+			)
+		}
+	}
+}
+
+// schemaOf derives the schema of the JSON encoding of typ, seen holds the struct schemas being derived
+func schemaOf(typ reflect.Type, seen map[reflect.Type]*OpenAPISchema) *OpenAPISchema {
+	if typ == nil {
+		return &OpenAPISchema{}
+	}
+	nullable := false
+	for typ.Kind() == reflect.Pointer {
+		typ, nullable = typ.Elem(), true
+	}
+	if typ == timeType {
+		return &OpenAPISchema{Type: "string", Format: "date-time", Nullable: nullable}
+	}
+	if typ.Implements(jsonMarshalerType) || reflect.PointerTo(typ).Implements(jsonMarshalerType) {
+		// Custom encodings can be anything
+		return &OpenAPISchema{}
+	}
+	if typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType) {
+		return &OpenAPISchema{Type: "string", Nullable: nullable}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean", Nullable: nullable}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &OpenAPISchema{Type: "integer", Nullable: nullable}
+	case reflect.Float32, reflect.Float64:
+		return &OpenAPISchema{Type: "number", Nullable: nullable}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string", Nullable: nullable}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			// Byte slices encode as base64 strings
+			return &OpenAPISchema{Type: "string", Nullable: nullable || typ.Kind() == reflect.Slice}
+		}
+		return &OpenAPISchema{Type: "array", Items: schemaOf(typ.Elem(), seen), Nullable: nullable || typ.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", Nullable: true, AdditionalProperties: &OpenAPIAdditionalProperties{Allowed: true, Schema: schemaOf(typ.Elem(), seen)}}
+	case reflect.Struct:
+		if s, ok := seen[typ]; ok {
+			if !nullable {
+				return s
+			}
+			nullableSchema := *s
+			nullableSchema.Nullable = true
+			return &nullableSchema
+		}
+		s := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}, AdditionalProperties: &OpenAPIAdditionalProperties{}}
+		fields := structFields(typ)
+		for _, field := range fields {
+			if !field.optional {
+				s.Required = append(s.Required, field.name)
+			}
+		}
+		// Required is complete before recursing, so nullable copies of recursive types share it
+		seen[typ] = s
+		for _, field := range fields {
+			if field.asString {
+				s.Properties[field.name] = &OpenAPISchema{Type: "string"}
+			} else {
+				s.Properties[field.name] = schemaOf(field.typ, seen)
+			}
+		}
+		if nullable {
+			nullableSchema := *s
+			nullableSchema.Nullable = true
+			return &nullableSchema
+		}
+		return s
+	}
+	// Interfaces and other kinds can be anything
+	return &OpenAPISchema{}
+}
+
+// schemaField is a JSON encoded struct field
+type schemaField struct {
+	name     string
+	typ      reflect.Type
+	asString bool
+	optional bool
+}
+
+// structFields returns the JSON encoded fields of the struct typ, flattening embedded structs
+func structFields(typ reflect.Type) []schemaField {
+	var fields []schemaField
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, structFields(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, schemaField{
+			name:     name,
+			typ:      field.Type,
+			asString: hasTagOption(opts, "string"),
+			optional: hasTagOption(opts, "omitempty") || hasTagOption(opts, "omitzero"),
+		})
+	}
+	return fields
+}
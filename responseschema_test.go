@@ -0,0 +1,117 @@
+package gonoleks
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaBase struct {
+	ID int `json:"id"`
+}
+
+type schemaUser struct {
+	schemaBase
+	Name      string            `json:"name"`
+	Email     string            `json:"email,omitempty"`
+	Tags      []string          `json:"tags"`
+	Manager   *schemaUser       `json:"manager"`
+	Created   time.Time         `json:"created"`
+	Count     int64             `json:"count,string"`
+	Extra     map[string]string `json:"extra,omitempty"`
+	Secret    string            `json:"-"`
+	unexposed string
+}
+
+func TestSchemaOf(t *testing.T) {
+	s := schemaOf(reflect.TypeFor[schemaUser](), map[reflect.Type]*OpenAPISchema{})
+	assert.Equal(t, "object", s.Type)
+	assert.Equal(t, []string{"id", "name", "tags", "manager", "created", "count"}, s.Required)
+	assert.Len(t, s.Properties, 8)
+	assert.Equal(t, "integer", s.Properties["id"].Type)
+	assert.Equal(t, "array", s.Properties["tags"].Type)
+	assert.True(t, s.Properties["tags"].Nullable)
+	assert.Equal(t, "string", s.Properties["tags"].Items.Type)
+	assert.Equal(t, "string", s.Properties["created"].Type)
+	assert.Equal(t, "string", s.Properties["count"].Type)
+	assert.Equal(t, "string", s.Properties["extra"].AdditionalProperties.Schema.Type)
+	// Recursive types reuse the schema being derived
+	manager := s.Properties["manager"]
+	assert.True(t, manager.Nullable)
+	assert.Equal(t, s.Required, manager.Required)
+	assert.Len(t, manager.Properties, 8)
+
+	assert.Equal(t, &OpenAPISchema{}, schemaOf(reflect.TypeFor[any](), nil))
+	assert.Equal(t, "string", schemaOf(reflect.TypeFor[[]byte](), nil).Type)
+	assert.Equal(t, "number", schemaOf(reflect.TypeFor[*float64](), nil).Type)
+}
+
+func TestResponseSchema(t *testing.T) {
+	var buf bytes.Buffer
+	app := New()
+	app.CheckResponseSchemas = true
+	app.SetDiagnosticsLogger(log.New(&buf))
+	app.GET("/good", func(c *Context) {
+		_ = c.JSON(StatusOK, schemaUser{Name: "a", Tags: []string{"x"}})
+	}).ResponseSchema(schemaUser{})
+	app.GET("/drift", func(c *Context) {
+		_ = c.JSON(StatusOK, H{"id": "1", "name": "a", "tags": nil, "manager": nil, "created": "", "count": "1", "nickname": "b"})
+	}).ResponseSchema(&schemaUser{})
+	app.GET("/error", func(c *Context) {
+		_ = c.JSON(StatusNotFound, H{"error": "not found"})
+	}).ResponseSchema(schemaUser{})
+	minimum := 1.0
+	app.GET("/openapi", func(c *Context) {
+		_ = c.JSON(StatusOK, H{"total": 0})
+	}).ResponseSchema(&OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{"total": {Type: "integer", Minimum: &minimum}}})
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/good", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Empty(t, buf.String())
+
+	// Mismatches are logged, the response is left untouched
+	reqCtx = newProxiedRequest(MethodGet, "/drift", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Contains(t, string(reqCtx.Response.Body()), "nickname")
+	assert.Contains(t, buf.String(), "Response does not match schema")
+	assert.Contains(t, buf.String(), "route=/drift")
+	assert.Contains(t, buf.String(), "name=/id")
+	assert.Contains(t, buf.String(), "name=/nickname")
+	assert.NotContains(t, buf.String(), "name=/tags")
+
+	buf.Reset()
+	app.router.Handler(newProxiedRequest(MethodGet, "/error", "203.0.113.5", nil))
+	assert.Empty(t, buf.String())
+
+	app.router.Handler(newProxiedRequest(MethodGet, "/openapi", "203.0.113.5", nil))
+	assert.Contains(t, buf.String(), "name=/total")
+
+	// Debug enables them as well
+	buf.Reset()
+	app.CheckResponseSchemas = false
+	app.Debug = true
+	app.router.Handler(newProxiedRequest(MethodGet, "/drift", "203.0.113.5", nil))
+	assert.NotEmpty(t, buf.String())
+
+	// Disabled checks only cost the middleware call
+	buf.Reset()
+	app.Debug = false
+	app.router.Handler(newProxiedRequest(MethodGet, "/drift", "203.0.113.5", nil))
+	assert.Empty(t, buf.String())
+}
+
+func TestResponseSchemaDefault(t *testing.T) {
+	// Checks need an explicit opt-in, Default does not enable them
+	assert.False(t, Default().CheckResponseSchemas)
+	assert.False(t, New().CheckResponseSchemas)
+	assert.Panics(t, func() {
+		New().GET("/", func(c *Context) {}).ResponseSchema(&OpenAPISchema{Type: "string", Pattern: "("})
+	})
+}
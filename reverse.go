@@ -0,0 +1,108 @@
+package gonoleks
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Name names the route, so URLs of it can be built with URLFor and url_for in templates
+// It panics when another route already has the name
+//
+//	app.GET("/users/:id", showUser).Name("users.show")
+func (r *Route) Name(name string) *Route {
+	if r.app == nil {
+		return r
+	}
+	if existing, ok := r.app.namedRoutes[name]; ok && existing != r {
+		panic(fmt.Sprintf("route name %q is already used by %s %s", name, existing.Method, existing.Path))
+	}
+	if r.app.namedRoutes == nil {
+		r.app.namedRoutes = make(map[string]*Route)
+	}
+	r.app.namedRoutes[name] = r
+	return r
+}
+
+// URLFor builds the path of the route name with params, given as alternating names and values
+// Values are formatted with fmt and escaped, params not used by the route are added as query
+// parameters, and a catch-all param may contain slashes
+//
+//	app.URLFor("users.show", "id", 42, "tab", "posts") // "/users/42?tab=posts"
+func (g *Gonoleks) URLFor(name string, params ...any) (string, error) {
+	route, ok := g.namedRoutes[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownRoute, name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("%w: odd number of params for %s", ErrRouteParams, name)
+	}
+	values := make(map[string]string, len(params)/2)
+	var order []string
+	for i := 0; i < len(params); i += 2 {
+		key, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("%w: param name %v of %s is not a string", ErrRouteParams, params[i], name)
+		}
+		if _, seen := values[key]; !seen {
+			order = append(order, key)
+		}
+		values[key] = fmt.Sprint(params[i+1])
+	}
+	used := make(map[string]bool, len(values))
+	value := func(param string) (string, error) {
+		v, ok := values[param]
+		if !ok {
+			return "", fmt.Errorf("%w: %s requires param %s", ErrRouteParams, name, param)
+		}
+		used[param] = true
+		return v, nil
+	}
+
+	var b strings.Builder
+	for segment := range strings.SplitSeq(strings.TrimPrefix(route.Path, "/"), "/") {
+		b.WriteByte('/')
+		if strings.HasPrefix(segment, "*") {
+			param := cmp.Or(segment[1:], "*")
+			v, err := value(param)
+			if err != nil {
+				return "", err
+			}
+			parts := strings.Split(strings.TrimPrefix(v, "/"), "/")
+			for i, part := range parts {
+				parts[i] = url.PathEscape(part)
+			}
+			b.WriteString(strings.Join(parts, "/"))
+			continue
+		}
+		for segment != "" {
+			colon := strings.IndexByte(segment, ':')
+			if colon < 0 {
+				b.WriteString(segment)
+				break
+			}
+			b.WriteString(segment[:colon])
+			end := colon + 1
+			for end < len(segment) && segment[end] != '.' && segment[end] != '-' {
+				end++
+			}
+			v, err := value(segment[colon+1 : end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(url.PathEscape(v))
+			segment = segment[end:]
+		}
+	}
+	query := url.Values{}
+	for _, key := range order {
+		if !used[key] {
+			query.Set(key, values[key])
+		}
+	}
+	if len(query) > 0 {
+		b.WriteString("?" + query.Encode())
+	}
+	return b.String(), nil
+}
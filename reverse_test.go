@@ -0,0 +1,46 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLFor(t *testing.T) {
+	app := New()
+	handler := func(c *Context) {}
+	app.GET("/", handler).Name("home")
+	api := app.Group("/api")
+	api.GET("/users/:id", handler).Name("users.show")
+	app.GET("/files/*path", handler).Name("files")
+	app.GET("/dl/:name.:ext", handler).Name("download")
+
+	tests := []struct {
+		name     string
+		params   []any
+		expected string
+	}{
+		{"home", nil, "/"},
+		{"users.show", []any{"id", 42}, "/api/users/42"},
+		{"users.show", []any{"id", "a b/c", "tab", "posts", "q", "x&y"}, "/api/users/a%20b%2Fc?q=x%26y&tab=posts"},
+		{"files", []any{"path", "/docs/read me.txt"}, "/files/docs/read%20me.txt"},
+		{"download", []any{"name", "report", "ext", "pdf"}, "/dl/report.pdf"},
+	}
+	for _, tt := range tests {
+		url, err := app.URLFor(tt.name, tt.params...)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.expected, url)
+	}
+
+	_, err := app.URLFor("missing")
+	assert.ErrorIs(t, err, ErrUnknownRoute)
+	_, err = app.URLFor("users.show")
+	assert.ErrorIs(t, err, ErrRouteParams)
+	_, err = app.URLFor("users.show", "id")
+	assert.ErrorIs(t, err, ErrRouteParams)
+	_, err = app.URLFor("users.show", 1, 2)
+	assert.ErrorIs(t, err, ErrRouteParams)
+
+	assert.Panics(t, func() { app.GET("/other", handler).Name("home") })
+}
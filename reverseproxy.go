@@ -0,0 +1,397 @@
+package gonoleks
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// hopHeaders are removed when forwarding requests and responses, see RFC 9110 section 7.6.1
+var hopHeaders = []string{
+	HeaderConnection, HeaderKeepAlive, HeaderProxyAuthenticate, HeaderProxyAuthorization,
+	HeaderTE, HeaderTrailer, HeaderTransferEncoding, HeaderUpgrade,
+}
+
+// hopHeader is implemented by fasthttp.RequestHeader and fasthttp.ResponseHeader
+type hopHeader interface {
+	PeekAll(key string) [][]byte
+	Del(key string)
+}
+
+// removeHopHeaders removes the hop-by-hop headers, along with the headers listed in Connection
+func removeHopHeaders(h hopHeader) {
+	var listed []string
+	for _, value := range h.PeekAll(HeaderConnection) {
+		for name := range strings.SplitSeq(string(value), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				listed = append(listed, name)
+			}
+		}
+	}
+	for _, name := range listed {
+		h.Del(name)
+	}
+	for _, header := range hopHeaders {
+		h.Del(header)
+	}
+}
+
+// Backend configures an upstream server of a ReverseProxy
+type Backend struct {
+	// URL is the base URL of the upstream, e.g. "http://10.0.0.5:8080"
+	URL string
+
+	// Weight is the relative share of traffic sent to the backend
+	// A weight of 0 keeps the backend in the pool without sending it traffic
+	Weight int
+}
+
+// BackendStats reports the state and metrics of a backend
+type BackendStats struct {
+	URL        string
+	Weight     int
+	Healthy    bool
+	Requests   uint64
+	Failures   uint64
+	AvgLatency time.Duration
+}
+
+// ReverseProxyConfig defines the config for NewReverseProxy
+type ReverseProxyConfig struct {
+	// Backends lists the upstream servers traffic is balanced between by weight
+	Backends []Backend
+
+	// HealthCheckPath is requested on every backend to determine its health,
+	// any 2xx or 3xx response marks it healthy
+	// Backends are always considered healthy when empty
+	HealthCheckPath string
+
+	// HealthCheckInterval is the time between health checks
+	HealthCheckInterval time.Duration // Default = 10s
+
+	// Timeout bounds each upstream request, including health checks
+	Timeout time.Duration // Default = 30s
+
+	// Client performs the upstream requests, a default client is used when nil
+	Client *fasthttp.Client
+
+	// Diagnostics logs the health changes of backends
+	Diagnostics *log.Logger // Default = the diagnostics logger of the app serving the proxy
+}
+
+// ReverseProxy forwards requests to a weighted pool of backends,
+// e.g. to shift traffic gradually from a stable release to a canary
+type ReverseProxy struct {
+	conf     ReverseProxyConfig
+	client   *fasthttp.Client
+	mu       sync.Mutex
+	backends []*proxyBackend
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	// diagnostics is Diagnostics, or the logger of the first app serving the proxy
+	diagnostics atomic.Pointer[log.Logger]
+}
+
+// proxyBackend holds the live state of a backend
+type proxyBackend struct {
+	url      string
+	weight   int
+	current  int // Smooth weighted round-robin state, guarded by ReverseProxy.mu
+	healthy  atomic.Bool
+	requests atomic.Uint64
+	failures atomic.Uint64
+	latency  atomic.Int64 // Total upstream latency in nanoseconds
+}
+
+// NewReverseProxy returns a reverse proxy for the given backends
+// Health checks start immediately when HealthCheckPath is set and run until Close
+//
+//	proxy, err := gonoleks.NewReverseProxy(gonoleks.ReverseProxyConfig{
+//		Backends: []gonoleks.Backend{
+//			{URL: "http://stable:8080", Weight: 95},
+//			{URL: "http://canary:8080", Weight: 5},
+//		},
+//		HealthCheckPath: "/healthz",
+//	})
+//	app.Any("/*path", proxy.Handler())
+func NewReverseProxy(conf ReverseProxyConfig) (*ReverseProxy, error) {
+	if conf.HealthCheckInterval <= 0 {
+		conf.HealthCheckInterval = 10 * time.Second
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = 30 * time.Second
+	}
+	p := &ReverseProxy{
+		conf:   conf,
+		client: conf.Client,
+		stop:   make(chan struct{}),
+	}
+	if p.client == nil {
+		p.client = &fasthttp.Client{}
+	}
+	if conf.Diagnostics != nil {
+		p.diagnostics.Store(conf.Diagnostics)
+	}
+	for _, backend := range conf.Backends {
+		if err := p.AddBackend(backend); err != nil {
+			return nil, err
+		}
+	}
+	if conf.HealthCheckPath != "" {
+		go p.runHealthChecks()
+	}
+	return p, nil
+}
+
+// AddBackend adds a backend to the pool, replacing the weight of an existing one with the same URL
+func (p *ReverseProxy) AddBackend(backend Backend) error {
+	u, err := url.Parse(backend.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || backend.Weight < 0 {
+		return ErrInvalidBackend
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	base := strings.TrimSuffix(backend.URL, "/")
+	for _, b := range p.backends {
+		if b.url == base {
+			b.weight = backend.Weight
+			return nil
+		}
+	}
+	b := &proxyBackend{url: base, weight: backend.Weight}
+	b.healthy.Store(true)
+	p.backends = append(p.backends, b)
+	return nil
+}
+
+// RemoveBackend removes a backend from the pool
+func (p *ReverseProxy) RemoveBackend(backendURL string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, b := range p.backends {
+		if b.url == strings.TrimSuffix(backendURL, "/") {
+			p.backends = append(p.backends[:i], p.backends[i+1:]...)
+			return nil
+		}
+	}
+	return ErrUnknownBackend
+}
+
+// SetWeight changes the share of traffic sent to a backend
+func (p *ReverseProxy) SetWeight(backendURL string, weight int) error {
+	if weight < 0 {
+		return ErrInvalidBackend
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b := p.findBackend(backendURL)
+	if b == nil {
+		return ErrUnknownBackend
+	}
+	b.weight = weight
+	return nil
+}
+
+// ShiftWeight gradually moves the weight of a backend towards target,
+// changing it by step every interval, e.g. to ramp up a canary from 5 to 100
+// The returned function stops the shift early
+func (p *ReverseProxy) ShiftWeight(backendURL string, target, step int, interval time.Duration) (stop func(), err error) {
+	if target < 0 || step <= 0 || interval <= 0 {
+		return nil, ErrInvalidBackend
+	}
+	p.mu.Lock()
+	found := p.findBackend(backendURL) != nil
+	p.mu.Unlock()
+	if !found {
+		return nil, ErrUnknownBackend
+	}
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-p.stop:
+				return
+			case <-ticker.C:
+			}
+			p.mu.Lock()
+			b := p.findBackend(backendURL)
+			if b == nil {
+				p.mu.Unlock()
+				return
+			}
+			switch {
+			case b.weight < target:
+				b.weight = min(b.weight+step, target)
+			case b.weight > target:
+				b.weight = max(b.weight-step, target)
+			}
+			reached := b.weight == target
+			p.mu.Unlock()
+			if reached {
+				return
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }, nil
+}
+
+// Stats returns the state and metrics of every backend
+func (p *ReverseProxy) Stats() []BackendStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make([]BackendStats, 0, len(p.backends))
+	for _, b := range p.backends {
+		s := BackendStats{
+			URL:      b.url,
+			Weight:   b.weight,
+			Healthy:  b.healthy.Load(),
+			Requests: b.requests.Load(),
+			Failures: b.failures.Load(),
+		}
+		if s.Requests > 0 {
+			s.AvgLatency = time.Duration(b.latency.Load() / int64(s.Requests))
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// Close stops health checks and weight shifts
+func (p *ReverseProxy) Close() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+// Handler returns a handler forwarding requests to the selected backend
+// The request path and query are appended to the backend URL, and the
+// X-Forwarded-For, X-Forwarded-Host and X-Forwarded-Proto headers are set
+// It responds with 503 when no healthy backend is available and 502 when the upstream fails
+func (p *ReverseProxy) Handler() handlerFunc {
+	return func(c *Context) {
+		if p.diagnostics.Load() == nil {
+			p.diagnostics.CompareAndSwap(nil, c.diagnostics())
+		}
+		b := p.next()
+		if b == nil {
+			c.requestCtx.Error(fasthttp.StatusMessage(StatusServiceUnavailable), StatusServiceUnavailable)
+			c.Abort()
+			return
+		}
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		c.requestCtx.Request.CopyTo(req)
+		req.SetRequestURI(b.url + string(c.requestCtx.RequestURI()))
+		req.Header.SetHost(string(req.URI().Host()))
+		removeHopHeaders(&req.Header)
+		clientIP := c.RemoteIP()
+		if prior := c.GetHeader(HeaderXForwardedFor); prior != "" && c.isTrustedProxy() {
+			clientIP = prior + ", " + clientIP
+		}
+		req.Header.Set(HeaderXForwardedFor, clientIP)
+		req.Header.Set(HeaderXForwardedHost, c.Host())
+		req.Header.Set(HeaderXForwardedProto, c.Scheme())
+
+		resp := &c.requestCtx.Response
+		start := time.Now()
+		err := p.client.DoTimeout(req, resp, p.conf.Timeout)
+		b.requests.Add(1)
+		b.latency.Add(int64(time.Since(start)))
+		if err != nil {
+			b.failures.Add(1)
+			c.diagnostics().Warn("Reverse proxy upstream failed", "backend", b.url, "error", err)
+			c.requestCtx.Error(fasthttp.StatusMessage(StatusBadGateway), StatusBadGateway)
+			c.Abort()
+			return
+		}
+		if resp.StatusCode() >= StatusInternalServerError {
+			b.failures.Add(1)
+		}
+		removeHopHeaders(&resp.Header)
+	}
+}
+
+// next selects a healthy backend using smooth weighted round-robin
+func (p *ReverseProxy) next() *proxyBackend {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var best *proxyBackend
+	total := 0
+	for _, b := range p.backends {
+		if b.weight == 0 || !b.healthy.Load() {
+			continue
+		}
+		b.current += b.weight
+		total += b.weight
+		if best == nil || b.current > best.current {
+			best = b
+		}
+	}
+	if best != nil {
+		best.current -= total
+	}
+	return best
+}
+
+// findBackend returns the backend with the given URL, the caller must hold p.mu
+func (p *ReverseProxy) findBackend(backendURL string) *proxyBackend {
+	backendURL = strings.TrimSuffix(backendURL, "/")
+	for _, b := range p.backends {
+		if b.url == backendURL {
+			return b
+		}
+	}
+	return nil
+}
+
+// runHealthChecks checks every backend until the proxy is closed
+func (p *ReverseProxy) runHealthChecks() {
+	p.checkHealth()
+	ticker := time.NewTicker(p.conf.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.checkHealth()
+		}
+	}
+}
+
+// logger returns the diagnostics logger of the proxy, the framework's until an app serves it
+func (p *ReverseProxy) logger() *log.Logger {
+	if logger := p.diagnostics.Load(); logger != nil {
+		return logger
+	}
+	return defaultDiagnostics
+}
+
+// checkHealth requests the health check path of every backend
+func (p *ReverseProxy) checkHealth() {
+	p.mu.Lock()
+	backends := make([]*proxyBackend, len(p.backends))
+	copy(backends, p.backends)
+	p.mu.Unlock()
+	for _, b := range backends {
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+		req.SetRequestURI(b.url + p.conf.HealthCheckPath)
+		err := p.client.DoTimeout(req, resp, p.conf.Timeout)
+		healthy := err == nil && resp.StatusCode() < StatusBadRequest
+		if b.healthy.Swap(healthy) != healthy {
+			p.logger().Info("Reverse proxy backend health changed", "backend", b.url, "healthy", healthy)
+		}
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+	}
+}
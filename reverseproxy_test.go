@@ -0,0 +1,163 @@
+package gonoleks
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestReverseProxyWeights(t *testing.T) {
+	client := startTestUpstream(t, func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.Header.Set(HeaderConnection, "keep-alive")
+		ctx.SetBodyString(string(ctx.Host()) + " " + string(ctx.RequestURI()) + " " + string(ctx.Request.Header.Peek(HeaderXForwardedFor)))
+	})
+	proxy, err := NewReverseProxy(ReverseProxyConfig{
+		Backends: []Backend{
+			{URL: "http://stable:8080", Weight: 3},
+			{URL: "http://canary:8080/", Weight: 1},
+		},
+		Client: client,
+	})
+	require.NoError(t, err)
+	defer proxy.Close()
+
+	app := New()
+	app.GET("/*path", proxy.Handler())
+	app.setupRouter()
+
+	counts := map[string]int{}
+	for range 8 {
+		reqCtx := newProxiedRequest(MethodGet, "/orders?id=1", "192.0.2.1", nil)
+		app.router.Handler(reqCtx)
+		require.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+		body := string(reqCtx.Response.Body())
+		assert.Contains(t, body, " /orders?id=1 192.0.2.1")
+		counts[body[:strings.IndexByte(body, ' ')]]++
+	}
+	assert.Equal(t, map[string]int{"stable:8080": 6, "canary:8080": 2}, counts)
+
+	require.NoError(t, proxy.SetWeight("http://canary:8080", 0))
+	for range 4 {
+		reqCtx := newProxiedRequest(MethodGet, "/users", "192.0.2.1", nil)
+		app.router.Handler(reqCtx)
+		assert.True(t, strings.HasPrefix(string(reqCtx.Response.Body()), "stable:8080"))
+	}
+
+	stats := proxy.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, uint64(10), stats[0].Requests)
+	assert.Equal(t, uint64(2), stats[1].Requests)
+	assert.Equal(t, 0, stats[1].Weight)
+	assert.True(t, stats[0].Healthy)
+
+	assert.ErrorIs(t, proxy.SetWeight("http://unknown", 1), ErrUnknownBackend)
+	assert.ErrorIs(t, proxy.AddBackend(Backend{URL: "ftp://files"}), ErrInvalidBackend)
+	require.NoError(t, proxy.RemoveBackend("http://canary:8080"))
+	assert.Len(t, proxy.Stats(), 1)
+}
+
+func TestReverseProxyHealthChecks(t *testing.T) {
+	var canaryHealthy atomic.Bool
+	client := startTestUpstream(t, func(ctx *fasthttp.RequestCtx) {
+		if string(ctx.Path()) == "/healthz" && string(ctx.Host()) == "canary" && !canaryHealthy.Load() {
+			ctx.SetStatusCode(StatusServiceUnavailable)
+			return
+		}
+		ctx.SetBodyString(string(ctx.Host()))
+	})
+	proxy, err := NewReverseProxy(ReverseProxyConfig{
+		Backends:            []Backend{{URL: "http://canary", Weight: 1}},
+		HealthCheckPath:     "/healthz",
+		HealthCheckInterval: 10 * time.Millisecond,
+		Client:              client,
+	})
+	require.NoError(t, err)
+	defer proxy.Close()
+
+	diag := &syncBuffer{}
+	app := New()
+	app.SetDiagnosticsLogger(log.New(diag))
+	app.GET("/", proxy.Handler())
+	app.setupRouter()
+
+	require.Eventually(t, func() bool { return !proxy.Stats()[0].Healthy }, time.Second, 5*time.Millisecond)
+	reqCtx := newProxiedRequest(MethodGet, "/", "192.0.2.1", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusServiceUnavailable, reqCtx.Response.StatusCode())
+
+	canaryHealthy.Store(true)
+	require.Eventually(t, func() bool { return proxy.Stats()[0].Healthy }, time.Second, 5*time.Millisecond)
+	reqCtx = newProxiedRequest(MethodGet, "/", "192.0.2.1", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "canary", string(reqCtx.Response.Body()))
+
+	// Health changes are logged by the app serving the proxy
+	assert.Contains(t, diag.String(), "Reverse proxy backend health changed")
+}
+
+func TestReverseProxyConnectionHeaders(t *testing.T) {
+	client := startTestUpstream(t, func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.Header.Set(HeaderConnection, "X-Upstream-Hop")
+		ctx.Response.Header.Set("X-Upstream-Hop", "1")
+		ctx.Response.Header.Set("X-Upstream", "1")
+		ctx.SetBodyString(string(ctx.Request.Header.Peek("X-Client-Hop")) + "|" + string(ctx.Request.Header.Peek("X-Other-Hop")) + "|" + string(ctx.Request.Header.Peek("X-Client")))
+	})
+	proxy, err := NewReverseProxy(ReverseProxyConfig{Backends: []Backend{{URL: "http://upstream", Weight: 1}}, Client: client})
+	require.NoError(t, err)
+	defer proxy.Close()
+
+	app := New()
+	app.GET("/", proxy.Handler())
+	app.setupRouter()
+
+	// Headers listed in Connection only concern the current hop
+	reqCtx := newProxiedRequest(MethodGet, "/", "192.0.2.1", map[string]string{
+		HeaderConnection: "X-Client-Hop, x-other-hop",
+		"X-Client-Hop":   "1",
+		"X-Other-Hop":    "1",
+		"X-Client":       "1",
+	})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "||1", string(reqCtx.Response.Body()))
+	assert.Empty(t, reqCtx.Response.Header.Peek("X-Upstream-Hop"))
+	assert.Equal(t, "1", string(reqCtx.Response.Header.Peek("X-Upstream")))
+}
+
+func TestReverseProxyShiftWeight(t *testing.T) {
+	proxy, err := NewReverseProxy(ReverseProxyConfig{Backends: []Backend{{URL: "http://canary", Weight: 5}}})
+	require.NoError(t, err)
+	defer proxy.Close()
+
+	stop, err := proxy.ShiftWeight("http://canary", 20, 10, time.Millisecond)
+	require.NoError(t, err)
+	defer stop()
+	require.Eventually(t, func() bool { return proxy.Stats()[0].Weight == 20 }, time.Second, time.Millisecond)
+
+	_, err = proxy.ShiftWeight("http://other", 20, 10, time.Millisecond)
+	assert.ErrorIs(t, err, ErrUnknownBackend)
+	_, err = proxy.ShiftWeight("http://canary", 20, 0, time.Millisecond)
+	assert.ErrorIs(t, err, ErrInvalidBackend)
+}
+
+func TestReverseProxyUpstreamFailure(t *testing.T) {
+	proxy, err := NewReverseProxy(ReverseProxyConfig{
+		Backends: []Backend{{URL: "http://127.0.0.1:1", Weight: 1}},
+		Timeout:  time.Second,
+	})
+	require.NoError(t, err)
+	defer proxy.Close()
+
+	app := New()
+	app.GET("/", proxy.Handler())
+	app.setupRouter()
+	reqCtx := newProxiedRequest(MethodGet, "/", "192.0.2.1", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusBadGateway, reqCtx.Response.StatusCode())
+	assert.Equal(t, uint64(1), proxy.Stats()[0].Failures)
+}
@@ -69,7 +69,10 @@ type router struct {
 	putTree          *node                    // Lookup tree for PUT HTTP method
 	staticRoutes     map[string]handlersChain // Static route cache for O(1) lookup
 	fastRouter       *FastRouter              // Router for static routes
-	globalMiddleware handlersChain            // Global middleware for all requests including errors
+	globalMiddleware handlersChain            // Middleware wrapping the NoRoute and NoMethod fallback chains
+	allowCache       *allowCache              // Allow header values by request path
+	routeHits        sync.Map                 // Hit counters of static routes when TrackRouteHits is enabled
+	routePatterns    map[*handlerFunc]string  // Route patterns keyed by the first handler of their chain
 }
 
 // acquireCtx gets a context from the pool and initializes it
@@ -83,6 +86,7 @@ func (r *router) acquireCtx(fctx *fasthttp.RequestCtx) *Context {
 	ctx.handlers = ctx.handlers[:0] // Reset length, keep capacity
 	ctx.index = -1
 	ctx.fullPath = ""
+	ctx.queryCache = nil
 	ctx.requestCtx = fctx
 	// Initialize or clear param values map
 	if ctx.paramValues == nil {
@@ -103,6 +107,7 @@ func (r *router) releaseCtx(ctx *Context) {
 	ctx.handlers = ctx.handlers[:0] // Reset length, keep capacity
 	ctx.index = -1
 	ctx.fullPath = ""
+	ctx.queryCache = nil
 	ctx.requestCtx = nil
 	// Clear map only if it has entries (performance optimization)
 	if len(ctx.paramValues) > 0 {
@@ -123,6 +128,8 @@ func (r *router) handle(method, path string, handlers handlersChain) {
 	} else if len(handlers) == 0 {
 		panic(fmt.Sprintf("router.handle: no handler functions provided for route %s %q", method, path))
 	}
+	// Allowed methods change with every registered route
+	r.allowCache = nil
 	// Initialize tree if it's empty
 	if r.trees == nil {
 		r.trees = make(map[string]*node)
@@ -155,44 +162,17 @@ func (r *router) handle(method, path string, handlers handlersChain) {
 			r.putTree = root
 		}
 	}
-	root.addRoute(path, handlers)
-}
-
-// allowed determines which HTTP methods are supported for a given path
-// It returns a comma-separated list of allowed methods for the path
-func (r *router) allowed(reqMethod, path string, ctx *Context) string {
-	var allow string
-	pathLen := len(path)
-	// Handle * and /* requests
-	if (pathLen == 1 && path[0] == '*') || (pathLen > 1 && path[1] == '*') {
-		for method := range r.trees {
-			if method == MethodOptions {
-				continue
-			}
-			if allow != "" {
-				allow += ", " + method
-			} else {
-				allow = method
-			}
-		}
-		return allow
+	stored := root.addRoute(path, handlers)
+	// Remember the pattern of the chains returned by lookups for Context.FullPath
+	// Chains registered for both a path and its trailing slash variant keep the first pattern
+	if r.routePatterns == nil {
+		r.routePatterns = make(map[*handlerFunc]string)
 	}
-	for method, tree := range r.trees {
-		if method == reqMethod || method == MethodOptions {
-			continue
+	for _, chain := range []handlersChain{handlers, stored} {
+		if _, ok := r.routePatterns[&chain[0]]; !ok {
+			r.routePatterns[&chain[0]] = path
 		}
-		if handlers := tree.matchRoute(path, ctx); handlers != nil {
-			if allow != "" {
-				allow += ", " + method
-			} else {
-				allow = method
-			}
-		}
-	}
-	if len(allow) > 0 {
-		allow += ", " + MethodOptions
 	}
-	return allow
 }
 
 // Handler is the main request handler that processes incoming HTTP requests
@@ -201,13 +181,63 @@ func (r *router) Handler(fctx *fasthttp.RequestCtx) {
 	// Acquire context from pool
 	ctx := r.acquireCtx(fctx)
 	defer r.releaseCtx(ctx)
+	// Turn banned clients away before anything else runs
+	if r.app.rejectBanned(ctx) {
+		return
+	}
+	// Let clients reconnect elsewhere while draining
+	if r.app.draining.Load() {
+		fctx.SetConnectionClose()
+	}
+	r.app.inFlightCount.Add(1)
+	defer r.app.inFlightCount.Add(-1)
+	if r.app.TrackInFlight {
+		r.app.beginInFlight(fctx)
+		defer r.app.inFlight.Delete(fctx.ID())
+	}
 	// Apply logging middleware for Default() mode (all requests)
-	if r.app != nil && r.app.enableLogging {
+	if r.app.enableLogging {
 		ctx.handlers = append(ctx.handlers, LoggerWithFormatter(DefaultLogFormatter))
 	}
+	// Render panics and server errors as debug error pages, inside the logger so it sees the final status
+	if r.app.debugErrorPageEnabled() {
+		ctx.handlers = append(ctx.handlers, debugErrorPage)
+	}
+	// Shed requests during maintenance or over the rate limit
+	if r.app.shedRequest(ctx) {
+		ctx.handlers = append(ctx.handlers, r.globalMiddleware...)
+		ctx.Next()
+		return
+	}
+	// Reject requests that took too long to arrive
+	if r.app.SlowRequestThreshold > 0 && r.app.checkSlowRequest(fctx) {
+		ctx.handlers = append(ctx.handlers, r.globalMiddleware...)
+		fctx.Error(fasthttp.StatusMessage(StatusRequestTimeout), StatusRequestTimeout)
+		ctx.Next()
+		return
+	}
+	// Reject requests with headers other servers may parse differently
+	if r.app.HeaderHardening.enabled() && r.app.checkHeaders(fctx) {
+		ctx.handlers = append(ctx.handlers, r.globalMiddleware...)
+		fctx.Error(fasthttp.StatusMessage(StatusBadRequest), StatusBadRequest)
+		// The rest of the stream can no longer be trusted
+		fctx.SetConnectionClose()
+		ctx.Next()
+		return
+	}
 	// Extract method and path with zero-copy optimization
 	methodBytes := fctx.Method()
 	pathBytes := fctx.Path()
+	if r.app.PathNormalization.enabled() {
+		normalized, ok := r.app.PathNormalization.normalize(fctx.Request.URI())
+		if !ok {
+			ctx.handlers = append(ctx.handlers, r.globalMiddleware...)
+			fctx.Error(fasthttp.StatusMessage(StatusBadRequest), StatusBadRequest)
+			ctx.Next()
+			return
+		}
+		pathBytes = getBytes(normalized)
+	}
 	var method, path string
 	if r.app.CaseInSensitive {
 		method = strings.ToUpper(getString(methodBytes))
@@ -216,26 +246,48 @@ func (r *router) Handler(fctx *fasthttp.RequestCtx) {
 		method = getString(methodBytes)
 		path = getString(pathBytes)
 	}
+	// Reject writes in GET-only mode before any route runs
+	if !isGETOnlyMethod(method) && r.getOnly(fctx) {
+		r.rejectNonGET(fctx, path, ctx)
+		return
+	}
 	// Try to handle the route
+	base := len(ctx.handlers)
 	if r.handleRoute(method, path, ctx) {
+		// Answer invalid path params instead of running the route, wrapped by global middleware
+		if len(r.app.paramRules) > 0 {
+			if problems := r.app.checkParams(ctx); len(problems) > 0 {
+				ctx.handlers = append(ctx.handlers[:base], r.globalMiddleware...)
+				ctx.handlers = append(ctx.handlers, func(c *Context) {
+					_ = c.AbortWithStatusProblem(StatusBadRequest, "invalid path params", problems)
+				})
+				ctx.Next()
+				return
+			}
+		}
+		if r.app.TrackRouteHits {
+			r.recordHit(method, path)
+		}
 		// Route was handled successfully, execute middleware chain
 		ctx.Next()
 		return
 	}
 	// Route not found, handle special cases but ensure logging still happens
 	handled := false
-	// Handle method not allowed
-	if !handled && r.app.HandleMethodNotAllowed {
+	// Answer OPTIONS requests for paths without an explicit OPTIONS route
+	if r.app.HandleOPTIONS && method == MethodOptions {
+		handled = r.handleOptions(fctx, path, ctx)
+	}
+	// Handle method not allowed, always for groups restricted with AllowMethods
+	if !handled && (r.app.HandleMethodNotAllowed || r.app.methodRestricted(path)) {
 		if r.handleMethodNotAllowed(fctx, method, path, ctx) {
 			handled = true
 		}
 	}
 	// Handle not found
+	// Global middleware such as Recovery and Logger wraps the fallback chain
 	if !handled {
-		// Apply global middleware for error responses in production mode
-		if r.app != nil && !r.app.enableLogging && len(r.globalMiddleware) > 0 {
-			ctx.handlers = append(ctx.handlers, r.globalMiddleware...)
-		}
+		ctx.handlers = append(ctx.handlers, r.globalMiddleware...)
 		if r.noRoute != nil {
 			ctx.handlers = append(ctx.handlers, r.noRoute...)
 		} else {
@@ -260,12 +312,14 @@ func (r *router) handleRoute(method, path string, context *Context) bool {
 		if handlers, exists := r.fastRouter.UltraFastLookup(methodPtr, pathPtr, len(method), len(path)); exists {
 			// Preserve existing handlers (like logger) and append route handlers
 			context.handlers = append(context.handlers, handlers...)
+			context.fullPath = r.routePatterns[&handlers[0]]
 			return true
 		}
 		// Fallback to regular fast lookup only if ultra-fast fails
 		if handlers, exists := r.fastRouter.FastLookup(method, path); exists {
 			// Preserve existing handlers (like logger) and append route handlers
 			context.handlers = append(context.handlers, handlers...)
+			context.fullPath = r.routePatterns[&handlers[0]]
 			return true
 		}
 	}
@@ -292,6 +346,7 @@ func (r *router) handleRoute(method, path string, context *Context) bool {
 	if handlers != nil {
 		// Preserve existing handlers (like logger) and append route handlers
 		context.handlers = append(context.handlers, handlers...)
+		context.fullPath = r.routePatterns[&handlers[0]]
 		return true
 	}
 	return false
@@ -300,22 +355,16 @@ func (r *router) handleRoute(method, path string, context *Context) bool {
 // handleMethodNotAllowed generates a 405 Method Not Allowed response
 // It returns true if the request was handled, false otherwise
 func (r *router) handleMethodNotAllowed(fctx *fasthttp.RequestCtx, method, path string, context *Context) bool {
-	if allow := r.allowed(method, path, context); len(allow) > 0 {
+	if allow := r.allowed(path, context); len(allow) > 0 {
 		fctx.Response.Header.Set(HeaderAllow, allow)
+		// Global middleware such as Recovery wraps the fallback chain
+		context.handlers = append(context.handlers, r.globalMiddleware...)
 		// Use custom handlers if available
 		if r.noMethod != nil {
-			// Apply global middleware for error responses in production mode
-			if r.app != nil && !r.app.enableLogging && len(r.globalMiddleware) > 0 {
-				context.handlers = append(context.handlers, r.globalMiddleware...)
-			}
 			fctx.SetStatusCode(StatusMethodNotAllowed)
 			context.handlers = append(context.handlers, r.noMethod...)
 			return true
 		}
-		// Apply global middleware for error responses in production mode
-		if r.app != nil && !r.app.enableLogging && len(r.globalMiddleware) > 0 {
-			context.handlers = append(context.handlers, r.globalMiddleware...)
-		}
 		// Default Method Not Allowed response
 		fctx.SetStatusCode(StatusMethodNotAllowed)
 		fctx.SetContentTypeBytes([]byte(MIMETextPlainCharsetUTF8))
@@ -434,9 +483,10 @@ func (fr *FastRouter) PutContext(ctx *Context) {
 	if len(ctx.paramValues) > 0 {
 		clear(ctx.paramValues)
 	}
-	// Reset index and clear full path
+	// Reset index, full path and query cache
 	ctx.index = -1
 	ctx.fullPath = ""
+	ctx.queryCache = nil
 	fr.ctxPool.Put(ctx)
 }
 
@@ -474,11 +524,26 @@ func (fr *FastRouter) UltraFastLookup(methodPtr, pathPtr unsafe.Pointer, methodL
 	return nil, false
 }
 
-// WarmupCache pre-loads frequently used routes into cache
+// WarmupCache pre-loads frequently used GET and POST routes into cache
 func (fr *FastRouter) WarmupCache(routes []string) {
 	for _, route := range routes {
-		// Trigger cache loading
-		fr.FastLookup(MethodGet, route)
-		fr.FastLookup(MethodPost, route)
+		fr.promote(MethodGet, route)
+		fr.promote(MethodPost, route)
 	}
 }
+
+// promote places a registered static route into every cache level,
+// evicting routes that collided with it
+// It returns false if the route is not registered
+func (fr *FastRouter) promote(method, path string) bool {
+	combinedHash := ultraFastCombinedHash(ultraFastStringHash(method), ultraFastStringHash(path))
+	handlers, exists := fr.routeHashes[combinedHash]
+	if !exists {
+		return false
+	}
+	hash32 := uint32(combinedHash)
+	fr.ultraCache.entries[hash32&fr.ultraCache.hashMask] = ultraFastCacheEntry{hash: hash32, handlers: handlers}
+	fr.commonRoutes[hash32&1023] = commonRoute{key: hash32, handlers: handlers}
+	fr.routeCache[hash32&255] = hashCacheEntry{hash: combinedHash, handlers: handlers}
+	return true
+}
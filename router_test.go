@@ -62,7 +62,7 @@ func TestRouterBasics(t *testing.T) {
 	// Test allowed methods
 	r.handle(MethodPost, "/test", handlersChain{handler})
 	r.handle(MethodPut, "/test", handlersChain{handler})
-	allowed := r.allowed(MethodDelete, "/test", ctx)
+	allowed := r.allowed("/test", ctx)
 	assert.Contains(t, allowed, MethodGet)
 	assert.Contains(t, allowed, MethodPost)
 	assert.Contains(t, allowed, MethodPut)
@@ -281,3 +281,63 @@ func TestRouterPerformance(t *testing.T) {
 		<-done
 	}
 }
+
+func TestFallbackChainsRecoverPanics(t *testing.T) {
+	for _, app := range []*Gonoleks{New(), Default()} {
+		app.HandleMethodNotAllowed = true
+		app.Use(Recovery())
+		app.GET("/users", func(c *Context) {})
+		app.NoRoute(func(c *Context) { panic("no route") })
+		app.NoMethod(func(c *Context) { panic("no method") })
+		app.setupRouter()
+
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI("/missing")
+		reqCtx.Request.Header.SetMethod(MethodGet)
+		assert.NotPanics(t, func() { app.router.Handler(reqCtx) })
+		assert.Equal(t, StatusInternalServerError, reqCtx.Response.StatusCode())
+
+		reqCtx = &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI("/users")
+		reqCtx.Request.Header.SetMethod(MethodPost)
+		assert.NotPanics(t, func() { app.router.Handler(reqCtx) })
+		assert.Equal(t, StatusInternalServerError, reqCtx.Response.StatusCode())
+	}
+}
+
+func TestFallbackMiddleware(t *testing.T) {
+	app := New()
+	app.Use(func(c *Context) {
+		c.Header("X-Global", "1")
+		c.Next()
+	})
+	app.FallbackMiddleware(func(c *Context) {
+		c.Header("X-Fallback", "1")
+		c.Next()
+	})
+	app.GET("/", func(c *Context) {})
+	app.setupRouter()
+
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/missing")
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusNotFound, reqCtx.Response.StatusCode())
+	assert.Equal(t, "1", string(reqCtx.Response.Header.Peek("X-Fallback")))
+	assert.Empty(t, reqCtx.Response.Header.Peek("X-Global"))
+
+	reqCtx = &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/")
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "1", string(reqCtx.Response.Header.Peek("X-Global")))
+	assert.Empty(t, reqCtx.Response.Header.Peek("X-Fallback"))
+
+	// Without arguments no middleware wraps the fallback chains
+	app = New()
+	app.Use(func(c *Context) { panic("must not run") })
+	app.FallbackMiddleware()
+	app.setupRouter()
+	reqCtx = &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/missing")
+	assert.NotPanics(t, func() { app.router.Handler(reqCtx) })
+	assert.Equal(t, StatusNotFound, reqCtx.Response.StatusCode())
+}
@@ -1,7 +1,9 @@
 package gonoleks
 
 import (
+	"fmt"
 	"io/fs"
+	"slices"
 	"strings"
 
 	"github.com/valyala/fasthttp"
@@ -27,6 +29,11 @@ type IRoutes interface {
 	StaticFileFS(string, string, fs.FS)
 	Static(string, string)
 	StaticFS(string, fs.FS)
+	StaticWithConfig(string, StaticConfig)
+	Split(string, ...any) *Route
+	Resource(string, any, ...handlerFunc) []*Route
+	SPA(string, SPAConfig)
+	Stubs(...Stub) []*Route
 }
 
 // RouterGroup represents a group of routes with a common prefix
@@ -37,9 +44,10 @@ type RouterGroup struct {
 
 // RouteHandler provides the core routing implementation that both Gonoleks and RouterGroup embed
 type RouteHandler struct {
-	app         *Gonoleks
-	prefix      string
-	middlewares handlersChain
+	app            *Gonoleks
+	prefix         string
+	middlewares    handlersChain
+	allowedMethods []string // Methods routes may be registered for, any when nil
 }
 
 // Use registers middleware functions to be executed for all routes in the specified group
@@ -62,13 +70,42 @@ func (rh *RouteHandler) Group(relativePath string, handlers ...handlerFunc) *Rou
 	}
 	rg := &RouterGroup{}
 	rg.RouteHandler = RouteHandler{
-		app:         rh.app,
-		prefix:      rh.prefix + relativePath,
-		middlewares: newMiddlewares,
+		app:            rh.app,
+		prefix:         rh.prefix + relativePath,
+		middlewares:    newMiddlewares,
+		allowedMethods: rh.allowedMethods,
 	}
 	return rg
 }
 
+// AllowMethods restricts the group, and groups created from it, to the given methods
+// Requests with any other method for a path of the group are answered with 405 Method Not Allowed
+// and an Allow header listing the methods registered for the path, even when
+// Options.HandleMethodNotAllowed is off. Registering a route for another method panics,
+// and Any only registers the allowed methods
+//
+//	api := app.Group("/api").AllowMethods(gonoleks.MethodGet, gonoleks.MethodPost)
+func (rg *RouterGroup) AllowMethods(methods ...string) *RouterGroup {
+	for _, method := range methods {
+		if !isValidMethod(method) {
+			panic(fmt.Sprintf("http method %q is not valid", method))
+		}
+	}
+	rg.allowedMethods = methods
+	rg.app.methodRestrictedPrefixes = append(rg.app.methodRestrictedPrefixes, rg.prefix)
+	return rg
+}
+
+// methodRestricted reports whether path belongs to a group restricted with AllowMethods
+func (g *Gonoleks) methodRestricted(path string) bool {
+	for _, prefix := range g.methodRestrictedPrefixes {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // BasePath returns the base path of the router group
 // For example, if group := app.Group("/rest/n/v1/api"), group.BasePath() is "/rest/n/v1/api"
 func (rg *RouterGroup) BasePath() string {
@@ -77,6 +114,12 @@ func (rg *RouterGroup) BasePath() string {
 
 // Handle implements the core routing logic
 func (rh *RouteHandler) Handle(httpMethod, relativePath string, handlers ...handlerFunc) *Route {
+	if !isValidMethod(httpMethod) {
+		panic(fmt.Sprintf("http method %q is not valid", httpMethod))
+	}
+	if rh.allowedMethods != nil && !slices.Contains(rh.allowedMethods, httpMethod) {
+		panic(fmt.Sprintf("http method %q is not allowed in group %q", httpMethod, rh.prefix))
+	}
 	if rh.app.CaseInSensitive {
 		relativePath = strings.ToLower(relativePath)
 	}
@@ -102,10 +145,14 @@ func (rh *RouteHandler) Handle(httpMethod, relativePath string, handlers ...hand
 
 // Any registers a route that matches all the HTTP methods
 // GET, POST, PUT, PATCH, HEAD, OPTIONS, DELETE, CONNECT, TRACE
+// and the custom methods added with RegisterMethod
+// In a group restricted with AllowMethods, only the allowed methods are registered
 func (rh *RouteHandler) Any(relativePath string, handlers ...handlerFunc) []*Route {
-	anyMethods := []string{
-		MethodGet, MethodPost, MethodPut, MethodPatch, MethodHead,
-		MethodOptions, MethodDelete, MethodConnect, MethodTrace,
+	anyMethods := rh.app.Methods()
+	if rh.allowedMethods != nil {
+		anyMethods = slices.DeleteFunc(anyMethods, func(method string) bool {
+			return !slices.Contains(rh.allowedMethods, method)
+		})
 	}
 	return rh.Match(anyMethods, relativePath, handlers...)
 }
@@ -178,7 +225,7 @@ func (rh *RouteHandler) StaticFile(relativePath, filePath string) {
 //	app.StaticFileFS("favicon.ico", "favicon.ico", os.DirFS("./assets"))
 func (rh *RouteHandler) StaticFileFS(relativePath, filePath string, fs fs.FS) {
 	rh.staticFileHandler(relativePath, func(c *Context) {
-		fasthttp.ServeFS(c.requestCtx, fs, filePath)
+		c.FileFromFS(filePath, fs)
 	})
 }
 
@@ -191,59 +238,91 @@ func (rh *RouteHandler) staticFileHandler(relativePath string, handler handlerFu
 }
 
 // Static serves static files from the specified root directory under the given URL prefix
+// It is StaticWithConfig without compression, so nothing is written next to the files
 //
 //	app.Static("/static", "./assets")
 func (rh *RouteHandler) Static(relativePath, root string) {
-	rh.createStaticHandler(relativePath, &fasthttp.FS{
-		Root:       root,
-		IndexNames: []string{"index.html"},
-	})
+	rh.StaticWithConfig(relativePath, StaticConfig{Root: root})
 }
 
 // StaticFS serves static files from the given file system under the specified URL prefix
+// It is StaticWithConfig compressing with gzip, Brotli and zstd
 //
 //	app.StaticFS("/static", os.DirFS("./assets"))
 //	app.StaticFS("/static", embed.FS)
 func (rh *RouteHandler) StaticFS(relativePath string, fs fs.FS) {
-	rh.createStaticHandler(relativePath, &fasthttp.FS{
-		FS:                 fs,
-		Root:               "",
-		AllowEmptyRoot:     true,
-		IndexNames:         []string{"index.html"},
-		GenerateIndexPages: false,
-		Compress:           true,
-		CompressBrotli:     true,
-		AcceptByteRange:    true,
+	rh.StaticWithConfig(relativePath, StaticConfig{
+		FS:             fs,
+		Compress:       true,
+		CompressBrotli: true,
+		CompressZstd:   true,
 	})
 }
 
 // createStaticHandler is a helper function for directory serving with common logic
-func (rh *RouteHandler) createStaticHandler(relativePath string, fs *fasthttp.FS) {
+func (rh *RouteHandler) createStaticHandler(relativePath string, fs *fasthttp.FS, conf StaticConfig) {
 	if rh.app.CaseInSensitive {
 		relativePath = strings.ToLower(relativePath)
 	}
 	fullPath := strings.TrimSuffix(rh.prefix+relativePath, "/")
-	// Configure relativePath rewrite for the file system
-	fs.PathRewrite = func(ctx *fasthttp.RequestCtx) []byte {
-		requestPath := ctx.Path()
-		if len(requestPath) >= len(fullPath) {
-			// Remove the route prefix from the request relativePath
+	// Resolve the file system path by removing the route prefix and sanitizing the rest
+	filePath := func(ctx *fasthttp.RequestCtx) (string, bool) {
+		requestPath := getString(ctx.Path())
+		if len(requestPath) >= len(fullPath) && strings.EqualFold(requestPath[:len(fullPath)], fullPath) {
 			requestPath = requestPath[len(fullPath):]
 		}
-		if len(requestPath) == 0 {
-			return []byte("/")
-		}
-		if requestPath[0] != '/' {
-			return append([]byte("/"), requestPath...)
-		}
-		return requestPath
+		return sanitizePath(requestPath)
 	}
+	fs.PathRewrite = func(ctx *fasthttp.RequestCtx) []byte {
+		requestPath, _ := filePath(ctx)
+		return []byte(requestPath)
+	}
+	fs.AcceptByteRange = true
 	fileHandler := fs.NewRequestHandler()
+	fsys := staticFileSystem(fs)
+	var lister *directoryLister
+	if conf.Browse {
+		lister = newDirectoryLister(fullPath, fs, conf)
+	}
 	handler := func(c *Context) {
 		fctx := c.Context()
+		requestPath, ok := filePath(fctx)
+		if !ok {
+			fctx.Error(fasthttp.StatusMessage(StatusBadRequest), StatusBadRequest)
+			c.Abort()
+			return
+		}
+		if conf.Authorize != nil && !conf.Authorize(c, requestPath) {
+			if !c.IsAborted() {
+				fctx.Error(fasthttp.StatusMessage(StatusForbidden), StatusForbidden)
+				c.Abort()
+			}
+			return
+		}
+		if lister != nil && lister.serve(c, requestPath) {
+			return
+		}
+		if len(fctx.Request.Header.Peek(HeaderRange)) > 0 {
+			c.prepareByteRange(statStaticFile(fsys, requestPath))
+		}
 		fileHandler(fctx)
+		c.finishByteRange()
 		// Handle not found cases
 		status := fctx.Response.StatusCode()
+		if conf.CacheControl != (CacheControlOptions{}) &&
+			(status == StatusOK || status == StatusPartialContent || status == StatusNotModified) {
+			c.CacheControl(conf.CacheControl)
+		}
+		if status == StatusOK {
+			c.serveStaticConditional()
+			return
+		}
+		// fasthttp redirects directories to the rewritten path with a trailing slash,
+		// which lacks the route prefix
+		if status == StatusFound {
+			fctx.Response.Header.Set(HeaderLocation, string(fctx.Path())+"/")
+			return
+		}
 		if status == StatusNotFound || status == StatusForbidden {
 			// Pass to custom not found handlers if available
 			if len(rh.app.router.noRoute) > 0 {
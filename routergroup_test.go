@@ -287,3 +287,41 @@ func TestRouteGroupStaticFileServing(t *testing.T) {
 	assert.True(t, foundBase, "Static should register a base GET route for root path")
 	assert.True(t, foundWildcard, "Static should register a wildcard GET route for root path")
 }
+
+func TestRouterGroupAllowMethods(t *testing.T) {
+	app := New()
+	api := app.Group("/api").AllowMethods(MethodGet, MethodPost)
+	api.GET("/users", func(c *Context) { c.Status(StatusOK) })
+	api.POST("/users", func(c *Context) { c.Status(StatusCreated) })
+	api.GET("/health", func(c *Context) { c.Status(StatusOK) })
+	routes := api.Group("/v2").Any("/items", func(c *Context) { c.Status(StatusOK) })
+	app.GET("/apiary", func(c *Context) { c.Status(StatusOK) })
+	app.setupRouter()
+
+	assert.Len(t, routes, 2, "Any should only register the allowed methods")
+	assert.PanicsWithValue(t, `http method "DELETE" is not allowed in group "/api"`, func() {
+		api.DELETE("/users", func(c *Context) {})
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		allow  string
+	}{
+		{MethodPost, "/api/users", StatusCreated, ""},
+		{MethodDelete, "/api/users", StatusMethodNotAllowed, "GET, POST, OPTIONS"},
+		{MethodPut, "/api/health", StatusMethodNotAllowed, "GET, OPTIONS"},
+		{MethodPatch, "/api/v2/items", StatusMethodNotAllowed, "GET, POST, OPTIONS"},
+		{MethodDelete, "/api/missing", StatusNotFound, ""},
+		{MethodDelete, "/apiary", StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			reqCtx := newProxiedRequest(tt.method, tt.path, "203.0.113.5", nil)
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.code, reqCtx.Response.StatusCode())
+			assert.Equal(t, tt.allow, string(reqCtx.Response.Header.Peek(HeaderAllow)))
+		})
+	}
+}
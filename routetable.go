@@ -0,0 +1,83 @@
+package gonoleks
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// RouteSpec declares a route by name rather than by function value, so route tables
+// can be generated or loaded from configuration
+// Handler and Middlewares refer to functions registered with RegisterHandler
+type RouteSpec struct {
+	Method      string         `json:"method" yaml:"method"`
+	Path        string         `json:"path" yaml:"path"`
+	Handler     string         `json:"handler" yaml:"handler"`
+	Middlewares []string       `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// RegisterHandler names a handler or middleware so route tables passed to Register can refer to it
+// Registering a name again replaces the previous function
+func (g *Gonoleks) RegisterHandler(name string, handler handlerFunc) {
+	if g.namedHandlers == nil {
+		g.namedHandlers = make(map[string]handlerFunc)
+	}
+	g.namedHandlers[name] = handler
+}
+
+// Register adds every route of a declarative route table, with each route's middlewares
+// running after the global and group ones, in the listed order
+// The whole table is validated first, so either all routes are registered or none is,
+// and the returned error lists every invalid entry
+//
+//	app.RegisterHandler("auth", authMiddleware)
+//	app.RegisterHandler("users.show", showUser)
+//	routes, err := app.Group("/api").Register([]gonoleks.RouteSpec{
+//		{Method: "GET", Path: "/users/:id", Handler: "users.show", Middlewares: []string{"auth"}},
+//	})
+func (rh *RouteHandler) Register(specs []RouteSpec) ([]*Route, error) {
+	chains := make([]handlersChain, len(specs))
+	var errs []error
+	for i, spec := range specs {
+		chain, err := rh.resolveRouteSpec(spec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("route %d (%s %s): %w", i, spec.Method, spec.Path, err))
+			continue
+		}
+		chains[i] = chain
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	routes := make([]*Route, len(specs))
+	for i, spec := range specs {
+		route := rh.Handle(spec.Method, spec.Path, chains[i]...)
+		route.Metadata = maps.Clone(spec.Metadata)
+		routes[i] = route
+	}
+	return routes, nil
+}
+
+// resolveRouteSpec validates a route spec and looks up its middlewares and handler by name
+func (rh *RouteHandler) resolveRouteSpec(spec RouteSpec) (handlersChain, error) {
+	if !isValidMethod(spec.Method) {
+		return nil, fmt.Errorf("%w: http method %q is not valid", ErrInvalidRouteSpec, spec.Method)
+	}
+	if rh.allowedMethods != nil && !slices.Contains(rh.allowedMethods, spec.Method) {
+		return nil, fmt.Errorf("%w: http method %q is not allowed in group %q", ErrInvalidRouteSpec, spec.Method, rh.prefix)
+	}
+	if spec.Path == "" || spec.Path[0] != '/' {
+		return nil, fmt.Errorf("%w: path %q must begin with '/'", ErrInvalidRouteSpec, spec.Path)
+	}
+	chain := make(handlersChain, 0, len(spec.Middlewares)+1)
+	for _, name := range slices.Concat(spec.Middlewares, []string{spec.Handler}) {
+		handler, ok := rh.app.namedHandlers[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownHandler, name)
+		}
+		chain = append(chain, handler)
+	}
+	return chain, nil
+}
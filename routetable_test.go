@@ -0,0 +1,60 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterRouteTable(t *testing.T) {
+	app := New()
+	app.RegisterHandler("auth", func(c *Context) {
+		c.Header("X-Auth", "checked")
+		c.Next()
+	})
+	app.RegisterHandler("users.show", func(c *Context) { c.String(StatusOK, "user %s", c.Param("id")) })
+	app.RegisterHandler("users.create", func(c *Context) { c.Status(StatusCreated) })
+
+	routes, err := app.Group("/api").Register([]RouteSpec{
+		{Method: MethodGet, Path: "/users/:id", Handler: "users.show", Middlewares: []string{"auth"}, Metadata: map[string]any{"scope": "read"}},
+		{Method: MethodPost, Path: "/users", Handler: "users.create"},
+	})
+	require.NoError(t, err)
+	require.Len(t, routes, 2)
+	assert.Equal(t, "/api/users/:id", routes[0].Path)
+	assert.Equal(t, "read", routes[0].Metadata["scope"])
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/api/users/7", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, "user 7", string(reqCtx.Response.Body()))
+	assert.Equal(t, "checked", string(reqCtx.Response.Header.Peek("X-Auth")))
+
+	reqCtx = newProxiedRequest(MethodPost, "/api/users", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusCreated, reqCtx.Response.StatusCode())
+}
+
+func TestRegisterRouteTableValidation(t *testing.T) {
+	app := New()
+	app.RegisterHandler("ok", func(c *Context) {})
+	group := app.Group("/ro").AllowMethods(MethodGet)
+
+	routes, err := group.Register([]RouteSpec{
+		{Method: MethodGet, Path: "/valid", Handler: "ok"},
+		{Method: MethodGet, Path: "/missing", Handler: "nope"},
+		{Method: MethodGet, Path: "/mw", Handler: "ok", Middlewares: []string{"ghost"}},
+		{Method: "BAD METHOD", Path: "/x", Handler: "ok"},
+		{Method: MethodGet, Path: "relative", Handler: "ok"},
+		{Method: MethodPost, Path: "/write", Handler: "ok"},
+	})
+	assert.Nil(t, routes)
+	require.ErrorIs(t, err, ErrUnknownHandler)
+	require.ErrorIs(t, err, ErrInvalidRouteSpec)
+	assert.Contains(t, err.Error(), `route 1 (GET /missing): unknown handler name: "nope"`)
+	assert.Contains(t, err.Error(), `"ghost"`)
+	assert.Contains(t, err.Error(), `http method "POST" is not allowed in group "/ro"`)
+	assert.Empty(t, app.registeredRoutes, "no route should be registered when the table is invalid")
+}
@@ -0,0 +1,239 @@
+package gonoleks
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// RPCCode is a gRPC status code, shared by the gRPC-Web and Connect protocols
+type RPCCode uint32
+
+// RPC status codes
+const (
+	RPCCodeOK                 RPCCode = 0
+	RPCCodeCanceled           RPCCode = 1
+	RPCCodeUnknown            RPCCode = 2
+	RPCCodeInvalidArgument    RPCCode = 3
+	RPCCodeDeadlineExceeded   RPCCode = 4
+	RPCCodeNotFound           RPCCode = 5
+	RPCCodeAlreadyExists      RPCCode = 6
+	RPCCodePermissionDenied   RPCCode = 7
+	RPCCodeResourceExhausted  RPCCode = 8
+	RPCCodeFailedPrecondition RPCCode = 9
+	RPCCodeAborted            RPCCode = 10
+	RPCCodeOutOfRange         RPCCode = 11
+	RPCCodeUnimplemented      RPCCode = 12
+	RPCCodeInternal           RPCCode = 13
+	RPCCodeUnavailable        RPCCode = 14
+	RPCCodeDataLoss           RPCCode = 15
+	RPCCodeUnauthenticated    RPCCode = 16
+)
+
+// rpcCodeNames holds the Connect protocol names of the status codes
+var rpcCodeNames = [...]string{
+	"ok", "canceled", "unknown", "invalid_argument", "deadline_exceeded", "not_found",
+	"already_exists", "permission_denied", "resource_exhausted", "failed_precondition",
+	"aborted", "out_of_range", "unimplemented", "internal", "unavailable", "data_loss",
+	"unauthenticated",
+}
+
+// rpcCodeHTTPStatus maps status codes to the HTTP status used by the Connect protocol
+var rpcCodeHTTPStatus = [...]int{
+	StatusOK, 499, StatusInternalServerError, StatusBadRequest, StatusGatewayTimeout, StatusNotFound,
+	StatusConflict, StatusForbidden, StatusTooManyRequests, StatusBadRequest,
+	StatusConflict, StatusBadRequest, StatusNotImplemented, StatusInternalServerError, StatusServiceUnavailable, StatusInternalServerError,
+	StatusUnauthorized,
+}
+
+// String returns the Connect protocol name of the code
+func (code RPCCode) String() string {
+	if int(code) < len(rpcCodeNames) {
+		return rpcCodeNames[code]
+	}
+	return "code_" + strconv.FormatUint(uint64(code), 10)
+}
+
+// RPCError is an error carrying an RPC status code
+// Procedures return it to control the status reported to the client;
+// any other error is reported as RPCCodeUnknown
+type RPCError struct {
+	Code    RPCCode
+	Message string
+}
+
+// NewRPCError creates an RPCError with the given code and message
+func NewRPCError(code RPCCode, message string) *RPCError {
+	return &RPCError{Code: code, Message: message}
+}
+
+// Error implements the error interface
+func (e *RPCError) Error() string {
+	return e.Code.String() + ": " + e.Message
+}
+
+// HandleRPC registers a unary RPC procedure reachable over both the gRPC-Web and Connect protocols
+// The procedure name has the form "/package.Service/Method", as generated by protoc
+//
+//	gonoleks.HandleRPC(app, "/greet.v1.GreetService/Greet", func(c *gonoleks.Context, req *greetv1.GreetRequest) (*greetv1.GreetResponse, error) {
+//	    return &greetv1.GreetResponse{Greeting: "Hello, " + req.Name}, nil
+//	})
+func HandleRPC[Req, Res proto.Message](routes IRoutes, procedure string, fn func(c *Context, req Req) (Res, error)) *Route {
+	var zero Req
+	// Generated messages support ProtoReflect on nil receivers, which gives access to the message type
+	messageType := zero.ProtoReflect().Type()
+	return routes.Handle(MethodPost, procedure, func(c *Context) {
+		protocol := detectRPCProtocol(c.ContentType())
+		if protocol == rpcProtocolUnknown {
+			c.requestCtx.Error(fmt.Sprintf("unsupported content type %q", c.ContentType()), StatusUnsupportedMediaType)
+			return
+		}
+		req := messageType.New().Interface().(Req)
+		if err := protocol.decode(c, req); err != nil {
+			protocol.writeError(c, NewRPCError(RPCCodeInvalidArgument, err.Error()))
+			return
+		}
+		res, err := fn(c, req)
+		if err != nil {
+			protocol.writeError(c, err)
+			return
+		}
+		if err := protocol.encode(c, res); err != nil {
+			protocol.writeError(c, NewRPCError(RPCCodeInternal, err.Error()))
+		}
+	})
+}
+
+// rpcProtocol identifies the wire protocol of an RPC request
+type rpcProtocol uint8
+
+const (
+	rpcProtocolUnknown rpcProtocol = iota
+	rpcProtocolGRPCWeb
+	rpcProtocolGRPCWebText
+	rpcProtocolConnectProto
+	rpcProtocolConnectJSON
+)
+
+// detectRPCProtocol determines the protocol from the request content type
+func detectRPCProtocol(contentType string) rpcProtocol {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	switch strings.TrimSpace(strings.ToLower(contentType)) {
+	case MIMEApplicationGRPCWeb, MIMEApplicationGRPCWebProto:
+		return rpcProtocolGRPCWeb
+	case MIMEApplicationGRPCWebText, MIMEApplicationGRPCWebText + "+proto":
+		return rpcProtocolGRPCWebText
+	case MIMEApplicationProto:
+		return rpcProtocolConnectProto
+	case MIMEApplicationJSON:
+		return rpcProtocolConnectJSON
+	}
+	return rpcProtocolUnknown
+}
+
+// decode reads the request message from the body
+func (p rpcProtocol) decode(c *Context, msg proto.Message) error {
+	body := c.requestCtx.Request.Body()
+	switch p {
+	case rpcProtocolGRPCWebText:
+		decoded, err := base64.StdEncoding.DecodeString(string(body))
+		if err != nil {
+			return err
+		}
+		body = decoded
+		fallthrough
+	case rpcProtocolGRPCWeb:
+		if len(body) < 5 {
+			return ErrRPCMalformedFrame
+		}
+		if body[0]&0x01 != 0 {
+			return ErrRPCCompressionUnsupported
+		}
+		size := binary.BigEndian.Uint32(body[1:5])
+		if uint64(len(body)-5) < uint64(size) {
+			return ErrRPCMalformedFrame
+		}
+		return proto.Unmarshal(body[5:5+size], msg)
+	case rpcProtocolConnectProto:
+		return proto.Unmarshal(body, msg)
+	case rpcProtocolConnectJSON:
+		return protojson.Unmarshal(body, msg)
+	}
+	return nil
+}
+
+// encode writes a successful response message
+func (p rpcProtocol) encode(c *Context, msg proto.Message) error {
+	switch p {
+	case rpcProtocolGRPCWeb, rpcProtocolGRPCWebText:
+		raw, err := proto.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		p.writeGRPCWeb(c, appendGRPCWebFrame(nil, 0x00, raw), RPCCodeOK, "")
+	case rpcProtocolConnectProto:
+		raw, err := proto.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		c.Data(StatusOK, MIMEApplicationProto, raw)
+	case rpcProtocolConnectJSON:
+		raw, err := protojson.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		c.Data(StatusOK, MIMEApplicationJSON, raw)
+	}
+	return nil
+}
+
+// writeError reports an error using the error model of the protocol
+func (p rpcProtocol) writeError(c *Context, err error) {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		rpcErr = NewRPCError(RPCCodeUnknown, err.Error())
+	}
+	switch p {
+	case rpcProtocolGRPCWeb, rpcProtocolGRPCWebText:
+		p.writeGRPCWeb(c, nil, rpcErr.Code, rpcErr.Message)
+	default:
+		status := StatusInternalServerError
+		if int(rpcErr.Code) < len(rpcCodeHTTPStatus) {
+			status = rpcCodeHTTPStatus[rpcErr.Code]
+		}
+		raw, _ := sonic.Marshal(H{"code": rpcErr.Code.String(), "message": rpcErr.Message})
+		c.Data(status, MIMEApplicationJSON, raw)
+	}
+}
+
+// writeGRPCWeb writes the data frames followed by the trailer frame carrying the status
+func (p rpcProtocol) writeGRPCWeb(c *Context, frames []byte, code RPCCode, message string) {
+	trailer := "grpc-status: " + strconv.FormatUint(uint64(code), 10) + "\r\n"
+	if message != "" {
+		trailer += "grpc-message: " + url.PathEscape(message) + "\r\n"
+	}
+	frames = appendGRPCWebFrame(frames, 0x80, []byte(trailer))
+	contentType := MIMEApplicationGRPCWebProto
+	if p == rpcProtocolGRPCWebText {
+		contentType = MIMEApplicationGRPCWebText + "+proto"
+		frames = []byte(base64.StdEncoding.EncodeToString(frames))
+	}
+	c.Data(StatusOK, contentType, frames)
+}
+
+// appendGRPCWebFrame appends a length-prefixed gRPC-Web frame with the given flags
+func appendGRPCWebFrame(dst []byte, flags byte, payload []byte) []byte {
+	dst = append(dst, flags)
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(payload)))
+	return append(dst, payload...)
+}
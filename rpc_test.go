@@ -0,0 +1,105 @@
+package gonoleks
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gonoleks/gonoleks/testdata/protoexample"
+)
+
+const testProcedure = "/testdata.UserService/GetProfile"
+
+func createTestRPCApp() *Gonoleks {
+	app := New()
+	HandleRPC(app, testProcedure, func(c *Context, req *protoexample.TestMessage) (*protoexample.UserProfile, error) {
+		if req.GetName() == "" {
+			return nil, NewRPCError(RPCCodeInvalidArgument, "name is required")
+		}
+		return &protoexample.UserProfile{Username: req.GetName(), Age: req.GetId()}, nil
+	})
+	app.setupRouter()
+	return app
+}
+
+func performRPCRequest(app *Gonoleks, contentType string, body []byte) *fasthttp.RequestCtx {
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI(testProcedure)
+	reqCtx.Request.Header.SetMethod(MethodPost)
+	reqCtx.Request.Header.SetContentType(contentType)
+	reqCtx.Request.SetBody(body)
+	app.router.Handler(reqCtx)
+	return reqCtx
+}
+
+func TestRPCConnectProtocol(t *testing.T) {
+	app := createTestRPCApp()
+
+	reqCtx := performRPCRequest(app, MIMEApplicationJSON, []byte(`{"name":"gopher","id":7}`))
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.JSONEq(t, `{"username":"gopher","age":7}`, string(reqCtx.Response.Body()))
+
+	raw, err := proto.Marshal(&protoexample.TestMessage{Name: "proto"})
+	require.NoError(t, err)
+	reqCtx = performRPCRequest(app, MIMEApplicationProto, raw)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	profile := &protoexample.UserProfile{}
+	require.NoError(t, proto.Unmarshal(reqCtx.Response.Body(), profile))
+	assert.Equal(t, "proto", profile.GetUsername())
+
+	reqCtx = performRPCRequest(app, MIMEApplicationJSON, []byte(`{}`))
+	assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode())
+	assert.JSONEq(t, `{"code":"invalid_argument","message":"name is required"}`, string(reqCtx.Response.Body()))
+
+	reqCtx = performRPCRequest(app, MIMETextPlain, []byte("hello"))
+	assert.Equal(t, StatusUnsupportedMediaType, reqCtx.Response.StatusCode())
+}
+
+func TestRPCGRPCWebProtocol(t *testing.T) {
+	app := createTestRPCApp()
+	raw, err := proto.Marshal(&protoexample.TestMessage{Name: "web", Id: 3})
+	require.NoError(t, err)
+	frame := appendGRPCWebFrame(nil, 0x00, raw)
+
+	reqCtx := performRPCRequest(app, MIMEApplicationGRPCWebProto, frame)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	body := reqCtx.Response.Body()
+	require.Greater(t, len(body), 5)
+	assert.Equal(t, byte(0x00), body[0], "First frame should carry the message")
+	size := binary.BigEndian.Uint32(body[1:5])
+	profile := &protoexample.UserProfile{}
+	require.NoError(t, proto.Unmarshal(body[5:5+size], profile))
+	assert.Equal(t, "web", profile.GetUsername())
+	trailer := body[5+size:]
+	assert.Equal(t, byte(0x80), trailer[0], "Last frame should be the trailer")
+	assert.Contains(t, string(trailer[5:]), "grpc-status: 0")
+
+	// gRPC-Web text variant is base64 encoded in both directions
+	reqCtx = performRPCRequest(app, MIMEApplicationGRPCWebText, []byte(base64.StdEncoding.EncodeToString(frame)))
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	decoded, err := base64.StdEncoding.DecodeString(string(reqCtx.Response.Body()))
+	require.NoError(t, err)
+	assert.Equal(t, body, decoded)
+
+	// Errors are reported in the trailer with HTTP status 200
+	empty := appendGRPCWebFrame(nil, 0x00, nil)
+	reqCtx = performRPCRequest(app, MIMEApplicationGRPCWeb, empty)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Contains(t, string(reqCtx.Response.Body()), "grpc-status: 3")
+	assert.Contains(t, string(reqCtx.Response.Body()), "grpc-message: name%20is%20required")
+
+	// Truncated frames are rejected
+	reqCtx = performRPCRequest(app, MIMEApplicationGRPCWeb, []byte{0x00, 0x00})
+	assert.Contains(t, string(reqCtx.Response.Body()), "grpc-status: 3")
+}
+
+func TestRPCCode(t *testing.T) {
+	assert.Equal(t, "not_found", RPCCodeNotFound.String())
+	assert.Equal(t, "code_99", RPCCode(99).String())
+	assert.Equal(t, "unavailable: down", NewRPCError(RPCCodeUnavailable, "down").Error())
+}
@@ -0,0 +1,347 @@
+package gonoleks
+
+import (
+	"html"
+	"html/template"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// HTMLPolicy is the allow-list applied by SanitizeHTML
+type HTMLPolicy struct {
+	// Elements maps the allowed elements to their allowed attributes, in lowercase
+	// Other elements are removed while keeping their text, except for elements such as script
+	// and style, which are removed with their content
+	// Event handler attributes such as onclick are never allowed
+	Elements map[string][]string
+
+	// URLSchemes lists the schemes allowed in URL attributes such as href and src,
+	// relative URLs are always allowed
+	URLSchemes []string
+
+	// NoFollow sets rel="nofollow noopener" on links, so user content earns no search ranking
+	// and cannot reach the opening window
+	NoFollow bool
+}
+
+// DefaultHTMLPolicy allows basic formatting, links, images, lists and tables, as found in
+// comments and other user-generated content
+var DefaultHTMLPolicy = HTMLPolicy{
+	Elements: map[string][]string{
+		"a": {"href", "title"}, "abbr": {"title"}, "b": nil, "blockquote": {"cite"}, "br": nil,
+		"code": nil, "del": nil, "em": nil, "h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil,
+		"h6": nil, "hr": nil, "i": nil, "img": {"src", "alt", "title", "width", "height"},
+		"ins": nil, "kbd": nil, "li": nil, "mark": nil, "ol": {"start"}, "p": nil, "pre": nil,
+		"q": {"cite"}, "s": nil, "small": nil, "span": nil, "strong": nil, "sub": nil, "sup": nil,
+		"table": nil, "tbody": nil, "td": {"colspan", "rowspan"}, "tfoot": nil,
+		"th": {"colspan", "rowspan", "scope"}, "thead": nil, "tr": nil, "u": nil, "ul": nil,
+	},
+	URLSchemes: []string{"http", "https", "mailto"},
+	NoFollow:   true,
+}
+
+// htmlDroppedElements are removed together with their content, as their content is not
+// markup or not meant to be displayed
+var htmlDroppedElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true, "xmp": true, "iframe": true,
+	"noembed": true, "noframes": true, "noscript": true, "plaintext": true,
+}
+
+// htmlVoidElements have no content and no end tag
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlURLAttributes hold URLs checked against HTMLPolicy.URLSchemes
+var htmlURLAttributes = map[string]bool{
+	"href": true, "src": true, "cite": true, "action": true, "formaction": true, "poster": true,
+	"background": true, "longdesc": true, "xlink:href": true,
+}
+
+// htmlAttribute is a parsed attribute of a tag
+type htmlAttribute struct {
+	name  string
+	value string
+}
+
+// SanitizeHTML removes everything not allowed by policy, defaulting to DefaultHTMLPolicy,
+// from user-generated HTML so it can be rendered safely
+// The result is rebuilt from the parsed input rather than filtered, with every text and
+// attribute value escaped and unclosed elements closed, so it is well-formed whatever the input
+// It is available to HTMLEngine templates as sanitize
+//
+//	{{ sanitize .Comment.Body }}
+func SanitizeHTML(s string, policy ...HTMLPolicy) template.HTML {
+	p := DefaultHTMLPolicy
+	if len(policy) > 0 {
+		p = policy[0]
+	}
+	var b strings.Builder
+	var open []string
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			writeHTMLText(&b, s)
+			break
+		}
+		writeHTMLText(&b, s[:lt])
+		s = s[lt:]
+		if strings.HasPrefix(s, "<!--") {
+			// Comments are removed
+			if end := strings.Index(s[4:], "-->"); end >= 0 {
+				s = s[4+end+3:]
+			} else {
+				s = ""
+			}
+			continue
+		}
+		if len(s) > 1 && (s[1] == '!' || s[1] == '?') {
+			// Doctypes, CDATA sections and processing instructions are removed
+			if end := strings.IndexByte(s, '>'); end >= 0 {
+				s = s[end+1:]
+			} else {
+				s = ""
+			}
+			continue
+		}
+		name, closing, attrs, n := parseHTMLTag(s)
+		if n == 0 {
+			b.WriteString("&lt;")
+			s = s[1:]
+			continue
+		}
+		s = s[n:]
+		switch {
+		case htmlDroppedElements[name]:
+			if !closing {
+				s = skipHTMLElement(s, name)
+			}
+		case !p.allows(name):
+		case closing:
+			if i := slices.Index(open, name); i >= 0 {
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+			}
+		default:
+			p.writeTag(&b, name, attrs)
+			if !htmlVoidElements[name] {
+				open = append(open, name)
+			}
+		}
+	}
+	for j := len(open) - 1; j >= 0; j-- {
+		b.WriteString("</" + open[j] + ">")
+	}
+	return template.HTML(b.String())
+}
+
+// allows reports whether the policy allows the element
+func (p *HTMLPolicy) allows(name string) bool {
+	_, ok := p.Elements[name]
+	return ok
+}
+
+// writeTag writes the start tag of an allowed element with its allowed attributes
+func (p *HTMLPolicy) writeTag(b *strings.Builder, name string, attrs []htmlAttribute) {
+	b.WriteString("<" + name)
+	allowed := p.Elements[name]
+	link := false
+	for _, attr := range attrs {
+		if strings.HasPrefix(attr.name, "on") || !slices.Contains(allowed, attr.name) {
+			continue
+		}
+		if name == "a" && attr.name == "rel" && p.NoFollow {
+			continue
+		}
+		if htmlURLAttributes[attr.name] && !p.allowsURL(attr.value) {
+			continue
+		}
+		link = link || name == "a" && attr.name == "href"
+		b.WriteString(" " + attr.name + `="` + html.EscapeString(attr.value) + `"`)
+	}
+	if link && p.NoFollow {
+		b.WriteString(` rel="nofollow noopener"`)
+	}
+	b.WriteByte('>')
+}
+
+// allowsURL reports whether a URL is relative or has an allowed scheme
+// Whitespace and control characters are ignored in the scheme, as browsers do
+func (p *HTMLPolicy) allowsURL(value string) bool {
+	colon := strings.IndexByte(value, ':')
+	if colon < 0 || strings.ContainsAny(value[:colon], "/?#") {
+		return true
+	}
+	scheme := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7F {
+			return -1
+		}
+		return r
+	}, value[:colon])
+	return slices.Contains(p.URLSchemes, strings.ToLower(scheme))
+}
+
+// writeHTMLText writes text with its character references normalized and escaped
+func writeHTMLText(b *strings.Builder, text string) {
+	b.WriteString(html.EscapeString(html.UnescapeString(text)))
+}
+
+// parseHTMLTag parses the tag at the start of s, returning its lowercase name, whether it is an
+// end tag, its attributes with unescaped values, and its length, zero when s starts with no tag
+func parseHTMLTag(s string) (name string, closing bool, attrs []htmlAttribute, n int) {
+	i := 1
+	if i < len(s) && s[i] == '/' {
+		closing = true
+		i++
+	}
+	start := i
+	for i < len(s) && (isASCIILetter(s[i]) || i > start && s[i] >= '0' && s[i] <= '9') {
+		i++
+	}
+	if i == start {
+		return "", false, nil, 0
+	}
+	name = strings.ToLower(s[start:i])
+	for {
+		for i < len(s) && (isHTMLSpace(s[i]) || s[i] == '/') {
+			i++
+		}
+		if i >= len(s) {
+			// Unterminated tags are text
+			return "", false, nil, 0
+		}
+		if s[i] == '>' {
+			return name, closing, attrs, i + 1
+		}
+		start = i
+		for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '/' && s[i] != '>' && (s[i] != '=' || i == start) {
+			i++
+		}
+		attr := htmlAttribute{name: strings.ToLower(s[start:i])}
+		for i < len(s) && isHTMLSpace(s[i]) {
+			i++
+		}
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isHTMLSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				end := strings.IndexByte(s[i+1:], s[i])
+				if end < 0 {
+					return "", false, nil, 0
+				}
+				attr.value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start = i
+				for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				attr.value = s[start:i]
+			}
+			attr.value = html.UnescapeString(attr.value)
+		}
+		attrs = append(attrs, attr)
+	}
+}
+
+// skipHTMLElement returns s after the end tag of the element name, or an empty string
+// when the element is not closed
+func skipHTMLElement(s, name string) string {
+	lower := strings.ToLower(s)
+	for i := 0; ; {
+		end := strings.Index(lower[i:], "</"+name)
+		if end < 0 {
+			return ""
+		}
+		i += end + 2 + len(name)
+		if i == len(s) || isHTMLSpace(s[i]) || s[i] == '/' || s[i] == '>' {
+			if gt := strings.IndexByte(s[i:], '>'); gt >= 0 {
+				return s[i+gt+1:]
+			}
+			return ""
+		}
+	}
+}
+
+// isASCIILetter reports whether c is an ASCII letter
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// isHTMLSpace reports whether c is HTML whitespace
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// SafeHTML is like HTML, but first sanitizes the template.HTML values of data with
+// SanitizeHTML and policy, so user-generated HTML marked as safe is rendered through the
+// allow-list instead of verbatim
+// The values of a map, e.g. H, or the fields of a struct are sanitized, nested values are not,
+// and data itself is left unchanged
+//
+//	return c.SafeHTML(gonoleks.StatusOK, "posts/show.html", gonoleks.H{
+//		"Title": post.Title,
+//		"Body":  template.HTML(post.BodyHTML),
+//	})
+func (c *Context) SafeHTML(code int, name string, data any, policy ...HTMLPolicy) error {
+	return c.HTML(code, name, sanitizeHTMLValues(data, policy))
+}
+
+// htmlType is the type of template.HTML
+var htmlType = reflect.TypeFor[template.HTML]()
+
+// sanitizeHTMLValues returns a copy of data with its top-level template.HTML values sanitized
+func sanitizeHTMLValues(data any, policy []HTMLPolicy) any {
+	v := reflect.ValueOf(data)
+	if !v.IsValid() {
+		return data
+	}
+	sanitize := func(value reflect.Value) (reflect.Value, bool) {
+		if value.Kind() == reflect.Interface {
+			value = value.Elem()
+		}
+		if !value.IsValid() || value.Type() != htmlType {
+			return value, false
+		}
+		return reflect.ValueOf(SanitizeHTML(value.String(), policy...)), true
+	}
+	if s, ok := sanitize(v); ok {
+		return s.Interface()
+	}
+	switch {
+	case v.Kind() == reflect.Map:
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			value := iter.Value()
+			if s, ok := sanitize(value); ok {
+				value = s.Convert(v.Type().Elem())
+			}
+			out.SetMapIndex(iter.Key(), value)
+		}
+		return out.Interface()
+	case v.Kind() == reflect.Struct, v.Kind() == reflect.Pointer && !v.IsNil() && v.Elem().Kind() == reflect.Struct:
+		ptr := v.Kind() == reflect.Pointer
+		if ptr {
+			v = v.Elem()
+		}
+		out := reflect.New(v.Type())
+		out.Elem().Set(v)
+		for i := range v.NumField() {
+			field := out.Elem().Field(i)
+			if field.CanSet() && field.Type() == htmlType {
+				field.SetString(string(SanitizeHTML(field.String(), policy...)))
+			}
+		}
+		if ptr {
+			return out.Interface()
+		}
+		return out.Elem().Interface()
+	}
+	return data
+}
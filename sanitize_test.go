@@ -0,0 +1,76 @@
+package gonoleks
+
+import (
+	"html/template"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`<p>Hello <b>world</b></p>`, `<p>Hello <b>world</b></p>`},
+		{`<P CLASS="x" onclick="evil()">hi</P>`, `<p>hi</p>`},
+		{`<script>alert(1)</script>ok`, `ok`},
+		{`<SCRIPT type="x">alert("</b>")</script >ok`, `ok`},
+		{`<style>p{}</style><p>x`, `<p>x</p>`},
+		{`<a href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="jav&#x09;ascript:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="/docs?a=1&amp;b=2" rel="me">x</a>`, `<a href="/docs?a=1&amp;b=2" rel="nofollow noopener">x</a>`},
+		{`<a href=https://example.com title='a "b"'>x</a>`, `<a href="https://example.com" title="a &#34;b&#34;" rel="nofollow noopener">x</a>`},
+		{`<img src="data:image/png;base64,xx" alt=x onerror=alert(1)>`, `<img alt="x">`},
+		{`<div><em>kept</em></div>`, `<em>kept</em>`},
+		{`<b><i>unclosed`, `<b><i>unclosed</i></b>`},
+		{`<b>a</i>b</b></b>`, `<b>ab</b>`},
+		{`<ul><li>a<li>b</ul>`, `<ul><li>a<li>b</li></li></ul>`},
+		{`a < b && c > d`, `a &lt; b &amp;&amp; c &gt; d`},
+		{`<!-- secret --><!DOCTYPE html>x<br/>`, `x<br>`},
+		{`<b title="x>y">t</b`, `<b>t&lt;/b</b>`},
+		{`&lt;script&gt;`, `&lt;script&gt;`},
+		{`<iframe src="https://evil"></iframe>`, ``},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, template.HTML(tt.expected), SanitizeHTML(tt.input), tt.input)
+	}
+
+	policy := HTMLPolicy{Elements: map[string][]string{"a": {"href"}}, URLSchemes: []string{"https"}}
+	assert.Equal(t, template.HTML(`<a>x</a> <a href="https://x">y</a>`),
+		SanitizeHTML(`<a href="http://x">x</a> <b><a href="https://x">y</a></b>`, policy))
+}
+
+func TestSafeHTML(t *testing.T) {
+	engine, err := NewHTMLEngine(HTMLEngineConfig{FS: fstest.MapFS{
+		"post.html": {Data: []byte(`{{.Title}}|{{.Body}}`)},
+	}})
+	require.NoError(t, err)
+	app := New()
+	app.SetTemplateEngine(engine)
+
+	data := H{"Title": "<b>t</b>", "Body": template.HTML(`<b onclick="x()">b</b><script>x()</script>`)}
+	c, requestCtx := createTestContext()
+	c.app = app
+	require.NoError(t, c.SafeHTML(StatusOK, "post.html", data))
+	assert.Equal(t, `&lt;b&gt;t&lt;/b&gt;|<b>b</b>`, string(requestCtx.Response.Body()))
+	// The data of the caller is left unchanged
+	assert.Contains(t, data["Body"], "script")
+
+	type post struct {
+		Title string
+		Body  template.HTML
+	}
+	p := &post{Title: "t", Body: `<p><img src=x onerror=y()></p>`}
+	require.NoError(t, c.SafeHTML(StatusOK, "post.html", p))
+	assert.Equal(t, `t|<p><img src="x"></p>`, string(requestCtx.Response.Body()))
+	assert.Contains(t, string(p.Body), "onerror")
+	require.NoError(t, c.SafeHTML(StatusOK, "post.html", *p, HTMLPolicy{}))
+	assert.Equal(t, `t|`, string(requestCtx.Response.Body()))
+
+	templates := map[string]template.HTML{"Title": "<i>x</i>", "Body": "<u>y</u>"}
+	require.NoError(t, c.SafeHTML(StatusOK, "post.html", templates, HTMLPolicy{Elements: map[string][]string{"u": nil}}))
+	assert.Equal(t, `x|<u>y</u>`, string(requestCtx.Response.Body()))
+}
@@ -0,0 +1,67 @@
+package gonoleks
+
+import (
+	"errors"
+	"fmt"
+)
+
+// resourceKey is the user value key of the request-scoped resource of type T
+type resourceKey[T any] struct{}
+
+// WithResource instances a middleware acquiring a resource for the request, typically a database
+// transaction, and releasing it after the handlers have run
+// Handlers get the resource with GetResource
+// release receives nil when the request succeeded, so the resource can be committed, or otherwise
+// the error to roll back for: the errors recorded with Error and AbortWithError, ErrResponseStatus
+// for a response status of 400 or higher, or ErrHandlerPanicked
+// A failed acquire aborts the request with 500
+//
+//	app.Use(gonoleks.WithResource(
+//	    func(c *gonoleks.Context) (*sql.Tx, error) { return db.BeginTx(c, nil) },
+//	    func(tx *sql.Tx, err error) {
+//	        if err != nil {
+//	            _ = tx.Rollback()
+//	            return
+//	        }
+//	        _ = tx.Commit()
+//	    },
+//	))
+func WithResource[T any](acquire func(c *Context) (T, error), release func(resource T, err error)) handlerFunc {
+	return func(c *Context) {
+		resource, err := acquire(c)
+		if err != nil {
+			_ = c.AbortWithError(StatusInternalServerError, err)
+			return
+		}
+		c.requestCtx.SetUserValue(resourceKey[T]{}, resource)
+		completed := false
+		defer func() {
+			if !completed {
+				// The handler panicked, release without recovering so the panic keeps its stack
+				release(resource, ErrHandlerPanicked)
+			}
+		}()
+		c.Next()
+		completed = true
+		release(resource, resourceOutcome(c))
+	}
+}
+
+// resourceOutcome returns the error a request-scoped resource is released with, nil on success
+func resourceOutcome(c *Context) error {
+	if errs := c.Errors(); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if status := c.requestCtx.Response.StatusCode(); status >= StatusBadRequest {
+		return fmt.Errorf("%w: %d", ErrResponseStatus, status)
+	}
+	return nil
+}
+
+// GetResource returns the resource of type T acquired by WithResource for the request
+//
+//	tx, ok := gonoleks.GetResource[*sql.Tx](c)
+func GetResource[T any](c *Context) (T, bool) {
+	resource, ok := c.requestCtx.UserValue(resourceKey[T]{}).(T)
+	return resource, ok
+}
@@ -0,0 +1,81 @@
+package gonoleks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTx struct {
+	id  int
+	err error
+}
+
+func TestWithResource(t *testing.T) {
+	app := New()
+	var released []*fakeTx
+	next := 0
+	app.Use(WithResource(
+		func(c *Context) (*fakeTx, error) {
+			if c.Query("fail") != "" {
+				return nil, errors.New("pool exhausted")
+			}
+			next++
+			return &fakeTx{id: next}, nil
+		},
+		func(tx *fakeTx, err error) {
+			tx.err = err
+			released = append(released, tx)
+		},
+	))
+	recorded := errors.New("insert failed")
+	app.GET("/ok", func(c *Context) {
+		tx, ok := GetResource[*fakeTx](c)
+		require.True(t, ok)
+		c.String(StatusOK, "tx %d", tx.id)
+	})
+	app.GET("/status", func(c *Context) { c.Status(StatusConflict) })
+	app.GET("/recorded", func(c *Context) {
+		_ = c.Error(recorded)
+		c.Status(StatusAccepted)
+	})
+	app.GET("/panic", func(c *Context) { panic("boom") })
+	app.setupRouter()
+
+	status, body := resourceRequest(app, MethodGet, "/ok", nil)
+	assert.Equal(t, StatusOK, status)
+	assert.Equal(t, "tx 1", body)
+	require.Len(t, released, 1)
+	assert.NoError(t, released[0].err)
+
+	resourceRequest(app, MethodGet, "/status", nil)
+	require.Len(t, released, 2)
+	assert.ErrorIs(t, released[1].err, ErrResponseStatus)
+
+	resourceRequest(app, MethodGet, "/recorded", nil)
+	require.Len(t, released, 3)
+	assert.ErrorIs(t, released[2].err, recorded)
+
+	assert.Panics(t, func() { resourceRequest(app, MethodGet, "/panic", nil) })
+	require.Len(t, released, 4)
+	assert.ErrorIs(t, released[3].err, ErrHandlerPanicked)
+
+	status, _ = resourceRequest(app, MethodGet, "/ok?fail=1", nil)
+	assert.Equal(t, StatusInternalServerError, status)
+	assert.Len(t, released, 4)
+}
+
+func TestContextErrors(t *testing.T) {
+	c, _ := createTestContext()
+	assert.Empty(t, c.Errors())
+	first := errors.New("first")
+	assert.Same(t, first, c.Error(first))
+	assert.NoError(t, c.Error(nil))
+	_ = c.AbortWithError(StatusBadRequest, ErrBindRequired)
+	assert.Equal(t, []error{first, ErrBindRequired}, c.Errors())
+
+	_, ok := GetResource[*fakeTx](c)
+	assert.False(t, ok)
+}
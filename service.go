@@ -0,0 +1,187 @@
+package gonoleks
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	contextType  = reflect.TypeFor[*Context]()
+	gonoleksType = reflect.TypeFor[*Gonoleks]()
+	errorType    = reflect.TypeFor[error]()
+)
+
+// serviceContainer holds the services provided to an app, constructed once on first use
+type serviceContainer struct {
+	mu        sync.Mutex
+	providers map[reflect.Type]*serviceProvider
+}
+
+// serviceProvider constructs a service, or holds it once constructed
+type serviceProvider struct {
+	constructor reflect.Value
+	value       reflect.Value
+	err         error
+	done        bool
+	resolving   bool
+}
+
+// Provide registers services for Service, Resolve and Inject, to replace global variables wiring
+// handlers to databases, clients and the like
+// A constructor is a function returning the service, optionally followed by an error, whose
+// params are other services; it is called once, when the service is first needed,
+// and must not call Resolve itself
+// Any other value is registered as a service of its own type
+// The app itself is always available as *Gonoleks
+// It panics on an invalid constructor or a type that is already provided
+//
+//	app.Provide(
+//	    cfg,
+//	    func(cfg *Config) (*sql.DB, error) { return sql.Open("postgres", cfg.DSN) },
+//	    func(db *sql.DB) UserRepository { return &pgUsers{db: db} },
+//	)
+func (g *Gonoleks) Provide(constructors ...any) {
+	if g.services == nil {
+		g.services = &serviceContainer{providers: make(map[reflect.Type]*serviceProvider)}
+	}
+	g.services.mu.Lock()
+	defer g.services.mu.Unlock()
+	for _, constructor := range constructors {
+		if constructor == nil {
+			panic("provide: nil constructor")
+		}
+		fn := reflect.ValueOf(constructor)
+		typ := fn.Type()
+		provider := &serviceProvider{value: fn, done: true}
+		if typ.Kind() == reflect.Func {
+			if typ.NumOut() == 0 || typ.NumOut() > 2 || (typ.NumOut() == 2 && typ.Out(1) != errorType) || typ.IsVariadic() {
+				panic(fmt.Sprintf("provide: constructor %s must return a service, optionally followed by an error", typ))
+			}
+			typ = typ.Out(0)
+			provider = &serviceProvider{constructor: fn}
+		}
+		if _, ok := g.services.providers[typ]; ok {
+			panic(fmt.Sprintf("provide: %s is already provided", typ))
+		}
+		g.services.providers[typ] = provider
+	}
+}
+
+// resolve returns the service of type typ, constructing it and its dependencies when needed
+func (g *Gonoleks) resolve(typ reflect.Type) (reflect.Value, error) {
+	if typ == gonoleksType {
+		return reflect.ValueOf(g), nil
+	}
+	if g.services == nil {
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrServiceNotProvided, typ)
+	}
+	g.services.mu.Lock()
+	defer g.services.mu.Unlock()
+	return g.services.resolve(g, typ, nil)
+}
+
+// resolve returns the service of type typ, path lists the services being constructed
+// The caller must hold the lock
+func (s *serviceContainer) resolve(g *Gonoleks, typ reflect.Type, path []reflect.Type) (reflect.Value, error) {
+	if typ == gonoleksType {
+		return reflect.ValueOf(g), nil
+	}
+	provider, ok := s.providers[typ]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrServiceNotProvided, typ)
+	}
+	if provider.done {
+		return provider.value, provider.err
+	}
+	path = append(path, typ)
+	if provider.resolving {
+		names := make([]string, len(path))
+		for i, t := range path {
+			names[i] = t.String()
+		}
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrServiceCycle, strings.Join(names, " -> "))
+	}
+	provider.resolving = true
+	defer func() { provider.resolving = false }()
+	fnType := provider.constructor.Type()
+	args := make([]reflect.Value, fnType.NumIn())
+	for i := range args {
+		arg, err := s.resolve(g, fnType.In(i), path)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("constructing %s: %w", typ, err)
+		}
+		args[i] = arg
+	}
+	out := provider.constructor.Call(args)
+	provider.value = out[0]
+	if len(out) == 2 && !out[1].IsNil() {
+		provider.err = fmt.Errorf("constructing %s: %w", typ, out[1].Interface().(error))
+	}
+	provider.done = true
+	return provider.value, provider.err
+}
+
+// Resolve returns the service of type T provided to the app, constructing it when needed
+// It fails with ErrServiceNotProvided when no service of type T is provided,
+// and with the error of its constructor
+func Resolve[T any](g *Gonoleks) (T, error) {
+	var service T
+	value, err := g.resolve(reflect.TypeFor[T]())
+	if err != nil {
+		return service, err
+	}
+	return value.Interface().(T), nil
+}
+
+// Service returns the service of type T provided to the app serving the request, see Provide
+// It panics when the service cannot be resolved, which Recovery answers with 500
+//
+//	users := gonoleks.Service[UserRepository](c)
+func Service[T any](c *Context) T {
+	if c.app == nil {
+		panic(fmt.Sprintf("service %s: %v", reflect.TypeFor[T](), ErrServiceNotProvided))
+	}
+	service, err := Resolve[T](c.app)
+	if err != nil {
+		panic(err)
+	}
+	return service
+}
+
+// Inject turns a function taking the Context and services into a handler,
+// resolving the services on every request
+// A service that cannot be resolved aborts the request with 500
+//
+//	app.GET("/users/:id", gonoleks.Inject(func(c *gonoleks.Context, users UserRepository, log *slog.Logger) {
+//	    ...
+//	}))
+func Inject(fn any) handlerFunc {
+	fnValue := reflect.ValueOf(fn)
+	fnType := fnValue.Type()
+	if fnType.Kind() != reflect.Func || fnType.NumOut() != 0 || fnType.IsVariadic() {
+		panic(fmt.Sprintf("inject: %T must be a function without results", fn))
+	}
+	return func(c *Context) {
+		args := make([]reflect.Value, fnType.NumIn())
+		for i := range args {
+			typ := fnType.In(i)
+			if typ == contextType {
+				args[i] = reflect.ValueOf(c)
+				continue
+			}
+			if c.app == nil {
+				_ = c.AbortWithError(StatusInternalServerError, fmt.Errorf("%w: %s", ErrServiceNotProvided, typ))
+				return
+			}
+			arg, err := c.app.resolve(typ)
+			if err != nil {
+				_ = c.AbortWithError(StatusInternalServerError, err)
+				return
+			}
+			args[i] = arg
+		}
+		fnValue.Call(args)
+	}
+}
@@ -0,0 +1,96 @@
+package gonoleks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type serviceConfig struct{ DSN string }
+
+type serviceDB struct{ dsn string }
+
+type serviceRepo interface{ Name() string }
+
+type serviceUsers struct{ db *serviceDB }
+
+func (u *serviceUsers) Name() string { return "users@" + u.db.dsn }
+
+func TestProvideAndResolve(t *testing.T) {
+	app := New()
+	constructed := 0
+	app.Provide(
+		&serviceConfig{DSN: "postgres://db"},
+		func(cfg *serviceConfig, g *Gonoleks) (*serviceDB, error) {
+			constructed++
+			assert.Same(t, app, g)
+			return &serviceDB{dsn: cfg.DSN}, nil
+		},
+		func(db *serviceDB) serviceRepo { return &serviceUsers{db: db} },
+	)
+
+	repo, err := Resolve[serviceRepo](app)
+	require.NoError(t, err)
+	assert.Equal(t, "users@postgres://db", repo.Name())
+	db, err := Resolve[*serviceDB](app)
+	require.NoError(t, err)
+	assert.Same(t, repo.(*serviceUsers).db, db)
+	assert.Equal(t, 1, constructed)
+
+	self, err := Resolve[*Gonoleks](app)
+	require.NoError(t, err)
+	assert.Same(t, app, self)
+	_, err = Resolve[string](app)
+	assert.ErrorIs(t, err, ErrServiceNotProvided)
+	_, err = Resolve[string](New())
+	assert.ErrorIs(t, err, ErrServiceNotProvided)
+
+	assert.Panics(t, func() { app.Provide(&serviceConfig{}) })
+	assert.Panics(t, func() { app.Provide(func() {}) })
+	assert.Panics(t, func() { app.Provide(func() (int, int) { return 0, 0 }) })
+	assert.Panics(t, func() { app.Provide(nil) })
+}
+
+func TestProvideErrors(t *testing.T) {
+	app := New()
+	boom := errors.New("boom")
+	app.Provide(
+		func() (*serviceDB, error) { return nil, boom },
+		func(db *serviceDB) serviceRepo { return &serviceUsers{db: db} },
+		func(b *serviceUsers) *serviceConfig { return nil },
+		func(c *serviceConfig) *serviceUsers { return nil },
+	)
+	_, err := Resolve[serviceRepo](app)
+	assert.ErrorIs(t, err, boom)
+	_, err = Resolve[*serviceDB](app)
+	assert.ErrorIs(t, err, boom)
+	_, err = Resolve[*serviceUsers](app)
+	assert.ErrorIs(t, err, ErrServiceCycle)
+	assert.Contains(t, err.Error(), "*gonoleks.serviceUsers -> *gonoleks.serviceConfig -> *gonoleks.serviceUsers")
+}
+
+func TestServiceAndInject(t *testing.T) {
+	app := New()
+	app.Provide(&serviceDB{dsn: "memory"}, func(db *serviceDB) serviceRepo { return &serviceUsers{db: db} })
+	app.GET("/service", func(c *Context) { c.String(StatusOK, "%s", Service[serviceRepo](c).Name()) })
+	app.GET("/inject", Inject(func(c *Context, repo serviceRepo, db *serviceDB) {
+		c.String(StatusOK, "%s %s", repo.Name(), db.dsn)
+	}))
+	app.GET("/missing", Inject(func(c *Context, cfg *serviceConfig) { c.String(StatusOK, "unreachable") }))
+	app.setupRouter()
+
+	status, body := resourceRequest(app, MethodGet, "/service", nil)
+	assert.Equal(t, StatusOK, status)
+	assert.Equal(t, "users@memory", body)
+	status, body = resourceRequest(app, MethodGet, "/inject", nil)
+	assert.Equal(t, StatusOK, status)
+	assert.Equal(t, "users@memory memory", body)
+	status, _ = resourceRequest(app, MethodGet, "/missing", nil)
+	assert.Equal(t, StatusInternalServerError, status)
+
+	c, _ := createTestContext()
+	assert.Panics(t, func() { Service[serviceRepo](c) })
+	assert.Panics(t, func() { Inject(42) })
+}
@@ -0,0 +1,54 @@
+package gonoleks
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestSignedURL(t *testing.T) {
+	app := New()
+	app.URLSigningKey = []byte("secret")
+	downloads := app.Group("/downloads", app.VerifySignedURL())
+	downloads.GET("/:file", func(c *Context) {
+		c.String(StatusOK, "%s for %s", c.Param("file"), c.Query("user"))
+	})
+	app.setupRouter()
+
+	request := func(link string) *fasthttp.RequestCtx {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(link)
+		reqCtx.Request.Header.SetMethod(MethodGet)
+		app.router.Handler(reqCtx)
+		return reqCtx
+	}
+
+	link, err := app.SignURL("/downloads/report.pdf", time.Minute, map[string]string{"user": "42"})
+	require.NoError(t, err)
+	reqCtx := request(link)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, "report.pdf for 42", string(reqCtx.Response.Body()))
+
+	// Tampering with a claim or the path invalidates the signature
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	query := u.Query()
+	query.Set("user", "43")
+	assert.Equal(t, StatusForbidden, request(u.Path+"?"+query.Encode()).Response.StatusCode())
+	assert.Equal(t, StatusForbidden, request("/downloads/other.pdf?"+u.RawQuery).Response.StatusCode())
+	assert.Equal(t, StatusForbidden, request("/downloads/report.pdf").Response.StatusCode())
+
+	// Expired links are rejected
+	expired, err := app.SignURL("/downloads/report.pdf", -time.Minute, nil)
+	require.NoError(t, err)
+	assert.Equal(t, StatusForbidden, request(expired).Response.StatusCode())
+	assert.ErrorIs(t, app.verifySignedURL(&Context{requestCtx: request(expired)}), ErrSignedURLExpired)
+
+	// Signing requires a key
+	_, err = New().SignURL("/downloads/report.pdf", time.Minute, nil)
+	assert.ErrorIs(t, err, ErrURLSigningKeyMissing)
+}
@@ -0,0 +1,178 @@
+package gonoleks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// SPAConfig defines the config for SPA
+type SPAConfig struct {
+	// FS is the built frontend bundle, e.g. a sub tree of an embed.FS
+	FS fs.FS
+
+	// Index is the page served for every path that is not a file of the bundle
+	Index string // Default = "index.html"
+
+	// Config is the runtime config injected into the index page, e.g. the API base URL,
+	// marshaled to JSON once at startup
+	Config any
+
+	// ConfigFunc returns the runtime config for a request instead of Config,
+	// e.g. to include the feature flags of the user, the index page is then rendered per request
+	ConfigFunc func(c *Context) any
+
+	// Global is the window property the config is assigned to
+	Global string // Default = "__APP_CONFIG__"
+
+	// Placeholder is replaced by the config script when the index page contains it,
+	// otherwise the script is inserted before </head>
+	Placeholder string // Default = "<!--app-config-->"
+
+	// Exclude lists path prefixes, relative to the mount path, answered with 404 Not Found
+	// instead of the index page when no file matches, e.g. "/api"
+	Exclude []string
+
+	// CacheControl sets the Cache-Control header of the bundle files unless zero,
+	// the index page is always revalidated
+	CacheControl CacheControlOptions
+}
+
+// spaHandler serves a single-page application bundle
+type spaHandler struct {
+	app    *Gonoleks
+	config SPAConfig
+	prefix string
+	index  []byte
+	page   []byte // Rendered index page when the config is static
+	etag   string
+}
+
+// SPA serves a single-page application from an embedded bundle under relativePath
+// Files of the bundle are served as they are, and any other path without a file extension
+// gets the index page, so client-side routes survive a reload
+// The index page carries the runtime config as a script assigning it to window.__APP_CONFIG__,
+// so one binary serves the same bundle in every environment
+// A <base href="/"> in the index page is rewritten to the mount path
+// It panics when the bundle has no index page
+//
+//	//go:embed all:web/dist
+//	var dist embed.FS
+//
+//	bundle, _ := fs.Sub(dist, "web/dist")
+//	app.SPA("/", gonoleks.SPAConfig{
+//	    FS:      bundle,
+//	    Config:  gonoleks.H{"apiBaseURL": os.Getenv("API_BASE_URL")},
+//	    Exclude: []string{"/api"},
+//	})
+func (rh *RouteHandler) SPA(relativePath string, config SPAConfig) {
+	if config.Index == "" {
+		config.Index = "index.html"
+	}
+	if config.Global == "" {
+		config.Global = "__APP_CONFIG__"
+	}
+	if config.Placeholder == "" {
+		config.Placeholder = "<!--app-config-->"
+	}
+	index, err := fs.ReadFile(config.FS, config.Index)
+	if err != nil {
+		panic(fmt.Sprintf("spa: reading index page: %v", err))
+	}
+	prefix := strings.TrimSuffix(rh.prefix+relativePath, "/")
+	if prefix != "" {
+		index = bytes.Replace(index, []byte(`<base href="/">`), []byte(`<base href="`+prefix+`/">`), 1)
+	}
+	h := &spaHandler{app: rh.app, config: config, prefix: prefix, index: index}
+	if config.ConfigFunc == nil {
+		page, err := h.render(config.Config)
+		if err != nil {
+			panic(fmt.Sprintf("spa: %v", err))
+		}
+		sum := sha256.Sum256(page)
+		h.page, h.etag = page, hex.EncodeToString(sum[:16])
+	}
+	rh.GET(relativePath, h.serve)
+	rh.GET(strings.TrimSuffix(relativePath, "/")+"/*", h.serve)
+}
+
+// serve answers a request with a bundle file or the index page
+func (h *spaHandler) serve(c *Context) {
+	requestPath := getString(c.requestCtx.Path())
+	if len(requestPath) >= len(h.prefix) && strings.EqualFold(requestPath[:len(h.prefix)], h.prefix) {
+		requestPath = requestPath[len(h.prefix):]
+	}
+	filePath, ok := sanitizePath(requestPath)
+	if !ok {
+		_ = c.AbortWithError(StatusBadRequest, ErrUnsafePath)
+		return
+	}
+	name := strings.TrimPrefix(filePath, "/")
+	if info, err := fs.Stat(h.config.FS, name); err == nil && !info.IsDir() && name != h.config.Index {
+		if h.config.CacheControl != (CacheControlOptions{}) {
+			c.CacheControl(h.config.CacheControl)
+		}
+		c.FileFromFS(filePath, h.config.FS)
+		return
+	}
+	if name != h.config.Index && (path.Ext(name) != "" || h.excluded(filePath)) {
+		// A missing asset or API endpoint must not get the index page
+		if len(h.app.router.noRoute) > 0 {
+			h.app.router.noRoute[0](c)
+			return
+		}
+		c.requestCtx.Error(fasthttp.StatusMessage(StatusNotFound), StatusNotFound)
+		c.Abort()
+		return
+	}
+	c.requestCtx.Response.Header.Set(HeaderCacheControl, "no-cache")
+	if h.page != nil {
+		if c.ServeConditional(time.Time{}, h.etag) {
+			return
+		}
+		c.Data(StatusOK, MIMETextHTMLCharsetUTF8, h.page)
+		return
+	}
+	page, err := h.render(h.config.ConfigFunc(c))
+	if err != nil {
+		_ = c.AbortWithError(StatusInternalServerError, err)
+		return
+	}
+	c.Data(StatusOK, MIMETextHTMLCharsetUTF8, page)
+}
+
+// excluded reports whether filePath falls under one of the Exclude prefixes
+func (h *spaHandler) excluded(filePath string) bool {
+	for _, prefix := range h.config.Exclude {
+		prefix = "/" + strings.Trim(prefix, "/")
+		if filePath == prefix || strings.HasPrefix(filePath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// render returns the index page with the config script injected
+// The JSON is marshaled with HTML escaping, so values cannot close the script element
+func (h *spaHandler) render(config any) ([]byte, error) {
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling runtime config: %w", err)
+	}
+	script := []byte("<script>window." + h.config.Global + " = " + string(raw) + ";</script>")
+	if i := bytes.Index(h.index, []byte(h.config.Placeholder)); i >= 0 {
+		return bytes.Join([][]byte{h.index[:i], script, h.index[i+len(h.config.Placeholder):]}, nil), nil
+	}
+	if i := bytes.Index(bytes.ToLower(h.index), []byte("</head>")); i >= 0 {
+		return bytes.Join([][]byte{h.index[:i], script, h.index[i:]}, nil), nil
+	}
+	return append(script, h.index...), nil
+}
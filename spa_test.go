@@ -0,0 +1,84 @@
+package gonoleks
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+var testSPABundle = fstest.MapFS{
+	"index.html":      {Data: []byte(`<html><head><base href="/"><title>App</title></head><body><div id="app"></div></body></html>`), ModTime: time.Now()},
+	"assets/app.js":   {Data: []byte("console.log('app')"), ModTime: time.Now()},
+	"placeholder.htm": {Data: []byte(`<head><!--app-config--></head>`), ModTime: time.Now()},
+}
+
+func spaRequest(app *Gonoleks, uri string, headers map[string]string) *fasthttp.RequestCtx {
+	reqCtx := newProxiedRequest(MethodGet, uri, "203.0.113.5", headers)
+	app.router.Handler(reqCtx)
+	return reqCtx
+}
+
+func TestSPA(t *testing.T) {
+	app := New()
+	app.GET("/api/users", func(c *Context) { c.String(StatusOK, "users") })
+	app.SPA("/", SPAConfig{
+		FS:           testSPABundle,
+		Config:       H{"apiBaseURL": "https://api.example.com", "banner": "</script><script>alert(1)"},
+		Exclude:      []string{"/api"},
+		CacheControl: CacheControlOptions{Public: true, MaxAge: time.Hour},
+	})
+	app.setupRouter()
+
+	for _, uri := range []string{"/", "/users/42/edit", "/index.html"} {
+		reqCtx := spaRequest(app, uri, nil)
+		require.Equal(t, StatusOK, reqCtx.Response.StatusCode(), uri)
+		body := string(reqCtx.Response.Body())
+		assert.Contains(t, body, `<script>window.__APP_CONFIG__ = {"apiBaseURL":"https://api.example.com",`, uri)
+		assert.Contains(t, body, `</script>`, uri)
+		assert.NotContains(t, body, "</script><script>", uri)
+		assert.Contains(t, body, `<base href="/">`, uri)
+		assert.Equal(t, "no-cache", string(reqCtx.Response.Header.Peek(HeaderCacheControl)), uri)
+	}
+
+	etag := string(spaRequest(app, "/", nil).Response.Header.Peek(HeaderETag))
+	require.NotEmpty(t, etag)
+	assert.Equal(t, StatusNotModified, spaRequest(app, "/dashboard", map[string]string{HeaderIfNoneMatch: etag}).Response.StatusCode())
+
+	reqCtx := spaRequest(app, "/assets/app.js", nil)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, "console.log('app')", string(reqCtx.Response.Body()))
+	assert.Equal(t, "public, max-age=3600", string(reqCtx.Response.Header.Peek(HeaderCacheControl)))
+
+	assert.Equal(t, "users", string(spaRequest(app, "/api/users", nil).Response.Body()))
+	assert.Equal(t, StatusNotFound, spaRequest(app, "/api/unknown", nil).Response.StatusCode())
+	assert.Equal(t, StatusNotFound, spaRequest(app, "/assets/missing.js", nil).Response.StatusCode())
+}
+
+func TestSPAConfigFunc(t *testing.T) {
+	app := New()
+	app.Group("/app").SPA("/", SPAConfig{
+		FS:     testSPABundle,
+		Global: "env",
+		ConfigFunc: func(c *Context) any {
+			return H{"beta": c.GetHeader(HeaderXTest) != ""}
+		},
+	})
+	app.SPA("/other", SPAConfig{FS: testSPABundle, Index: "placeholder.htm"})
+	app.setupRouter()
+
+	reqCtx := spaRequest(app, "/app/settings", map[string]string{HeaderXTest: "1"})
+	require.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	body := string(reqCtx.Response.Body())
+	assert.Contains(t, body, `<base href="/app/">`)
+	assert.Contains(t, body, `<script>window.env = {"beta":true};</script></head>`)
+	assert.Contains(t, string(spaRequest(app, "/app/", nil).Response.Body()), `{"beta":false}`)
+	assert.Equal(t, "console.log('app')", string(spaRequest(app, "/app/assets/app.js", nil).Response.Body()))
+
+	assert.Equal(t, `<head><script>window.__APP_CONFIG__ = null;</script></head>`, string(spaRequest(app, "/other/x", nil).Response.Body()))
+
+	assert.Panics(t, func() { New().SPA("/", SPAConfig{FS: fstest.MapFS{}}) })
+}
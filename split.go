@@ -0,0 +1,149 @@
+package gonoleks
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// variantKey is the user value key under which the assigned split variant is stored
+const variantKey = "gonoleksVariant"
+
+// SplitVariant is one arm of a traffic split
+type SplitVariant struct {
+	// Name identifies the variant in the sticky cookie and exposure logs
+	Name string
+
+	// Weight is the relative share of traffic, e.g. 0.9 and 0.1
+	Weight float64
+
+	// Handler serves the requests assigned to the variant
+	Handler handlerFunc
+}
+
+// SplitConfig defines the config for SplitWithConfig
+type SplitConfig struct {
+	// Name identifies the experiment
+	Name string // Default = "split"
+
+	// Variants lists the variants traffic is split between
+	Variants []SplitVariant
+
+	// CookieName is the cookie storing the assigned variant so clients stay sticky
+	CookieName string // Default = "gonoleks_split_" + Name
+
+	// CookieMaxAge is how long the assignment is remembered
+	CookieMaxAge time.Duration // Default = 30 days
+
+	// OnExposure is called whenever a request is served by a variant,
+	// with assigned reporting whether the variant was newly assigned
+	OnExposure func(c *Context, experiment, variant string, assigned bool)
+}
+
+// Split registers a GET route splitting traffic between weighted handlers,
+// given as alternating weights and handlers
+// Variants are named "A", "B", and so on, and clients stick to their variant through a cookie
+// Both variants share the route path and params
+// It panics if the arguments are not weight and handler pairs
+//
+//	app.Split("/checkout", 0.9, checkoutA, 0.1, checkoutB)
+func (rh *RouteHandler) Split(relativePath string, weightsAndHandlers ...any) *Route {
+	if len(weightsAndHandlers) == 0 || len(weightsAndHandlers)%2 != 0 {
+		panic("split: arguments must be weight and handler pairs")
+	}
+	variants := make([]SplitVariant, 0, len(weightsAndHandlers)/2)
+	for i := 0; i < len(weightsAndHandlers); i += 2 {
+		variant := SplitVariant{Name: string(rune('A' + i/2))}
+		switch weight := weightsAndHandlers[i].(type) {
+		case float64:
+			variant.Weight = weight
+		case int:
+			variant.Weight = float64(weight)
+		default:
+			panic(fmt.Sprintf("split: argument %d must be a weight, got %T", i, weightsAndHandlers[i]))
+		}
+		switch handler := weightsAndHandlers[i+1].(type) {
+		case handlerFunc:
+			variant.Handler = handler
+		case func(*Context):
+			variant.Handler = handler
+		default:
+			panic(fmt.Sprintf("split: argument %d must be a handler, got %T", i+1, weightsAndHandlers[i+1]))
+		}
+		variants = append(variants, variant)
+	}
+	name := strings.Trim(strings.ReplaceAll(rh.prefix+relativePath, "/", "_"), "_")
+	return rh.GET(relativePath, SplitWithConfig(SplitConfig{Name: name, Variants: variants}))
+}
+
+// SplitWithConfig instances a handler splitting traffic between weighted variants
+// It can be registered for any method, e.g. app.POST("/checkout", gonoleks.SplitWithConfig(conf))
+// It panics if there are no variants or the total weight is not positive
+func SplitWithConfig(conf SplitConfig) handlerFunc {
+	if conf.Name == "" {
+		conf.Name = "split"
+	}
+	if conf.CookieName == "" {
+		conf.CookieName = "gonoleks_split_" + conf.Name
+	}
+	if conf.CookieMaxAge == 0 {
+		conf.CookieMaxAge = 30 * 24 * time.Hour
+	}
+	total := 0.0
+	for _, variant := range conf.Variants {
+		if variant.Weight < 0 || variant.Handler == nil {
+			panic(fmt.Sprintf("split: variant %q needs a handler and a non-negative weight", variant.Name))
+		}
+		total += variant.Weight
+	}
+	if total <= 0 {
+		panic("split: total variant weight must be positive")
+	}
+	return func(c *Context) {
+		assigned := false
+		variant, ok := conf.variant(c)
+		if !ok {
+			variant = conf.pick(c.Rand(), total)
+			assigned = true
+			c.SetCookie(conf.CookieName, variant.Name, int(conf.CookieMaxAge/time.Second), "/", "", c.Secure(), true)
+		}
+		c.requestCtx.SetUserValue(variantKey, variant.Name)
+		if conf.OnExposure != nil {
+			conf.OnExposure(c, conf.Name, variant.Name, assigned)
+		}
+		variant.Handler(c)
+	}
+}
+
+// variant returns the variant stored in the sticky cookie, if it still exists with a positive weight
+func (conf *SplitConfig) variant(c *Context) (SplitVariant, bool) {
+	name, err := c.Cookie(conf.CookieName)
+	if err != nil {
+		return SplitVariant{}, false
+	}
+	for _, variant := range conf.Variants {
+		if variant.Name == name && variant.Weight > 0 {
+			return variant, true
+		}
+	}
+	return SplitVariant{}, false
+}
+
+// pick chooses a variant at random according to the weights, drawing from r
+func (conf *SplitConfig) pick(r *rand.Rand, total float64) SplitVariant {
+	n := r.Float64() * total
+	for _, variant := range conf.Variants {
+		if n < variant.Weight {
+			return variant
+		}
+		n -= variant.Weight
+	}
+	return conf.Variants[len(conf.Variants)-1]
+}
+
+// Variant returns the name of the split variant serving the current request, or "" outside a split
+func (c *Context) Variant() string {
+	variant, _ := c.requestCtx.UserValue(variantKey).(string)
+	return variant
+}
@@ -0,0 +1,83 @@
+package gonoleks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestSplit(t *testing.T) {
+	app := New()
+	exposures := map[string]int{}
+	app.GET("/orders/:id", SplitWithConfig(SplitConfig{
+		Name: "orders",
+		Variants: []SplitVariant{
+			{Name: "old", Weight: 0.5, Handler: func(c *Context) { c.String(StatusOK, "old %s", c.Param("id")) }},
+			{Name: "new", Weight: 0.5, Handler: func(c *Context) { c.String(StatusOK, "new %s", c.Param("id")) }},
+		},
+		OnExposure: func(c *Context, experiment, variant string, assigned bool) {
+			assert.Equal(t, "orders", experiment)
+			assert.Equal(t, variant, c.Variant())
+			if assigned {
+				exposures[variant]++
+			}
+		},
+	}))
+	app.setupRouter()
+
+	for range 200 {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI("/orders/7")
+		app.router.Handler(reqCtx)
+		body := string(reqCtx.Response.Body())
+		assert.Contains(t, []string{"old 7", "new 7"}, body)
+
+		// The variant sticks through the cookie
+		cookie := fasthttp.AcquireCookie()
+		cookie.SetKey("gonoleks_split_orders")
+		assert.True(t, reqCtx.Response.Header.Cookie(cookie))
+		sticky := &fasthttp.RequestCtx{}
+		sticky.Request.SetRequestURI("/orders/8")
+		sticky.Request.Header.SetCookie("gonoleks_split_orders", string(cookie.Value()))
+		app.router.Handler(sticky)
+		assert.Equal(t, body[:3]+" 8", string(sticky.Response.Body()))
+		assert.Empty(t, sticky.Response.Header.PeekCookie("gonoleks_split_orders"))
+		fasthttp.ReleaseCookie(cookie)
+	}
+	assert.Equal(t, 200, exposures["old"]+exposures["new"])
+	assert.Greater(t, exposures["old"], 50)
+	assert.Greater(t, exposures["new"], 50)
+}
+
+func TestSplitWeightsAndHandlers(t *testing.T) {
+	app := New()
+	app.Split("/checkout", 1, func(c *Context) { c.String(StatusOK, "A") }, 0.0, func(c *Context) { c.String(StatusOK, "B") })
+	app.setupRouter()
+
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/checkout")
+	reqCtx.Request.Header.SetCookie("gonoleks_split_checkout", "B")
+	app.router.Handler(reqCtx)
+	// A variant with zero weight is never served, even to sticky clients
+	assert.Equal(t, "A", string(reqCtx.Response.Body()))
+
+	// Seeded apps serve new clients the same variant on every run
+	seeded := New()
+	seeded.SetRandSeed(3)
+	seeded.Split("/checkout", 0.5, func(c *Context) { c.String(StatusOK, "A") }, 0.5, func(c *Context) { c.String(StatusOK, "B") })
+	seeded.setupRouter()
+	reqCtx = newProxiedRequest(MethodGet, "/checkout", "203.0.113.5", nil)
+	seeded.router.Handler(reqCtx)
+	variant := string(reqCtx.Response.Body())
+	for range 5 {
+		reqCtx = newProxiedRequest(MethodGet, "/checkout", "203.0.113.5", nil)
+		seeded.router.Handler(reqCtx)
+		assert.Equal(t, variant, string(reqCtx.Response.Body()))
+	}
+
+	assert.Panics(t, func() { app.Split("/x", 0.5) })
+	assert.Panics(t, func() { app.Split("/x", "half", func(c *Context) {}) })
+	assert.Panics(t, func() { app.Split("/x", 0.5, "handler") })
+	assert.Panics(t, func() { app.Split("/x", 0.0, func(c *Context) {}) })
+}
@@ -0,0 +1,224 @@
+package gonoleks
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/valyala/fasthttp/prefork"
+)
+
+// StartupFormat selects how the startup message is printed
+type StartupFormat int
+
+const (
+	// StartupText logs a single line through the diagnostics logger
+	StartupText StartupFormat = iota
+
+	// StartupBanner prints a plain multi-line banner without colors, suitable for log collectors
+	StartupBanner
+
+	// StartupJSON prints a single JSON startup event, for machine consumption
+	StartupJSON
+)
+
+// StartupConfig defines the config of the startup message
+type StartupConfig struct {
+	// Format selects how the startup message is printed
+	Format StartupFormat // Default = StartupText
+
+	// Output receives the StartupBanner and StartupJSON messages and is passed to Hook
+	Output io.Writer // Default = os.Stdout
+
+	// Routes includes the registered routes in the startup message
+	Routes bool
+
+	// Hook is called after the startup message, e.g. to print custom information
+	Hook func(w io.Writer, info StartupInfo)
+}
+
+// StartupInfo describes a starting server
+type StartupInfo struct {
+	// AppName is the ServerName of the app
+	AppName string `json:"app"`
+
+	// Version is the application version, see SetVersion
+	Version string `json:"version,omitempty"`
+
+	// Commit is the VCS revision the application was built from
+	Commit string `json:"commit,omitempty"`
+
+	// BuildDate is when the application was built
+	BuildDate string `json:"build_date,omitempty"`
+
+	// Address is the address the server listens on
+	Address string `json:"address"`
+
+	// PID is the process ID
+	PID int `json:"pid"`
+
+	// Worker is set in prefork child processes
+	Worker bool `json:"worker,omitempty"`
+
+	// Prefork is set when the server runs in prefork mode
+	Prefork bool `json:"prefork"`
+
+	// TLS is set when the server serves HTTPS
+	TLS bool `json:"tls"`
+
+	// RouteCount is the number of registered routes
+	RouteCount int `json:"route_count"`
+
+	// MiddlewareCount is the number of global middlewares
+	MiddlewareCount int `json:"middleware_count"`
+
+	// GoVersion is the Go version the binary was built with
+	GoVersion string `json:"go_version"`
+
+	// Time is when the server started
+	Time time.Time `json:"time"`
+
+	// Routes lists the registered routes when StartupConfig.Routes is set
+	Routes []StartupRoute `json:"routes,omitempty"`
+}
+
+// StartupRoute is a route listed in the startup message
+type StartupRoute struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// StartupMessage enables the startup message with the given config
+// Apps created with Default print it as a single log line
+//
+//	app.StartupMessage(gonoleks.StartupConfig{
+//		Format: gonoleks.StartupJSON,
+//		Hook: func(w io.Writer, info gonoleks.StartupInfo) {
+//			fmt.Fprintf(w, "docs: http://localhost%s/docs\n", info.Address)
+//		},
+//	})
+func (g *Gonoleks) StartupMessage(config ...StartupConfig) {
+	g.enableStartupMessage = true
+	g.startup = StartupConfig{}
+	if len(config) > 0 {
+		g.startup = config[0]
+	}
+}
+
+// DisableStartupMessage disables the startup message
+func (g *Gonoleks) DisableStartupMessage() {
+	g.enableStartupMessage = false
+}
+
+// startupInfo collects the information shown in the startup message
+func (g *Gonoleks) startupInfo(addr string, tls bool) StartupInfo {
+	version := g.Version()
+	info := StartupInfo{
+		AppName:         g.ServerName,
+		Version:         version.Version,
+		Commit:          version.Commit,
+		BuildDate:       version.BuildDate,
+		Address:         addr,
+		PID:             os.Getpid(),
+		Worker:          prefork.IsChild(),
+		Prefork:         g.Prefork,
+		TLS:             tls,
+		RouteCount:      len(g.routeIndex),
+		MiddlewareCount: len(g.router.globalMiddleware),
+		GoVersion:       version.GoVersion,
+		Time:            time.Now(),
+	}
+	if g.startup.Routes {
+		info.Routes = make([]StartupRoute, 0, len(g.routeIndex))
+		for _, route := range g.routeIndex {
+			info.Routes = append(info.Routes, StartupRoute{Method: route.Method, Path: route.Path})
+		}
+		slices.SortFunc(info.Routes, func(a, b StartupRoute) int {
+			return cmp.Or(strings.Compare(a.Path, b.Path), strings.Compare(a.Method, b.Method))
+		})
+	}
+	return info
+}
+
+// printStartupMessage displays server startup information in the configured format
+func (g *Gonoleks) printStartupMessage(addr string, tls bool) {
+	info := g.startupInfo(addr, tls)
+	out := g.startup.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	switch g.startup.Format {
+	case StartupBanner:
+		if !info.Worker {
+			_, _ = io.WriteString(out, formatStartupBanner(info))
+		}
+	case StartupJSON:
+		raw, err := sonic.Marshal(struct {
+			Event string `json:"event"`
+			StartupInfo
+		}{Event: "startup", StartupInfo: info})
+		if err != nil {
+			g.diagnostics.Error("Failed to encode startup event", "error", err)
+			break
+		}
+		_, _ = out.Write(append(raw, '\n'))
+	default:
+		if info.Worker {
+			g.diagnostics.Info("Worker process started", "pid", info.PID)
+			break
+		}
+		port := addr[strings.LastIndex(addr, ":"):]
+		g.diagnostics.Infof("%s started on %s", g.ServerName, port)
+		for _, route := range info.Routes {
+			g.diagnostics.Info("Route", "method", route.Method, "path", route.Path)
+		}
+	}
+	if g.startup.Hook != nil {
+		g.startup.Hook(out, info)
+	}
+}
+
+// formatStartupBanner formats the StartupBanner message
+func formatStartupBanner(info StartupInfo) string {
+	var b strings.Builder
+	title := info.AppName
+	if info.Version != "" {
+		title += " " + info.Version
+	}
+	scheme := "http"
+	if info.TLS {
+		scheme = "https"
+	}
+	rows := [][2]string{
+		{"Address", scheme + "://" + info.Address},
+		{"PID", strconv.Itoa(info.PID)},
+		{"Prefork", strconv.FormatBool(info.Prefork)},
+		{"Routes", strconv.Itoa(info.RouteCount)},
+		{"Middlewares", strconv.Itoa(info.MiddlewareCount)},
+		{"Go", info.GoVersion},
+	}
+	if info.Commit != "" {
+		rows = append(rows, [2]string{"Commit", info.Commit})
+	}
+	if info.BuildDate != "" {
+		rows = append(rows, [2]string{"Built", info.BuildDate})
+	}
+	b.WriteString(title + "\n")
+	b.WriteString(strings.Repeat("-", max(len(title), 32)) + "\n")
+	for _, row := range rows {
+		fmt.Fprintf(&b, "%-12s %s\n", row[0], row[1])
+	}
+	if len(info.Routes) > 0 {
+		b.WriteString("\n")
+		for _, route := range info.Routes {
+			fmt.Fprintf(&b, "%-7s %s\n", route.Method, route.Path)
+		}
+	}
+	return b.String()
+}
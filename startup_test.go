@@ -0,0 +1,76 @@
+package gonoleks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStartupTestApp() *Gonoleks {
+	app := New()
+	app.Use(func(c *Context) { c.Next() })
+	app.GET("/users/:id", func(c *Context) {})
+	app.POST("/users", func(c *Context) {})
+	app.setupRouter()
+	return app
+}
+
+func TestStartupMessageBanner(t *testing.T) {
+	var out bytes.Buffer
+	app := newStartupTestApp()
+	app.StartupMessage(StartupConfig{Format: StartupBanner, Output: &out, Routes: true})
+	assert.True(t, app.enableStartupMessage)
+	app.printStartupMessage("127.0.0.1:8080", true)
+
+	banner := out.String()
+	assert.Contains(t, banner, "Gonoleks\n")
+	assert.Contains(t, banner, "Address      https://127.0.0.1:8080\n")
+	assert.Contains(t, banner, fmt.Sprintf("PID          %d\n", os.Getpid()))
+	assert.Contains(t, banner, "Routes       2\n")
+	assert.Contains(t, banner, "Middlewares  1\n")
+	assert.Contains(t, banner, "POST    /users\nGET     /users/:id\n")
+	assert.NotContains(t, banner, "\x1b[")
+}
+
+func TestStartupMessageJSON(t *testing.T) {
+	var out bytes.Buffer
+	app := newStartupTestApp()
+	app.StartupMessage(StartupConfig{
+		Format: StartupJSON,
+		Output: &out,
+		Hook: func(w io.Writer, info StartupInfo) {
+			_, _ = fmt.Fprintf(w, "custom %s\n", info.Address)
+		},
+	})
+	app.printStartupMessage("0.0.0.0:9000", false)
+
+	line, rest, ok := bytes.Cut(out.Bytes(), []byte("\n"))
+	require.True(t, ok)
+	var event map[string]any
+	require.NoError(t, sonic.Unmarshal(line, &event))
+	assert.Equal(t, "startup", event["event"])
+	assert.Equal(t, "Gonoleks", event["app"])
+	assert.Equal(t, "0.0.0.0:9000", event["address"])
+	assert.EqualValues(t, os.Getpid(), event["pid"])
+	assert.EqualValues(t, 2, event["route_count"])
+	assert.EqualValues(t, 1, event["middleware_count"])
+	assert.NotContains(t, event, "routes")
+	assert.Equal(t, "custom 0.0.0.0:9000\n", string(rest))
+}
+
+func TestStartupMessageToggle(t *testing.T) {
+	assert.True(t, Default().enableStartupMessage)
+	app := New()
+	assert.False(t, app.enableStartupMessage)
+	app.StartupMessage()
+	assert.True(t, app.enableStartupMessage)
+	assert.Equal(t, StartupText, app.startup.Format)
+	app.DisableStartupMessage()
+	assert.False(t, app.enableStartupMessage)
+}
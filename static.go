@@ -0,0 +1,317 @@
+package gonoleks
+
+import (
+	"bytes"
+	"cmp"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// StaticConfig defines the config for StaticWithConfig
+type StaticConfig struct {
+	// Root is the local directory files are served from, used when FS is nil
+	Root string
+
+	// FS is the file system files are served from
+	FS fs.FS
+
+	// IndexNames lists the files served for a directory request
+	IndexNames []string // Default = ["index.html"]
+
+	// Browse renders a directory listing for directories without an index file
+	Browse bool
+
+	// BrowseTemplate renders directory listings with a DirectoryListing as data
+	BrowseTemplate *template.Template // Default = built-in listing page
+
+	// ShowHidden includes entries whose name starts with a dot in directory listings
+	ShowHidden bool
+
+	// Authorize is called with the sanitized file path, relative to the static root, for every request
+	// Requests are rejected with 403 Forbidden when it returns false, unless it has already
+	// written a response and aborted, e.g. with a 401 and a login challenge
+	Authorize func(c *Context, path string) bool
+
+	// Compress transparently compresses responses with gzip, and with Brotli and zstd when enabled
+	// below, for clients accepting them
+	// Compressed files are cached in memory, and next to the originals when Root is writable
+	Compress bool
+
+	// CompressBrotli prefers Brotli for clients accepting it, used with Compress
+	CompressBrotli bool
+
+	// CompressZstd prefers zstd for clients accepting it and not Brotli, used with Compress
+	CompressZstd bool
+
+	// CacheControl sets the Cache-Control header of served files unless zero,
+	// e.g. a long MaxAge with Immutable for fingerprinted assets
+	CacheControl CacheControlOptions
+
+	// CacheDuration is how long open file handles and compressed files stay cached while unused
+	CacheDuration time.Duration // Default = 10 seconds
+}
+
+// DirectoryListing is the data passed to the directory listing template
+type DirectoryListing struct {
+	// Path is the URL path of the listed directory
+	Path string
+
+	// Breadcrumbs links every parent directory from the static root down to Path
+	Breadcrumbs []DirectoryBreadcrumb
+
+	// Entries are the listed files and directories, sorted as requested
+	Entries []DirectoryEntry
+
+	// Sort is the active sort column: name, size or modtime
+	Sort string
+
+	// Order is the active sort order: asc or desc
+	Order string
+}
+
+// DirectoryBreadcrumb is a single link in the directory listing breadcrumbs
+type DirectoryBreadcrumb struct {
+	Name string
+	URL  string
+}
+
+// DirectoryEntry describes a file or directory in a directory listing
+type DirectoryEntry struct {
+	Name    string
+	URL     string
+	IsDir   bool
+	Size    int64
+	ModTime time.Time
+}
+
+// SortURL returns the query string that sorts the listing by column,
+// toggling the order when the listing is already sorted by it
+func (l DirectoryListing) SortURL(column string) string {
+	order := "asc"
+	if l.Sort == column && l.Order == "asc" {
+		order = "desc"
+	}
+	return "?sort=" + column + "&order=" + order
+}
+
+// StaticWithConfig serves static files under the given URL prefix with config
+//
+//	app.StaticWithConfig("/files", gonoleks.StaticConfig{Root: "./files", Browse: true})
+func (rh *RouteHandler) StaticWithConfig(relativePath string, conf StaticConfig) {
+	if len(conf.IndexNames) == 0 {
+		conf.IndexNames = []string{"index.html"}
+	}
+	fs := &fasthttp.FS{
+		Root:            conf.Root,
+		IndexNames:      conf.IndexNames,
+		AcceptByteRange: true,
+		Compress:        conf.Compress,
+		CompressBrotli:  conf.CompressBrotli,
+		CompressZstd:    conf.CompressZstd,
+		CacheDuration:   conf.CacheDuration,
+	}
+	if conf.FS != nil {
+		fs.FS = conf.FS
+		fs.Root = ""
+		fs.AllowEmptyRoot = true
+	}
+	rh.createStaticHandler(relativePath, fs, conf)
+}
+
+// defaultDirectoryTemplate is the built-in directory listing page
+var defaultDirectoryTemplate = template.Must(template.New("directory").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Index of {{.Path}}</title>
+<style>
+body{font-family:system-ui,sans-serif;margin:2em}
+table{border-collapse:collapse;min-width:40em}
+th,td{text-align:left;padding:.25em 1em .25em 0}
+td.size{text-align:right}
+</style>
+</head>
+<body>
+<h1>{{range $i, $b := .Breadcrumbs}}{{if $i}} / {{end}}<a href="{{$b.URL}}">{{$b.Name}}</a>{{end}}</h1>
+<table>
+<thead><tr>
+<th><a href="{{.SortURL "name"}}">Name</a></th>
+<th><a href="{{.SortURL "size"}}">Size</a></th>
+<th><a href="{{.SortURL "modtime"}}">Modified</a></th>
+</tr></thead>
+<tbody>
+{{range .Entries}}<tr>
+<td><a href="{{.URL}}">{{.Name}}{{if .IsDir}}/{{end}}</a></td>
+<td class="size">{{if not .IsDir}}{{.Size}}{{end}}</td>
+<td>{{.ModTime.UTC.Format "2006-01-02 15:04:05"}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
+
+// staticFileSystem returns the file system a fasthttp.FS serves files from
+func staticFileSystem(ffs *fasthttp.FS) fs.FS {
+	if ffs.FS != nil {
+		return ffs.FS
+	}
+	return os.DirFS(ffs.Root)
+}
+
+// statStaticFile returns information about a sanitized request path, or nil if it does not exist
+func statStaticFile(fsys fs.FS, requestPath string) fs.FileInfo {
+	name := strings.Trim(requestPath, "/")
+	if name == "" {
+		name = "."
+	}
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil
+	}
+	return info
+}
+
+// directoryLister renders listings for directories under a static route
+type directoryLister struct {
+	urlPrefix  string
+	fsys       fs.FS
+	indexNames []string
+	showHidden bool
+	tmpl       *template.Template
+}
+
+// newDirectoryLister creates a lister for the file system behind fs
+func newDirectoryLister(urlPrefix string, fs *fasthttp.FS, conf StaticConfig) *directoryLister {
+	fsys := staticFileSystem(fs)
+	tmpl := conf.BrowseTemplate
+	if tmpl == nil {
+		tmpl = defaultDirectoryTemplate
+	}
+	return &directoryLister{
+		urlPrefix:  urlPrefix,
+		fsys:       fsys,
+		indexNames: fs.IndexNames,
+		showHidden: conf.ShowHidden,
+		tmpl:       tmpl,
+	}
+}
+
+// serve renders a listing when requestPath is a directory without an index file
+// It returns false when the request should be handled by the file server
+func (l *directoryLister) serve(c *Context, requestPath string) bool {
+	name := strings.TrimPrefix(path.Clean("/"+requestPath), "/")
+	if name == "" {
+		name = "."
+	}
+	info, err := fs.Stat(l.fsys, name)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	for _, index := range l.indexNames {
+		if _, err := fs.Stat(l.fsys, path.Join(name, index)); err == nil {
+			return false
+		}
+	}
+	entries, err := fs.ReadDir(l.fsys, name)
+	if err != nil {
+		return false
+	}
+	dirURL := l.urlPrefix + "/"
+	if name != "." {
+		dirURL += escapePathSegments(name) + "/"
+	}
+	listing := DirectoryListing{
+		Path:        dirURL,
+		Breadcrumbs: l.breadcrumbs(name),
+		Entries:     make([]DirectoryEntry, 0, len(entries)),
+		Sort:        c.DefaultQuery("sort", "name"),
+		Order:       c.DefaultQuery("order", "asc"),
+	}
+	for _, entry := range entries {
+		if !l.showHidden && strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		// File names may contain characters such as '#' or '?' that end the path of a URL
+		entryURL := dirURL + url.PathEscape(entry.Name())
+		if entry.IsDir() {
+			entryURL += "/"
+		}
+		listing.Entries = append(listing.Entries, DirectoryEntry{
+			Name:    entry.Name(),
+			URL:     entryURL,
+			IsDir:   entry.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	sortDirectoryEntries(listing.Entries, listing.Sort, listing.Order == "desc")
+	var buf bytes.Buffer
+	if err := l.tmpl.Execute(&buf, listing); err != nil {
+		_ = c.AbortWithError(StatusInternalServerError, err)
+		return true
+	}
+	c.Data(StatusOK, MIMETextHTMLCharsetUTF8, buf.Bytes())
+	return true
+}
+
+// breadcrumbs builds links from the static root down to the directory name
+func (l *directoryLister) breadcrumbs(name string) []DirectoryBreadcrumb {
+	crumbs := []DirectoryBreadcrumb{{Name: l.urlPrefix + "/", URL: l.urlPrefix + "/"}}
+	if name == "." {
+		return crumbs
+	}
+	current := l.urlPrefix + "/"
+	for segment := range strings.SplitSeq(name, "/") {
+		current += url.PathEscape(segment) + "/"
+		crumbs = append(crumbs, DirectoryBreadcrumb{Name: segment, URL: current})
+	}
+	return crumbs
+}
+
+// escapePathSegments escapes every segment of a slash-separated path
+func escapePathSegments(name string) string {
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// sortDirectoryEntries sorts entries by the given column, listing directories first
+func sortDirectoryEntries(entries []DirectoryEntry, column string, desc bool) {
+	slices.SortStableFunc(entries, func(a, b DirectoryEntry) int {
+		if a.IsDir != b.IsDir {
+			if a.IsDir {
+				return -1
+			}
+			return 1
+		}
+		var result int
+		switch column {
+		case "size":
+			result = cmp.Compare(a.Size, b.Size)
+		case "modtime":
+			result = a.ModTime.Compare(b.ModTime)
+		}
+		if result == 0 {
+			result = strings.Compare(a.Name, b.Name)
+		}
+		if desc {
+			return -result
+		}
+		return result
+	})
+}
@@ -0,0 +1,218 @@
+package gonoleks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestStaticWithConfigBrowse(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs", "guides"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "small.txt"), []byte("a"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "large.txt"), []byte("abcdef"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".secret"), []byte("x"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "index.html"), []byte("<p>docs</p>"), 0o600))
+
+	app := New()
+	app.StaticWithConfig("/files", StaticConfig{Root: dir, Browse: true})
+	app.StaticWithConfig("/plain", StaticConfig{Root: dir})
+	app.setupRouter()
+
+	request := func(uri string) *fasthttp.RequestCtx {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		req.SetRequestURI(uri)
+		req.Header.SetMethod(MethodGet)
+		// Init attaches a logger, which fasthttp.FS needs for directories it cannot serve
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Init(req, nil, nil)
+		app.router.Handler(reqCtx)
+		return reqCtx
+	}
+
+	reqCtx := request("/files/")
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	body := string(reqCtx.Response.Body())
+	assert.Contains(t, body, `<a href="/files/docs/">docs/</a>`)
+	assert.Contains(t, body, `<a href="/files/small.txt">small.txt</a>`)
+	assert.NotContains(t, body, ".secret", "Hidden files should be filtered")
+	assert.Less(t, strings.Index(body, "docs/"), strings.Index(body, "large.txt"), "Directories should be listed first")
+	assert.Less(t, strings.Index(body, "large.txt"), strings.Index(body, "small.txt"))
+
+	// Sorting by size in descending order
+	body = string(request("/files/?sort=size&order=desc").Response.Body())
+	assert.Less(t, strings.Index(body, "large.txt"), strings.Index(body, "small.txt"))
+	assert.Contains(t, body, `href="?sort=size&amp;order=asc"`)
+
+	// Directories with an index file are served as usual
+	docs := request("/files/docs")
+	assert.Equal(t, StatusFound, docs.Response.StatusCode())
+	assert.Equal(t, "/files/docs/", string(docs.Response.Header.Peek(HeaderLocation)))
+	assert.Equal(t, "<p>docs</p>", string(request("/files/docs/").Response.Body()))
+
+	// Nested directories get breadcrumbs
+	body = string(request("/files/docs/guides").Response.Body())
+	assert.Contains(t, body, `<a href="/files/">/files/</a> / <a href="/files/docs/">docs</a> / <a href="/files/docs/guides/">guides</a>`)
+
+	// Names are escaped in links
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "my docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "my docs", "notes #1.txt"), []byte("n"), 0o600))
+	body = string(request("/files/my%20docs/").Response.Body())
+	assert.Contains(t, body, `<a href="/files/my%20docs/notes%20%231.txt">notes #1.txt</a>`)
+	assert.Contains(t, body, `<a href="/files/my%20docs/">my docs</a>`)
+	assert.Equal(t, "n", string(request("/files/my%20docs/notes%20%231.txt").Response.Body()))
+
+	// Files are still served and listing is opt-in
+	assert.Equal(t, "abcdef", string(request("/files/large.txt").Response.Body()))
+	assert.NotContains(t, string(request("/plain/").Response.Body()), "small.txt")
+}
+
+func TestStaticWithConfigFS(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":       {Data: []byte("a"), ModTime: time.Now()},
+		".hidden.txt": {Data: []byte("h"), ModTime: time.Now()},
+	}
+	app := New()
+	app.StaticWithConfig("/fs", StaticConfig{FS: testFS, Browse: true, ShowHidden: true})
+	app.setupRouter()
+
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/fs")
+	reqCtx.Request.Header.SetMethod(MethodGet)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Contains(t, string(reqCtx.Response.Body()), "a.txt")
+	assert.Contains(t, string(reqCtx.Response.Body()), ".hidden.txt")
+}
+
+func TestStaticPathTraversal(t *testing.T) {
+	root := t.TempDir()
+	public := filepath.Join(root, "public")
+	require.NoError(t, os.MkdirAll(public, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(public, "app.css"), []byte("body{}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), 0o600))
+
+	app := New()
+	app.Static("/static", public)
+	app.StaticFS("/fs", os.DirFS(public))
+	app.GET("/download/:name", func(c *Context) {
+		c.File(public + "/../" + c.Param("name"))
+	})
+	app.GET("/embedded/*path", func(c *Context) {
+		c.FileFromFS(c.Param("path"), os.DirFS(public))
+	})
+	app.setupRouter()
+
+	request := func(uri string) *fasthttp.RequestCtx {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(uri)
+		reqCtx.Request.Header.SetMethod(MethodGet)
+		app.router.Handler(reqCtx)
+		return reqCtx
+	}
+
+	assert.Equal(t, "body{}", string(request("/static/app.css").Response.Body()))
+	for _, uri := range []string{
+		"/static/%252e%252e/secret.txt",
+		"/static/..%252fsecret.txt",
+		"/static/%5c..%5csecret.txt",
+		"/fs/%252e%252e/secret.txt",
+		"/embedded/%252e%252e/secret.txt",
+		"/download/secret.txt",
+	} {
+		reqCtx := request(uri)
+		assert.Equal(t, StatusBadRequest, reqCtx.Response.StatusCode(), uri)
+		assert.NotContains(t, string(reqCtx.Response.Body()), "secret", uri)
+	}
+}
+
+func TestStaticWithConfigAuthorize(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "alice"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "alice", "report.txt"), []byte("alice report"), 0o600))
+
+	var authorizedPaths []string
+	app := New()
+	app.StaticWithConfig("/buckets", StaticConfig{
+		Root: dir,
+		Authorize: func(c *Context, path string) bool {
+			authorizedPaths = append(authorizedPaths, path)
+			user := c.GetHeader("X-User")
+			if user == "" {
+				c.AbortWithStatus(StatusUnauthorized)
+				return false
+			}
+			return strings.HasPrefix(path, "/"+user+"/")
+		},
+	})
+	app.setupRouter()
+
+	request := func(user string) *fasthttp.RequestCtx {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI("/buckets/alice//report.txt")
+		reqCtx.Request.Header.SetMethod(MethodGet)
+		if user != "" {
+			reqCtx.Request.Header.Set("X-User", user)
+		}
+		app.router.Handler(reqCtx)
+		return reqCtx
+	}
+
+	reqCtx := request("alice")
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, "alice report", string(reqCtx.Response.Body()))
+	assert.Equal(t, "/alice/report.txt", authorizedPaths[0], "Authorize should receive the sanitized path")
+
+	assert.Equal(t, StatusForbidden, request("bob").Response.StatusCode())
+	assert.Equal(t, StatusUnauthorized, request("").Response.StatusCode())
+}
+
+func TestStaticWithConfigCompression(t *testing.T) {
+	css := []byte(strings.Repeat("body { margin: 0; padding: 0; }\n", 200))
+	testFS := fstest.MapFS{"app.css": {Data: css, ModTime: time.Now()}}
+	app := New()
+	app.StaticWithConfig("/gzip", StaticConfig{FS: testFS, Compress: true})
+	app.StaticWithConfig("/assets", StaticConfig{
+		FS:           testFS,
+		Compress:     true,
+		CompressZstd: true,
+		CacheControl: CacheControlOptions{Public: true, MaxAge: 365 * 24 * time.Hour, Immutable: true},
+	})
+	app.StaticFS("/fs", testFS)
+	app.setupRouter()
+
+	request := func(uri, acceptEncoding string) *fasthttp.RequestCtx {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(uri)
+		reqCtx.Request.Header.SetMethod(MethodGet)
+		reqCtx.Request.Header.Set(HeaderAcceptEncoding, acceptEncoding)
+		app.router.Handler(reqCtx)
+		return reqCtx
+	}
+
+	for _, tc := range []struct{ uri, accept, encoding string }{
+		{"/gzip/app.css", "gzip, br, zstd", "gzip"},
+		{"/assets/app.css", "gzip, br, zstd", "zstd"},
+		{"/assets/app.css", "gzip", "gzip"},
+		{"/fs/app.css", "gzip, br, zstd", "br"},
+		{"/fs/app.css", "gzip, zstd", "zstd"},
+		{"/gzip/app.css", "", ""},
+	} {
+		reqCtx := request(tc.uri, tc.accept)
+		assert.Equal(t, StatusOK, reqCtx.Response.StatusCode(), tc.uri)
+		assert.Equal(t, tc.encoding, string(reqCtx.Response.Header.ContentEncoding()), tc.uri+" "+tc.accept)
+	}
+
+	reqCtx := request("/assets/app.css", "")
+	assert.Equal(t, "public, immutable, max-age=31536000", string(reqCtx.Response.Header.Peek(HeaderCacheControl)))
+	assert.Equal(t, css, reqCtx.Response.Body())
+	assert.Empty(t, request("/gzip/app.css", "").Response.Header.Peek(HeaderCacheControl))
+}
@@ -0,0 +1,81 @@
+package gonoleks
+
+import "time"
+
+// Store is a key-value store with per-key expiry backing stateful middleware such as
+// Nonce and the rate limit
+// Implementations shared between instances, e.g. RedisStore, make these work across a cluster
+type Store interface {
+	// Get returns the value of key and whether it was found
+	Get(key string) ([]byte, bool, error)
+
+	// Set stores value under key, a non-positive ttl never expires
+	Set(key string, value []byte, ttl time.Duration) error
+
+	// SetNX stores value under key unless it already exists, and reports whether it was stored
+	// It must be atomic, so only one of several concurrent callers succeeds
+	SetNX(key string, value []byte, ttl time.Duration) (bool, error)
+
+	// Delete removes key
+	Delete(key string) error
+
+	// Incr adds delta to the integer stored under key and returns the result
+	// A missing key starts from zero and expires after ttl
+	Incr(key string, delta int64, ttl time.Duration) (int64, error)
+}
+
+// Store returns the KV store as a Store
+func (kv *KV) Store() Store {
+	return kvStore{kv: kv}
+}
+
+// kvStore adapts a KV store to the Store interface
+type kvStore struct {
+	kv *KV
+}
+
+// Get implements Store
+func (s kvStore) Get(key string) ([]byte, bool, error) {
+	value, ok := s.kv.Get(key)
+	return value, ok, nil
+}
+
+// Set implements Store
+func (s kvStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.kv.Set(key, value, ttl)
+	return nil
+}
+
+// SetNX implements Store
+func (s kvStore) SetNX(key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.kv.SetNX(key, value, ttl), nil
+}
+
+// Delete implements Store
+func (s kvStore) Delete(key string) error {
+	s.kv.Delete(key)
+	return nil
+}
+
+// Incr implements Store
+func (s kvStore) Incr(key string, delta int64, ttl time.Duration) (int64, error) {
+	return s.kv.Incr(key, delta, ttl)
+}
+
+// StoreNonceStore returns a NonceStore keeping used nonces in store
+//
+//	redis := gonoleks.NewRedisStore(gonoleks.RedisStoreConfig{Addr: "redis:6379"})
+//	app.Use(gonoleks.NonceWithConfig(gonoleks.NonceConfig{Store: gonoleks.StoreNonceStore(redis)}))
+func StoreNonceStore(store Store) NonceStore {
+	return storeNonceStore{store: store}
+}
+
+// storeNonceStore is a NonceStore backed by a Store
+type storeNonceStore struct {
+	store Store
+}
+
+// Use implements NonceStore
+func (s storeNonceStore) Use(nonce string, ttl time.Duration) (bool, error) {
+	return s.store.SetNX("nonce:"+nonce, []byte{'1'}, ttl)
+}
@@ -0,0 +1,91 @@
+package gonoleks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVStore(t *testing.T) {
+	kv, err := NewKV()
+	require.NoError(t, err)
+	defer kv.Close()
+	var store Store = kv.Store()
+
+	require.NoError(t, store.Set("a", []byte("1"), 0))
+	value, ok, err := store.Get("a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1", string(value))
+	n, err := store.Incr("a", 4, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, n)
+	stored, err := store.SetNX("a", nil, 0)
+	require.NoError(t, err)
+	assert.False(t, stored)
+	require.NoError(t, store.Delete("a"))
+	_, ok, err = store.Get("a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestStoreNonceStore(t *testing.T) {
+	_, addr := startFakeRedis(t)
+	redis := NewRedisStore(RedisStoreConfig{Addr: addr})
+	defer redis.Close()
+	nonces := StoreNonceStore(redis)
+	ok, err := nonces.Use("abc", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = nonces.Use("abc", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRateLimitStore(t *testing.T) {
+	_, addr := startFakeRedis(t)
+	redis := NewRedisStore(RedisStoreConfig{Addr: addr})
+	defer redis.Close()
+
+	// Two instances sharing the store share the limit
+	newApp := func() *Gonoleks {
+		app := New()
+		app.RateLimit = 0.5
+		app.RateLimitBurst = 2
+		app.RateLimitStore = redis
+		app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+		app.setupRouter()
+		return app
+	}
+	first, second := newApp(), newApp()
+	statuses := make([]int, 0, 3)
+	for _, app := range []*Gonoleks{first, second, first} {
+		reqCtx := newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+		app.router.Handler(reqCtx)
+		statuses = append(statuses, reqCtx.Response.StatusCode())
+	}
+	// The window may roll over between requests, so only the shared count is checked
+	assert.Contains(t, statuses, StatusOK)
+	if statuses[0] == StatusOK && statuses[1] == StatusOK {
+		assert.Equal(t, StatusTooManyRequests, statuses[2])
+	}
+
+	wait, err := takeStoreRateLimit(redis, "198.51.100.1", 1, 1)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+}
+
+func TestRateLimitStoreFailsOpen(t *testing.T) {
+	app := New()
+	app.RateLimit = 1
+	app.RateLimitStore = NewRedisStore(RedisStoreConfig{Addr: "127.0.0.1:1", DialTimeout: 50 * time.Millisecond})
+	app.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	app.setupRouter()
+	for range 3 {
+		reqCtx := newProxiedRequest(MethodGet, "/", "203.0.113.5", nil)
+		app.router.Handler(reqCtx)
+		assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	}
+}
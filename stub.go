@@ -0,0 +1,223 @@
+package gonoleks
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"gopkg.in/yaml.v3"
+)
+
+// Stub is a canned response served by a stub route, e.g. to stand in for a dependency
+// in integration test environments
+type Stub struct {
+	// Method is the HTTP method of the route
+	Method string `yaml:"method"` // Default = "GET"
+
+	// Path is the route path, with params, e.g. "/users/:id"
+	Path string `yaml:"path"`
+
+	// Match restricts the stub to some requests, so stubs of the same route answer different requests
+	Match StubMatch `yaml:"match"`
+
+	// Status is the response status code
+	Status int `yaml:"status"` // Default = 200
+
+	// Headers are set on the response
+	Headers map[string]string `yaml:"headers"`
+
+	// Body is a text/template rendered with StubRequest, e.g. `{"id": "{{.Params.id}}"}`
+	Body string `yaml:"body"`
+
+	// JSON is sent as a JSON body when Body is empty
+	JSON any `yaml:"json"`
+
+	// Latency delays the response, e.g. "250ms" in fixtures
+	Latency time.Duration `yaml:"latency"`
+
+	body *template.Template
+}
+
+// StubMatch holds the conditions a request must meet to be answered by a stub
+type StubMatch struct {
+	// Query holds the query params the request must have, with their value
+	Query map[string]string `yaml:"query"`
+
+	// Headers holds the headers the request must have, with their value
+	Headers map[string]string `yaml:"headers"`
+}
+
+// StubRequest is the data the body template of a stub is rendered with
+type StubRequest struct {
+	Method  string
+	Path    string
+	Params  map[string]string
+	Query   map[string]string
+	Headers map[string]string
+	Body    string
+}
+
+// LoadStubs parses a list of stubs in YAML or JSON format, as served by Stubs
+//
+//	# users.stubs.yaml
+//	- path: /users/:id
+//	  latency: 50ms
+//	  json: {id: 1, name: Ada}
+//	- path: /users/:id
+//	  match: {headers: {Authorization: ""}}
+//	  status: 401
+//	- method: POST
+//	  path: /users
+//	  status: 201
+//	  headers: {Content-Type: application/json}
+//	  body: '{"created": {{.Body}}}'
+func LoadStubs(data []byte) ([]Stub, error) {
+	var stubs []Stub
+	if err := yaml.Unmarshal(data, &stubs); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidStub, err)
+	}
+	for i := range stubs {
+		if err := stubs[i].compile(); err != nil {
+			return nil, err
+		}
+	}
+	return stubs, nil
+}
+
+// compile applies the defaults of the stub and parses its body template
+func (s *Stub) compile() error {
+	if s.Method == "" {
+		s.Method = MethodGet
+	}
+	s.Method = strings.ToUpper(s.Method)
+	if s.Status == 0 {
+		s.Status = StatusOK
+	}
+	if !strings.HasPrefix(s.Path, "/") {
+		return fmt.Errorf("%w: %s %q: path must start with /", ErrInvalidStub, s.Method, s.Path)
+	}
+	if s.Body == "" || s.body != nil {
+		return nil
+	}
+	tmpl, err := template.New(s.Method + " " + s.Path).Option("missingkey=zero").Parse(s.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidStub, err)
+	}
+	s.body = tmpl
+	return nil
+}
+
+// matches reports whether the request meets the conditions of the stub
+func (s *Stub) matches(c *Context) bool {
+	for name, value := range s.Match.Query {
+		if c.Query(name) != value {
+			return false
+		}
+	}
+	for name, value := range s.Match.Headers {
+		if c.GetHeader(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Stubs registers routes answering with canned responses, e.g. loaded with LoadStubs,
+// so the app can serve as a stub server in integration test environments
+// Stubs of the same method and path share a route, which answers with the first stub
+// whose Match conditions the request meets, or with 404 Not Found
+// It panics on an invalid stub
+//
+//	data, _ := os.ReadFile("testdata/users.stubs.yaml")
+//	stubs, err := gonoleks.LoadStubs(data)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	app.Stubs(stubs...)
+func (rh *RouteHandler) Stubs(stubs ...Stub) []*Route {
+	var routes []*Route
+	byRoute := map[string][]*Stub{}
+	for i := range stubs {
+		s := stubs[i]
+		if err := s.compile(); err != nil {
+			panic(err.Error())
+		}
+		key := s.Method + " " + s.Path
+		if _, ok := byRoute[key]; !ok {
+			routes = append(routes, rh.Handle(s.Method, s.Path, func(c *Context) { serveStubs(c, byRoute[key]) }))
+		}
+		byRoute[key] = append(byRoute[key], &s)
+	}
+	return routes
+}
+
+// serveStubs answers with the first stub matching the request
+func serveStubs(c *Context, stubs []*Stub) {
+	for _, s := range stubs {
+		if s.matches(c) {
+			s.serve(c)
+			return
+		}
+	}
+	c.AbortWithStatus(StatusNotFound)
+}
+
+// serve answers with the canned response of the stub
+func (s *Stub) serve(c *Context) {
+	if s.Latency > 0 {
+		timer := time.NewTimer(s.Latency)
+		select {
+		case <-timer.C:
+		case <-serverDone(c.requestCtx):
+			timer.Stop()
+		}
+	}
+	var body []byte
+	contentType := MIMETextPlainCharsetUTF8
+	switch {
+	case s.body != nil:
+		var buf bytes.Buffer
+		if err := s.body.Execute(&buf, newStubRequest(c)); err != nil {
+			_ = c.AbortWithError(StatusInternalServerError, fmt.Errorf("%w: %w", ErrInvalidStub, err))
+			return
+		}
+		body = buf.Bytes()
+	case s.JSON != nil:
+		raw, err := sonic.Marshal(s.JSON)
+		if err != nil {
+			_ = c.AbortWithError(StatusInternalServerError, fmt.Errorf("%w: %w", ErrInvalidStub, err))
+			return
+		}
+		body, contentType = raw, MIMEApplicationJSONCharsetUTF8
+	}
+	c.requestCtx.Response.Header.SetContentType(contentType)
+	for name, value := range s.Headers {
+		c.requestCtx.Response.Header.Set(name, value)
+	}
+	c.requestCtx.SetStatusCode(s.Status)
+	c.requestCtx.SetBody(body)
+}
+
+// newStubRequest returns the template data of the request
+func newStubRequest(c *Context) StubRequest {
+	r := StubRequest{
+		Method:  string(c.requestCtx.Method()),
+		Path:    string(c.requestCtx.Path()),
+		Params:  make(map[string]string, len(c.paramValues)),
+		Query:   map[string]string{},
+		Headers: map[string]string{},
+		Body:    string(c.requestCtx.Request.Body()),
+	}
+	maps.Copy(r.Params, c.paramValues)
+	for name, value := range c.requestCtx.QueryArgs().All() {
+		r.Query[string(name)] = string(value)
+	}
+	for name, value := range c.requestCtx.Request.Header.All() {
+		r.Headers[string(name)] = string(value)
+	}
+	return r
+}
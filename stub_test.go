@@ -0,0 +1,95 @@
+package gonoleks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStubs = `
+- path: /users/:id
+  match: {headers: {Authorization: ""}}
+  status: 401
+- path: /users/:id
+  latency: 20ms
+  headers: {X-Stub: users}
+  json: {id: 1, name: Ada}
+- method: post
+  path: /users
+  status: 201
+  headers: {Content-Type: application/json}
+  body: '{"created": {{.Body}}, "role": "{{.Query.role}}", "agent": "{{index .Headers "User-Agent"}}"}'
+- path: /users/:id/avatar
+  body: 'avatar of {{.Params.id}}'
+`
+
+func TestStubs(t *testing.T) {
+	stubs, err := LoadStubs([]byte(testStubs))
+	require.NoError(t, err)
+	require.Len(t, stubs, 4)
+	assert.Equal(t, MethodGet, stubs[0].Method)
+	assert.Equal(t, MethodPost, stubs[2].Method)
+	assert.Equal(t, 20*time.Millisecond, stubs[1].Latency)
+
+	app := New()
+	routes := app.Stubs(stubs...)
+	assert.Len(t, routes, 3)
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/users/1", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusUnauthorized, reqCtx.Response.StatusCode())
+
+	start := time.Now()
+	reqCtx = newProxiedRequest(MethodGet, "/users/1", "203.0.113.5", map[string]string{HeaderAuthorization: "Bearer x"})
+	app.router.Handler(reqCtx)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, MIMEApplicationJSONCharsetUTF8, string(reqCtx.Response.Header.ContentType()))
+	assert.Equal(t, "users", string(reqCtx.Response.Header.Peek("X-Stub")))
+	assert.JSONEq(t, `{"id":1,"name":"Ada"}`, string(reqCtx.Response.Body()))
+
+	reqCtx = newProxiedRequest(MethodPost, "/users?role=admin", "203.0.113.5", map[string]string{HeaderUserAgent: "test"})
+	reqCtx.Request.SetBodyString(`{"name":"Bob"}`)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusCreated, reqCtx.Response.StatusCode())
+	assert.Equal(t, MIMEApplicationJSON, string(reqCtx.Response.Header.ContentType()))
+	assert.JSONEq(t, `{"created":{"name":"Bob"},"role":"admin","agent":"test"}`, string(reqCtx.Response.Body()))
+
+	reqCtx = newProxiedRequest(MethodGet, "/users/7/avatar", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, MIMETextPlainCharsetUTF8, string(reqCtx.Response.Header.ContentType()))
+	assert.Equal(t, "avatar of 7", string(reqCtx.Response.Body()))
+}
+
+func TestStubsUnmatched(t *testing.T) {
+	app := New()
+	app.Stubs(Stub{Path: "/search", Match: StubMatch{Query: map[string]string{"q": "go"}}, Body: "found"})
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/search?q=go", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "found", string(reqCtx.Response.Body()))
+	reqCtx = newProxiedRequest(MethodGet, "/search?q=rust", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusNotFound, reqCtx.Response.StatusCode())
+}
+
+func TestLoadStubsInvalid(t *testing.T) {
+	_, err := LoadStubs([]byte(`{path: /a}`))
+	assert.ErrorIs(t, err, ErrInvalidStub)
+	_, err = LoadStubs([]byte(`[{path: a}]`))
+	assert.ErrorIs(t, err, ErrInvalidStub)
+	_, err = LoadStubs([]byte(`[{path: /a, body: "{{.Params"}]`))
+	assert.ErrorIs(t, err, ErrInvalidStub)
+
+	// JSON fixtures are valid YAML
+	stubs, err := LoadStubs([]byte(`[{"path": "/a", "status": 204, "latency": "1s"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, StatusNoContent, stubs[0].Status)
+	assert.Equal(t, time.Second, stubs[0].Latency)
+
+	assert.Panics(t, func() { New().Stubs(Stub{Path: "a"}) })
+}
@@ -0,0 +1,435 @@
+package gonoleks
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TemplateEngine renders the named HTML templates of Context.HTML
+type TemplateEngine interface {
+	// Render writes the template name executed with data to w
+	Render(w io.Writer, name string, data any) error
+}
+
+// TemplateEngineFunc adapts a function to a TemplateEngine
+type TemplateEngineFunc func(w io.Writer, name string, data any) error
+
+// Render implements TemplateEngine
+func (f TemplateEngineFunc) Render(w io.Writer, name string, data any) error {
+	return f(w, name, data)
+}
+
+// MultiEngine is a TemplateEngine choosing the engine of each template by its extension, so
+// template code bases mixing several languages can be served during a migration
+//
+//	engine := gonoleks.NewMultiEngine().
+//		Register(".html", htmlEngine).
+//		Register(".tmpl", legacyEngine).
+//		Register(".md", markdownEngine)
+//	app.SetTemplateEngine(engine)
+type MultiEngine struct {
+	engines  map[string]TemplateEngine
+	fallback TemplateEngine
+}
+
+// NewMultiEngine creates an empty MultiEngine
+func NewMultiEngine() *MultiEngine {
+	return &MultiEngine{engines: make(map[string]TemplateEngine)}
+}
+
+// Register renders the templates whose name ends with ext, e.g. ".md", with engine
+// The first registered engine also renders names without a registered extension, such as the
+// blocks rendered by HTMLFragment, unless Fallback sets another one
+// It must be called before the server starts
+func (m *MultiEngine) Register(ext string, engine TemplateEngine) *MultiEngine {
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	m.engines[strings.ToLower(ext)] = engine
+	if m.fallback == nil {
+		m.fallback = engine
+	}
+	return m
+}
+
+// Fallback sets the engine rendering names without a registered extension
+func (m *MultiEngine) Fallback(engine TemplateEngine) *MultiEngine {
+	m.fallback = engine
+	return m
+}
+
+// Render implements TemplateEngine
+func (m *MultiEngine) Render(w io.Writer, name string, data any) error {
+	engine, ok := m.engines[strings.ToLower(path.Ext(name))]
+	if !ok {
+		engine = m.fallback
+	}
+	if engine == nil {
+		return fmt.Errorf("%w: %s", ErrTemplateEngineMissing, name)
+	}
+	return engine.Render(w, name, data)
+}
+
+// HTMLEngineConfig defines the config for NewHTMLEngine
+type HTMLEngineConfig struct {
+	// FS holds the templates, e.g. an embed.FS
+	FS fs.FS // Default = os.DirFS(Dir)
+
+	// Dir is the directory holding the templates, used when FS is nil
+	Dir string // Default = "views"
+
+	// Extension selects the template files
+	Extension string // Default = ".html"
+
+	// Funcs are added to the built-in template functions, replacing those of the same name
+	Funcs template.FuncMap
+
+	// Reload parses the templates again before every render, to pick up changes during development
+	Reload bool
+
+	// Delims replaces the action delimiters, e.g. with "[[" and "]]" when pages also hold
+	// client-side templates such as Alpine.js or Vue expressions using "{{"
+	// htmx attributes need no change, as JSON in hx-vals or hx-headers never opens with "{{",
+	// but a literal "{{" in an attribute must be written as {{"{{"}} with the default delimiters
+	Delims [2]string // Default = {"{{", "}}"}
+
+	// TrimActionLines strips the indentation and line break of lines holding only control actions,
+	// such as {{if}}, {{range}}, {{end}} or {{define}}, so the rendered HTML doesn't accumulate
+	// blank lines
+	// Lines printing a value are kept, {{- and -}} trim whitespace around single actions
+	TrimActionLines bool
+}
+
+// HTMLEngine is a TemplateEngine based on html/template
+// Templates are named by their path relative to the root, e.g. "users/show.html", and share a
+// single namespace, so pages can use layouts and partials defined in other files
+// Besides Funcs, templates can call sanitize, see SanitizeHTML, and url_for, see URLFor
+type HTMLEngine struct {
+	config HTMLEngineConfig
+	app    *Gonoleks
+	mu     sync.RWMutex
+	tmpl   *template.Template
+}
+
+// NewHTMLEngine creates an HTMLEngine and parses its templates
+//
+//	//go:embed views
+//	var views embed.FS
+//
+//	engine, err := gonoleks.NewHTMLEngine(gonoleks.HTMLEngineConfig{FS: views})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	app.SetTemplateEngine(engine)
+func NewHTMLEngine(config ...HTMLEngineConfig) (*HTMLEngine, error) {
+	var conf HTMLEngineConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if conf.FS == nil {
+		if conf.Dir == "" {
+			conf.Dir = "views"
+		}
+		conf.FS = os.DirFS(conf.Dir)
+	}
+	if conf.Extension == "" {
+		conf.Extension = ".html"
+	}
+	if conf.Delims[0] == "" || conf.Delims[1] == "" {
+		conf.Delims = [2]string{"{{", "}}"}
+	}
+	engine := &HTMLEngine{config: conf}
+	if err := engine.Load(); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// Load parses the templates again
+func (e *HTMLEngine) Load() error {
+	funcs := template.FuncMap{"sanitize": SanitizeHTML, "url_for": e.urlFor}
+	for name, fn := range e.config.Funcs {
+		funcs[name] = fn
+	}
+	root := template.New("").Delims(e.config.Delims[0], e.config.Delims[1]).Funcs(funcs)
+	err := fs.WalkDir(e.config.FS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, e.config.Extension) {
+			return err
+		}
+		raw, err := fs.ReadFile(e.config.FS, path)
+		if err != nil {
+			return err
+		}
+		src := string(raw)
+		if e.config.TrimActionLines {
+			src = trimActionLines(src, e.config.Delims[0], e.config.Delims[1])
+		}
+		_, err = root.New(path).Parse(src)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTemplateParse, err)
+	}
+	e.mu.Lock()
+	e.tmpl = root
+	e.mu.Unlock()
+	return nil
+}
+
+// Render implements TemplateEngine
+func (e *HTMLEngine) Render(w io.Writer, name string, data any) error {
+	if e.config.Reload {
+		if err := e.Load(); err != nil {
+			return err
+		}
+	}
+	e.mu.RLock()
+	tmpl := e.tmpl
+	e.mu.RUnlock()
+	return tmpl.ExecuteTemplate(w, name, data)
+}
+
+// templateControlKeywords open the control actions removed with their line by TrimActionLines
+var templateControlKeywords = []string{"if", "else", "end", "range", "with", "define", "block", "break", "continue"}
+
+// trimActionLines strips the indentation and line break of the lines of src holding only
+// control actions, see HTMLEngineConfig.TrimActionLines
+func trimActionLines(src, left, right string) string {
+	var b strings.Builder
+	b.Grow(len(src))
+	for line := range strings.SplitAfterSeq(src, "\n") {
+		if trimmed := strings.TrimSpace(line); isControlActionLine(trimmed, left, right) {
+			// Keep the actions, drop the whitespace around them
+			line = trimmed
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// isControlActionLine reports whether line consists of control actions separated by whitespace
+func isControlActionLine(line, left, right string) bool {
+	if line == "" {
+		return false
+	}
+	for line != "" {
+		if !strings.HasPrefix(line, left) {
+			return false
+		}
+		end := strings.Index(line, right)
+		if end < 0 {
+			return false
+		}
+		action := strings.TrimSpace(strings.Trim(line[len(left):end], "-"))
+		if !isControlAction(action) {
+			return false
+		}
+		line = strings.TrimSpace(line[end+len(right):])
+	}
+	return true
+}
+
+// isControlAction reports whether the text of an action is a comment, a variable declaration
+// or assignment, or starts with a control keyword
+func isControlAction(action string) bool {
+	if strings.HasPrefix(action, "/*") {
+		return true
+	}
+	if strings.HasPrefix(action, "$") {
+		return strings.Contains(action, ":=") || strings.Contains(action, " = ")
+	}
+	keyword, _, _ := strings.Cut(action, " ")
+	return slices.Contains(templateControlKeywords, keyword)
+}
+
+// urlFor implements the url_for template function with the app the engine is set on
+func (e *HTMLEngine) urlFor(name string, params ...any) (string, error) {
+	if e.app == nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownRoute, name)
+	}
+	return e.app.URLFor(name, params...)
+}
+
+// SetTemplateEngine sets the engine rendering the templates of Context.HTML
+func (g *Gonoleks) SetTemplateEngine(engine TemplateEngine) {
+	g.templateEngine = engine
+	g.attachTemplateEngine(engine)
+}
+
+// attachTemplateEngine binds the HTMLEngines among engine to the app, for url_for
+func (g *Gonoleks) attachTemplateEngine(engine TemplateEngine) {
+	switch e := engine.(type) {
+	case *HTMLEngine:
+		e.app = g
+	case *MultiEngine:
+		for _, inner := range e.engines {
+			g.attachTemplateEngine(inner)
+		}
+		g.attachTemplateEngine(e.fallback)
+	}
+}
+
+// Context keys read into the template globals, for middlewares providing these values
+const (
+	// CSRFTokenKey is the Context.Set key of the CSRF token of the request, exposed as CSRFToken
+	CSRFTokenKey = "csrf_token"
+
+	// FlashesKey is the Context.Set key of the flash messages of the request, exposed as Flashes
+	FlashesKey = "flashes"
+)
+
+// viewDataKey is the user value key of the view data of the request
+const viewDataKey = "gonoleksViewData"
+
+// ViewData sets a value merged into the data of every HTML render of the request, so
+// middlewares can provide layout data such as navigation items once for all templates
+// Values passed to the render take precedence, see Context.HTML
+//
+//	app.Use(func(c *gonoleks.Context) {
+//		c.ViewData("Nav", navItems)
+//		c.Next()
+//	})
+func (c *Context) ViewData(key string, value any) *Context {
+	data, _ := c.requestCtx.UserValue(viewDataKey).(H)
+	if data == nil {
+		data = make(H)
+		c.requestCtx.SetUserValue(viewDataKey, data)
+	}
+	data[key] = value
+	return c
+}
+
+// templateData merges the request globals and view data into map data, e.g. H, with the values
+// of data taking precedence, and returns data of other types unchanged
+func (c *Context) templateData(data any) any {
+	var values map[string]any
+	switch d := data.(type) {
+	case nil:
+	case H:
+		values = d
+	case map[string]any:
+		values = d
+	default:
+		return data
+	}
+	merged := make(H, len(values)+6)
+	merged["Path"] = string(c.requestCtx.Path())
+	merged["Query"] = c.QueryValues()
+	merged["Locale"] = c.Locale()
+	merged["User"] = c.Principal()
+	if token, ok := c.Get(CSRFTokenKey); ok {
+		merged["CSRFToken"] = token
+	}
+	if flashes, ok := c.Get(FlashesKey); ok {
+		merged["Flashes"] = flashes
+	}
+	if view, ok := c.requestCtx.UserValue(viewDataKey).(H); ok {
+		maps.Copy(merged, view)
+	}
+	maps.Copy(merged, values)
+	return merged
+}
+
+// HTML renders the template name with data as a text/html response
+// The template is rendered into a buffer first, so a failing template sends nothing
+// Map data, e.g. H, is completed with the values set with ViewData and with request globals,
+// unless it sets them itself: Path, Query, Locale, User (the Principal), CSRFToken and Flashes,
+// see CSRFTokenKey and FlashesKey
+//
+//	return c.HTML(gonoleks.StatusOK, "users/show.html", gonoleks.H{"User": user})
+func (c *Context) HTML(code int, name string, data any) error {
+	if c.app == nil || c.app.templateEngine == nil {
+		return ErrTemplateEngineMissing
+	}
+	var buf bytes.Buffer
+	start := time.Now()
+	err := c.app.templateEngine.Render(&buf, name, c.templateData(data))
+	c.app.recordTemplateRender(name, time.Since(start), err)
+	if err != nil {
+		c.diagnostics().Error("Template rendering failed", "template", name, "error", err)
+		return fmt.Errorf("%w: %s: %w", ErrTemplateRender, name, err)
+	}
+	c.requestCtx.Response.SetStatusCode(code)
+	c.requestCtx.Response.Header.SetContentType(MIMETextHTMLCharsetUTF8)
+	c.requestCtx.Response.SetBodyRaw(buf.Bytes())
+	return nil
+}
+
+// TemplateStats reports the renders of a template by Context.HTML
+type TemplateStats struct {
+	Name    string        `json:"name"`
+	Renders uint64        `json:"renders"`
+	Errors  uint64        `json:"errors"`
+	Total   time.Duration `json:"total"`
+	Average time.Duration `json:"average"`
+	Max     time.Duration `json:"max"`
+}
+
+// templateStat accumulates the renders of a template
+type templateStat struct {
+	renders atomic.Uint64
+	errors  atomic.Uint64
+	total   atomic.Int64
+	max     atomic.Int64
+}
+
+// TemplateStats returns the render counts and durations of every rendered template,
+// slowest on average first, to find expensive templates
+func (g *Gonoleks) TemplateStats() []TemplateStats {
+	var stats []TemplateStats
+	g.templateStats.Range(func(name, value any) bool {
+		stat := value.(*templateStat)
+		s := TemplateStats{
+			Name:    name.(string),
+			Renders: stat.renders.Load(),
+			Errors:  stat.errors.Load(),
+			Total:   time.Duration(stat.total.Load()),
+			Max:     time.Duration(stat.max.Load()),
+		}
+		if s.Renders > 0 {
+			s.Average = s.Total / time.Duration(s.Renders)
+		}
+		stats = append(stats, s)
+		return true
+	})
+	slices.SortFunc(stats, func(a, b TemplateStats) int {
+		return cmp.Or(cmp.Compare(b.Average, a.Average), strings.Compare(a.Name, b.Name))
+	})
+	return stats
+}
+
+// recordTemplateRender counts a render of the template name, logging it when slower than
+// Options.SlowTemplateThreshold
+func (g *Gonoleks) recordTemplateRender(name string, took time.Duration, err error) {
+	value, ok := g.templateStats.Load(name)
+	if !ok {
+		value, _ = g.templateStats.LoadOrStore(name, &templateStat{})
+	}
+	stat := value.(*templateStat)
+	stat.renders.Add(1)
+	if err != nil {
+		stat.errors.Add(1)
+	}
+	stat.total.Add(int64(took))
+	for {
+		current := stat.max.Load()
+		if int64(took) <= current || stat.max.CompareAndSwap(current, int64(took)) {
+			break
+		}
+	}
+	if g.SlowTemplateThreshold > 0 && took > g.SlowTemplateThreshold {
+		g.diagnostics.Warn("Slow template render", "template", name, "duration", took, "threshold", g.SlowTemplateThreshold)
+	}
+}
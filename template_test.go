@@ -0,0 +1,223 @@
+package gonoleks
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"charm.land/log/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLEngine(t *testing.T) {
+	engine, err := NewHTMLEngine(HTMLEngineConfig{
+		FS: fstest.MapFS{
+			"layout.html":     {Data: []byte(`{{define "layout"}}<main>{{template "content" .}}</main>{{end}}`)},
+			"users/show.html": {Data: []byte(`{{define "content"}}{{upper .Name}} {{sanitize .Bio}}{{end}}{{template "layout" .}}`)},
+			"notes.txt":       {Data: []byte(`{{ broken`)},
+		},
+		Funcs: map[string]any{"upper": strings.ToUpper},
+	})
+	require.NoError(t, err)
+
+	app := New()
+	app.GET("/users/:name", func(c *Context) {
+		_ = c.HTML(StatusOK, "users/show.html", H{"Name": c.Param("name"), "Bio": "<b>hi</b><script>x</script>"})
+	})
+	app.GET("/missing", func(c *Context) {
+		assert.ErrorIs(t, c.HTML(StatusOK, "missing.html", nil), ErrTemplateRender)
+	})
+	app.SetTemplateEngine(engine)
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/users/ada", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, MIMETextHTMLCharsetUTF8, string(reqCtx.Response.Header.ContentType()))
+	assert.Equal(t, `<main>ADA <b>hi</b></main>`, string(reqCtx.Response.Body()))
+
+	reqCtx = newProxiedRequest(MethodGet, "/missing", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Empty(t, reqCtx.Response.Body())
+}
+
+func TestHTMLEngineReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "page.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(`v1`), 0o644))
+	engine, err := NewHTMLEngine(HTMLEngineConfig{Dir: dir, Extension: ".tmpl", Reload: true})
+	require.NoError(t, err)
+	var out strings.Builder
+	require.NoError(t, engine.Render(&out, "page.tmpl", nil))
+	require.NoError(t, os.WriteFile(path, []byte(`v2`), 0o644))
+	require.NoError(t, engine.Render(&out, "page.tmpl", nil))
+	assert.Equal(t, "v1v2", out.String())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{{ broken`), 0o644))
+	assert.ErrorIs(t, engine.Render(&out, "page.tmpl", nil), ErrTemplateParse)
+	_, err = NewHTMLEngine(HTMLEngineConfig{Dir: dir, Extension: ".tmpl"})
+	assert.ErrorIs(t, err, ErrTemplateParse)
+}
+
+func TestHTMLTemplateGlobals(t *testing.T) {
+	engine, err := NewHTMLEngine(HTMLEngineConfig{FS: fstest.MapFS{
+		"page.html": {Data: []byte(`{{.Path}}|{{.Query.Get "q"}}|{{.Locale}}|{{with .User}}{{.ID}}{{end}}|{{.CSRFToken}}|{{range .Flashes}}{{.}};{{end}}|{{.Title}}|{{url_for "users.show" "id" 7}}`)},
+	}})
+	require.NoError(t, err)
+	app := New()
+	app.SetTemplateEngine(engine)
+	app.GET("/users/:id", func(c *Context) {}).Name("users.show")
+	app.GET("/page", func(c *Context) {
+		c.SetPrincipal(&Principal{ID: "ada"})
+		c.Set(CSRFTokenKey, "tok")
+		c.Set(FlashesKey, []string{"saved"})
+		_ = c.HTML(StatusOK, "page.html", H{"Title": "t", "Locale": "override"})
+	})
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/page?q=go", "203.0.113.5", map[string]string{HeaderAcceptLanguage: "fr"})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, `/page|go|override|ada|tok|saved;|t|/users/7`, string(reqCtx.Response.Body()))
+}
+
+func TestViewData(t *testing.T) {
+	engine, err := NewHTMLEngine(HTMLEngineConfig{FS: fstest.MapFS{
+		"page.html": {Data: []byte(`{{range .Nav}}{{.}} {{end}}|{{.Title}}|{{.Locale}}`)},
+	}})
+	require.NoError(t, err)
+	app := New()
+	app.SetTemplateEngine(engine)
+	app.Use(func(c *Context) {
+		c.ViewData("Nav", []string{"home", "about"}).ViewData("Title", "default").ViewData("Locale", "en")
+		c.Next()
+	})
+	app.GET("/", func(c *Context) { _ = c.HTML(StatusOK, "page.html", H{"Title": "home"}) })
+	app.GET("/plain", func(c *Context) { _ = c.HTML(StatusOK, "page.html", nil) })
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/", "203.0.113.5", map[string]string{HeaderAcceptLanguage: "fr"})
+	app.router.Handler(reqCtx)
+	assert.Equal(t, `home about |home|en`, string(reqCtx.Response.Body()))
+	reqCtx = newProxiedRequest(MethodGet, "/plain", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, `home about |default|en`, string(reqCtx.Response.Body()))
+}
+
+func TestHTMLEngineTrimActionLines(t *testing.T) {
+	page := `<ul>
+  {{/* items */}}
+  {{ $count := 0 }}
+  {{range .Items}}
+    {{if .}}
+    <li>{{.}}</li>
+    {{end}}
+  {{end}}
+</ul>
+<pre>
+  {{ "kept" }}
+</pre>
+`
+	engine, err := NewHTMLEngine(HTMLEngineConfig{FS: fstest.MapFS{"page.html": {Data: []byte(page)}}, TrimActionLines: true})
+	require.NoError(t, err)
+	var out strings.Builder
+	require.NoError(t, engine.Render(&out, "page.html", H{"Items": []string{"a", "", "b"}}))
+	assert.Equal(t, "<ul>\n    <li>a</li>\n    <li>b</li>\n</ul>\n<pre>\n  kept\n</pre>\n", out.String())
+}
+
+func TestHTMLEngineDelims(t *testing.T) {
+	engine, err := NewHTMLEngine(HTMLEngineConfig{
+		FS:              fstest.MapFS{"page.html": {Data: []byte(`<p x-text="{{ message }}">[[ .Name ]]</p>` + "\n[[ if .Name ]]\n!\n[[ end ]]\n")}},
+		Delims:          [2]string{"[[", "]]"},
+		TrimActionLines: true,
+	})
+	require.NoError(t, err)
+	var out strings.Builder
+	require.NoError(t, engine.Render(&out, "page.html", H{"Name": "ada"}))
+	assert.Equal(t, `<p x-text="{{ message }}">ada</p>`+"\n!\n", out.String())
+}
+
+func TestMultiEngine(t *testing.T) {
+	html, err := NewHTMLEngine(HTMLEngineConfig{FS: fstest.MapFS{
+		"page.html": {Data: []byte(`<a href="{{url_for "home"}}">{{block "title" .}}{{.Title}}{{end}}</a>`)},
+	}})
+	require.NoError(t, err)
+	legacy, err := NewHTMLEngine(HTMLEngineConfig{Extension: ".tmpl", FS: fstest.MapFS{
+		"old.tmpl": {Data: []byte(`[[.Title]]`)},
+	}, Delims: [2]string{"[[", "]]"}})
+	require.NoError(t, err)
+	markdown := TemplateEngineFunc(func(w io.Writer, name string, data any) error {
+		_, err := io.WriteString(w, "<h1>"+strings.TrimSuffix(name, ".md")+"</h1>")
+		return err
+	})
+	engine := NewMultiEngine().Register(".html", html).Register("tmpl", legacy).Register(".MD", markdown)
+
+	app := New()
+	app.GET("/", func(c *Context) {}).Name("home")
+	app.SetTemplateEngine(engine)
+	c, _ := createTestContext()
+	c.app = app
+	for name, expected := range map[string]string{
+		"page.html":     `<a href="/">t</a>`,
+		"old.tmpl":      `t`,
+		"docs/Guide.md": `<h1>docs/Guide</h1>`,
+		"title":         `t`,
+	} {
+		require.NoError(t, c.HTML(StatusOK, name, H{"Title": "t"}), name)
+		assert.Equal(t, expected, string(c.requestCtx.Response.Body()), name)
+	}
+
+	engine.Fallback(nil)
+	assert.ErrorIs(t, c.HTML(StatusOK, "title", nil), ErrTemplateEngineMissing)
+}
+
+// slowEngine renders templates by sleeping for a duration named by the template
+type slowEngine struct{}
+
+func (slowEngine) Render(w io.Writer, name string, data any) error {
+	took, err := time.ParseDuration(name)
+	if err != nil {
+		return err
+	}
+	time.Sleep(took)
+	return nil
+}
+
+func TestTemplateStats(t *testing.T) {
+	var diag bytes.Buffer
+	app := New()
+	app.SetDiagnosticsLogger(log.New(&diag))
+	app.SlowTemplateThreshold = 15 * time.Millisecond
+	app.SetTemplateEngine(slowEngine{})
+	c, _ := createTestContext()
+	c.app = app
+	require.NoError(t, c.HTML(StatusOK, "1ms", nil))
+	require.NoError(t, c.HTML(StatusOK, "1ms", nil))
+	require.NoError(t, c.HTML(StatusOK, "20ms", nil))
+	assert.Error(t, c.HTML(StatusOK, "broken", nil))
+
+	stats := app.TemplateStats()
+	require.Len(t, stats, 3)
+	assert.Equal(t, "20ms", stats[0].Name)
+	assert.GreaterOrEqual(t, stats[0].Max, 20*time.Millisecond)
+	assert.Equal(t, "1ms", stats[1].Name)
+	assert.EqualValues(t, 2, stats[1].Renders)
+	assert.GreaterOrEqual(t, stats[1].Total, 2*time.Millisecond)
+	assert.Equal(t, stats[1].Total/2, stats[1].Average)
+	assert.Equal(t, TemplateStats{Name: "broken", Renders: 1, Errors: 1, Total: stats[2].Total, Average: stats[2].Average, Max: stats[2].Max}, stats[2])
+
+	assert.Contains(t, diag.String(), "Slow template render")
+	assert.Contains(t, diag.String(), "template=20ms")
+	assert.NotContains(t, diag.String(), "template=1ms")
+}
+
+func TestHTMLWithoutEngine(t *testing.T) {
+	c, _ := createTestContext()
+	assert.ErrorIs(t, c.HTML(StatusOK, "x", nil), ErrTemplateEngineMissing)
+}
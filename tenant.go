@@ -0,0 +1,185 @@
+package gonoleks
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// tenantKey is the user value key under which the resolved tenant is stored
+const tenantKey = "gonoleksTenant"
+
+// Tenant describes the customer a request belongs to
+type Tenant struct {
+	// ID identifies the tenant, e.g. the subdomain "acme"
+	ID string
+
+	// Name is a display name
+	Name string
+
+	// Metadata holds application specific data such as plan or feature settings
+	Metadata map[string]any
+
+	// RateLimit is the number of requests per second the tenant may make
+	// Requests above the limit are rejected with 429 Too Many Requests, and 0 disables the limit
+	RateLimit float64
+
+	// Burst is the number of requests allowed at once above the steady rate
+	Burst int // Default = RateLimit rounded up
+}
+
+// TenantProvider looks up tenants by ID
+type TenantProvider interface {
+	// Tenant returns the tenant with the given ID, or nil if there is none
+	Tenant(id string) (*Tenant, error)
+}
+
+// TenantProviderFunc adapts a function to a TenantProvider
+type TenantProviderFunc func(id string) (*Tenant, error)
+
+// Tenant implements TenantProvider
+func (f TenantProviderFunc) Tenant(id string) (*Tenant, error) {
+	return f(id)
+}
+
+// StaticTenants returns a TenantProvider serving a fixed set of tenants
+func StaticTenants(tenants ...*Tenant) TenantProvider {
+	byID := make(map[string]*Tenant, len(tenants))
+	for _, tenant := range tenants {
+		byID[tenant.ID] = tenant
+	}
+	return TenantProviderFunc(func(id string) (*Tenant, error) {
+		return byID[id], nil
+	})
+}
+
+// TenantResolver returns the tenant ID a request addresses, or "" if it names none
+type TenantResolver func(c *Context) string
+
+// TenantFromSubdomain resolves the tenant from the subdomain closest to the domain,
+// so both "acme.example.com" and "www.acme.example.com" yield "acme"
+// The offset is the number of labels forming the domain, see Context.Subdomains
+func TenantFromSubdomain(offset int) TenantResolver {
+	return func(c *Context) string {
+		subdomains := c.Subdomains(offset)
+		if len(subdomains) == 0 {
+			return ""
+		}
+		return subdomains[len(subdomains)-1]
+	}
+}
+
+// TenantFromHeader resolves the tenant from a request header, e.g. "X-Tenant-ID"
+func TenantFromHeader(name string) TenantResolver {
+	return func(c *Context) string {
+		return c.GetHeader(name)
+	}
+}
+
+// TenantFromPathPrefix resolves the tenant from the first path segment, e.g. "acme" for /acme/invoices
+// Routes are registered below a parameter such as app.Group("/:tenant")
+func TenantFromPathPrefix() TenantResolver {
+	return func(c *Context) string {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(string(c.requestCtx.Path()), "/"), "/")
+		return segment
+	}
+}
+
+// TenantConfig defines the config for TenantWithConfig
+type TenantConfig struct {
+	// Resolvers are tried in order until one yields a tenant ID
+	Resolvers []TenantResolver
+
+	// Provider looks up the resolved tenant ID
+	Provider TenantProvider
+
+	// Optional lets requests without a tenant ID through with c.Tenant() returning nil,
+	// instead of rejecting them with 400 Bad Request
+	Optional bool
+}
+
+// TenantWithConfig instances a multitenancy middleware that resolves the tenant of each request
+// and makes it available through Context.Tenant
+// Unknown tenants are rejected with 404 Not Found. The tenant ID is added to the request logger,
+// and the tenant's rate limit is enforced
+//
+//	app.Use(gonoleks.TenantWithConfig(gonoleks.TenantConfig{
+//		Resolvers: []gonoleks.TenantResolver{gonoleks.TenantFromSubdomain(2), gonoleks.TenantFromHeader("X-Tenant-ID")},
+//		Provider:  gonoleks.StaticTenants(&gonoleks.Tenant{ID: "acme", RateLimit: 50}),
+//	}))
+func TenantWithConfig(conf TenantConfig) handlerFunc {
+	var limiters sync.Map // Tenant ID to *tokenBucket
+	return func(c *Context) {
+		var id string
+		for _, resolve := range conf.Resolvers {
+			if id = resolve(c); id != "" {
+				break
+			}
+		}
+		if id == "" {
+			if conf.Optional {
+				c.Next()
+				return
+			}
+			_ = c.AbortWithError(StatusBadRequest, ErrTenantMissing)
+			return
+		}
+		tenant, err := conf.Provider.Tenant(id)
+		if err != nil {
+			_ = c.AbortWithError(StatusInternalServerError, err)
+			return
+		}
+		if tenant == nil {
+			_ = c.AbortWithError(StatusNotFound, ErrTenantUnknown)
+			return
+		}
+		if tenant.RateLimit > 0 {
+			burst := tenant.Burst
+			if burst <= 0 {
+				burst = int(math.Ceil(tenant.RateLimit))
+			}
+			limiter, _ := limiters.LoadOrStore(tenant.ID, &tokenBucket{tokens: float64(burst), last: time.Now()})
+			if wait := limiter.(*tokenBucket).take(tenant.RateLimit, burst); wait > 0 {
+				c.requestCtx.Error(fasthttp.StatusMessage(StatusTooManyRequests), StatusTooManyRequests)
+				c.requestCtx.Response.Header.Set(HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				c.Abort()
+				return
+			}
+		}
+		c.requestCtx.SetUserValue(tenantKey, tenant)
+		c.SetLogger(c.Logger().With("tenant", tenant.ID))
+		c.Next()
+	}
+}
+
+// Tenant returns the tenant resolved by the tenant middleware, or nil
+func (c *Context) Tenant() *Tenant {
+	tenant, _ := c.requestCtx.UserValue(tenantKey).(*Tenant)
+	return tenant
+}
+
+// tokenBucket is a token bucket rate limiter
+type tokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// take consumes a token, refilling at rate tokens per second up to burst
+// It returns zero when a token was available, otherwise the time until the next one
+func (b *tokenBucket) take(rate float64, burst int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
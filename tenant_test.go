@@ -0,0 +1,125 @@
+package gonoleks
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	app := New()
+	var buf bytes.Buffer
+	app.Use(func(c *Context) {
+		c.SetLogger(log.New(&buf))
+		c.Next()
+	})
+	app.Use(TenantWithConfig(TenantConfig{
+		Resolvers: []TenantResolver{TenantFromHeader("X-Tenant-ID"), TenantFromSubdomain(2)},
+		Provider: StaticTenants(
+			&Tenant{ID: "acme", Name: "Acme Corp"},
+			&Tenant{ID: "globex", Metadata: map[string]any{"plan": "pro"}},
+		),
+	}))
+	var tenant *Tenant
+	app.GET("/invoices", func(c *Context) {
+		tenant = c.Tenant()
+		c.Logger().Info("Listing invoices")
+	})
+	app.setupRouter()
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		code    int
+		tenant  string
+	}{
+		{"Subdomain", map[string]string{HeaderHost: "acme.example.com"}, StatusOK, "acme"},
+		{"Nested subdomain", map[string]string{HeaderHost: "www.acme.example.com"}, StatusOK, "acme"},
+		{"Header beats subdomain", map[string]string{HeaderHost: "acme.example.com", "X-Tenant-ID": "globex"}, StatusOK, "globex"},
+		{"Unknown tenant", map[string]string{HeaderHost: "initech.example.com"}, StatusNotFound, ""},
+		{"No tenant", map[string]string{HeaderHost: "example.com"}, StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant = nil
+			buf.Reset()
+			reqCtx := newProxiedRequest(MethodGet, "/invoices", "203.0.113.5", tt.headers)
+			app.router.Handler(reqCtx)
+			assert.Equal(t, tt.code, reqCtx.Response.StatusCode())
+			if tt.tenant == "" {
+				assert.Nil(t, tenant)
+				return
+			}
+			require.NotNil(t, tenant)
+			assert.Equal(t, tt.tenant, tenant.ID)
+			assert.Contains(t, buf.String(), "tenant="+tt.tenant, "The tenant should be added to the request logger")
+		})
+	}
+}
+
+func TestTenantFromPathPrefix(t *testing.T) {
+	app := New()
+	tenants := app.Group("/:tenant", TenantWithConfig(TenantConfig{
+		Resolvers: []TenantResolver{TenantFromPathPrefix()},
+		Provider: TenantProviderFunc(func(id string) (*Tenant, error) {
+			if id == "broken" {
+				return nil, errors.New("database unavailable")
+			}
+			return &Tenant{ID: id}, nil
+		}),
+	}))
+	tenants.GET("/dashboard", func(c *Context) {
+		c.String(StatusOK, "%s", c.Tenant().ID)
+	})
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/acme/dashboard", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, "acme", string(reqCtx.Response.Body()))
+
+	reqCtx = newProxiedRequest(MethodGet, "/broken/dashboard", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusInternalServerError, reqCtx.Response.StatusCode())
+}
+
+func TestTenantOptional(t *testing.T) {
+	c, requestCtx := createTestContext()
+	TenantWithConfig(TenantConfig{Provider: StaticTenants(), Optional: true})(c)
+	assert.Equal(t, StatusOK, requestCtx.Response.StatusCode())
+	assert.Nil(t, c.Tenant())
+}
+
+func TestTenantRateLimit(t *testing.T) {
+	app := New()
+	app.Use(TenantWithConfig(TenantConfig{
+		Resolvers: []TenantResolver{TenantFromHeader("X-Tenant-ID")},
+		Provider:  StaticTenants(&Tenant{ID: "small", RateLimit: 1, Burst: 2}, &Tenant{ID: "other", RateLimit: 1}),
+	}))
+	app.GET("/api", func(c *Context) {
+		c.Status(StatusOK)
+	})
+	app.setupRouter()
+
+	request := func(tenant string) int {
+		reqCtx := newProxiedRequest(MethodGet, "/api", "203.0.113.5", map[string]string{"X-Tenant-ID": tenant})
+		app.router.Handler(reqCtx)
+		return reqCtx.Response.StatusCode()
+	}
+	assert.Equal(t, StatusOK, request("small"))
+	assert.Equal(t, StatusOK, request("small"))
+	assert.Equal(t, StatusTooManyRequests, request("small"), "Requests above the burst should be limited")
+	assert.Equal(t, StatusOK, request("other"), "Limits should apply per tenant")
+}
+
+func TestTokenBucket(t *testing.T) {
+	bucket := &tokenBucket{tokens: 1, last: time.Now()}
+	assert.Zero(t, bucket.take(10, 1))
+	wait := bucket.take(10, 1)
+	assert.Greater(t, wait, time.Duration(0))
+	assert.LessOrEqual(t, wait, 100*time.Millisecond)
+}
@@ -25,8 +25,9 @@ type node struct {
 }
 
 // addRoute adds a node with the provided handlers to the specified path
-// It parses the path into segments and builds the routing tree accordingly
-func (n *node) addRoute(path string, handlers handlersChain) {
+// It parses the path into segments and builds the routing tree accordingly,
+// and returns the handlers stored for the path
+func (n *node) addRoute(path string, handlers handlersChain) handlersChain {
 	currentNode := n
 	originalPath := path
 	path = path[1:] // Remove leading slash
@@ -46,7 +47,7 @@ func (n *node) addRoute(path string, handlers handlersChain) {
 		pathLen := len(path)
 		if pathLen == 0 {
 			n.setHandlers(currentNode, handlers)
-			break
+			return currentNode.handlers
 		}
 		segmentDelimiter := strings.Index(path, "/")
 		if segmentDelimiter == -1 {
@@ -180,6 +181,12 @@ func extractParamNames(pathSegment string, paramNames map[string]bool) {
 //
 //go:noinline
 func (n *node) matchRoute(path string, ctx *Context) handlersChain {
+	return n.traceRoute(path, ctx, nil)
+}
+
+// traceRoute implements matchRoute, appending the pattern of every node it
+// traverses to trace when it is not nil
+func (n *node) traceRoute(path string, ctx *Context, trace *[]string) handlersChain {
 	currentNode := n
 	// Optimized path preprocessing - avoid repeated slice operations
 	pathStart := 0
@@ -250,6 +257,9 @@ func (n *node) matchRoute(path string, ctx *Context) handlersChain {
 					} else {
 						ctx.paramValues[paramName] = pathSegment
 					}
+					if trace != nil {
+						*trace = append(*trace, currentNode.param.path)
+					}
 					return currentNode.param.handlers
 				default:
 					return nil
@@ -259,6 +269,9 @@ func (n *node) matchRoute(path string, ctx *Context) handlersChain {
 				return nil
 			}
 		}
+		if trace != nil {
+			*trace = append(*trace, currentNode.path)
+		}
 		// Traverse to the next segment - optimized without slice creation
 		pathStart = segmentEnd
 		if pathStart < pathLen && path[pathStart] == '/' {
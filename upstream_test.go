@@ -0,0 +1,21 @@
+package gonoleks
+
+import (
+	"net"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+// startTestUpstream serves the handler on an in-memory listener and returns a client dialing it
+func startTestUpstream(t *testing.T, handler fasthttp.RequestHandler) *fasthttp.Client {
+	ln := fasthttputil.NewInmemoryListener()
+	go func() {
+		_ = fasthttp.Serve(ln, handler)
+	}()
+	t.Cleanup(func() { _ = ln.Close() })
+	return &fasthttp.Client{
+		Dial: func(addr string) (net.Conn, error) { return ln.Dial() },
+	}
+}
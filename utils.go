@@ -1,9 +1,14 @@
 package gonoleks
 
 import (
+	"cmp"
 	"encoding/xml"
+	"net/url"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 	"unsafe"
 
 	"charm.land/log/v2"
@@ -43,7 +48,6 @@ func (h H) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 // It returns a properly formatted address string with IPv4 as default
 func resolveAddress(portStr string) string {
 	if portStr == "" {
-		log.Warnf("Empty port format, using default port %s", defaultPort)
 		return wildcardIPv4Addr + defaultPort
 	}
 	if strings.HasPrefix(portStr, ":") {
@@ -96,3 +100,122 @@ func getString(b []byte) string {
 	}
 	return unsafe.String(&b[0], len(b))
 }
+
+// contentDisposition builds a Content-Disposition header value
+// Names that are not plain ASCII get an ASCII fallback in filename and
+// the exact name in filename* using RFC 5987 encoding
+func contentDisposition(disposition, fileName string) string {
+	fallback := make([]byte, 0, len(fileName))
+	ascii := true
+	for _, r := range fileName {
+		switch {
+		case r >= utf8.RuneSelf || r < 0x20 || r == 0x7f:
+			ascii = false
+			fallback = append(fallback, '_')
+		case r == '"' || r == '\\':
+			fallback = append(fallback, '\\', byte(r))
+		default:
+			fallback = append(fallback, byte(r))
+		}
+	}
+	value := disposition + `; filename="` + string(fallback) + `"`
+	if !ascii {
+		value += "; filename*=UTF-8''" + encodeRFC5987(fileName)
+	}
+	return value
+}
+
+// encodeRFC5987 percent-encodes every byte that is not an attr-char as defined by RFC 5987
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || strings.IndexByte("!#$&+-.^_`|~", ch) >= 0 {
+			b.WriteByte(ch)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[ch>>4])
+		b.WriteByte(hex[ch&0x0f])
+	}
+	return b.String()
+}
+
+// sanitizePath cleans a slash-separated request path and reports whether it is safe to serve
+// The path is percent-decoded repeatedly so double-encoded sequences such as %252e%252e are caught,
+// and it is rejected when any decoded form contains a parent directory reference,
+// a backslash or a NUL byte
+// The returned path is rooted and cleaned
+func sanitizePath(p string) (string, bool) {
+	decoded := p
+	for range 3 {
+		if hasUnsafePathSegment(decoded) {
+			return "", false
+		}
+		next, err := url.PathUnescape(decoded)
+		if err != nil || next == decoded {
+			break
+		}
+		decoded = next
+	}
+	if hasUnsafePathSegment(decoded) {
+		return "", false
+	}
+	cleaned := path.Clean("/" + p)
+	// Keep the trailing slash, it marks directory requests
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned, true
+}
+
+// hasUnsafePathSegment reports whether p contains a NUL byte, a backslash or a ".." segment
+func hasUnsafePathSegment(p string) bool {
+	if strings.ContainsAny(p, "\x00\\") {
+		return true
+	}
+	for segment := range strings.SplitSeq(p, "/") {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAcceptHeader returns the values of an Accept-style header ordered by preference
+// Values with q=0 are dropped and values with equal quality keep their order
+func parseAcceptHeader(header string) []string {
+	type acceptValue struct {
+		value   string
+		quality float64
+	}
+	values := make([]acceptValue, 0, 4)
+	for part := range strings.SplitSeq(header, ",") {
+		value, params, _ := strings.Cut(part, ";")
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		quality := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			key, raw, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(raw, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			values = append(values, acceptValue{value: value, quality: quality})
+		}
+	}
+	slices.SortStableFunc(values, func(a, b acceptValue) int {
+		return cmp.Compare(b.quality, a.quality)
+	})
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = v.value
+	}
+	return result
+}
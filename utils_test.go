@@ -116,3 +116,57 @@ func TestMarshalXML(t *testing.T) {
 	assert.Contains(t, actual4, "<id>1</id>")
 	assert.Contains(t, actual4, "<name>Arman</name>")
 }
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		expected string
+	}{
+		{"ASCII name", "report.pdf", `attachment; filename="report.pdf"`},
+		{"Quoted name", `a "b".txt`, `attachment; filename="a \"b\".txt"`},
+		{"Non-ASCII name", "отчёт 2024.pdf", `attachment; filename="_____ 2024.pdf"; filename*=UTF-8''%D0%BE%D1%82%D1%87%D1%91%D1%82%202024.pdf`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, contentDisposition("attachment", tt.fileName))
+		})
+	}
+}
+
+func TestSanitizePath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"/css/app.css", "/css/app.css", true},
+		{"css//app.css", "/css/app.css", true},
+		{"/docs/", "/docs/", true},
+		{"/a/./b", "/a/b", true},
+		{"", "/", true},
+		{"/../etc/passwd", "", false},
+		{"/css/../../etc/passwd", "", false},
+		{"/%2e%2e/etc/passwd", "", false},
+		{"/%252e%252e/etc/passwd", "", false},
+		{"/%25252e%25252e/etc/passwd", "", false},
+		{"/..%2fetc/passwd", "", false},
+		{"/..%252fetc/passwd", "", false},
+		{"/..\\etc\\passwd", "", false},
+		{"/%5c..%5cetc", "", false},
+		{"/file.txt%00.png", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			cleaned, ok := sanitizePath(tt.input)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, cleaned)
+		})
+	}
+}
+
+func TestParseAcceptHeader(t *testing.T) {
+	assert.Equal(t, []string{"de-AT", "fr", "en"}, parseAcceptHeader("fr;q=0.9, de-AT, en;q=0.5, ja;q=0"))
+	assert.Equal(t, []string{"text/html", "application/json"}, parseAcceptHeader("text/html, application/json"))
+	assert.Empty(t, parseAcceptHeader(""))
+}
@@ -0,0 +1,87 @@
+package gonoleks
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// VersionInfo describes the build of the application
+type VersionInfo struct {
+	// Version is the application version, e.g. "v1.4.2"
+	Version string `json:"version"`
+
+	// Commit is the VCS revision the application was built from
+	Commit string `json:"commit,omitempty"`
+
+	// BuildDate is when the application was built
+	BuildDate string `json:"build_date,omitempty"`
+
+	// GoVersion is the Go version the binary was built with
+	GoVersion string `json:"go_version"`
+}
+
+// SetVersion sets the application version shown in the startup message and served by
+// VersionHeader and VersionHandler, typically injected at build time
+//
+//	var version, commit, date string // -ldflags "-X main.version=v1.4.2 ..."
+//	app.SetVersion(version, commit, date)
+func (g *Gonoleks) SetVersion(version, commit, buildDate string) {
+	g.version = &VersionInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// Version returns the application version set with SetVersion
+// Without it, the version of the main module and the VCS revision and time
+// recorded by the Go toolchain are returned
+func (g *Gonoleks) Version() VersionInfo {
+	if g.version != nil {
+		return *g.version
+	}
+	return buildVersionInfo()
+}
+
+// VersionHeader instances a middleware adding the application version in the X-App-Version
+// response header, to verify which build served a request during deployments
+func (g *Gonoleks) VersionHeader() handlerFunc {
+	return func(c *Context) {
+		if version := g.Version().Version; version != "" {
+			c.requestCtx.Response.Header.Set(HeaderXAppVersion, version)
+		}
+		c.Next()
+	}
+}
+
+// VersionHandler returns a handler answering with the application version as JSON
+//
+//	app.GET("/version", app.VersionHandler())
+func (g *Gonoleks) VersionHandler() handlerFunc {
+	return func(c *Context) {
+		c.requestCtx.Response.Header.Set(HeaderCacheControl, "no-store")
+		_ = c.JSON(StatusOK, g.Version())
+	}
+}
+
+// buildVersionInfo returns the version information recorded in the binary by the Go toolchain
+func buildVersionInfo() VersionInfo {
+	info := VersionInfo{GoVersion: runtime.Version()}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			info.BuildDate = setting.Value
+		}
+	}
+	return info
+}
@@ -0,0 +1,52 @@
+package gonoleks
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetVersion(t *testing.T) {
+	app := New()
+	assert.Equal(t, runtime.Version(), app.Version().GoVersion)
+
+	app.SetVersion("v1.4.2", "abc1234", "2026-10-01T12:00:00Z")
+	assert.Equal(t, VersionInfo{
+		Version:   "v1.4.2",
+		Commit:    "abc1234",
+		BuildDate: "2026-10-01T12:00:00Z",
+		GoVersion: runtime.Version(),
+	}, app.Version())
+
+	var out bytes.Buffer
+	app.StartupMessage(StartupConfig{Format: StartupBanner, Output: &out})
+	app.setupRouter()
+	app.printStartupMessage("127.0.0.1:8080", false)
+	assert.Contains(t, out.String(), "Gonoleks v1.4.2\n")
+	assert.Contains(t, out.String(), "Commit       abc1234\n")
+	assert.Contains(t, out.String(), "Built        2026-10-01T12:00:00Z\n")
+}
+
+func TestVersionHeaderAndHandler(t *testing.T) {
+	app := New()
+	app.SetVersion("v2.0.0", "def5678", "")
+	app.Use(app.VersionHeader())
+	app.GET("/version", app.VersionHandler())
+	app.setupRouter()
+
+	reqCtx := newProxiedRequest(MethodGet, "/version", "203.0.113.5", nil)
+	app.router.Handler(reqCtx)
+	assert.Equal(t, StatusOK, reqCtx.Response.StatusCode())
+	assert.Equal(t, "v2.0.0", string(reqCtx.Response.Header.Peek(HeaderXAppVersion)))
+	assert.Equal(t, "no-store", string(reqCtx.Response.Header.Peek(HeaderCacheControl)))
+	var body map[string]any
+	require.NoError(t, sonic.Unmarshal(reqCtx.Response.Body(), &body))
+	assert.Equal(t, "v2.0.0", body["version"])
+	assert.Equal(t, "def5678", body["commit"])
+	assert.NotContains(t, body, "build_date")
+	assert.Equal(t, runtime.Version(), body["go_version"])
+}
@@ -0,0 +1,124 @@
+package gonoleks
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// RouteHit is the number of requests served by a static route
+type RouteHit struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Hits   uint64 `json:"hits"`
+}
+
+// WarmupRoutes preloads the given static routes into the route cache so the first
+// requests after a deploy do not pay for cache misses
+// Entries are paths served by GET, or a method and path separated by a space, e.g. "POST /login"
+// Routes are preloaded when the router is set up, later ones winning cache slots
+func (g *Gonoleks) WarmupRoutes(paths []string) {
+	g.warmupRoutes = append(g.warmupRoutes, paths...)
+	if g.router.fastRouter != nil {
+		g.warmupRouter()
+	}
+}
+
+// RouteHits returns the hit counts recorded while TrackRouteHits is enabled, hottest first
+func (g *Gonoleks) RouteHits() []RouteHit {
+	hits := make([]RouteHit, 0)
+	g.router.routeHits.Range(func(key, value any) bool {
+		method, path, _ := strings.Cut(key.(string), " ")
+		hits = append(hits, RouteHit{Method: method, Path: path, Hits: value.(*atomic.Uint64).Load()})
+		return true
+	})
+	slices.SortFunc(hits, func(a, b RouteHit) int {
+		if c := cmp.Compare(b.Hits, a.Hits); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Method+" "+a.Path, b.Method+" "+b.Path)
+	})
+	return hits
+}
+
+// WriteRouteHits writes a JSON snapshot of RouteHits, suitable for WarmupSnapshot
+func (g *Gonoleks) WriteRouteHits(w io.Writer) error {
+	return json.NewEncoder(w).Encode(g.RouteHits())
+}
+
+// recordHit counts a request served by a static route
+func (r *router) recordHit(method, path string) {
+	if _, static := r.staticRoutes[method+path]; !static {
+		return
+	}
+	key := method + " " + path
+	counter, ok := r.routeHits.Load(key)
+	if !ok {
+		counter, _ = r.routeHits.LoadOrStore(key, new(atomic.Uint64))
+	}
+	counter.(*atomic.Uint64).Add(1)
+}
+
+// warmupRouter preloads the routes from WarmupRoutes and the warmup snapshot
+func (g *Gonoleks) warmupRouter() {
+	if g.router.fastRouter == nil {
+		return
+	}
+	routes := g.warmupRoutes
+	if g.WarmupSnapshot != "" {
+		hits, err := loadWarmupSnapshot(g.WarmupSnapshot)
+		if err != nil && !os.IsNotExist(err) {
+			g.diagnostics.Warn("Failed to read route warmup snapshot", "file", g.WarmupSnapshot, "error", err)
+		}
+		topN := g.WarmupTopN
+		if topN <= 0 {
+			topN = 100
+		}
+		hits = hits[:min(topN, len(hits))]
+		// Hottest routes go last so they win cache slots on collisions
+		for i := len(hits) - 1; i >= 0; i-- {
+			routes = append(routes, hits[i].Method+" "+hits[i].Path)
+		}
+	}
+	for _, route := range routes {
+		method, path, found := strings.Cut(route, " ")
+		if !found {
+			method, path = MethodGet, route
+		}
+		if g.CaseInSensitive {
+			path = strings.ToLower(path)
+		}
+		g.router.fastRouter.promote(method, path)
+	}
+}
+
+// loadWarmupSnapshot reads a snapshot written by WriteRouteHits, hottest first
+func loadWarmupSnapshot(name string) ([]RouteHit, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var hits []RouteHit
+	if err := json.Unmarshal(data, &hits); err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(hits, func(a, b RouteHit) int { return cmp.Compare(b.Hits, a.Hits) })
+	return hits, nil
+}
+
+// saveWarmupSnapshot writes the route hits to WarmupSnapshot
+func (g *Gonoleks) saveWarmupSnapshot() error {
+	f, err := os.Create(g.WarmupSnapshot)
+	if err != nil {
+		return err
+	}
+	if err := g.WriteRouteHits(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
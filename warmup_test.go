@@ -0,0 +1,94 @@
+package gonoleks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestFastRouterPromote(t *testing.T) {
+	fr := NewFastRouter()
+	handlers := handlersChain{func(c *Context) {}}
+	fr.AddRoute(MethodGet, "/hot", handlers)
+	hash := uint32(ultraFastCombinedHash(ultraFastStringHash(MethodGet), ultraFastStringHash("/hot")))
+
+	// Simulate a colliding route evicting the entry
+	fr.ultraCache.entries[hash&fr.ultraCache.hashMask] = ultraFastCacheEntry{}
+	fr.routeCache[hash&255] = hashCacheEntry{}
+	fr.commonRoutes[hash&1023] = commonRoute{}
+
+	fr.WarmupCache([]string{"/hot"})
+	assert.Equal(t, hash, fr.ultraCache.entries[hash&fr.ultraCache.hashMask].hash)
+	assert.Equal(t, hash, fr.commonRoutes[hash&1023].key)
+	assert.False(t, fr.promote(MethodGet, "/cold"))
+}
+
+func TestWarmupRoutes(t *testing.T) {
+	app := New()
+	app.GET("/hot", func(c *Context) {})
+	app.POST("/login", func(c *Context) {})
+	app.WarmupRoutes([]string{"/hot", "POST /login"})
+	app.setupRouter()
+
+	fr := app.router.fastRouter
+	for _, route := range [][2]string{{MethodGet, "/hot"}, {MethodPost, "/login"}} {
+		hash := uint32(ultraFastCombinedHash(ultraFastStringHash(route[0]), ultraFastStringHash(route[1])))
+		fr.ultraCache.entries[hash&fr.ultraCache.hashMask] = ultraFastCacheEntry{}
+	}
+	// Warming up after setup applies immediately
+	app.WarmupRoutes(nil)
+	for _, route := range [][2]string{{MethodGet, "/hot"}, {MethodPost, "/login"}} {
+		hash := uint32(ultraFastCombinedHash(ultraFastStringHash(route[0]), ultraFastStringHash(route[1])))
+		assert.Equal(t, hash, fr.ultraCache.entries[hash&fr.ultraCache.hashMask].hash)
+	}
+}
+
+func TestRouteHitsSnapshot(t *testing.T) {
+	app := New()
+	app.TrackRouteHits = true
+	app.GET("/a", func(c *Context) {})
+	app.GET("/b", func(c *Context) {})
+	app.GET("/users/:id", func(c *Context) {})
+	app.setupRouter()
+
+	for _, path := range []string{"/a", "/b", "/b", "/users/1", "/missing"} {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(path)
+		app.router.Handler(reqCtx)
+	}
+	assert.Equal(t, []RouteHit{
+		{Method: MethodGet, Path: "/b", Hits: 2},
+		{Method: MethodGet, Path: "/a", Hits: 1},
+	}, app.RouteHits())
+
+	snapshot := filepath.Join(t.TempDir(), "routes.json")
+	var buf bytes.Buffer
+	require.NoError(t, app.WriteRouteHits(&buf))
+	require.NoError(t, os.WriteFile(snapshot, buf.Bytes(), 0o600))
+
+	hits, err := loadWarmupSnapshot(snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, "/b", hits[0].Path)
+
+	next := New()
+	next.WarmupSnapshot = snapshot
+	next.WarmupTopN = 1
+	next.TrackRouteHits = true
+	next.GET("/a", func(c *Context) {})
+	next.GET("/b", func(c *Context) {})
+	next.setupRouter()
+	hash := uint32(ultraFastCombinedHash(ultraFastStringHash(MethodGet), ultraFastStringHash("/b")))
+	assert.Equal(t, hash, next.router.fastRouter.ultraCache.entries[hash&511].hash)
+
+	// Shutdown rewrites the snapshot with the hits of the current run
+	require.NoError(t, next.Shutdown())
+	data, err := os.ReadFile(snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, "[]", strings.TrimSpace(string(data)))
+}
@@ -0,0 +1,122 @@
+package gonoleks
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+)
+
+// XMLBindingConfig defines the limits of an XML binding, guarding against documents crafted
+// to exhaust the server, such as deeply nested or billion laughs style documents
+// Zero values disable a limit
+type XMLBindingConfig struct {
+	// MaxDepth is the maximum nesting depth of elements
+	MaxDepth int
+
+	// MaxTokens is the maximum number of tokens, i.e. elements, text nodes, comments and
+	// processing instructions, bounding the work spent on a document
+	MaxTokens int
+
+	// AllowDTD accepts documents with a DOCTYPE or other directive, which are rejected otherwise
+	// encoding/xml never expands entities declared in a DTD, so rejecting DTDs turns
+	// entity expansion attempts into an early error instead of a failure on the first entity
+	AllowDTD bool
+}
+
+// DefaultXMLBindingConfig is the default config of NewXMLBinding and Context.XMLDecoder
+var DefaultXMLBindingConfig = XMLBindingConfig{
+	MaxDepth:  100,
+	MaxTokens: 1_000_000,
+}
+
+// NewXMLBinding creates an XML binding enforcing the limits of config, defaulting to
+// DefaultXMLBindingConfig
+// Replace BindingXML to apply the limits to every XML request bound with ShouldBind and Bind
+//
+//	gonoleks.BindingXML = gonoleks.NewXMLBinding(gonoleks.XMLBindingConfig{MaxDepth: 32, MaxTokens: 10_000})
+func NewXMLBinding(config ...XMLBindingConfig) BindingBody {
+	conf := DefaultXMLBindingConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	return xmlBinding{config: conf}
+}
+
+// XMLDecoder returns a decoder reading the request body token by token, enforcing the limits of
+// config, defaulting to DefaultXMLBindingConfig
+// Large documents can be decoded element by element instead of all at once, and the body is
+// read from the connection as it arrives when the server streams request bodies
+//
+//	decoder := c.XMLDecoder()
+//	for {
+//		token, err := decoder.Token()
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "item" {
+//			var item Item
+//			if err := decoder.DecodeElement(&item, &start); err != nil {
+//				return err
+//			}
+//			process(item)
+//		}
+//	}
+func (c *Context) XMLDecoder(config ...XMLBindingConfig) *xml.Decoder {
+	conf := DefaultXMLBindingConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	body := c.requestCtx.RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.requestCtx.Request.Body())
+	}
+	return newXMLDecoder(body, conf)
+}
+
+// newXMLDecoder returns a decoder of r enforcing the limits of config
+func newXMLDecoder(r io.Reader, config XMLBindingConfig) *xml.Decoder {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = xmlCharsetReader
+	if config == (XMLBindingConfig{AllowDTD: true}) {
+		// Nothing to enforce
+		return decoder
+	}
+	return xml.NewTokenDecoder(&xmlLimitReader{decoder: decoder, config: config})
+}
+
+// xmlLimitReader passes the tokens of a decoder through, failing once a limit is exceeded
+type xmlLimitReader struct {
+	decoder *xml.Decoder
+	config  XMLBindingConfig
+	depth   int
+	tokens  int
+}
+
+// Token implements xml.TokenReader
+func (r *xmlLimitReader) Token() (xml.Token, error) {
+	token, err := r.decoder.Token()
+	if err != nil {
+		return token, err
+	}
+	r.tokens++
+	if r.config.MaxTokens > 0 && r.tokens > r.config.MaxTokens {
+		return nil, ErrXMLTooManyTokens
+	}
+	switch token.(type) {
+	case xml.StartElement:
+		r.depth++
+		if r.config.MaxDepth > 0 && r.depth > r.config.MaxDepth {
+			return nil, ErrXMLTooDeep
+		}
+	case xml.EndElement:
+		r.depth--
+	case xml.Directive:
+		if !r.config.AllowDTD {
+			return nil, ErrXMLDirective
+		}
+	}
+	return token, nil
+}
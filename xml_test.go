@@ -0,0 +1,63 @@
+package gonoleks
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewXMLBinding(t *testing.T) {
+	binding := NewXMLBinding()
+	var user bindingUser
+	require.NoError(t, binding.BindBody([]byte(`<user><name>gopher</name><age>13</age></user>`), &user))
+	assert.Equal(t, bindingUser{Name: "gopher", Age: 13}, user)
+
+	laughs := `<?xml version="1.0"?><!DOCTYPE lolz [<!ENTITY lol "lol"><!ENTITY lol2 "&lol;&lol;">]><user><name>&lol2;</name></user>`
+	assert.ErrorIs(t, binding.BindBody([]byte(laughs), &user), ErrXMLDirective)
+	// The default binding keeps accepting DTDs, and fails on the undeclared entity instead
+	err := BindingXML.BindBody([]byte(laughs), &user)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrXMLDirective)
+
+	deep := strings.Repeat("<a>", 101) + strings.Repeat("</a>", 101)
+	assert.ErrorIs(t, binding.BindBody([]byte(deep), &user), ErrXMLTooDeep)
+	assert.NoError(t, NewXMLBinding(XMLBindingConfig{MaxDepth: 101}).BindBody([]byte(deep), &user))
+
+	wide := "<user>" + strings.Repeat("<name>x</name>", 10) + "</user>"
+	err = NewXMLBinding(XMLBindingConfig{MaxTokens: 20}).BindBody([]byte(wide), &user)
+	assert.ErrorIs(t, err, ErrXMLTooManyTokens)
+	var errs BindErrors
+	assert.ErrorAs(t, err, &errs)
+}
+
+func TestXMLDecoder(t *testing.T) {
+	c, requestCtx := createTestContext()
+	requestCtx.Request.SetBodyString(`<users xmlns="urn:users"><user><name>a</name></user><user><name>b</name></user></users>`)
+	decoder := c.XMLDecoder()
+	var names []string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "user" {
+			var user bindingUser
+			require.NoError(t, decoder.DecodeElement(&user, &start))
+			names = append(names, user.Name)
+		}
+	}
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	requestCtx.Request.SetBodyString(`<a><b><c/></b></a>`)
+	decoder = c.XMLDecoder(XMLBindingConfig{MaxDepth: 2})
+	var err error
+	for err == nil {
+		_, err = decoder.Token()
+	}
+	assert.ErrorIs(t, err, ErrXMLTooDeep)
+}